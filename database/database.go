@@ -7,19 +7,64 @@ import (
 	"time"
 
 	_ "github.com/go-sql-driver/mysql" // Pakai driver MySQL
+	_ "github.com/mattn/go-sqlite3"    // Driver SQLite untuk mode single machine
 )
 
-func InitDB(dbUser, dbPass, dbHost, dbPort, dbName string) (*sql.DB, error) {
-	// Format DSN MySQL: user:password@tcp(host:port)/dbname?parseTime=true
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", 
-		dbUser, dbPass, dbHost, dbPort, dbName)
+// Dialect menandai jenis database yang dipakai, supaya repository bisa
+// menyesuaikan SQL yang tidak portable (CURDATE, dsb).
+type Dialect string
 
-	db, err := sql.Open("mysql", dsn)
+const (
+	MySQL  Dialect = "mysql"
+	SQLite Dialect = "sqlite3"
+)
+
+// DB membungkus *sql.DB beserta dialect-nya
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+// ParseDialect mengubah nilai DB_DRIVER menjadi Dialect, default ke MySQL
+func ParseDialect(driver string) (Dialect, error) {
+	switch driver {
+	case "", "mysql":
+		return MySQL, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	default:
+		return "", fmt.Errorf("unsupported DB_DRIVER %q (use mysql or sqlite)", driver)
+	}
+}
+
+// InitDB membuka koneksi sesuai driver. Untuk SQLite, dbName adalah path file database.
+// Jika autoMigrate true, semua migration yang belum diterapkan langsung dijalankan.
+func InitDB(driver, dbUser, dbPass, dbHost, dbPort, dbName string, autoMigrate bool) (*DB, error) {
+	dialect, err := ParseDialect(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	var dsn string
+	switch dialect {
+	case SQLite:
+		// _txlock=immediate supaya write transaction langsung ambil lock (menghindari SQLITE_BUSY saat upgrade lock)
+		dsn = fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate", dbName)
+	default:
+		// Format DSN MySQL: user:password@tcp(host:port)/dbname?parseTime=true
+		// clientFoundRows supaya RowsAffected menghitung baris yang match walau nilainya tidak berubah
+		// (tanpa ini UPDATE dengan data yang sama dianggap "not found")
+		dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&clientFoundRows=true",
+			dbUser, dbPass, dbHost, dbPort, dbName)
+	}
+
+	db, err := sql.Open(string(dialect), dsn)
 	if err != nil {
 		return nil, err
 	}
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -27,6 +72,49 @@ func InitDB(dbUser, dbPass, dbHost, dbPort, dbName string) (*sql.DB, error) {
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(5 * time.Minute)
 
-	log.Println("MySQL Database connected successfully")
-	return db, nil
-}
\ No newline at end of file
+	log.Printf("%s database connected successfully", dialect)
+
+	wrapped := &DB{DB: db, Dialect: dialect}
+	if autoMigrate {
+		if err := wrapped.MigrateUp(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return wrapped, nil
+}
+
+// Today mengembalikan ekspresi SQL untuk tanggal hari ini (waktu lokal)
+func (d Dialect) Today() string {
+	if d == SQLite {
+		return "DATE('now', 'localtime')"
+	}
+	return "CURDATE()"
+}
+
+// DateOf mengembalikan ekspresi SQL untuk mengambil tanggal (waktu lokal) dari kolom timestamp
+func (d Dialect) DateOf(column string) string {
+	if d == SQLite {
+		// CURRENT_TIMESTAMP di SQLite disimpan dalam UTC
+		return fmt.Sprintf("DATE(%s, 'localtime')", column)
+	}
+	return fmt.Sprintf("DATE(%s)", column)
+}
+
+// FormatDate mengembalikan ekspresi SQL tanggal lokal kolom timestamp sebagai
+// string, format memakai spesifier yang sama di kedua database (%Y-%m-%d, %Y-%m)
+func (d Dialect) FormatDate(column, format string) string {
+	if d == SQLite {
+		return fmt.Sprintf("strftime('%s', %s, 'localtime')", format, column)
+	}
+	return fmt.Sprintf("DATE_FORMAT(%s, '%s')", column, format)
+}
+
+// ForUpdate mengembalikan klausa row lock untuk SELECT di dalam transaksi.
+// SQLite tidak punya row lock; write transaction sudah eksklusif karena _txlock=immediate.
+func (d Dialect) ForUpdate() string {
+	if d == SQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
@@ -0,0 +1,315 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File migration disimpan per dialect: migrations/<dialect>/<versi>_<nama>.<up|down>.sql
+//
+//go:embed migrations
+var migrationFS embed.FS
+
+// Migration adalah satu langkah perubahan schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus dipakai oleh subcommand `migrate status`
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+func migrationDir(d Dialect) string {
+	if d == SQLite {
+		return "migrations/sqlite"
+	}
+	return "migrations/mysql"
+}
+
+// LoadMigrations membaca semua migration untuk dialect tertentu, urut berdasarkan versi
+func LoadMigrations(d Dialect) ([]Migration, error) {
+	dir := migrationDir(d)
+	entries, err := fs.ReadDir(migrationFS, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		name := e.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		versionStr, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(migrationFS, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration version %d used by %q and %q", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements memecah script jadi statement tunggal, karena driver MySQL
+// tidak menerima multi statement dalam satu Exec
+func splitStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var stmts []string
+	for _, stmt := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+func (db *DB) ensureMigrationTable() error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`
+	_, err := db.Exec(query)
+	return err
+}
+
+func (db *DB) appliedVersions() (map[int]time.Time, error) {
+	if err := db.ensureMigrationTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// runMigration menjalankan script dan mencatat/menghapus versi di schema_migrations.
+// Versi dicek ulang di dalam transaction, jadi migration yang sudah diterapkan instance
+// lain dilewati (ran false). Catatan: di MySQL statement DDL melakukan implicit commit,
+// jadi rollback hanya efektif di SQLite.
+func (db *DB) runMigration(m Migration, up bool) (ran bool, err error) {
+	script, record, args := m.Up, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", []interface{}{m.Version, m.Name}
+	if !up {
+		script, record, args = m.Down, "DELETE FROM schema_migrations WHERE version = ?", []interface{}{m.Version}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", m.Version).Scan(&count); err != nil {
+		return false, err
+	}
+	if applied := count > 0; applied == up {
+		return false, nil
+	}
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.Exec(stmt); err != nil {
+			return false, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+	if _, err := tx.Exec(record, args...); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Advisory lock MySQL yang dipegang selama migration berjalan
+const (
+	migrationLockName    = "kasir_api_schema_migrations"
+	migrationLockTimeout = 60 // detik
+)
+
+// withMigrationLock menjalankan fn sambil memegang lock migration, supaya dua instance
+// yang start bersamaan tidak menerapkan migration yang sama. MySQL memakai GET_LOCK di
+// satu koneksi khusus; di SQLite write transaction sudah eksklusif (_txlock=immediate)
+// dan runMigration mengecek ulang versinya di dalam transaction.
+func (db *DB) withMigrationLock(fn func() error) error {
+	if db.Dialect == SQLite {
+		return fn()
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockTimeout).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return fmt.Errorf("timed out waiting for migration lock %q", migrationLockName)
+	}
+	defer func() {
+		var released sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName).Scan(&released); err != nil {
+			log.Printf("release migration lock: %v", err)
+		}
+	}()
+	return fn()
+}
+
+// MigrateUp menjalankan semua migration yang belum diterapkan, urut dari versi terkecil
+func (db *DB) MigrateUp() error {
+	migrations, err := LoadMigrations(db.Dialect)
+	if err != nil {
+		return err
+	}
+	return db.withMigrationLock(func() error {
+		applied, err := db.appliedVersions()
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			ran, err := db.runMigration(m, true)
+			if err != nil {
+				return err
+			}
+			if ran {
+				log.Printf("Migration %04d_%s applied", m.Version, m.Name)
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateDown me-rollback sejumlah steps migration terakhir yang sudah diterapkan
+func (db *DB) MigrateDown(steps int) error {
+	migrations, err := LoadMigrations(db.Dialect)
+	if err != nil {
+		return err
+	}
+	return db.withMigrationLock(func() error {
+		applied, err := db.appliedVersions()
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %04d_%s has no down script", m.Version, m.Name)
+			}
+			ran, err := db.runMigration(m, false)
+			if err != nil {
+				return err
+			}
+			if ran {
+				log.Printf("Migration %04d_%s rolled back", m.Version, m.Name)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// MigrationStatuses mengembalikan status setiap migration yang ada di binary
+func (db *DB) MigrationStatuses() ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations(db.Dialect)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := db.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		if at, ok := applied[m.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = &at
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
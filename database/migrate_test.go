@@ -0,0 +1,100 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := "-- komentar\nCREATE TABLE a (id INT);\n\nINSERT INTO a VALUES (1);\n"
+	got := splitStatements(script)
+	if len(got) != 2 || got[0] != "CREATE TABLE a (id INT)" || got[1] != "INSERT INTO a VALUES (1)" {
+		t.Errorf("splitStatements = %q", got)
+	}
+}
+
+func TestLoadMigrationsOrdered(t *testing.T) {
+	for _, d := range []Dialect{MySQL, SQLite} {
+		migrations, err := LoadMigrations(d)
+		if err != nil {
+			t.Fatalf("%s: %v", d, err)
+		}
+		for i, m := range migrations {
+			if m.Up == "" || m.Down == "" {
+				t.Errorf("%s: migration %d missing up or down script", d, m.Version)
+			}
+			if i > 0 && migrations[i-1].Version >= m.Version {
+				t.Errorf("%s: migrations not ordered at %d", d, m.Version)
+			}
+		}
+	}
+}
+
+func TestMigrateUpDownSQLite(t *testing.T) {
+	db, err := InitDB("sqlite", "", "", "", "", filepath.Join(t.TempDir(), "migrate.db"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	migrations, _ := LoadMigrations(SQLite)
+
+	if err := db.MigrateUp(); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+	// idempotent
+	if err := db.MigrateUp(); err != nil {
+		t.Fatalf("second MigrateUp: %v", err)
+	}
+
+	var name string
+	if err := db.QueryRow("SELECT name FROM categories WHERE id = 1").Scan(&name); err != nil || name != "No Category" {
+		t.Fatalf("default category = %q, %v", name, err)
+	}
+
+	if err := db.MigrateDown(len(migrations)); err != nil {
+		t.Fatalf("MigrateDown: %v", err)
+	}
+	statuses, err := db.MigrationStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.Applied {
+			t.Errorf("migration %d still applied after full rollback", s.Version)
+		}
+	}
+	if err := db.QueryRow("SELECT name FROM categories").Scan(&name); err == nil {
+		t.Error("categories table still exists after rollback")
+	}
+}
+
+func TestConcurrentMigrateUpSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "concurrent.db")
+	var dbs []*DB
+	for i := 0; i < 2; i++ {
+		db, err := InitDB("sqlite", "", "", "", "", path, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		dbs = append(dbs, db)
+	}
+
+	// Dua instance yang start bersamaan: migration yang sama tidak boleh dijalankan dua kali
+	errs := make(chan error, len(dbs))
+	for _, db := range dbs {
+		go func() { errs <- db.MigrateUp() }()
+	}
+	for range dbs {
+		if err := <-errs; err != nil {
+			t.Errorf("MigrateUp: %v", err)
+		}
+	}
+
+	migrations, _ := LoadMigrations(SQLite)
+	var count int
+	if err := dbs[0].QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count); err != nil || count != len(migrations) {
+		t.Errorf("schema_migrations rows = %d, %v, want %d", count, err, len(migrations))
+	}
+}
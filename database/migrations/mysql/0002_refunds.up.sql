@@ -0,0 +1,27 @@
+ALTER TABLE transactions
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'completed',
+    ADD COLUMN refunded_amount INT NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_details
+    ADD COLUMN refunded_quantity INT NOT NULL DEFAULT 0;
+
+CREATE TABLE refunds (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    amount INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_refunds_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+CREATE TABLE refund_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    refund_id INT NOT NULL,
+    transaction_detail_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL,
+    amount INT NOT NULL,
+    CONSTRAINT fk_refund_items_refund FOREIGN KEY (refund_id) REFERENCES refunds (id) ON DELETE CASCADE,
+    CONSTRAINT fk_refund_items_detail FOREIGN KEY (transaction_detail_id) REFERENCES transaction_details (id) ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS payments;
+
+ALTER TABLE transactions
+    DROP COLUMN paid_amount,
+    DROP COLUMN change_amount;
@@ -0,0 +1,16 @@
+ALTER TABLE transactions
+    ADD COLUMN paid_amount INT NOT NULL DEFAULT 0,
+    ADD COLUMN change_amount INT NOT NULL DEFAULT 0;
+
+-- Transaksi lama dianggap dibayar pas
+UPDATE transactions SET paid_amount = total_amount;
+
+CREATE TABLE payments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    amount INT NOT NULL,
+    tendered INT NOT NULL,
+    KEY idx_payments_method (method),
+    CONSTRAINT fk_payments_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE
+) ENGINE=InnoDB;
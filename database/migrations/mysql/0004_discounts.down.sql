@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS promo_codes;
+
+ALTER TABLE transaction_details
+    DROP COLUMN discount_amount;
+
+ALTER TABLE transactions
+    DROP COLUMN gross_amount,
+    DROP COLUMN discount_amount,
+    DROP COLUMN promo_code;
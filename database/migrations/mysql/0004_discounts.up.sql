@@ -0,0 +1,24 @@
+ALTER TABLE transactions
+    ADD COLUMN gross_amount INT NOT NULL DEFAULT 0,
+    ADD COLUMN discount_amount INT NOT NULL DEFAULT 0,
+    ADD COLUMN promo_code VARCHAR(50) NULL;
+
+-- Transaksi lama tidak punya diskon
+UPDATE transactions SET gross_amount = total_amount;
+
+ALTER TABLE transaction_details
+    ADD COLUMN discount_amount INT NOT NULL DEFAULT 0;
+
+CREATE TABLE promo_codes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    value INT NOT NULL,
+    min_spend INT NOT NULL DEFAULT 0,
+    usage_limit INT NOT NULL DEFAULT 0,
+    used_count INT NOT NULL DEFAULT 0,
+    valid_from DATETIME NULL,
+    valid_until DATETIME NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    UNIQUE KEY uq_promo_codes_code (code)
+) ENGINE=InnoDB;
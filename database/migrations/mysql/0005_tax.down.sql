@@ -0,0 +1,14 @@
+ALTER TABLE refund_items
+    DROP COLUMN tax_amount;
+
+ALTER TABLE transaction_details
+    DROP COLUMN service_charge,
+    DROP COLUMN tax_amount,
+    DROP COLUMN total;
+
+ALTER TABLE transactions
+    DROP COLUMN subtotal,
+    DROP COLUMN service_charge,
+    DROP COLUMN tax_amount,
+    DROP COLUMN tax_inclusive,
+    DROP COLUMN refunded_tax;
@@ -0,0 +1,18 @@
+ALTER TABLE transactions
+    ADD COLUMN subtotal INT NOT NULL DEFAULT 0,
+    ADD COLUMN service_charge INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN refunded_tax INT NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_details
+    ADD COLUMN service_charge INT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount INT NOT NULL DEFAULT 0,
+    ADD COLUMN total INT NOT NULL DEFAULT 0;
+
+ALTER TABLE refund_items
+    ADD COLUMN tax_amount INT NOT NULL DEFAULT 0;
+
+-- Transaksi lama tanpa pajak dan service charge
+UPDATE transactions SET subtotal = total_amount;
+UPDATE transaction_details SET total = subtotal;
@@ -0,0 +1,21 @@
+CREATE TABLE carts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    reserved_until DATETIME NULL,
+    transaction_id INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_carts_status (status)
+) ENGINE=InnoDB;
+
+CREATE TABLE cart_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    cart_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL,
+    discount_type VARCHAR(10) NULL,
+    discount_value INT NOT NULL DEFAULT 0,
+    UNIQUE KEY uq_cart_items_product (cart_id, product_id),
+    CONSTRAINT fk_cart_items_cart FOREIGN KEY (cart_id) REFERENCES carts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_cart_items_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,9 @@
+-- expires_at disimpan sebagai unix timestamp supaya perbandingannya sama di MySQL dan SQLite
+CREATE TABLE idempotency_keys (
+    idempotency_key VARCHAR(255) NOT NULL PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    response MEDIUMBLOB NULL,
+    expires_at BIGINT NOT NULL,
+    KEY idx_idempotency_keys_expires_at (expires_at)
+) ENGINE=InnoDB;
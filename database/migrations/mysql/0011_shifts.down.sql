@@ -0,0 +1,17 @@
+DELETE FROM role_permissions WHERE permission = 'shift:read';
+
+ALTER TABLE refunds
+    DROP FOREIGN KEY fk_refunds_cashier,
+    DROP FOREIGN KEY fk_refunds_shift;
+ALTER TABLE refunds
+    DROP COLUMN cashier_id,
+    DROP COLUMN shift_id;
+
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_cashier,
+    DROP FOREIGN KEY fk_transactions_shift;
+ALTER TABLE transactions
+    DROP COLUMN cashier_id,
+    DROP COLUMN shift_id;
+
+DROP TABLE IF EXISTS shifts;
@@ -0,0 +1,29 @@
+CREATE TABLE shifts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    opening_cash INT NOT NULL DEFAULT 0,
+    expected_cash INT NULL,
+    counted_cash INT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    opened_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at DATETIME NULL,
+    KEY idx_shifts_user_status (user_id, status),
+    CONSTRAINT fk_shifts_user FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB;
+
+ALTER TABLE transactions
+    ADD COLUMN cashier_id INT NULL,
+    ADD COLUMN shift_id INT NULL,
+    ADD CONSTRAINT fk_transactions_cashier FOREIGN KEY (cashier_id) REFERENCES users (id),
+    ADD CONSTRAINT fk_transactions_shift FOREIGN KEY (shift_id) REFERENCES shifts (id);
+
+-- Refund dibayar dari laci shift yang sedang berjalan, bukan shift transaksi aslinya
+ALTER TABLE refunds
+    ADD COLUMN cashier_id INT NULL,
+    ADD COLUMN shift_id INT NULL,
+    ADD CONSTRAINT fk_refunds_cashier FOREIGN KEY (cashier_id) REFERENCES users (id),
+    ADD CONSTRAINT fk_refunds_shift FOREIGN KEY (shift_id) REFERENCES shifts (id);
+
+-- Laporan rekonsiliasi shift untuk owner dan manager
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'shift:read' FROM roles WHERE name IN ('owner', 'manager');
@@ -0,0 +1,17 @@
+-- Append-only: aplikasi hanya INSERT, tidak pernah UPDATE/DELETE.
+-- actor_id tanpa foreign key supaya riwayat tetap ada walaupun user dihapus.
+CREATE TABLE audit_logs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    actor_id INT NULL,
+    entity VARCHAR(30) NOT NULL,
+    entity_id INT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    before_data LONGTEXT NULL,
+    after_data LONGTEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_audit_logs_entity (entity, entity_id),
+    KEY idx_audit_logs_actor (actor_id),
+    KEY idx_audit_logs_created_at (created_at)
+) ENGINE=InnoDB;
+
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'audit:read' FROM roles WHERE name = 'owner';
@@ -0,0 +1,20 @@
+-- Ledger stock: setiap perubahan products.stock dicatat di sini di dalam DB
+-- transaction yang sama, jadi SUM(quantity) per produk selalu sama dengan stock.
+-- product_id dan actor_id tanpa foreign key supaya riwayat tetap ada walaupun
+-- produk atau user dihapus.
+CREATE TABLE stock_movements (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    product_id INT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    quantity INT NOT NULL,
+    reference_id INT NULL,
+    actor_id INT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_stock_movements_product (product_id, id),
+    KEY idx_stock_movements_created_at (created_at)
+) ENGINE=InnoDB;
+
+-- Saldo awal untuk produk yang sudah ada
+INSERT INTO stock_movements (product_id, type, quantity, note)
+SELECT id, 'adjustment', stock, 'opening balance' FROM products WHERE stock <> 0;
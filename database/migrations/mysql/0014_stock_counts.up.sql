@@ -0,0 +1,26 @@
+CREATE TABLE stock_counts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_by INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_by INT NULL,
+    closed_at DATETIME NULL,
+    KEY idx_stock_counts_status (status)
+) ENGINE=InnoDB;
+
+-- Satu baris per produk per sesi; hitungan yang dikirim ulang menggantikan baris lama
+CREATE TABLE stock_count_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    stock_count_id INT NOT NULL,
+    product_id INT NOT NULL,
+    system_stock INT NOT NULL,
+    counted_quantity INT NOT NULL,
+    counted_by INT NULL,
+    counted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_stock_count_items_product (stock_count_id, product_id),
+    CONSTRAINT fk_stock_count_items_count FOREIGN KEY (stock_count_id) REFERENCES stock_counts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_count_items_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'stock:count' FROM roles WHERE name IN ('owner', 'manager');
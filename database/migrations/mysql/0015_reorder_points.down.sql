@@ -0,0 +1,3 @@
+ALTER TABLE products
+    DROP COLUMN reorder_point,
+    DROP COLUMN reorder_quantity;
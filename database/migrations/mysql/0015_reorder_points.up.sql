@@ -0,0 +1,3 @@
+ALTER TABLE products
+    ADD COLUMN reorder_point INT NOT NULL DEFAULT 0,
+    ADD COLUMN reorder_quantity INT NOT NULL DEFAULT 0;
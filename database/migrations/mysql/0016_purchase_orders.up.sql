@@ -0,0 +1,53 @@
+CREATE TABLE suppliers (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_suppliers_name (name)
+) ENGINE=InnoDB;
+
+CREATE TABLE purchase_orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    supplier_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_by INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approved_by INT NULL,
+    approved_at DATETIME NULL,
+    closed_by INT NULL,
+    closed_at DATETIME NULL,
+    KEY idx_purchase_orders_status (status),
+    CONSTRAINT fk_purchase_orders_supplier FOREIGN KEY (supplier_id) REFERENCES suppliers (id)
+) ENGINE=InnoDB;
+
+-- Produk di PO tidak boleh dihapus selama PO-nya masih ada
+CREATE TABLE purchase_order_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    purchase_order_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL,
+    unit_cost INT NOT NULL,
+    received_quantity INT NOT NULL DEFAULT 0,
+    UNIQUE KEY uq_purchase_order_items_product (purchase_order_id, product_id),
+    CONSTRAINT fk_purchase_order_items_order FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_purchase_order_items_product FOREIGN KEY (product_id) REFERENCES products (id)
+) ENGINE=InnoDB;
+
+-- Satu baris per produk per penerimaan barang (batch), dengan harga beli batch tersebut
+CREATE TABLE purchase_receipts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    purchase_order_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL,
+    unit_cost INT NOT NULL,
+    received_by INT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_purchase_receipts_product (product_id, id),
+    CONSTRAINT fk_purchase_receipts_order FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_purchase_receipts_product FOREIGN KEY (product_id) REFERENCES products (id)
+) ENGINE=InnoDB;
+
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'purchase:manage' FROM roles WHERE name IN ('owner', 'manager');
@@ -0,0 +1,2 @@
+ALTER TABLE transaction_details DROP COLUMN unit_cost;
+ALTER TABLE products DROP COLUMN cost_price;
@@ -0,0 +1,12 @@
+-- Bagian refund yang dibayar tunai dari laci; sisanya dikembalikan lewat metode non-tunai
+ALTER TABLE refunds ADD COLUMN cash_amount INT NOT NULL DEFAULT 0;
+
+-- Refund lama dibagi proporsional terhadap pembayaran cash transaksinya.
+-- Transaksi tanpa data pembayaran dianggap dibayar tunai.
+UPDATE refunds r
+LEFT JOIN (
+    SELECT transaction_id, SUM(amount) AS paid, SUM(CASE WHEN method = 'cash' THEN amount ELSE 0 END) AS cash
+    FROM payments
+    GROUP BY transaction_id
+) py ON py.transaction_id = r.transaction_id
+SET r.cash_amount = CASE WHEN py.paid IS NULL OR py.paid = 0 THEN r.amount ELSE r.amount * py.cash DIV py.paid END;
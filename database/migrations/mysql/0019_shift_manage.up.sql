@@ -0,0 +1,2 @@
+-- Owner dan manager bisa menutup shift kasir lain yang lupa ditutup
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'shift:manage' FROM roles WHERE name IN ('owner', 'manager');
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE transaction_details DROP COLUMN refunded_quantity;
+
+ALTER TABLE transactions DROP COLUMN refunded_amount;
+ALTER TABLE transactions DROP COLUMN status;
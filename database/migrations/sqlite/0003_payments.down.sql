@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS payments;
+
+ALTER TABLE transactions DROP COLUMN change_amount;
+ALTER TABLE transactions DROP COLUMN paid_amount;
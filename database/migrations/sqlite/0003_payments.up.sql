@@ -0,0 +1,15 @@
+ALTER TABLE transactions ADD COLUMN paid_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN change_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Transaksi lama dianggap dibayar pas
+UPDATE transactions SET paid_amount = total_amount;
+
+CREATE TABLE payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    method TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    tendered INTEGER NOT NULL
+);
+
+CREATE INDEX idx_payments_method ON payments (method);
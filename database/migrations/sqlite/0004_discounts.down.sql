@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS promo_codes;
+
+ALTER TABLE transaction_details DROP COLUMN discount_amount;
+
+ALTER TABLE transactions DROP COLUMN promo_code;
+ALTER TABLE transactions DROP COLUMN discount_amount;
+ALTER TABLE transactions DROP COLUMN gross_amount;
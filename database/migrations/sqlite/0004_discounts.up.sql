@@ -0,0 +1,21 @@
+ALTER TABLE transactions ADD COLUMN gross_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN promo_code TEXT NULL;
+
+-- Transaksi lama tidak punya diskon
+UPDATE transactions SET gross_amount = total_amount;
+
+ALTER TABLE transaction_details ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE promo_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE,
+    type TEXT NOT NULL,
+    value INTEGER NOT NULL,
+    min_spend INTEGER NOT NULL DEFAULT 0,
+    usage_limit INTEGER NOT NULL DEFAULT 0,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    valid_from DATETIME NULL,
+    valid_until DATETIME NULL,
+    active BOOLEAN NOT NULL DEFAULT 1
+);
@@ -0,0 +1,11 @@
+ALTER TABLE refund_items DROP COLUMN tax_amount;
+
+ALTER TABLE transaction_details DROP COLUMN total;
+ALTER TABLE transaction_details DROP COLUMN tax_amount;
+ALTER TABLE transaction_details DROP COLUMN service_charge;
+
+ALTER TABLE transactions DROP COLUMN refunded_tax;
+ALTER TABLE transactions DROP COLUMN tax_inclusive;
+ALTER TABLE transactions DROP COLUMN tax_amount;
+ALTER TABLE transactions DROP COLUMN service_charge;
+ALTER TABLE transactions DROP COLUMN subtotal;
@@ -0,0 +1,15 @@
+ALTER TABLE transactions ADD COLUMN subtotal INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN service_charge INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN refunded_tax INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE transaction_details ADD COLUMN service_charge INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN total INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE refund_items ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Transaksi lama tanpa pajak dan service charge
+UPDATE transactions SET subtotal = total_amount;
+UPDATE transaction_details SET total = subtotal;
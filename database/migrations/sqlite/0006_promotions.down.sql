@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transaction_promotions;
+DROP TABLE IF EXISTS promotion_items;
+DROP TABLE IF EXISTS promotions;
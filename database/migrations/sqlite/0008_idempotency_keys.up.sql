@@ -0,0 +1,10 @@
+-- expires_at disimpan sebagai unix timestamp supaya perbandingannya sama di MySQL dan SQLite
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT NOT NULL PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response BLOB NULL,
+    expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,32 @@
+CREATE TABLE roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission TEXT NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+CREATE TABLE user_roles (
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+
+-- Role bawaan; permission-nya bisa diubah lewat PUT /api/roles/{id}
+INSERT INTO roles (id, name) VALUES (1, 'owner'), (2, 'manager'), (3, 'cashier');
+
+INSERT INTO role_permissions (role_id, permission) VALUES
+    (1, 'product:read'), (1, 'product:write'), (1, 'category:read'), (1, 'category:write'),
+    (1, 'transaction:create'), (1, 'transaction:read'), (1, 'transaction:refund'), (1, 'report:read'),
+    (1, 'promo:read'), (1, 'promo:write'), (1, 'user:manage'),
+    (2, 'product:read'), (2, 'product:write'), (2, 'category:read'), (2, 'category:write'),
+    (2, 'transaction:create'), (2, 'transaction:read'), (2, 'transaction:refund'),
+    (2, 'promo:read'), (2, 'promo:write'),
+    (3, 'product:read'), (3, 'category:read'), (3, 'transaction:create'), (3, 'transaction:read'),
+    (3, 'promo:read');
+
+-- Sebelum ada role semua user bisa mengakses semua route, jadi user lama menjadi owner
+INSERT INTO user_roles (user_id, role_id) SELECT id, 1 FROM users;
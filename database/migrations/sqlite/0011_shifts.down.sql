@@ -0,0 +1,11 @@
+DELETE FROM role_permissions WHERE permission = 'shift:read';
+
+DROP INDEX IF EXISTS idx_refunds_shift;
+ALTER TABLE refunds DROP COLUMN shift_id;
+ALTER TABLE refunds DROP COLUMN cashier_id;
+
+DROP INDEX IF EXISTS idx_transactions_shift;
+ALTER TABLE transactions DROP COLUMN shift_id;
+ALTER TABLE transactions DROP COLUMN cashier_id;
+
+DROP TABLE IF EXISTS shifts;
@@ -0,0 +1,26 @@
+CREATE TABLE shifts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id),
+    status TEXT NOT NULL DEFAULT 'open',
+    opening_cash INTEGER NOT NULL DEFAULT 0,
+    expected_cash INTEGER NULL,
+    counted_cash INTEGER NULL,
+    note TEXT NOT NULL DEFAULT '',
+    opened_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at DATETIME NULL
+);
+
+CREATE INDEX idx_shifts_user_status ON shifts (user_id, status);
+
+-- SQLite tidak bisa DROP COLUMN yang punya REFERENCES, jadi kolom baru tanpa foreign key
+ALTER TABLE transactions ADD COLUMN cashier_id INTEGER NULL;
+ALTER TABLE transactions ADD COLUMN shift_id INTEGER NULL;
+CREATE INDEX idx_transactions_shift ON transactions (shift_id);
+
+-- Refund dibayar dari laci shift yang sedang berjalan, bukan shift transaksi aslinya
+ALTER TABLE refunds ADD COLUMN cashier_id INTEGER NULL;
+ALTER TABLE refunds ADD COLUMN shift_id INTEGER NULL;
+CREATE INDEX idx_refunds_shift ON refunds (shift_id);
+
+-- Laporan rekonsiliasi shift untuk owner dan manager
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'shift:read' FROM roles WHERE name IN ('owner', 'manager');
@@ -0,0 +1,18 @@
+-- Append-only: aplikasi hanya INSERT, tidak pernah UPDATE/DELETE.
+-- actor_id tanpa foreign key supaya riwayat tetap ada walaupun user dihapus.
+CREATE TABLE audit_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id INTEGER NULL,
+    entity TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    before_data TEXT NULL,
+    after_data TEXT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_logs_entity ON audit_logs (entity, entity_id);
+CREATE INDEX idx_audit_logs_actor ON audit_logs (actor_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
+
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'audit:read' FROM roles WHERE name = 'owner';
@@ -0,0 +1,21 @@
+-- Ledger stock: setiap perubahan products.stock dicatat di sini di dalam DB
+-- transaction yang sama, jadi SUM(quantity) per produk selalu sama dengan stock.
+-- product_id dan actor_id tanpa foreign key supaya riwayat tetap ada walaupun
+-- produk atau user dihapus.
+CREATE TABLE stock_movements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    reference_id INTEGER NULL,
+    actor_id INTEGER NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stock_movements_product ON stock_movements (product_id, id);
+CREATE INDEX idx_stock_movements_created_at ON stock_movements (created_at);
+
+-- Saldo awal untuk produk yang sudah ada
+INSERT INTO stock_movements (product_id, type, quantity, note)
+SELECT id, 'adjustment', stock, 'opening balance' FROM products WHERE stock <> 0;
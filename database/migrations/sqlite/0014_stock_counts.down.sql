@@ -0,0 +1,4 @@
+DELETE FROM role_permissions WHERE permission = 'stock:count';
+
+DROP TABLE IF EXISTS stock_count_items;
+DROP TABLE IF EXISTS stock_counts;
@@ -0,0 +1,25 @@
+CREATE TABLE stock_counts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    status TEXT NOT NULL DEFAULT 'open',
+    note TEXT NOT NULL DEFAULT '',
+    created_by INTEGER NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_by INTEGER NULL,
+    closed_at DATETIME NULL
+);
+
+CREATE INDEX idx_stock_counts_status ON stock_counts (status);
+
+-- Satu baris per produk per sesi; hitungan yang dikirim ulang menggantikan baris lama
+CREATE TABLE stock_count_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    stock_count_id INTEGER NOT NULL REFERENCES stock_counts (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    system_stock INTEGER NOT NULL,
+    counted_quantity INTEGER NOT NULL,
+    counted_by INTEGER NULL,
+    counted_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (stock_count_id, product_id)
+);
+
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'stock:count' FROM roles WHERE name IN ('owner', 'manager');
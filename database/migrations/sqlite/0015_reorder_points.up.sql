@@ -0,0 +1,2 @@
+ALTER TABLE products ADD COLUMN reorder_point INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN reorder_quantity INTEGER NOT NULL DEFAULT 0;
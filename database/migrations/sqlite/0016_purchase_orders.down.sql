@@ -0,0 +1,6 @@
+DELETE FROM role_permissions WHERE permission = 'purchase:manage';
+
+DROP TABLE IF EXISTS purchase_receipts;
+DROP TABLE IF EXISTS purchase_order_items;
+DROP TABLE IF EXISTS purchase_orders;
+DROP TABLE IF EXISTS suppliers;
@@ -0,0 +1,51 @@
+CREATE TABLE suppliers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    phone TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE purchase_orders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    supplier_id INTEGER NOT NULL REFERENCES suppliers (id),
+    status TEXT NOT NULL DEFAULT 'draft',
+    note TEXT NOT NULL DEFAULT '',
+    created_by INTEGER NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approved_by INTEGER NULL,
+    approved_at DATETIME NULL,
+    closed_by INTEGER NULL,
+    closed_at DATETIME NULL
+);
+
+CREATE INDEX idx_purchase_orders_status ON purchase_orders (status);
+CREATE INDEX idx_purchase_orders_supplier ON purchase_orders (supplier_id);
+
+-- Produk di PO tidak boleh dihapus selama PO-nya masih ada
+CREATE TABLE purchase_order_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products (id),
+    quantity INTEGER NOT NULL,
+    unit_cost INTEGER NOT NULL,
+    received_quantity INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (purchase_order_id, product_id)
+);
+
+-- Satu baris per produk per penerimaan barang (batch), dengan harga beli batch tersebut
+CREATE TABLE purchase_receipts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    purchase_order_id INTEGER NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products (id),
+    quantity INTEGER NOT NULL,
+    unit_cost INTEGER NOT NULL,
+    received_by INTEGER NULL,
+    received_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_purchase_receipts_order ON purchase_receipts (purchase_order_id);
+CREATE INDEX idx_purchase_receipts_product ON purchase_receipts (product_id, id);
+
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'purchase:manage' FROM roles WHERE name IN ('owner', 'manager');
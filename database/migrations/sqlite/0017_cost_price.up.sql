@@ -0,0 +1,8 @@
+ALTER TABLE products ADD COLUMN cost_price INTEGER NOT NULL DEFAULT 0;
+-- NULL berarti HPP tidak diketahui: transaksi sebelum migrasi ini tidak mencatat
+-- harga pokok saat jual, jadi tidak diisi dengan harga pokok sekarang
+ALTER TABLE transaction_details ADD COLUMN unit_cost INTEGER;
+
+-- Harga pokok awal diambil dari penerimaan barang terakhir
+UPDATE products
+SET cost_price = IFNULL((SELECT r.unit_cost FROM purchase_receipts r WHERE r.product_id = products.id ORDER BY r.id DESC LIMIT 1), 0);
@@ -0,0 +1,10 @@
+-- Bagian refund yang dibayar tunai dari laci; sisanya dikembalikan lewat metode non-tunai
+ALTER TABLE refunds ADD COLUMN cash_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Refund lama dibagi proporsional terhadap pembayaran cash transaksinya.
+-- Transaksi tanpa data pembayaran dianggap dibayar tunai.
+UPDATE refunds
+SET cash_amount = IFNULL(
+    amount * (SELECT SUM(CASE WHEN method = 'cash' THEN amount ELSE 0 END) FROM payments WHERE transaction_id = refunds.transaction_id)
+        / NULLIF((SELECT SUM(amount) FROM payments WHERE transaction_id = refunds.transaction_id), 0),
+    amount);
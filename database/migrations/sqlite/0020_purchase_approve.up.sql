@@ -0,0 +1,2 @@
+-- Approve purchase order dipisah dari purchase:manage, hanya owner dan manager
+INSERT INTO role_permissions (role_id, permission) SELECT id, 'purchase:approve' FROM roles WHERE name IN ('owner', 'manager');
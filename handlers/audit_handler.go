@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+	"net/http"
+)
+
+type AuditHandler struct {
+	service services.AuditService
+}
+
+func NewAuditHandler(service services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// HandleAudit -> GET /api/audit?entity=&entity_id=&actor_id=&action=&start_date=&end_date=&page=&limit=
+func (h *AuditHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		Entity:    query.Get("entity"),
+		Action:    query.Get("action"),
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+	}
+	for key, dst := range map[string]*int{"entity_id": &filter.EntityID, "actor_id": &filter.ActorID, "page": &filter.Page, "limit": &filter.Limit} {
+		v, err := queryInt(r, key)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if v != nil {
+			*dst = *v
+		}
+	}
+
+	page, err := h.service.List(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestAuditHandler(t *testing.T) {
+	h := newAuthMux(t)
+	owner := login(t, h, "admin", "rahasia123")
+	if rec := doAuthRequest(t, h, owner.Token, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create product status = %d", rec.Code)
+	}
+	if rec := doAuthRequest(t, h, owner.Token, http.MethodPut, "/api/products/1", `{"name":"Teh","price":6000,"stock":10,"category_id":1}`); rec.Code != http.StatusOK {
+		t.Fatalf("update product status = %d, body %s", rec.Code, rec.Body)
+	}
+	manager := createUserWithRole(t, h, owner.Token, "manajer", "manager")
+
+	rec := doAuthRequest(t, h, owner.Token, http.MethodGet, "/api/audit?entity=product&action=update", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("audit status = %d, body %s", rec.Code, rec.Body)
+	}
+	var page models.AuditPage
+	decode(t, rec, &page)
+	if page.Total != 1 || page.Page != 1 || page.Limit != 20 {
+		t.Fatalf("audit page = %+v", page)
+	}
+	e := page.Data[0]
+	if e.EntityID != 1 || e.ActorID == nil || *e.ActorID != owner.User.ID || e.ActorUsername != "admin" {
+		t.Errorf("audit entry = %+v", e)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		want   int
+	}{
+		{"manager forbidden", manager, http.MethodGet, "/api/audit", http.StatusForbidden},
+		{"invalid entity", owner.Token, http.MethodGet, "/api/audit?entity=user", http.StatusBadRequest},
+		{"invalid entity ID", owner.Token, http.MethodGet, "/api/audit?entity_id=abc", http.StatusBadRequest},
+		{"invalid date range", owner.Token, http.MethodGet, "/api/audit?start_date=2025-02-01&end_date=2025-01-01", http.StatusBadRequest},
+		{"method", owner.Token, http.MethodPost, "/api/audit", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doAuthRequest(t, h, tt.token, tt.method, tt.path, ""); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
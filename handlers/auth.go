@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+)
+
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// UserFromContext mengembalikan user yang sudah login; ok false untuk route publik
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*models.User)
+	return user, ok
+}
+
+// currentUserID mengembalikan ID user yang login, 0 kalau request tanpa login
+func currentUserID(r *http.Request) int {
+	if user, ok := UserFromContext(r.Context()); ok {
+		return user.ID
+	}
+	return 0
+}
+
+// bearerToken mengambil token dari header "Authorization: Bearer <token>"
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// RequireAuth membungkus seluruh mux: setiap request wajib membawa token yang
+// masih berlaku, kecuali path di publicPaths (misalnya login). User yang login
+// disimpan di context request, ambil dengan UserFromContext.
+func RequireAuth(auth services.AuthService, next http.Handler, publicPaths ...string) http.Handler {
+	public := make(map[string]bool, len(publicPaths))
+	for _, p := range publicPaths {
+		public[p] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if public[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := auth.Authenticate(bearerToken(r))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey, user)))
+	})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+	"net/http"
+)
+
+type AuthHandler struct {
+	service services.AuthService
+}
+
+func NewAuthHandler(service services.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// HandleLogin -> POST /api/auth/login (publik)
+func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	var req models.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	token, err := h.service.Login(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, token)
+}
+
+// HandleRefresh -> POST /api/auth/refresh, token lama tidak berlaku lagi setelahnya
+func (h *AuthHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	token, err := h.service.Refresh(bearerToken(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, token)
+}
+
+// HandleLogout -> POST /api/auth/logout, mencabut token yang dipakai
+func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	if err := h.service.Logout(bearerToken(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
+}
+
+// HandleMe -> GET /api/auth/me, user pemilik token
+func (h *AuthHandler) HandleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	user, ok := UserFromContext(r.Context())
+	if !ok {
+		writeError(w, services.NewUnauthorizedError("authentication required"))
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
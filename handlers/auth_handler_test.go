@@ -0,0 +1,131 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/handlers"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+// newAuthMux adalah newTestMux yang dibungkus RequireAuth dan RequirePermission
+// seperti di main.go, dengan satu user admin (owner) yang sudah terdaftar
+func newAuthMux(t *testing.T) http.Handler {
+	t.Helper()
+	mux, store := newTestMux()
+	users, sessions, roles := memory.NewUserRepository(store), memory.NewSessionRepository(store), memory.NewRoleRepository(store)
+	if _, err := services.NewUserService(users, sessions, roles, testHasher).EnsureAdmin("admin", "rahasia123"); err != nil {
+		t.Fatal(err)
+	}
+	authz := handlers.RequirePermission(services.NewRoleService(roles, users), handlers.RoutePermissions, mux)
+	return handlers.RequireAuth(services.NewAuthService(users, sessions, time.Hour), authz, "/api/auth/login")
+}
+
+func doAuthRequest(t *testing.T, h http.Handler, token, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func login(t *testing.T, h http.Handler, username, password string) models.AuthToken {
+	t.Helper()
+	rec := doRequest(t, h, http.MethodPost, "/api/auth/login", `{"username":"`+username+`","password":"`+password+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login status = %d, body %s", rec.Code, rec.Body)
+	}
+	var token models.AuthToken
+	decode(t, rec, &token)
+	return token
+}
+
+func TestRequireAuth(t *testing.T) {
+	h := newAuthMux(t)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"no token", ""},
+		{"unknown token", "bukan-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doAuthRequest(t, h, tt.token, http.MethodGet, "/api/products", "")
+			if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Fatalf("status = %d, want 401 with WWW-Authenticate", rec.Code)
+			}
+			assertErrorCode(t, rec, "UNAUTHORIZED")
+		})
+	}
+
+	rec := doRequest(t, h, http.MethodPost, "/api/auth/login", `{"username":"admin","password":"salah12345"}`)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password status = %d, want 401", rec.Code)
+	}
+
+	token := login(t, h, "admin", "rahasia123")
+	if rec := doAuthRequest(t, h, token.Token, http.MethodGet, "/api/products", ""); rec.Code != http.StatusOK {
+		t.Errorf("authenticated status = %d, want 200", rec.Code)
+	}
+	var me models.User
+	decode(t, doAuthRequest(t, h, token.Token, http.MethodGet, "/api/auth/me", ""), &me)
+	if me.Username != "admin" {
+		t.Errorf("me = %+v, want admin", me)
+	}
+}
+
+func TestAuthRefreshAndLogout(t *testing.T) {
+	h := newAuthMux(t)
+	token := login(t, h, "admin", "rahasia123")
+
+	rec := doAuthRequest(t, h, token.Token, http.MethodPost, "/api/auth/refresh", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh status = %d, body %s", rec.Code, rec.Body)
+	}
+	var refreshed models.AuthToken
+	decode(t, rec, &refreshed)
+	if rec := doAuthRequest(t, h, token.Token, http.MethodGet, "/api/products", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("old token status = %d, want 401", rec.Code)
+	}
+
+	if rec := doAuthRequest(t, h, refreshed.Token, http.MethodPost, "/api/auth/logout", ""); rec.Code != http.StatusOK {
+		t.Fatalf("logout status = %d", rec.Code)
+	}
+	if rec := doAuthRequest(t, h, refreshed.Token, http.MethodGet, "/api/products", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("token after logout status = %d, want 401", rec.Code)
+	}
+}
+
+func TestUserHandler(t *testing.T) {
+	h := newAuthMux(t)
+	admin := login(t, h, "admin", "rahasia123")
+
+	rec := doAuthRequest(t, h, admin.Token, http.MethodPost, "/api/users", `{"username":"kasir1","name":"Kasir","password":"kasir12345"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "password") || strings.Contains(body, "kasir12345") {
+		t.Errorf("response leaks password: %s", body)
+	}
+	var user models.User
+	decode(t, rec, &user)
+	if !user.Active {
+		t.Errorf("user = %+v, want active by default", user)
+	}
+
+	rec = doAuthRequest(t, h, admin.Token, http.MethodPost, "/api/users", `{"username":"KASIR1","password":"kasir12345"}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("duplicate username status = %d, want 409", rec.Code)
+	}
+	login(t, h, "kasir1", "kasir12345")
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+)
+
+// RoutePermission adalah permission yang dibutuhkan sebuah route. Pattern
+// mengikuti http.ServeMux: akhiran "/" berarti semua path di bawahnya dan
+// segment "{name}" cocok dengan satu segment apa saja.
+// Read untuk GET, Write untuk method lain; kosong berarti cukup login.
+type RoutePermission struct {
+	Pattern string
+	Read    string
+	Write   string
+}
+
+// RoutePermissions memetakan route di main.go ke permission
+var RoutePermissions = []RoutePermission{
+	{"/api/categories", models.PermCategoryRead, models.PermCategoryWrite},
+	{"/api/categories/", models.PermCategoryRead, models.PermCategoryWrite},
+	{"/api/products", models.PermProductRead, models.PermProductWrite},
+	{"/api/products/", models.PermProductRead, models.PermProductWrite},
+	{"/api/products/{id}/adjust-stock", models.PermProductRead, models.PermStockCount}, // koreksi stock ikut hak stock opname
+	{"/api/checkout", models.PermTransactionCreate, models.PermTransactionCreate},
+	{"/api/checkout/", models.PermTransactionCreate, models.PermTransactionCreate},
+	{"/api/transactions", models.PermTransactionRead, models.PermTransactionRead},
+	{"/api/transactions/", models.PermTransactionRead, models.PermTransactionRefund}, // POST void & refund
+	{"/api/report", models.PermReportRead, models.PermReportRead},
+	{"/api/report/", models.PermReportRead, models.PermReportRead},
+	{"/api/promo-codes", models.PermPromoRead, models.PermPromoWrite},
+	{"/api/promo-codes/", models.PermPromoRead, models.PermPromoWrite},
+	{"/api/promotions", models.PermPromoRead, models.PermPromoWrite},
+	{"/api/promotions/", models.PermPromoRead, models.PermPromoWrite},
+	{"/api/carts", models.PermTransactionCreate, models.PermTransactionCreate},
+	{"/api/carts/", models.PermTransactionCreate, models.PermTransactionCreate},
+	{"/api/shifts", models.PermShiftRead, models.PermTransactionCreate},  // GET semua shift, POST buka shift sendiri
+	{"/api/shifts/", models.PermShiftRead, models.PermTransactionCreate}, // GET report
+	{"/api/shifts/current", models.PermTransactionCreate, models.PermTransactionCreate},
+	{"/api/shifts/{id}/close", models.PermShiftRead, ""}, // pemilik shift atau shift:manage, dicek di service
+	{"/api/audit", models.PermAuditRead, models.PermAuditRead},
+	{"/api/stock-counts", models.PermStockCount, models.PermStockCount},
+	{"/api/stock-counts/", models.PermStockCount, models.PermStockCount},
+	{"/api/suppliers", models.PermPurchase, models.PermPurchase},
+	{"/api/suppliers/", models.PermPurchase, models.PermPurchase},
+	{"/api/purchase-orders", models.PermPurchase, models.PermPurchase},
+	{"/api/purchase-orders/", models.PermPurchase, models.PermPurchase},
+	{"/api/purchase-orders/{id}/approve", models.PermPurchase, models.PermPurchaseApprove},
+	{"/api/auth/", "", ""},
+	{"/api/users", models.PermUserManage, models.PermUserManage},
+	{"/api/users/", models.PermUserManage, models.PermUserManage},
+	{"/api/roles", models.PermUserManage, models.PermUserManage},
+	{"/api/roles/", models.PermUserManage, models.PermUserManage},
+}
+
+// matchRoute mencari rule dengan pattern terpanjang yang cocok, seperti ServeMux
+func matchRoute(rules []RoutePermission, path string) (RoutePermission, bool) {
+	var best RoutePermission
+	found := false
+	for _, rule := range rules {
+		if routeMatches(rule.Pattern, path) && (!found || len(rule.Pattern) > len(best.Pattern)) {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
+
+// routeMatches membandingkan pattern dan path per segment
+func routeMatches(pattern, path string) bool {
+	subtree := strings.HasSuffix(pattern, "/")
+	want := strings.Split(strings.TrimSuffix(pattern, "/"), "/")
+	got := strings.Split(path, "/")
+	if len(got) < len(want) || len(got) == len(want) && subtree || len(got) > len(want) && !subtree {
+		return false
+	}
+	for i, seg := range want {
+		wildcard := strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+		if wildcard && got[i] == "" || !wildcard && got[i] != seg {
+			return false
+		}
+	}
+	return true
+}
+
+// RequirePermission mengecek permission user (dari RequireAuth) sesuai rules
+// sebelum request diteruskan. Path yang tidak ada di rules ditolak 403 supaya
+// route baru tidak terbuka tanpa sengaja.
+func RequirePermission(roles services.RoleService, rules []RoutePermission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule, ok := matchRoute(rules, r.URL.Path)
+		if !ok {
+			writeError(w, services.NewForbiddenError("no permission is configured for this route"))
+			return
+		}
+		permission := rule.Write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			permission = rule.Read
+		}
+		if permission == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, ok := UserFromContext(r.Context())
+		if !ok {
+			writeError(w, services.NewUnauthorizedError("authentication required"))
+			return
+		}
+		if err := roles.Authorize(user.ID, permission); err != nil {
+			writeError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
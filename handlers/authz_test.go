@@ -0,0 +1,140 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+// createUserWithRole membuat user lewat API sebagai owner lalu login sebagai user itu
+func createUserWithRole(t *testing.T, h http.Handler, ownerToken, username, role string) string {
+	t.Helper()
+	rec := doAuthRequest(t, h, ownerToken, http.MethodPost, "/api/users", `{"username":"`+username+`","password":"rahasia123"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create user status = %d, body %s", rec.Code, rec.Body)
+	}
+	var user models.User
+	decode(t, rec, &user)
+	rec = doAuthRequest(t, h, ownerToken, http.MethodPut, "/api/users/"+strconv.Itoa(user.ID)+"/roles", `{"roles":["`+role+`"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("assign role status = %d, body %s", rec.Code, rec.Body)
+	}
+	return login(t, h, username, "rahasia123").Token
+}
+
+func TestRoutePermissions(t *testing.T) {
+	h := newAuthMux(t)
+	owner := login(t, h, "admin", "rahasia123").Token
+	if rec := doAuthRequest(t, h, owner, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`); rec.Code != http.StatusCreated {
+		t.Fatalf("owner create product status = %d", rec.Code)
+	}
+	cashier := createUserWithRole(t, h, owner, "kasir", "cashier")
+	manager := createUserWithRole(t, h, owner, "manajer", "manager")
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"cashier reads products", cashier, http.MethodGet, "/api/products", "", http.StatusOK},
+		{"cashier opens shift", cashier, http.MethodPost, "/api/shifts", `{"opening_cash":100000}`, http.StatusCreated},
+		{"cashier current shift", cashier, http.MethodGet, "/api/shifts/current", "", http.StatusOK},
+		{"cashier lists shifts", cashier, http.MethodGet, "/api/shifts", "", http.StatusForbidden},
+		{"cashier checkout", cashier, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":1}]}`, http.StatusOK},
+		{"cashier changes price", cashier, http.MethodPut, "/api/products/1", `{"name":"Teh","price":1,"stock":10}`, http.StatusForbidden},
+		{"cashier deletes category", cashier, http.MethodDelete, "/api/categories/1", "", http.StatusForbidden},
+		{"cashier voids", cashier, http.MethodPost, "/api/transactions/1/void", `{"reason":"salah"}`, http.StatusForbidden},
+		{"cashier report", cashier, http.MethodGet, "/api/report/hari-ini", "", http.StatusForbidden},
+		{"cashier manages users", cashier, http.MethodGet, "/api/users", "", http.StatusForbidden},
+		{"cashier own profile", cashier, http.MethodGet, "/api/auth/me", "", http.StatusOK},
+		{"manager changes price", manager, http.MethodPut, "/api/products/1", `{"name":"Teh","price":6000,"stock":10,"category_id":1}`, http.StatusOK},
+		{"manager shift report", manager, http.MethodGet, "/api/shifts/1", "", http.StatusOK},
+		{"manager report", manager, http.MethodGet, "/api/report?start_date=2026-01-01&end_date=2026-12-31", "", http.StatusForbidden},
+		{"owner report", owner, http.MethodGet, "/api/report/hari-ini", "", http.StatusOK},
+		{"unconfigured route", owner, http.MethodGet, "/api/unknown", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doAuthRequest(t, h, tt.token, tt.method, tt.path, tt.body)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusForbidden {
+				assertErrorCode(t, rec, "FORBIDDEN")
+			}
+		})
+	}
+}
+
+// Koreksi stock butuh stock:count, bukan product:write dari rule /api/products/
+func TestAdjustStockPermission(t *testing.T) {
+	h := newAuthMux(t)
+	owner := login(t, h, "admin", "rahasia123").Token
+	if rec := doAuthRequest(t, h, owner, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`); rec.Code != http.StatusCreated {
+		t.Fatalf("owner create product status = %d", rec.Code)
+	}
+	cashier := createUserWithRole(t, h, owner, "kasir", "cashier")
+
+	tests := []struct {
+		name        string
+		permissions string
+		want        int
+	}{
+		{"product write only", `["product:read","product:write"]`, http.StatusForbidden},
+		{"stock count only", `["product:read","stock:count"]`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doAuthRequest(t, h, owner, http.MethodPut, "/api/roles/3", `{"permissions":`+tt.permissions+`}`); rec.Code != http.StatusOK {
+				t.Fatalf("update role status = %d, body %s", rec.Code, rec.Body)
+			}
+			rec := doAuthRequest(t, h, cashier, http.MethodPost, "/api/products/1/adjust-stock", `{"quantity":-1,"note":"pecah"}`)
+			if rec.Code != tt.want {
+				t.Fatalf("adjust stock status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestRoleHandler(t *testing.T) {
+	h := newAuthMux(t)
+	owner := login(t, h, "admin", "rahasia123").Token
+	cashier := createUserWithRole(t, h, owner, "kasir", "cashier")
+
+	// Permission role bisa diubah tanpa deploy: beri kasir akses report
+	rec := doAuthRequest(t, h, owner, http.MethodPut, "/api/roles/3", `{"permissions":["report:read","transaction:create"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update role status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := doAuthRequest(t, h, cashier, http.MethodGet, "/api/report/hari-ini", ""); rec.Code != http.StatusOK {
+		t.Errorf("cashier report after grant status = %d, want 200", rec.Code)
+	}
+	if rec := doAuthRequest(t, h, cashier, http.MethodGet, "/api/products", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("cashier products after revoke status = %d, want 403", rec.Code)
+	}
+
+	rec = doAuthRequest(t, h, owner, http.MethodPut, "/api/roles/3", `{"permissions":["product:delete"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown permission status = %d, want 400", rec.Code)
+	}
+	rec = doAuthRequest(t, h, owner, http.MethodPut, "/api/users/1/roles", `{"roles":["cashier"]}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("remove last owner status = %d, want 409", rec.Code)
+	}
+
+	var roles []models.Role
+	decode(t, doAuthRequest(t, h, owner, http.MethodGet, "/api/roles", ""), &roles)
+	if len(roles) != 3 {
+		t.Errorf("roles = %+v, want 3", roles)
+	}
+	var userRoles []models.Role
+	decode(t, doAuthRequest(t, h, owner, http.MethodGet, "/api/users/2/roles", ""), &userRoles)
+	if len(userRoles) != 1 || userRoles[0].Name != models.RoleCashier {
+		t.Errorf("user roles = %+v", userRoles)
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type CartHandler struct {
+	service services.CartService
+}
+
+func NewCartHandler(service services.CartService) *CartHandler {
+	return &CartHandler{service: service}
+}
+
+// HandleCarts -> GET /api/carts (cart yang masih open) & POST /api/carts
+func (h *CartHandler) HandleCarts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// HandleCartByID -> GET/PUT/DELETE /api/carts/{id}, POST /api/carts/{id}/items,
+// PUT/DELETE /api/carts/{id}/items/{product_id}, POST /api/carts/{id}/checkout
+func (h *CartHandler) HandleCartByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/carts/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, "invalid cart ID")
+		return
+	}
+
+	if productStr, ok := strings.CutPrefix(action, "items/"); ok {
+		productID, err := strconv.Atoi(productStr)
+		if err != nil {
+			writeBadRequest(w, "invalid product ID")
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			h.UpdateItem(w, r, id, productID)
+		case http.MethodDelete:
+			h.RemoveItem(w, r, id, productID)
+		default:
+			writeMethodNotAllowed(w)
+		}
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Cancel(w, r, id)
+	case action == "items" && r.Method == http.MethodPost:
+		h.AddItem(w, r, id)
+	case action == "checkout" && r.Method == http.MethodPost:
+		h.Checkout(w, r, id)
+	case action == "" || action == "items" || action == "checkout":
+		writeMethodNotAllowed(w)
+	default:
+		writeError(w, services.NewNotFoundError("route not found"))
+	}
+}
+
+func (h *CartHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	carts, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, carts)
+}
+
+func (h *CartHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	cart, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, cart)
+}
+
+func (h *CartHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var cart models.Cart
+	if err := json.NewDecoder(r.Body).Decode(&cart); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.service.Create(&cart); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, cart)
+}
+
+// Update -> PUT /api/carts/{id} dengan {"note": ..., "reserved": ...}
+func (h *CartHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var cart models.Cart
+	if err := json.NewDecoder(r.Body).Decode(&cart); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	cart.ID = id // Pastikan ID sesuai URL
+
+	if err := h.service.Update(&cart); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cart)
+}
+
+func (h *CartHandler) Cancel(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.Cancel(id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Cart cancelled successfully"})
+}
+
+func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request, id int) {
+	var item models.CartItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	cart, err := h.service.AddItem(id, item)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cart)
+}
+
+func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request, id, productID int) {
+	var item models.CartItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	item.ProductID = productID // Pastikan product ID sesuai URL
+
+	cart, err := h.service.UpdateItem(id, item)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cart)
+}
+
+func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request, id, productID int) {
+	cart, err := h.service.RemoveItem(id, productID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cart)
+}
+
+// Checkout -> POST /api/carts/{id}/checkout dengan body seperti /api/checkout tanpa items
+func (h *CartHandler) Checkout(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) { // body boleh kosong
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	req.CashierID = currentUserID(r)
+
+	transaction, err := h.service.Checkout(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transaction)
+}
@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestCartHandler(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Kopi","price":8000,"stock":2}`)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create", http.MethodPost, "/api/carts", `{"note":"Meja 1","reserved":true,"items":[{"product_id":1,"quantity":2}]}`, http.StatusCreated},
+		{"create invalid json", http.MethodPost, "/api/carts", `{`, http.StatusBadRequest},
+		{"create over stock", http.MethodPost, "/api/carts", `{"reserved":true,"items":[{"product_id":2,"quantity":9}]}`, http.StatusConflict},
+		{"list", http.MethodGet, "/api/carts", "", http.StatusOK},
+		{"list wrong method", http.MethodDelete, "/api/carts", "", http.StatusMethodNotAllowed},
+		{"get", http.MethodGet, "/api/carts/1", "", http.StatusOK},
+		{"get missing", http.MethodGet, "/api/carts/99", "", http.StatusNotFound},
+		{"invalid id", http.MethodGet, "/api/carts/x", "", http.StatusBadRequest},
+		{"add item", http.MethodPost, "/api/carts/1/items", `{"product_id":2,"quantity":1}`, http.StatusOK},
+		{"add invalid item", http.MethodPost, "/api/carts/1/items", `{"product_id":2,"quantity":0}`, http.StatusBadRequest},
+		{"update item", http.MethodPut, "/api/carts/1/items/2", `{"quantity":2}`, http.StatusOK},
+		{"update missing item", http.MethodPut, "/api/carts/1/items/9", `{"quantity":1}`, http.StatusNotFound},
+		{"remove item", http.MethodDelete, "/api/carts/1/items/2", "", http.StatusOK},
+		{"item wrong method", http.MethodGet, "/api/carts/1/items/2", "", http.StatusMethodNotAllowed},
+		{"update note", http.MethodPut, "/api/carts/1", `{"note":"Meja 2","reserved":true}`, http.StatusOK},
+		{"checkout wrong method", http.MethodGet, "/api/carts/1/checkout", "", http.StatusMethodNotAllowed},
+		{"checkout", http.MethodPost, "/api/carts/1/checkout", "", http.StatusOK},
+		{"checkout again", http.MethodPost, "/api/carts/1/checkout", "", http.StatusConflict},
+		{"unknown action", http.MethodPost, "/api/carts/1/park", "", http.StatusNotFound},
+		{"create to cancel", http.MethodPost, "/api/carts", `{"reserved":true,"items":[{"product_id":2,"quantity":2}]}`, http.StatusCreated},
+		{"cancel", http.MethodDelete, "/api/carts/2", "", http.StatusOK},
+		{"cancel again", http.MethodDelete, "/api/carts/2", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	var trx models.Transaction
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/transactions/1", ""), &trx)
+	if trx.TotalAmount != 10000 || len(trx.Details) != 1 {
+		t.Errorf("cart transaction = %+v", trx)
+	}
+	for id, want := range map[string]int{"1": 8, "2": 2} {
+		var p models.Product
+		decode(t, doRequest(t, mux, http.MethodGet, "/api/products/"+id, ""), &p)
+		if p.Stock != want {
+			t.Errorf("product %s stock = %d, want %d", id, p.Stock, want)
+		}
+	}
+}
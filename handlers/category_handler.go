@@ -10,10 +10,10 @@ import (
 )
 
 type CategoryHandler struct {
-	service *services.CategoryService
+	service services.CategoryService
 }
 
-func NewCategoryHandler(service *services.CategoryService) *CategoryHandler {
+func NewCategoryHandler(service services.CategoryService) *CategoryHandler {
 	return &CategoryHandler{service: service}
 }
 
@@ -25,7 +25,7 @@ func (h *CategoryHandler) HandleCategories(w http.ResponseWriter, r *http.Reques
 	case http.MethodPost:
 		h.Create(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 	}
 }
 
@@ -35,7 +35,7 @@ func (h *CategoryHandler) HandleCategoryDelete(w http.ResponseWriter, r *http.Re
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/categories/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		writeBadRequest(w, "invalid category ID")
 		return
 	}
 
@@ -47,7 +47,7 @@ func (h *CategoryHandler) HandleCategoryDelete(w http.ResponseWriter, r *http.Re
 	case http.MethodDelete:
 		h.Delete(w, r, id)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 	}
 }
 
@@ -56,62 +56,58 @@ func (h *CategoryHandler) HandleCategoryDelete(w http.ResponseWriter, r *http.Re
 func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	categories, err := h.service.GetAll()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(categories)
+	writeJSON(w, http.StatusOK, categories)
 }
 
 func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
 	category, err := h.service.GetByID(id)
 	if err != nil {
-		http.Error(w, "Category not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(category)
+	writeJSON(w, http.StatusOK, category)
 }
 
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var category models.Category
 	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, "invalid request body")
 		return
 	}
 
-	if err := h.service.Create(&category); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.Create(&category, currentUserID(r)); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(category)
+	writeJSON(w, http.StatusCreated, category)
 }
 
 func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
 	var category models.Category
 	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, "invalid request body")
 		return
 	}
 	category.ID = id // Pastikan ID sesuai URL
 
-	if err := h.service.Update(&category); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.Update(&category, currentUserID(r)); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(category)
+	writeJSON(w, http.StatusOK, category)
 }
 
 func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
 	// Logic safe delete ada di service, handler cuma manggil
-	if err := h.service.Delete(id); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest) // Error bisa karena user coba hapus 'No Category'
+	if err := h.service.Delete(id, currentUserID(r)); err != nil {
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Category deleted successfully, products moved to default"})
-}
\ No newline at end of file
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Category deleted successfully, products moved to default"})
+}
@@ -0,0 +1,60 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestCategoryHandler(t *testing.T) {
+	mux, _ := newTestMux()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create", http.MethodPost, "/api/categories", `{"name":"Minuman"}`, http.StatusCreated},
+		{"create invalid json", http.MethodPost, "/api/categories", `{`, http.StatusBadRequest},
+		{"list", http.MethodGet, "/api/categories", "", http.StatusOK},
+		{"method not allowed", http.MethodDelete, "/api/categories", "", http.StatusMethodNotAllowed},
+		{"get by id", http.MethodGet, "/api/categories/2", "", http.StatusOK},
+		{"get missing", http.MethodGet, "/api/categories/99", "", http.StatusNotFound},
+		{"invalid id", http.MethodGet, "/api/categories/x", "", http.StatusBadRequest},
+		{"create duplicate", http.MethodPost, "/api/categories", `{"name":"Minuman"}`, http.StatusConflict},
+		{"update", http.MethodPut, "/api/categories/2", `{"name":"Minuman Dingin"}`, http.StatusOK},
+		{"update missing", http.MethodPut, "/api/categories/99", `{"name":"Lain"}`, http.StatusNotFound},
+		{"create without name", http.MethodPost, "/api/categories", `{"name":""}`, http.StatusBadRequest},
+		{"delete default category", http.MethodDelete, "/api/categories/1", "", http.StatusConflict},
+		{"delete missing", http.MethodDelete, "/api/categories/99", "", http.StatusNotFound},
+		{"delete", http.MethodDelete, "/api/categories/2", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestCategoryHandlerDeleteMovesProducts(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/categories", `{"name":"Minuman"}`)
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"category_id":2}`)
+
+	if rec := doRequest(t, mux, http.MethodDelete, "/api/categories/2", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	var p models.Product
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/products/1", ""), &p)
+	if p.CategoryID != 1 || p.CategoryName != "No Category" {
+		t.Errorf("product after delete = %+v, want moved to No Category", p)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/handlers"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+// testHasher: iterasi kecil supaya login dan pembuatan user di test tetap cepat
+var testHasher = services.PasswordHasher{Iterations: 1000}
+
+// newTestMux merangkai handler dengan repository in-memory, route-nya sama dengan main.go
+func newTestMux() (*http.ServeMux, *memory.Store) {
+	store := memory.NewStore()
+	productRepo := memory.NewProductRepository(store)
+	categoryRepo := memory.NewCategoryRepository(store)
+	transactionRepo := memory.NewTransactionRepository(store)
+	promoCodeRepo := memory.NewPromoCodeRepository(store)
+	promotionRepo := memory.NewPromotionRepository(store)
+	cartRepo := memory.NewCartRepository(store)
+	idempotencyService := services.NewIdempotencyService(memory.NewIdempotencyRepository(store), time.Hour)
+	userRepo := memory.NewUserRepository(store)
+	sessionRepo := memory.NewSessionRepository(store)
+	roleService := services.NewRoleService(memory.NewRoleRepository(store), userRepo)
+	shiftRepo := memory.NewShiftRepository(store)
+
+	categoryHandler := handlers.NewCategoryHandler(services.NewCategoryService(categoryRepo, productRepo))
+	productHandler := handlers.NewProductHandler(services.NewProductService(productRepo, memory.NewStockMovementRepository(store)))
+	transactionService := services.NewTransactionService(transactionRepo, promoCodeRepo, promotionRepo, shiftRepo, services.TaxConfig{}, nil)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(services.NewPromoCodeService(promoCodeRepo))
+	promotionHandler := handlers.NewPromotionHandler(services.NewPromotionService(promotionRepo))
+	cartHandler := handlers.NewCartHandler(services.NewCartService(cartRepo, transactionService, time.Minute))
+	authHandler := handlers.NewAuthHandler(services.NewAuthService(userRepo, sessionRepo, time.Hour))
+	userHandler := handlers.NewUserHandler(services.NewUserService(userRepo, sessionRepo, memory.NewRoleRepository(store), testHasher), roleService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	shiftHandler := handlers.NewShiftHandler(services.NewShiftService(shiftRepo, roleService))
+	auditHandler := handlers.NewAuditHandler(services.NewAuditService(memory.NewAuditRepository(store)))
+	stockCountHandler := handlers.NewStockCountHandler(services.NewStockCountService(memory.NewStockCountRepository(store)))
+	supplierRepo := memory.NewSupplierRepository(store)
+	supplierHandler := handlers.NewSupplierHandler(services.NewSupplierService(supplierRepo))
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(services.NewPurchaseOrderService(memory.NewPurchaseOrderRepository(store), supplierRepo))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/categories", categoryHandler.HandleCategories)
+	mux.HandleFunc("/api/categories/", categoryHandler.HandleCategoryDelete)
+	mux.HandleFunc("/api/products", productHandler.HandleProducts)
+	mux.HandleFunc("/api/products/", productHandler.HandleProductByID)
+	mux.HandleFunc("/api/checkout", handlers.Idempotent(idempotencyService, transactionHandler.HandleCheckout))
+	mux.HandleFunc("/api/checkout/preview", transactionHandler.HandleCheckoutPreview)
+	mux.HandleFunc("/api/transactions", transactionHandler.HandleTransactions)
+	mux.HandleFunc("/api/transactions/", transactionHandler.HandleTransactionByID)
+	mux.HandleFunc("/api/report/hari-ini", transactionHandler.HandleReportHariIni)
+	mux.HandleFunc("/api/report/profit", transactionHandler.HandleProfitReport)
+	mux.HandleFunc("/api/report", transactionHandler.HandleReport)
+	mux.HandleFunc("/api/promo-codes", promoCodeHandler.HandlePromoCodes)
+	mux.HandleFunc("/api/promo-codes/", promoCodeHandler.HandlePromoCodeByID)
+	mux.HandleFunc("/api/promotions", promotionHandler.HandlePromotions)
+	mux.HandleFunc("/api/promotions/", promotionHandler.HandlePromotionByID)
+	mux.HandleFunc("/api/carts", cartHandler.HandleCarts)
+	mux.HandleFunc("/api/carts/", cartHandler.HandleCartByID)
+	mux.HandleFunc("/api/shifts", shiftHandler.HandleShifts)
+	mux.HandleFunc("/api/shifts/", shiftHandler.HandleShiftByID)
+	mux.HandleFunc("/api/audit", auditHandler.HandleAudit)
+	mux.HandleFunc("/api/stock-counts", stockCountHandler.HandleStockCounts)
+	mux.HandleFunc("/api/stock-counts/", stockCountHandler.HandleStockCountByID)
+	mux.HandleFunc("/api/suppliers", supplierHandler.HandleSuppliers)
+	mux.HandleFunc("/api/suppliers/", supplierHandler.HandleSupplierByID)
+	mux.HandleFunc("/api/purchase-orders", purchaseOrderHandler.HandlePurchaseOrders)
+	mux.HandleFunc("/api/purchase-orders/", purchaseOrderHandler.HandlePurchaseOrderByID)
+	mux.HandleFunc("/api/auth/login", authHandler.HandleLogin)
+	mux.HandleFunc("/api/auth/refresh", authHandler.HandleRefresh)
+	mux.HandleFunc("/api/auth/logout", authHandler.HandleLogout)
+	mux.HandleFunc("/api/auth/me", authHandler.HandleMe)
+	mux.HandleFunc("/api/users", userHandler.HandleUsers)
+	mux.HandleFunc("/api/users/", userHandler.HandleUserByID)
+	mux.HandleFunc("/api/roles", roleHandler.HandleRoles)
+	mux.HandleFunc("/api/roles/", roleHandler.HandleRoleByID)
+	return mux, store
+}
+
+func doRequest(t *testing.T, h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// assertErrorCode memastikan response error memakai format JSON {code, message, details}
+func assertErrorCode(t *testing.T, rec *httptest.ResponseRecorder, want string) {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body handlers.ErrorResponse
+	decode(t, rec, &body)
+	if string(body.Code) != want || body.Message == "" {
+		t.Errorf("error body = %+v, want code %s", body, want)
+	}
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+}
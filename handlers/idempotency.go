@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kasir-api-golang-v1/services"
+)
+
+// IdempotencyKeyHeader dikirim client POS supaya retry request yang timeout aman
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength: kolom idempotency_key 255 karakter, sisanya untuk prefix user ID
+const maxIdempotencyKeyLength = 200
+
+// captureWriter meneruskan response ke client sambil menyimpan status dan body-nya
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *captureWriter) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *captureWriter) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// Idempotent membungkus handler POST supaya request dengan Idempotency-Key yang
+// sama hanya diproses sekali. Retry dengan body yang sama mendapat response
+// pertama apa adanya (header Idempotent-Replayed: true); key yang sama dengan
+// body berbeda ditolak 422. Response 5xx tidak disimpan supaya bisa dicoba lagi.
+// Request tanpa header diproses seperti biasa. Key berlaku per user yang login,
+// jadi user lain dengan key dan body yang sama tidak mendapat response milik user pertama.
+func Idempotent(service services.IdempotencyService, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+		if key == "" || r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(w, services.NewValidationError("invalid Idempotency-Key", services.FieldError{
+				Field: IdempotencyKeyHeader, Message: fmt.Sprintf("must be at most %d characters", maxIdempotencyKeyLength),
+			}))
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeBadRequest(w, "invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		key = strconv.Itoa(currentUserID(r)) + ":" + key
+		sum := sha256.Sum256(append([]byte(key+"\n"+r.URL.Path+"\n"), body...))
+
+		rec, err := service.Begin(key, hex.EncodeToString(sum[:]))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if rec != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(rec.StatusCode)
+			w.Write(rec.Response)
+			return
+		}
+
+		capture := &captureWriter{ResponseWriter: w}
+		next(capture, r)
+		if capture.status == 0 || capture.status >= http.StatusInternalServerError {
+			err = service.Abort(key)
+		} else {
+			err = service.Complete(key, capture.status, capture.body.Bytes())
+		}
+		if err != nil {
+			log.Println("idempotency key", key+":", err)
+		}
+	}
+}
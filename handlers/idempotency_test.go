@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kasir-api-golang-v1/handlers"
+	"kasir-api-golang-v1/models"
+)
+
+func doIdempotentCheckout(t *testing.T, mux http.Handler, key, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/checkout", strings.NewReader(body))
+	req.Header.Set(handlers.IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCheckoutIdempotencyKey(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	body := `{"items":[{"product_id":1,"quantity":2}]}`
+
+	first := doIdempotentCheckout(t, mux, "pos-1-0001", body)
+	retry := doIdempotentCheckout(t, mux, "pos-1-0001", body)
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK {
+		t.Fatalf("status = %d / %d (body %s)", first.Code, retry.Code, retry.Body)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %s, want replay of %s", retry.Body, first.Body)
+	}
+
+	// Key sama dengan body berbeda ditolak
+	rec := doIdempotentCheckout(t, mux, "pos-1-0001", `{"items":[{"product_id":1,"quantity":3}]}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key status = %d, want 422", rec.Code)
+	}
+	assertErrorCode(t, rec, "IDEMPOTENCY_KEY_REUSED")
+
+	// Error bisnis (4xx) juga di-replay, bukan diproses ulang
+	over := `{"items":[{"product_id":1,"quantity":50}]}`
+	if rec := doIdempotentCheckout(t, mux, "pos-1-0002", over); rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", rec.Code)
+	}
+	if rec := doIdempotentCheckout(t, mux, "pos-1-0002", over); rec.Code != http.StatusConflict || rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replayed error status = %d", rec.Code)
+	}
+
+	// Tanpa header setiap request tetap diproses
+	doRequest(t, mux, http.MethodPost, "/api/checkout", body)
+
+	var page models.TransactionPage
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/transactions", ""), &page)
+	if page.Total != 2 {
+		t.Errorf("transactions = %d, want 2 (retry must not create a new one)", page.Total)
+	}
+	var p models.Product
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/products/1", ""), &p)
+	if p.Stock != 6 {
+		t.Errorf("stock = %d, want 6", p.Stock)
+	}
+}
+
+func TestIdempotencyKeyScopedPerUser(t *testing.T) {
+	h := newAuthMux(t)
+	owner := login(t, h, "admin", "rahasia123").Token
+	doAuthRequest(t, h, owner, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	first := createUserWithRole(t, h, owner, "kasir1", "cashier")
+	second := createUserWithRole(t, h, owner, "kasir2", "cashier")
+
+	body := `{"items":[{"product_id":1,"quantity":1}]}`
+	var receipts []models.Transaction
+	for _, token := range []string{first, second} {
+		if rec := doAuthRequest(t, h, token, http.MethodPost, "/api/shifts", `{"opening_cash":100000}`); rec.Code != http.StatusCreated {
+			t.Fatalf("open shift status = %d, body %s", rec.Code, rec.Body)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/checkout", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(handlers.IdempotencyKeyHeader, "pos-1-0001")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Header().Get("Idempotent-Replayed") != "" {
+			t.Fatalf("checkout status = %d, replayed %q (body %s)", rec.Code, rec.Header().Get("Idempotent-Replayed"), rec.Body)
+		}
+		var trx models.Transaction
+		decode(t, rec, &trx)
+		receipts = append(receipts, trx)
+	}
+
+	// Key yang sama dari user lain adalah transaksi baru, bukan replay struk user pertama
+	if receipts[0].ID == receipts[1].ID || receipts[0].CashierID == nil || receipts[1].CashierID == nil || *receipts[0].CashierID == *receipts[1].CashierID {
+		t.Errorf("receipts = %+v / %+v", receipts[0], receipts[1])
+	}
+}
@@ -10,10 +10,10 @@ import (
 )
 
 type ProductHandler struct {
-	service *services.ProductService
+	service services.ProductService
 }
 
-func NewProductHandler(service *services.ProductService) *ProductHandler {
+func NewProductHandler(service services.ProductService) *ProductHandler {
 	return &ProductHandler{service: service}
 }
 
@@ -24,27 +24,48 @@ func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request)
 	case http.MethodPost:
 		h.Create(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 	}
 }
 
+// HandleProductByID -> GET/PUT/DELETE /api/products/{id}, GET /api/products/{id}/stock-history,
+// POST /api/products/{id}/adjust-stock, GET /api/products/stock-check dan GET /api/products/low-stock
 func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/products/")
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/products/"), "/")
+	if (idStr == "stock-check" || idStr == "low-stock") && action == "" {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		if idStr == "low-stock" {
+			h.LowStock(w, r)
+		} else {
+			h.CheckStock(w, r)
+		}
+		return
+	}
+
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		writeBadRequest(w, "invalid product ID")
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
+	switch {
+	case action == "" && r.Method == http.MethodGet:
 		h.GetByID(w, r, id)
-	case http.MethodPut:
+	case action == "" && r.Method == http.MethodPut:
 		h.Update(w, r, id)
-	case http.MethodDelete:
+	case action == "" && r.Method == http.MethodDelete:
 		h.Delete(w, r, id)
+	case action == "stock-history" && r.Method == http.MethodGet:
+		h.StockHistory(w, r, id)
+	case action == "adjust-stock" && r.Method == http.MethodPost:
+		h.AdjustStock(w, r, id)
+	case action == "" || action == "stock-history" || action == "adjust-stock":
+		writeMethodNotAllowed(w)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeError(w, services.NewNotFoundError("route not found"))
 	}
 }
 
@@ -52,54 +73,115 @@ func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("name")
 	products, err := h.service.GetAll(name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
+	writeJSON(w, http.StatusOK, products)
 }
 
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var product models.Product
 	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+		writeBadRequest(w, "invalid request body")
 		return
 	}
-	if err := h.service.Create(&product); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.Create(&product, currentUserID(r)); err != nil {
+		writeError(w, err)
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(product)
+	writeJSON(w, http.StatusCreated, product)
 }
 
 func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
 	product, err := h.service.GetByID(id)
 	if err != nil {
-		http.Error(w, "Product not found", http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
-	json.NewEncoder(w).Encode(product)
+	writeJSON(w, http.StatusOK, product)
 }
 
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
-	var product models.Product
-	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	var req models.ProductUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
 		return
 	}
-	product.ID = id
-	if err := h.service.Update(&product); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	product, err := h.service.Update(id, currentUserID(r), req)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
-	json.NewEncoder(w).Encode(product)
+	writeJSON(w, http.StatusOK, product)
 }
 
 func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
-	if err := h.service.Delete(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.Delete(id, currentUserID(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Product deleted"})
+}
+
+// StockHistory -> GET /api/products/{id}/stock-history?type=&start_date=&end_date=&page=&limit=
+func (h *ProductHandler) StockHistory(w http.ResponseWriter, r *http.Request, id int) {
+	query := r.URL.Query()
+	filter := models.StockMovementFilter{
+		ProductID: id,
+		Type:      query.Get("type"),
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+	}
+	for key, dst := range map[string]*int{"page": &filter.Page, "limit": &filter.Limit} {
+		v, err := queryInt(r, key)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if v != nil {
+			*dst = *v
+		}
+	}
+
+	history, err := h.service.StockHistory(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// CheckStock -> GET /api/products/stock-check, hitung ulang stock dari ledger
+func (h *ProductHandler) CheckStock(w http.ResponseWriter, r *http.Request) {
+	check, err := h.service.CheckStock()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, check)
+}
+
+// LowStock -> GET /api/products/low-stock, produk yang sudah perlu di-order ulang
+func (h *ProductHandler) LowStock(w http.ResponseWriter, r *http.Request) {
+	products, err := h.service.LowStock()
+	if err != nil {
+		writeError(w, err)
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]string{"message": "Product deleted"})
-}
\ No newline at end of file
+	writeJSON(w, http.StatusOK, products)
+}
+
+// AdjustStock -> POST /api/products/{id}/adjust-stock, koreksi stock tanpa PUT produk lengkap
+func (h *ProductHandler) AdjustStock(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	product, err := h.service.AdjustStock(id, currentUserID(r), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, product)
+}
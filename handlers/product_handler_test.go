@@ -0,0 +1,204 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestProductHandler(t *testing.T) {
+	mux, _ := newTestMux()
+
+	rec := doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", rec.Code, rec.Body)
+	}
+	var created models.Product
+	decode(t, rec, &created)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"list", http.MethodGet, "/api/products", "", http.StatusOK, ""},
+		{"list with filter", http.MethodGet, "/api/products?name=teh", "", http.StatusOK, ""},
+		{"create invalid json", http.MethodPost, "/api/products", `{`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"create without name", http.MethodPost, "/api/products", `{"price":1000}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"create negative cost price", http.MethodPost, "/api/products", `{"name":"Gula","price":2000,"cost_price":-1}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"method not allowed", http.MethodPatch, "/api/products", "", http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED"},
+		{"get by id", http.MethodGet, "/api/products/1", "", http.StatusOK, ""},
+		{"get missing", http.MethodGet, "/api/products/99", "", http.StatusNotFound, "NOT_FOUND"},
+		{"invalid id", http.MethodGet, "/api/products/abc", "", http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"update", http.MethodPut, "/api/products/1", `{"name":"Teh Manis","price":6000,"stock":10,"category_id":1}`, http.StatusOK, ""},
+		{"update invalid json", http.MethodPut, "/api/products/1", `nope`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"delete", http.MethodDelete, "/api/products/1", "", http.StatusOK, ""},
+		{"get after delete", http.MethodGet, "/api/products/1", "", http.StatusNotFound, "NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantCode != "" {
+				assertErrorCode(t, rec, tt.wantCode)
+			}
+		})
+	}
+
+	if created.ID != 1 || created.Price != 5000 {
+		t.Errorf("created product = %+v", created)
+	}
+}
+
+// PUT dari client yang belum mengirim cost_price dan field reorder tidak
+// mereset harga pokok atau mematikan alert stock menipis
+func TestProductHandlerUpdateKeepsOmittedFields(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"cost_price":3000,"stock":10,"reorder_point":5,"reorder_quantity":24}`)
+
+	rec := doRequest(t, mux, http.MethodPut, "/api/products/1", `{"name":"Teh Manis","price":6000,"stock":10,"category_id":1}`)
+	var p models.Product
+	decode(t, rec, &p)
+	if rec.Code != http.StatusOK || p.Name != "Teh Manis" || p.Price != 6000 || p.CostPrice != 3000 {
+		t.Errorf("update = %d, %+v", rec.Code, p)
+	}
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/products/1", ""), &p)
+	if p.CostPrice != 3000 || p.ReorderPoint != 5 || p.ReorderQuantity != 24 {
+		t.Errorf("stored product = %+v, want cost 3000 and reorder 5/24", p)
+	}
+
+	// Field reorder yang dikirim tetap bisa diubah, termasuk ke 0
+	rec = doRequest(t, mux, http.MethodPut, "/api/products/1", `{"name":"Teh Manis","price":6000,"stock":10,"category_id":1,"reorder_point":0}`)
+	decode(t, rec, &p)
+	if rec.Code != http.StatusOK || p.ReorderPoint != 0 || p.ReorderQuantity != 24 {
+		t.Errorf("update reorder point = %d, %+v", rec.Code, p)
+	}
+}
+
+func TestProductHandlerListFilter(t *testing.T) {
+	mux, _ := newTestMux()
+	for _, body := range []string{`{"name":"Teh"}`, `{"name":"Kopi"}`} {
+		if rec := doRequest(t, mux, http.MethodPost, "/api/products", body); rec.Code != http.StatusCreated {
+			t.Fatalf("create status = %d", rec.Code)
+		}
+	}
+
+	rec := doRequest(t, mux, http.MethodGet, "/api/products?name=kop", "")
+	var products []models.Product
+	decode(t, rec, &products)
+	if len(products) != 1 || products[0].Name != "Kopi" {
+		t.Errorf("products = %+v, want only Kopi", products)
+	}
+}
+
+func TestProductHandlerErrorMapping(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":1}]}`)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"get missing", http.MethodGet, "/api/products/99", "", http.StatusNotFound, "NOT_FOUND"},
+		{"update missing", http.MethodPut, "/api/products/99", `{"name":"X","category_id":1}`, http.StatusNotFound, "NOT_FOUND"},
+		{"delete missing", http.MethodDelete, "/api/products/99", "", http.StatusNotFound, "NOT_FOUND"},
+		{"create with unknown category", http.MethodPost, "/api/products", `{"name":"X","category_id":42}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"delete product with transactions", http.MethodDelete, "/api/products/1", "", http.StatusConflict, "CONFLICT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			assertErrorCode(t, rec, tt.wantCode)
+		})
+	}
+}
+
+func TestProductHandlerStockHistory(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":4}]}`)
+
+	rec := doRequest(t, mux, http.MethodGet, "/api/products/1/stock-history?limit=1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stock history status = %d, body %s", rec.Code, rec.Body)
+	}
+	var history models.StockMovementPage
+	decode(t, rec, &history)
+	if history.Stock != 6 || history.Total != 2 || len(history.Data) != 1 || history.Data[0].Type != models.StockSale || history.Data[0].Quantity != -4 {
+		t.Errorf("stock history = %+v", history)
+	}
+
+	var check models.StockCheck
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/products/stock-check", ""), &check)
+	if !check.Consistent || check.ProductsChecked != 1 || len(check.Discrepancies) != 0 {
+		t.Errorf("stock check = %+v", check)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing product", http.MethodGet, "/api/products/99/stock-history", http.StatusNotFound, "NOT_FOUND"},
+		{"invalid type", http.MethodGet, "/api/products/1/stock-history?type=gift", http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"invalid page", http.MethodGet, "/api/products/1/stock-history?page=abc", http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"history method", http.MethodPost, "/api/products/1/stock-history", http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED"},
+		{"check method", http.MethodPost, "/api/products/stock-check", http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED"},
+		{"low stock method", http.MethodPost, "/api/products/low-stock", http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED"},
+		{"unknown action", http.MethodGet, "/api/products/1/stock", http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			assertErrorCode(t, rec, tt.wantCode)
+		})
+	}
+}
+
+func TestProductHandlerLowStock(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10,"reorder_point":5,"reorder_quantity":24}`)
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Kopi","price":8000,"stock":10}`)
+
+	var low []models.Product
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/products/low-stock", ""), &low)
+	if len(low) != 0 {
+		t.Fatalf("low stock before checkout = %+v", low)
+	}
+
+	doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":5},{"product_id":2,"quantity":9}]}`)
+	rec := doRequest(t, mux, http.MethodGet, "/api/products/low-stock", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("low stock status = %d, body %s", rec.Code, rec.Body)
+	}
+	decode(t, rec, &low)
+	if len(low) != 1 || low[0].ID != 1 || low[0].Stock != 5 || low[0].ReorderQuantity != 24 {
+		t.Errorf("low stock = %+v", low)
+	}
+
+	rec = doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Gula","price":2000,"stock":1,"reorder_point":-1}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("negative reorder point status = %d", rec.Code)
+	}
+	assertErrorCode(t, rec, "VALIDATION_ERROR")
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type PromoCodeHandler struct {
+	service services.PromoCodeService
+}
+
+func NewPromoCodeHandler(service services.PromoCodeService) *PromoCodeHandler {
+	return &PromoCodeHandler{service: service}
+}
+
+// HandlePromoCodes -> GET /api/promo-codes & POST /api/promo-codes
+func (h *PromoCodeHandler) HandlePromoCodes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// HandlePromoCodeByID -> GET/PUT/DELETE /api/promo-codes/{id}
+func (h *PromoCodeHandler) HandlePromoCodeByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/promo-codes/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, "invalid promo code ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r, id)
+	case http.MethodPut:
+		h.Update(w, r, id)
+	case http.MethodDelete:
+		h.Delete(w, r, id)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+func (h *PromoCodeHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	promos, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, promos)
+}
+
+func (h *PromoCodeHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	promo, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, promo)
+}
+
+func (h *PromoCodeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	promo := models.PromoCode{Active: true} // default aktif kalau "active" tidak dikirim
+	if err := json.NewDecoder(r.Body).Decode(&promo); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.service.Create(&promo); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, promo)
+}
+
+func (h *PromoCodeHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	promo := models.PromoCode{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&promo); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	promo.ID = id // Pastikan ID sesuai URL
+
+	if err := h.service.Update(&promo); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promo)
+}
+
+func (h *PromoCodeHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.Delete(id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Promo code deleted successfully"})
+}
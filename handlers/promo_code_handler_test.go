@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestPromoCodeHandler(t *testing.T) {
+	mux, _ := newTestMux()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create", http.MethodPost, "/api/promo-codes", `{"code":"hemat10","type":"percent","value":10,"min_spend":10000}`, http.StatusCreated},
+		{"create invalid json", http.MethodPost, "/api/promo-codes", `{`, http.StatusBadRequest},
+		{"create invalid type", http.MethodPost, "/api/promo-codes", `{"code":"X","type":"gratis","value":1}`, http.StatusBadRequest},
+		{"create duplicate", http.MethodPost, "/api/promo-codes", `{"code":"HEMAT10","type":"fixed","value":1000}`, http.StatusConflict},
+		{"list", http.MethodGet, "/api/promo-codes", "", http.StatusOK},
+		{"method not allowed", http.MethodDelete, "/api/promo-codes", "", http.StatusMethodNotAllowed},
+		{"get by id", http.MethodGet, "/api/promo-codes/1", "", http.StatusOK},
+		{"get missing", http.MethodGet, "/api/promo-codes/99", "", http.StatusNotFound},
+		{"invalid id", http.MethodGet, "/api/promo-codes/x", "", http.StatusBadRequest},
+		{"update", http.MethodPut, "/api/promo-codes/1", `{"code":"HEMAT15","type":"percent","value":15}`, http.StatusOK},
+		{"delete missing", http.MethodDelete, "/api/promo-codes/99", "", http.StatusNotFound},
+		{"delete", http.MethodDelete, "/api/promo-codes/1", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestCheckoutWithPromoCode(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/promo-codes", `{"code":"HEMAT","type":"fixed","value":2000}`)
+
+	rec := doRequest(t, mux, http.MethodPost, "/api/checkout",
+		`{"items":[{"product_id":1,"quantity":2,"discount":{"type":"percent","value":10}}],"promo_code":"hemat"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var trx models.Transaction
+	decode(t, rec, &trx)
+	if trx.GrossAmount != 10000 || trx.DiscountAmount != 3000 || trx.TotalAmount != 7000 || trx.PromoCode != "HEMAT" {
+		t.Errorf("transaction = %+v", trx)
+	}
+
+	var report map[string]interface{}
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/report/hari-ini", ""), &report)
+	if report["gross_revenue"] != float64(10000) || report["total_diskon"] != float64(3000) || report["total_revenue"] != float64(7000) {
+		t.Errorf("report = %v", report)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type PromotionHandler struct {
+	service services.PromotionService
+}
+
+func NewPromotionHandler(service services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// HandlePromotions -> GET /api/promotions & POST /api/promotions
+func (h *PromotionHandler) HandlePromotions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// HandlePromotionByID -> GET/PUT/DELETE /api/promotions/{id}
+func (h *PromotionHandler) HandlePromotionByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/promotions/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, "invalid promotion ID")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r, id)
+	case http.MethodPut:
+		h.Update(w, r, id)
+	case http.MethodDelete:
+		h.Delete(w, r, id)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+func (h *PromotionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	promos, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, promos)
+}
+
+func (h *PromotionHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	promo, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, promo)
+}
+
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	promo := models.Promotion{Active: true} // default aktif kalau "active" tidak dikirim
+	if err := json.NewDecoder(r.Body).Decode(&promo); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.service.Create(&promo); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, promo)
+}
+
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	promo := models.Promotion{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&promo); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	promo.ID = id // Pastikan ID sesuai URL
+
+	if err := h.service.Update(&promo); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, promo)
+}
+
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.Delete(id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Promotion deleted successfully"})
+}
@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestPromotionHandler(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create", http.MethodPost, "/api/promotions", `{"name":"Teh 2+1","type":"buy_x_get_y","buy_qty":2,"free_qty":1,"items":[{"product_id":1}]}`, http.StatusCreated},
+		{"create invalid json", http.MethodPost, "/api/promotions", `{`, http.StatusBadRequest},
+		{"create invalid type", http.MethodPost, "/api/promotions", `{"name":"X","type":"gratis"}`, http.StatusBadRequest},
+		{"create unknown product", http.MethodPost, "/api/promotions", `{"name":"X","type":"bundle","bundle_price":1,"items":[{"product_id":9}]}`, http.StatusBadRequest},
+		{"list", http.MethodGet, "/api/promotions", "", http.StatusOK},
+		{"method not allowed", http.MethodDelete, "/api/promotions", "", http.StatusMethodNotAllowed},
+		{"get by id", http.MethodGet, "/api/promotions/1", "", http.StatusOK},
+		{"get missing", http.MethodGet, "/api/promotions/99", "", http.StatusNotFound},
+		{"invalid id", http.MethodGet, "/api/promotions/x", "", http.StatusBadRequest},
+		{"update", http.MethodPut, "/api/promotions/1", `{"name":"Teh 3+1","type":"buy_x_get_y","buy_qty":3,"free_qty":1,"items":[{"product_id":1}]}`, http.StatusOK},
+		{"delete missing", http.MethodDelete, "/api/promotions/99", "", http.StatusNotFound},
+		{"delete", http.MethodDelete, "/api/promotions/1", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestCheckoutWithPromotion(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/promotions", `{"name":"Teh 2+1","type":"buy_x_get_y","buy_qty":2,"free_qty":1,"items":[{"product_id":1}]}`)
+
+	rec := doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":3}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var trx models.Transaction
+	decode(t, rec, &trx)
+	if trx.TotalAmount != 10000 || len(trx.Promotions) != 1 || trx.Promotions[0].Name != "Teh 2+1" || trx.Promotions[0].DiscountAmount != 5000 {
+		t.Errorf("transaction = %+v", trx)
+	}
+
+	// Struk cetak ulang menampilkan promosi yang sama
+	var receipt models.Transaction
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/transactions/1", ""), &receipt)
+	if len(receipt.Promotions) != 1 || receipt.Promotions[0].PromotionID != 1 {
+		t.Errorf("receipt promotions = %+v", receipt.Promotions)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+)
+
+type PurchaseOrderHandler struct {
+	service services.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service services.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+// HandlePurchaseOrders -> GET /api/purchase-orders?status=&supplier_id= dan POST /api/purchase-orders (draft)
+func (h *PurchaseOrderHandler) HandlePurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// HandlePurchaseOrderByID -> GET /api/purchase-orders/{id}, POST {id}/approve, {id}/receive dan {id}/cancel
+func (h *PurchaseOrderHandler) HandlePurchaseOrderByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/purchase-orders/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, "invalid purchase order ID")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "approve" && r.Method == http.MethodPost:
+		h.Approve(w, r, id)
+	case action == "receive" && r.Method == http.MethodPost:
+		h.Receive(w, r, id)
+	case action == "cancel" && r.Method == http.MethodPost:
+		h.Cancel(w, r, id)
+	case action == "" || action == "approve" || action == "receive" || action == "cancel":
+		writeMethodNotAllowed(w)
+	default:
+		writeError(w, services.NewNotFoundError("route not found"))
+	}
+}
+
+func (h *PurchaseOrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := models.PurchaseOrderFilter{Status: r.URL.Query().Get("status")}
+	supplierID, err := queryInt(r, "supplier_id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if supplierID != nil {
+		filter.SupplierID = *supplierID
+	}
+	orders, err := h.service.GetAll(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreatePurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	po, err := h.service.Create(currentUserID(r), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, po)
+}
+
+func (h *PurchaseOrderHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	po, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, po)
+}
+
+func (h *PurchaseOrderHandler) Approve(w http.ResponseWriter, r *http.Request, id int) {
+	po, err := h.service.Approve(id, currentUserID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, po)
+}
+
+func (h *PurchaseOrderHandler) Receive(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.ReceivePurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	po, err := h.service.Receive(id, currentUserID(r), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, po)
+}
+
+func (h *PurchaseOrderHandler) Cancel(w http.ResponseWriter, r *http.Request, id int) {
+	po, err := h.service.Cancel(id, currentUserID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, po)
+}
@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestPurchaseOrderHandler(t *testing.T) {
+	h := newAuthMux(t)
+	owner := login(t, h, "admin", "rahasia123").Token
+	for _, body := range []string{`{"name":"Teh","price":5000,"stock":2}`, `{"name":"Kopi","price":8000,"stock":0}`} {
+		if rec := doAuthRequest(t, h, owner, http.MethodPost, "/api/products", body); rec.Code != http.StatusCreated {
+			t.Fatalf("create product status = %d", rec.Code)
+		}
+	}
+	manager := createUserWithRole(t, h, owner, "manajer", "manager")
+	cashier := createUserWithRole(t, h, owner, "kasir", "cashier")
+
+	rec := doAuthRequest(t, h, manager, http.MethodPost, "/api/suppliers", `{"name":" CV Sumber Teh ","phone":"0812","email":"teh@example.com"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create supplier status = %d, body %s", rec.Code, rec.Body)
+	}
+	var supplier models.Supplier
+	decode(t, rec, &supplier)
+	if supplier.Name != "CV Sumber Teh" {
+		t.Errorf("supplier = %+v", supplier)
+	}
+
+	rec = doAuthRequest(t, h, manager, http.MethodPost, "/api/purchase-orders",
+		`{"supplier_id":1,"note":"restock mingguan","items":[{"product_id":1,"quantity":10,"unit_cost":3000},{"product_id":2,"quantity":5,"unit_cost":6000}]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create PO status = %d, body %s", rec.Code, rec.Body)
+	}
+	var po models.PurchaseOrder
+	decode(t, rec, &po)
+	if po.Status != models.PurchaseOrderDraft || po.SupplierName != "CV Sumber Teh" || po.TotalCost != 60000 || po.CreatedBy == nil {
+		t.Errorf("created PO = %+v", po)
+	}
+
+	for _, step := range []struct {
+		path, body string
+	}{
+		{"/api/purchase-orders/1/approve", ""},
+		{"/api/purchase-orders/1/receive", `{"items":[{"product_id":1,"quantity":4}]}`},
+		{"/api/purchase-orders/1/receive", `{"items":[{"product_id":1,"quantity":6,"unit_cost":3200},{"product_id":2,"quantity":5}]}`},
+	} {
+		rec = doAuthRequest(t, h, manager, http.MethodPost, step.path, step.body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, body %s", step.path, rec.Code, rec.Body)
+		}
+	}
+	decode(t, rec, &po)
+	if po.Status != models.PurchaseOrderReceived || len(po.Receipts) != 3 || po.ReceivedCost != 4*3000+6*3200+5*6000 {
+		t.Errorf("received PO = %+v", po)
+	}
+	var teh models.Product
+	decode(t, doAuthRequest(t, h, owner, http.MethodGet, "/api/products/1", ""), &teh)
+	if teh.Stock != 12 {
+		t.Errorf("teh stock = %d, want 12", teh.Stock)
+	}
+	var history models.StockMovementPage
+	decode(t, doAuthRequest(t, h, owner, http.MethodGet, "/api/products/1/stock-history?type=purchase", ""), &history)
+	if history.Total != 2 {
+		t.Errorf("purchase movements = %+v", history)
+	}
+
+	// PO kedua untuk validasi dan cancel
+	if rec := doAuthRequest(t, h, manager, http.MethodPost, "/api/purchase-orders", `{"supplier_id":1,"items":[{"product_id":1,"quantity":1,"unit_cost":3000}]}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create second PO status = %d", rec.Code)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"cashier cannot list suppliers", cashier, http.MethodGet, "/api/suppliers", "", http.StatusForbidden},
+		{"cashier cannot receive", cashier, http.MethodPost, "/api/purchase-orders/2/receive", `{"items":[{"product_id":1,"quantity":1}]}`, http.StatusForbidden},
+		{"duplicate supplier", manager, http.MethodPost, "/api/suppliers", `{"name":"CV Sumber Teh"}`, http.StatusConflict},
+		{"supplier without name", manager, http.MethodPost, "/api/suppliers", `{"phone":"0812"}`, http.StatusBadRequest},
+		{"supplier invalid email", manager, http.MethodPost, "/api/suppliers", `{"name":"UD Kopi","email":"kopi"}`, http.StatusBadRequest},
+		{"update supplier", manager, http.MethodPut, "/api/suppliers/1", `{"name":"CV Sumber Teh","address":"Bandung"}`, http.StatusOK},
+		{"supplier in use", manager, http.MethodDelete, "/api/suppliers/1", "", http.StatusConflict},
+		{"missing supplier", manager, http.MethodGet, "/api/suppliers/99", "", http.StatusNotFound},
+		{"PO unknown supplier", manager, http.MethodPost, "/api/purchase-orders", `{"supplier_id":99,"items":[{"product_id":1,"quantity":1,"unit_cost":1}]}`, http.StatusBadRequest},
+		{"PO unknown product", manager, http.MethodPost, "/api/purchase-orders", `{"supplier_id":1,"items":[{"product_id":99,"quantity":1,"unit_cost":1}]}`, http.StatusBadRequest},
+		{"PO without cost", manager, http.MethodPost, "/api/purchase-orders", `{"supplier_id":1,"items":[{"product_id":1,"quantity":1}]}`, http.StatusBadRequest},
+		{"PO without items", manager, http.MethodPost, "/api/purchase-orders", `{"supplier_id":1,"items":[]}`, http.StatusBadRequest},
+		{"receive draft", manager, http.MethodPost, "/api/purchase-orders/2/receive", `{"items":[{"product_id":1,"quantity":1}]}`, http.StatusConflict},
+		{"receive completed", manager, http.MethodPost, "/api/purchase-orders/1/receive", `{"items":[{"product_id":1,"quantity":1}]}`, http.StatusConflict},
+		{"cancel received", manager, http.MethodPost, "/api/purchase-orders/1/cancel", "", http.StatusConflict},
+		{"list received", manager, http.MethodGet, "/api/purchase-orders?status=received&supplier_id=1", "", http.StatusOK},
+		{"invalid status", manager, http.MethodGet, "/api/purchase-orders?status=done", "", http.StatusBadRequest},
+		{"invalid supplier filter", manager, http.MethodGet, "/api/purchase-orders?supplier_id=abc", "", http.StatusBadRequest},
+		{"missing PO", manager, http.MethodGet, "/api/purchase-orders/99", "", http.StatusNotFound},
+		{"unknown action", manager, http.MethodPost, "/api/purchase-orders/1/finalize", "", http.StatusNotFound},
+		{"approve method", manager, http.MethodGet, "/api/purchase-orders/1/approve", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doAuthRequest(t, h, tt.token, tt.method, tt.path, tt.body); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	// Staf gudang dengan purchase:manage saja boleh membuat PO tapi tidak approve
+	if rec := doAuthRequest(t, h, owner, http.MethodPut, "/api/roles/3", `{"permissions":["purchase:manage"]}`); rec.Code != http.StatusOK {
+		t.Fatalf("update role status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := doAuthRequest(t, h, cashier, http.MethodGet, "/api/purchase-orders/2", ""); rec.Code != http.StatusOK {
+		t.Errorf("purchase:manage get PO status = %d", rec.Code)
+	}
+	if rec := doAuthRequest(t, h, cashier, http.MethodPost, "/api/purchase-orders/2/approve", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("purchase:manage approve status = %d, want 403", rec.Code)
+	}
+
+	// Approve lalu terima lebih dari yang dipesan ditolak, lalu cancel
+	if rec := doAuthRequest(t, h, manager, http.MethodPost, "/api/purchase-orders/2/approve", ""); rec.Code != http.StatusOK {
+		t.Fatalf("approve second status = %d", rec.Code)
+	}
+	rec = doAuthRequest(t, h, manager, http.MethodPost, "/api/purchase-orders/2/receive", `{"items":[{"product_id":1,"quantity":2}]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("over-receipt status = %d, body %s", rec.Code, rec.Body)
+	}
+	rec = doAuthRequest(t, h, manager, http.MethodPost, "/api/purchase-orders/2/cancel", "")
+	decode(t, rec, &po)
+	if rec.Code != http.StatusOK || po.Status != models.PurchaseOrderCancelled || po.ClosedBy == nil {
+		t.Errorf("cancel = %d, %+v", rec.Code, po)
+	}
+}
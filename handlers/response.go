@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"kasir-api-golang-v1/services"
+)
+
+// CodeMethodNotAllowed hanya dipakai di layer HTTP, tidak ada padanannya di service
+const CodeMethodNotAllowed services.ErrorCode = "METHOD_NOT_ALLOWED"
+
+// ErrorResponse adalah format body untuk semua response error
+type ErrorResponse struct {
+	Code    services.ErrorCode `json:"code"`
+	Message string             `json:"message"`
+	Details interface{}        `json:"details,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// statusFor memetakan kode error service ke HTTP status
+func statusFor(code services.ErrorCode) int {
+	switch code {
+	case services.CodeNotFound:
+		return http.StatusNotFound
+	case services.CodeValidation:
+		return http.StatusBadRequest
+	case services.CodeConflict, services.CodeInsufficientStock:
+		return http.StatusConflict
+	case services.CodeInsufficientPayment, services.CodeIdempotencyMismatch:
+		return http.StatusUnprocessableEntity
+	case services.CodeUnauthorized:
+		return http.StatusUnauthorized
+	case services.CodeForbidden:
+		return http.StatusForbidden
+	case CodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError mengirim error dari service sebagai JSON {code, message, details}
+func writeError(w http.ResponseWriter, err error) {
+	e := services.AsError(err)
+	if e.Code == services.CodeInternal {
+		log.Println("internal error:", err)
+	}
+	writeJSON(w, statusFor(e.Code), ErrorResponse{Code: e.Code, Message: e.Message, Details: e.Details})
+}
+
+// writeBadRequest untuk input yang gagal di-parse di handler (ID, body JSON, query)
+func writeBadRequest(w http.ResponseWriter, message string) {
+	writeError(w, services.NewValidationError(message))
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeError(w, &services.Error{Code: CodeMethodNotAllowed, Message: "method not allowed"})
+}
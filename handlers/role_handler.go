@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type RoleHandler struct {
+	service services.RoleService
+}
+
+func NewRoleHandler(service services.RoleService) *RoleHandler {
+	return &RoleHandler{service: service}
+}
+
+// HandleRoles -> GET /api/roles, semua role beserta permission-nya
+func (h *RoleHandler) HandleRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	roles, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// HandleRoleByID -> PUT /api/roles/{id} dengan body {"permissions": [...]}
+func (h *RoleHandler) HandleRoleByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/roles/"))
+	if err != nil {
+		writeBadRequest(w, "invalid role ID")
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	var req models.Role
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	role, err := h.service.UpdatePermissions(id, req.Permissions)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, role)
+}
+
+// GetUserRoles -> GET /api/users/{id}/roles
+func (h *RoleHandler) GetUserRoles(w http.ResponseWriter, r *http.Request, userID int) {
+	roles, err := h.service.GetUserRoles(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+// SetUserRoles -> PUT /api/users/{id}/roles dengan body {"roles": ["cashier"]}
+func (h *RoleHandler) SetUserRoles(w http.ResponseWriter, r *http.Request, userID int) {
+	var req models.RoleAssignment
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	roles, err := h.service.SetUserRoles(userID, req.Roles)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
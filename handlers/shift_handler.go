@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ShiftHandler struct {
+	service services.ShiftService
+}
+
+func NewShiftHandler(service services.ShiftService) *ShiftHandler {
+	return &ShiftHandler{service: service}
+}
+
+// HandleShifts -> GET /api/shifts?user_id=&status=, POST /api/shifts buka shift user yang login
+func (h *ShiftHandler) HandleShifts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	case http.MethodPost:
+		h.Open(w, r)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// HandleShiftByID -> GET /api/shifts/current, GET /api/shifts/{id} dan POST /api/shifts/{id}/close
+func (h *ShiftHandler) HandleShiftByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/shifts/"), "/")
+	if idStr == "current" && action == "" {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		h.Current(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, "invalid shift ID")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetReport(w, r, id)
+	case action == "close" && r.Method == http.MethodPost:
+		h.Close(w, r, id)
+	case action == "" || action == "close":
+		writeMethodNotAllowed(w)
+	default:
+		writeError(w, services.NewNotFoundError("route not found"))
+	}
+}
+
+// shiftUserID mengembalikan user yang login; shift selalu milik user tersebut
+func shiftUserID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID := currentUserID(r)
+	if userID == 0 {
+		writeError(w, services.NewUnauthorizedError("authentication required"))
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *ShiftHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := models.ShiftFilter{Status: r.URL.Query().Get("status")}
+	userID, err := queryInt(r, "user_id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if userID != nil {
+		filter.UserID = *userID
+	}
+
+	shifts, err := h.service.GetAll(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, shifts)
+}
+
+func (h *ShiftHandler) Open(w http.ResponseWriter, r *http.Request) {
+	userID, ok := shiftUserID(w, r)
+	if !ok {
+		return
+	}
+	var req models.OpenShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	shift, err := h.service.Open(userID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, shift)
+}
+
+func (h *ShiftHandler) Current(w http.ResponseWriter, r *http.Request) {
+	userID, ok := shiftUserID(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.service.Current(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (h *ShiftHandler) GetReport(w http.ResponseWriter, r *http.Request, id int) {
+	report, err := h.service.GetReport(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (h *ShiftHandler) Close(w http.ResponseWriter, r *http.Request, id int) {
+	userID, ok := shiftUserID(w, r)
+	if !ok {
+		return
+	}
+	var req models.CloseShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	report, err := h.service.Close(userID, id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
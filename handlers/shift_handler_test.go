@@ -0,0 +1,103 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestShiftHandler(t *testing.T) {
+	h := newAuthMux(t)
+	owner := login(t, h, "admin", "rahasia123").Token
+	if rec := doAuthRequest(t, h, owner, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create product status = %d", rec.Code)
+	}
+	cashier := createUserWithRole(t, h, owner, "kasir", "cashier")
+	manager := createUserWithRole(t, h, owner, "manajer", "manager")
+	other := createUserWithRole(t, h, owner, "kasir2", "cashier")
+
+	rec := doAuthRequest(t, h, cashier, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":1}]}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("checkout without shift status = %d, want 409", rec.Code)
+	}
+
+	rec = doAuthRequest(t, h, cashier, http.MethodPost, "/api/shifts", `{"opening_cash":100000}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("open shift status = %d, body %s", rec.Code, rec.Body)
+	}
+	var shift models.Shift
+	decode(t, rec, &shift)
+	if shift.Username != "kasir" || shift.Status != models.ShiftOpen {
+		t.Errorf("opened shift = %+v", shift)
+	}
+
+	rec = doAuthRequest(t, h, cashier, http.MethodPost, "/api/checkout",
+		`{"items":[{"product_id":1,"quantity":2}],"payments":[{"method":"cash","amount":20000}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout status = %d, body %s", rec.Code, rec.Body)
+	}
+	var trx models.Transaction
+	decode(t, rec, &trx)
+	if trx.CashierID == nil || trx.ShiftID == nil || *trx.ShiftID != shift.ID {
+		t.Errorf("checkout cashier/shift = %v/%v", trx.CashierID, trx.ShiftID)
+	}
+
+	var page models.TransactionPage
+	decode(t, doAuthRequest(t, h, owner, http.MethodGet, "/api/transactions?shift_id=1", ""), &page)
+	if page.Total != 1 {
+		t.Errorf("transactions in shift = %d, want 1", page.Total)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"invalid ID", owner, http.MethodGet, "/api/shifts/abc", "", http.StatusBadRequest},
+		{"unknown action", owner, http.MethodGet, "/api/shifts/1/audit", "", http.StatusNotFound},
+		{"missing shift", owner, http.MethodGet, "/api/shifts/99", "", http.StatusNotFound},
+		{"owner has no shift", owner, http.MethodGet, "/api/shifts/current", "", http.StatusNotFound},
+		{"other cashier closes shift", other, http.MethodPost, "/api/shifts/1/close", `{"counted_cash":0}`, http.StatusForbidden},
+		{"counted cash required", cashier, http.MethodPost, "/api/shifts/1/close", `{}`, http.StatusBadRequest},
+		{"close method", owner, http.MethodGet, "/api/shifts/1/close", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doAuthRequest(t, h, tt.token, tt.method, tt.path, tt.body); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	rec = doAuthRequest(t, h, cashier, http.MethodPost, "/api/shifts/1/close", `{"counted_cash":111000,"note":"lebih"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("close status = %d, body %s", rec.Code, rec.Body)
+	}
+	var report models.ShiftReport
+	decode(t, rec, &report)
+	if report.ExpectedCash != 110000 || report.Difference == nil || *report.Difference != 1000 || report.Shift.Note != "lebih" {
+		t.Errorf("close report = %+v", report)
+	}
+
+	var shifts []models.Shift
+	decode(t, doAuthRequest(t, h, manager, http.MethodGet, "/api/shifts?status=closed", ""), &shifts)
+	if len(shifts) != 1 || shifts[0].CountedCash == nil || *shifts[0].CountedCash != 111000 {
+		t.Errorf("closed shifts = %+v", shifts)
+	}
+
+	// Kasir pulang tanpa menutup shift: manager (shift:manage) yang menutupnya
+	rec = doAuthRequest(t, h, cashier, http.MethodPost, "/api/shifts", `{"opening_cash":50000}`)
+	decode(t, rec, &shift)
+	rec = doAuthRequest(t, h, manager, http.MethodPost, "/api/shifts/"+strconv.Itoa(shift.ID)+"/close", `{"counted_cash":50000,"note":"ditutup manager"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("manager close status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := doAuthRequest(t, h, cashier, http.MethodPost, "/api/shifts", `{"opening_cash":50000}`); rec.Code != http.StatusCreated {
+		t.Errorf("reopen after manager close status = %d, body %s", rec.Code, rec.Body)
+	}
+}
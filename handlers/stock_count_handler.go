@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+)
+
+type StockCountHandler struct {
+	service services.StockCountService
+}
+
+func NewStockCountHandler(service services.StockCountService) *StockCountHandler {
+	return &StockCountHandler{service: service}
+}
+
+// HandleStockCounts -> GET /api/stock-counts?status= dan POST /api/stock-counts (mulai sesi)
+func (h *StockCountHandler) HandleStockCounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	case http.MethodPost:
+		h.Start(w, r)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// HandleStockCountByID -> GET /api/stock-counts/{id}, POST {id}/items, {id}/finalize dan {id}/cancel
+func (h *StockCountHandler) HandleStockCountByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/stock-counts/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, "invalid stock count ID")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "items" && r.Method == http.MethodPost:
+		h.Submit(w, r, id)
+	case action == "finalize" && r.Method == http.MethodPost:
+		h.Finalize(w, r, id)
+	case action == "cancel" && r.Method == http.MethodPost:
+		h.Cancel(w, r, id)
+	case action == "" || action == "items" || action == "finalize" || action == "cancel":
+		writeMethodNotAllowed(w)
+	default:
+		writeError(w, services.NewNotFoundError("route not found"))
+	}
+}
+
+func (h *StockCountHandler) List(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, counts)
+}
+
+func (h *StockCountHandler) Start(w http.ResponseWriter, r *http.Request) {
+	var req models.StartStockCountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	count, err := h.service.Start(currentUserID(r), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, count)
+}
+
+func (h *StockCountHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	count, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, count)
+}
+
+func (h *StockCountHandler) Submit(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.SubmitStockCountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	count, err := h.service.Submit(id, currentUserID(r), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, count)
+}
+
+func (h *StockCountHandler) Finalize(w http.ResponseWriter, r *http.Request, id int) {
+	count, err := h.service.Finalize(id, currentUserID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, count)
+}
+
+func (h *StockCountHandler) Cancel(w http.ResponseWriter, r *http.Request, id int) {
+	count, err := h.service.Cancel(id, currentUserID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, count)
+}
@@ -0,0 +1,122 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestStockCountHandler(t *testing.T) {
+	h := newAuthMux(t)
+	owner := login(t, h, "admin", "rahasia123").Token
+	for _, body := range []string{`{"name":"Teh","price":5000,"stock":10}`, `{"name":"Kopi","price":8000,"stock":5}`} {
+		if rec := doAuthRequest(t, h, owner, http.MethodPost, "/api/products", body); rec.Code != http.StatusCreated {
+			t.Fatalf("create product status = %d", rec.Code)
+		}
+	}
+	manager := createUserWithRole(t, h, owner, "manajer", "manager")
+	cashier := createUserWithRole(t, h, owner, "kasir", "cashier")
+
+	rec := doAuthRequest(t, h, manager, http.MethodPost, "/api/stock-counts", `{"note":"Opname Januari"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("start status = %d, body %s", rec.Code, rec.Body)
+	}
+	var count models.StockCount
+	decode(t, rec, &count)
+
+	// Dua device mengirim batch masing-masing; teh dihitung ulang oleh device kedua
+	for _, body := range []string{
+		`{"items":[{"product_id":1,"counted_quantity":7},{"product_id":2,"counted_quantity":5}]}`,
+		`{"items":[{"product_id":1,"counted_quantity":8}]}`,
+	} {
+		if rec := doAuthRequest(t, h, manager, http.MethodPost, "/api/stock-counts/1/items", body); rec.Code != http.StatusOK {
+			t.Fatalf("submit status = %d, body %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec = doAuthRequest(t, h, owner, http.MethodPost, "/api/stock-counts/1/finalize", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("finalize status = %d, body %s", rec.Code, rec.Body)
+	}
+	decode(t, rec, &count)
+	want := models.StockCountSummary{ItemsCounted: 2, ItemsWithVariance: 1, ShortageQuantity: 2, VarianceValue: -10000}
+	if count.Status != models.StockCountFinalized || count.Summary != want {
+		t.Errorf("finalized count = %+v, want summary %+v", count, want)
+	}
+	var teh models.Product
+	decode(t, doAuthRequest(t, h, owner, http.MethodGet, "/api/products/1", ""), &teh)
+	if teh.Stock != 8 {
+		t.Errorf("teh stock = %d, want 8", teh.Stock)
+	}
+
+	rec = doAuthRequest(t, h, manager, http.MethodPost, "/api/products/2/adjust-stock", `{"quantity":-1,"note":"kemasan rusak"}`)
+	var kopi models.Product
+	decode(t, rec, &kopi)
+	if rec.Code != http.StatusOK || kopi.Stock != 4 || kopi.Name != "Kopi" || kopi.Price != 8000 {
+		t.Errorf("adjust stock = %d, %+v", rec.Code, kopi)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"cashier forbidden", cashier, http.MethodGet, "/api/stock-counts", "", http.StatusForbidden},
+		{"cashier cannot adjust", cashier, http.MethodPost, "/api/products/1/adjust-stock", `{"quantity":1,"note":"x"}`, http.StatusForbidden},
+		{"list finalized", manager, http.MethodGet, "/api/stock-counts?status=finalized", "", http.StatusOK},
+		{"invalid status", manager, http.MethodGet, "/api/stock-counts?status=done", "", http.StatusBadRequest},
+		{"submit after finalize", manager, http.MethodPost, "/api/stock-counts/1/items", `{"items":[{"product_id":1,"counted_quantity":1}]}`, http.StatusConflict},
+		{"finalize twice", manager, http.MethodPost, "/api/stock-counts/1/finalize", "", http.StatusConflict},
+		{"missing count", manager, http.MethodGet, "/api/stock-counts/99", "", http.StatusNotFound},
+		{"invalid ID", manager, http.MethodGet, "/api/stock-counts/abc", "", http.StatusBadRequest},
+		{"unknown action", manager, http.MethodPost, "/api/stock-counts/1/approve", "", http.StatusNotFound},
+		{"finalize method", manager, http.MethodGet, "/api/stock-counts/1/finalize", "", http.StatusMethodNotAllowed},
+		{"adjust zero", manager, http.MethodPost, "/api/products/1/adjust-stock", `{"quantity":0,"note":"x"}`, http.StatusBadRequest},
+		{"adjust without note", manager, http.MethodPost, "/api/products/1/adjust-stock", `{"quantity":1}`, http.StatusBadRequest},
+		{"adjust below zero", manager, http.MethodPost, "/api/products/1/adjust-stock", `{"quantity":-9,"note":"hilang"}`, http.StatusConflict},
+		{"adjust missing product", manager, http.MethodPost, "/api/products/99/adjust-stock", `{"quantity":1,"note":"x"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doAuthRequest(t, h, tt.token, tt.method, tt.path, tt.body); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	// Sesi kedua: validasi batch, finalize tanpa hitungan dan cancel
+	if rec := doAuthRequest(t, h, manager, http.MethodPost, "/api/stock-counts", `{}`); rec.Code != http.StatusCreated {
+		t.Fatalf("start second status = %d", rec.Code)
+	}
+	second := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"second open count", http.MethodPost, "/api/stock-counts", `{}`, http.StatusConflict},
+		{"empty batch", http.MethodPost, "/api/stock-counts/2/items", `{"items":[]}`, http.StatusBadRequest},
+		{"negative count", http.MethodPost, "/api/stock-counts/2/items", `{"items":[{"product_id":1,"counted_quantity":-1}]}`, http.StatusBadRequest},
+		{"missing quantity", http.MethodPost, "/api/stock-counts/2/items", `{"items":[{"product_id":1}]}`, http.StatusBadRequest},
+		{"duplicate product", http.MethodPost, "/api/stock-counts/2/items", `{"items":[{"product_id":1,"counted_quantity":1},{"product_id":1,"counted_quantity":2}]}`, http.StatusBadRequest},
+		{"unknown product", http.MethodPost, "/api/stock-counts/2/items", `{"items":[{"product_id":99,"counted_quantity":1}]}`, http.StatusBadRequest},
+		{"finalize uncounted", http.MethodPost, "/api/stock-counts/2/finalize", "", http.StatusConflict},
+	}
+	for _, tt := range second {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doAuthRequest(t, h, manager, tt.method, tt.path, tt.body); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+	rec = doAuthRequest(t, h, manager, http.MethodPost, "/api/stock-counts/2/cancel", "")
+	decode(t, rec, &count)
+	if rec.Code != http.StatusOK || count.Status != models.StockCountCancelled || count.ClosedBy == nil {
+		t.Errorf("cancel = %d, %+v", rec.Code, count)
+	}
+}
@@ -5,13 +5,15 @@ import (
 	"kasir-api-golang-v1/models"
 	"kasir-api-golang-v1/services"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type TransactionHandler struct {
-	service *services.TransactionService
+	service services.TransactionService
 }
 
-func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
+func NewTransactionHandler(service services.TransactionService) *TransactionHandler {
 	return &TransactionHandler{service: service}
 }
 
@@ -20,7 +22,7 @@ func (h *TransactionHandler) HandleCheckout(w http.ResponseWriter, r *http.Reque
 	case http.MethodPost:
 		h.Checkout(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 	}
 }
 
@@ -28,47 +30,186 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	var req models.CheckoutRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeBadRequest(w, "invalid request body")
 		return
 	}
+	req.CashierID = currentUserID(r)
 
-	// Validasi: items tidak boleh kosong
-	if len(req.Items) == 0 {
-		http.Error(w, "Items cannot be empty", http.StatusBadRequest)
+	transaction, err := h.service.Checkout(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+// HandleCheckoutPreview -> POST /api/checkout/preview, hitung total tanpa menyimpan transaksi
+func (h *TransactionHandler) HandleCheckoutPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	var req models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	preview, err := h.service.Preview(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preview)
+}
+
+// HandleTransactions -> GET /api/transactions?start_date=&end_date=&min_total=&max_total=&cashier_id=&shift_id=&page=&limit=
+func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// HandleTransactionByID -> GET /api/transactions/{id}
+// POST /api/transactions/{id}/void & POST /api/transactions/{id}/refund
+func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, "invalid transaction ID")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "void" && r.Method == http.MethodPost:
+		h.Void(w, r, id)
+	case action == "refund" && r.Method == http.MethodPost:
+		h.Refund(w, r, id)
+	case action == "" || action == "void" || action == "refund":
+		writeMethodNotAllowed(w)
+	default:
+		writeError(w, services.NewNotFoundError("route not found"))
+	}
+}
+
+// queryInt membaca query param integer opsional, nil jika tidak diisi
+func queryInt(r *http.Request, key string) (*int, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, services.NewValidationError("invalid query parameter", services.FieldError{Field: key, Message: "must be an integer"})
+	}
+	return &v, nil
+}
+
+func (h *TransactionHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := models.TransactionFilter{
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+	}
+
+	var err error
+	if filter.MinTotal, err = queryInt(r, "min_total"); err != nil {
+		writeError(w, err)
 		return
 	}
+	if filter.MaxTotal, err = queryInt(r, "max_total"); err != nil {
+		writeError(w, err)
+		return
+	}
+	for key, dst := range map[string]*int{"page": &filter.Page, "limit": &filter.Limit, "cashier_id": &filter.CashierID, "shift_id": &filter.ShiftID} {
+		v, err := queryInt(r, key)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if v != nil {
+			*dst = *v
+		}
+	}
 
-	transaction, err := h.service.Checkout(req.Items)
+	page, err := h.service.List(filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
+	writeJSON(w, http.StatusOK, page)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(transaction)
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	transaction, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	req.CashierID = currentUserID(r)
+
+	refund, err := h.service.Void(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, refund)
+}
+
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	req.CashierID = currentUserID(r)
+
+	refund, err := h.service.Refund(id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, refund)
 }
 
 // HandleReportHariIni untuk sales summary hari ini
 func (h *TransactionHandler) HandleReportHariIni(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
 	report, err := h.service.GetTodayReport()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(report)
+	writeJSON(w, http.StatusOK, report)
 }
 
 // HandleReport untuk sales summary dengan date range
 func (h *TransactionHandler) HandleReport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeMethodNotAllowed(w)
 		return
 	}
 
@@ -76,16 +217,32 @@ func (h *TransactionHandler) HandleReport(w http.ResponseWriter, r *http.Request
 	endDate := r.URL.Query().Get("end_date")
 
 	if startDate == "" || endDate == "" {
-		http.Error(w, "start_date and end_date are required", http.StatusBadRequest)
+		writeBadRequest(w, "start_date and end_date are required")
 		return
 	}
 
 	report, err := h.service.GetRangeReport(startDate, endDate)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// HandleProfitReport untuk laporan laba kotor: GET /api/report/profit?start_date=&end_date=&group_by=
+func (h *TransactionHandler) HandleProfitReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	q := r.URL.Query()
+	report, err := h.service.GetProfitReport(q.Get("start_date"), q.Get("end_date"), q.Get("group_by"))
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(report)
+	writeJSON(w, http.StatusOK, report)
 }
@@ -0,0 +1,333 @@
+package handlers_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+)
+
+func TestTransactionHandlerCheckout(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantTotal  int
+	}{
+		{"success", http.MethodPost, `{"items":[{"product_id":1,"quantity":2}]}`, http.StatusOK, 10000},
+		{"empty items", http.MethodPost, `{"items":[]}`, http.StatusBadRequest, 0},
+		{"invalid json", http.MethodPost, `{"items":`, http.StatusBadRequest, 0},
+		{"insufficient stock", http.MethodPost, `{"items":[{"product_id":1,"quantity":50}]}`, http.StatusConflict, 0},
+		{"cash with change", http.MethodPost, `{"items":[{"product_id":1,"quantity":2}],"payments":[{"method":"cash","amount":20000}]}`, http.StatusOK, 10000},
+		{"underpayment", http.MethodPost, `{"items":[{"product_id":1,"quantity":2}],"payments":[{"method":"cash","amount":5000}]}`, http.StatusUnprocessableEntity, 0},
+		{"unknown payment method", http.MethodPost, `{"items":[{"product_id":1,"quantity":2}],"payments":[{"method":"bitcoin","amount":10000}]}`, http.StatusBadRequest, 0},
+		{"method not allowed", http.MethodGet, "", http.StatusMethodNotAllowed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newTestMux()
+			doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+
+			rec := doRequest(t, mux, tt.method, "/api/checkout", tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var trx models.Transaction
+			decode(t, rec, &trx)
+			if trx.TotalAmount != tt.wantTotal || len(trx.Details) != 1 {
+				t.Errorf("transaction = %+v, want total %d", trx, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestTransactionHandlerReports(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"cost_price":3000,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":2}]}`)
+
+	today := time.Now().Format("2006-01-02")
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantStatus  int
+		wantRevenue float64
+	}{
+		{"today", http.MethodGet, "/api/report/hari-ini", http.StatusOK, 10000},
+		{"today wrong method", http.MethodPost, "/api/report/hari-ini", http.StatusMethodNotAllowed, 0},
+		{"range", http.MethodGet, "/api/report?start_date=" + today + "&end_date=" + today, http.StatusOK, 10000},
+		{"range missing params", http.MethodGet, "/api/report?start_date=" + today, http.StatusBadRequest, 0},
+		{"range wrong method", http.MethodPost, "/api/report", http.StatusMethodNotAllowed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var report map[string]interface{}
+			decode(t, rec, &report)
+			if report["total_revenue"] != tt.wantRevenue {
+				t.Errorf("total_revenue = %v, want %v", report["total_revenue"], tt.wantRevenue)
+			}
+			byMethod, _ := report["revenue_per_metode"].(map[string]interface{})
+			if byMethod["cash"] != tt.wantRevenue {
+				t.Errorf("revenue_per_metode = %v, want cash %v", report["revenue_per_metode"], tt.wantRevenue)
+			}
+			if report["total_cogs"] != 6000.0 || report["gross_profit"] != 4000.0 || report["gross_margin"] != 40.0 {
+				t.Errorf("profit = %v / %v / %v", report["total_cogs"], report["gross_profit"], report["gross_margin"])
+			}
+		})
+	}
+}
+
+func TestTransactionHandlerProfitReport(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/categories", `{"name":"Minuman"}`)
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"cost_price":3000,"stock":10,"category_id":2}`)
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Roti","price":4000,"cost_price":3500,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":2},{"product_id":2,"quantity":1}]}`)
+
+	today := time.Now().Format("2006-01-02")
+	query := "/api/report/profit?start_date=" + today + "&end_date=" + today
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantLines  []models.ProfitLine
+	}{
+		{"total only", http.MethodGet, query, http.StatusOK, []models.ProfitLine{}},
+		{"by product", http.MethodGet, query + "&group_by=product", http.StatusOK, []models.ProfitLine{
+			{ID: 1, Name: "Teh", Quantity: 2, NetSales: 10000, COGS: 6000, GrossProfit: 4000, Margin: 40},
+			{ID: 2, Name: "Roti", Quantity: 1, NetSales: 4000, COGS: 3500, GrossProfit: 500, Margin: 12.5},
+		}},
+		{"by category", http.MethodGet, query + "&group_by=category", http.StatusOK, []models.ProfitLine{
+			{ID: 1, Name: "No Category", Quantity: 1, NetSales: 4000, COGS: 3500, GrossProfit: 500, Margin: 12.5},
+			{ID: 2, Name: "Minuman", Quantity: 2, NetSales: 10000, COGS: 6000, GrossProfit: 4000, Margin: 40},
+		}},
+		{"by day", http.MethodGet, query + "&group_by=day", http.StatusOK, []models.ProfitLine{
+			{Period: today, Quantity: 3, NetSales: 14000, COGS: 9500, GrossProfit: 4500, Margin: 32.14},
+		}},
+		{"invalid group", http.MethodGet, query + "&group_by=cashier", http.StatusBadRequest, nil},
+		{"missing dates", http.MethodGet, "/api/report/profit?start_date=" + today, http.StatusBadRequest, nil},
+		{"invalid date", http.MethodGet, "/api/report/profit?start_date=kemarin&end_date=" + today, http.StatusBadRequest, nil},
+		{"wrong method", http.MethodPost, query, http.StatusMethodNotAllowed, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var report models.ProfitReport
+			decode(t, rec, &report)
+			if !reflect.DeepEqual(report.Lines, tt.wantLines) {
+				t.Errorf("lines = %+v, want %+v", report.Lines, tt.wantLines)
+			}
+			total := models.ProfitLine{Quantity: 3, NetSales: 14000, COGS: 9500, GrossProfit: 4500, Margin: 32.14}
+			if report.Total != total {
+				t.Errorf("total = %+v, want %+v", report.Total, total)
+			}
+		})
+	}
+}
+
+func TestTransactionHandlerInsufficientStockBody(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":3}`)
+
+	rec := doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":5}]}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", rec.Code)
+	}
+
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details struct {
+			ProductID   int    `json:"product_id"`
+			ProductName string `json:"product_name"`
+			Available   int    `json:"available"`
+			Requested   int    `json:"requested"`
+		} `json:"details"`
+	}
+	decode(t, rec, &body)
+	if body.Code != "INSUFFICIENT_STOCK" || body.Details.ProductID != 1 || body.Details.Available != 3 || body.Details.Requested != 5 {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestTransactionHandlerHistory(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":1}]}`)
+	doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":3}]}`)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantRows   int
+	}{
+		{"list all", http.MethodGet, "/api/transactions", http.StatusOK, 2},
+		{"list min total", http.MethodGet, "/api/transactions?min_total=10000", http.StatusOK, 1},
+		{"list paginated", http.MethodGet, "/api/transactions?page=2&limit=1", http.StatusOK, 1},
+		{"list bad min total", http.MethodGet, "/api/transactions?min_total=abc", http.StatusBadRequest, 0},
+		{"list bad date", http.MethodGet, "/api/transactions?start_date=kemarin", http.StatusBadRequest, 0},
+		{"list wrong method", http.MethodPost, "/api/transactions", http.StatusMethodNotAllowed, 0},
+		{"get by id", http.MethodGet, "/api/transactions/2", http.StatusOK, 0},
+		{"get missing", http.MethodGet, "/api/transactions/99", http.StatusNotFound, 0},
+		{"get invalid id", http.MethodGet, "/api/transactions/abc", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantRows > 0 {
+				var page models.TransactionPage
+				decode(t, rec, &page)
+				if len(page.Data) != tt.wantRows || page.Total == 0 {
+					t.Errorf("page = %+v, want %d rows", page, tt.wantRows)
+				}
+			}
+		})
+	}
+
+	var trx models.Transaction
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/transactions/2", ""), &trx)
+	if trx.TotalAmount != 15000 || len(trx.Details) != 1 || trx.Details[0].ProductName != "Teh" {
+		t.Errorf("receipt = %+v", trx)
+	}
+}
+
+func TestTransactionHandlerVoidAndRefund(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+	doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":4}]}`)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"refund without reason", http.MethodPost, "/api/transactions/1/refund", `{"items":[{"detail_id":1,"quantity":1}]}`, http.StatusBadRequest},
+		{"refund without items", http.MethodPost, "/api/transactions/1/refund", `{"reason":"rusak"}`, http.StatusBadRequest},
+		{"refund too many", http.MethodPost, "/api/transactions/1/refund", `{"reason":"rusak","items":[{"detail_id":1,"quantity":9}]}`, http.StatusBadRequest},
+		{"refund one", http.MethodPost, "/api/transactions/1/refund", `{"reason":"rusak","items":[{"detail_id":1,"quantity":1}]}`, http.StatusOK},
+		{"void wrong method", http.MethodGet, "/api/transactions/1/void", "", http.StatusMethodNotAllowed},
+		{"void missing transaction", http.MethodPost, "/api/transactions/99/void", `{"reason":"batal"}`, http.StatusNotFound},
+		{"void", http.MethodPost, "/api/transactions/1/void", `{"reason":"batal"}`, http.StatusOK},
+		{"void again", http.MethodPost, "/api/transactions/1/void", `{"reason":"batal"}`, http.StatusConflict},
+		{"unknown action", http.MethodPost, "/api/transactions/1/cancel", `{}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	var p models.Product
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/products/1", ""), &p)
+	if p.Stock != 10 {
+		t.Errorf("stock after void = %d, want 10", p.Stock)
+	}
+
+	var report map[string]interface{}
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/report/hari-ini", ""), &report)
+	if report["total_revenue"] != float64(0) || report["total_transaksi"] != float64(0) {
+		t.Errorf("report after void = %v", report)
+	}
+}
+
+func TestTransactionHandlerCheckoutLineValidation(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":10}`)
+
+	rec := doRequest(t, mux, http.MethodPost, "/api/checkout", `{"items":[{"product_id":1,"quantity":-3},{"product_id":1,"quantity":0}]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400 (body %s)", rec.Code, rec.Body)
+	}
+	var body struct {
+		Code    string `json:"code"`
+		Details []struct {
+			Field string `json:"field"`
+		} `json:"details"`
+	}
+	decode(t, rec, &body)
+	if body.Code != "VALIDATION_ERROR" || len(body.Details) != 2 || body.Details[0].Field != "items[0].quantity" {
+		t.Errorf("body = %+v", body)
+	}
+
+	var p models.Product
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/products/1", ""), &p)
+	if p.Stock != 10 {
+		t.Errorf("stock = %d, negative quantity must not change stock", p.Stock)
+	}
+}
+
+func TestTransactionHandlerCheckoutPreview(t *testing.T) {
+	mux, _ := newTestMux()
+	doRequest(t, mux, http.MethodPost, "/api/products", `{"name":"Teh","price":5000,"stock":3}`)
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		wantStatus   int
+		wantTotal    int
+		wantWarnings int
+	}{
+		{"preview", http.MethodPost, `{"items":[{"product_id":1,"quantity":2}]}`, http.StatusOK, 10000, 0},
+		{"stock warning", http.MethodPost, `{"items":[{"product_id":1,"quantity":5}]}`, http.StatusOK, 25000, 1},
+		{"invalid json", http.MethodPost, `{"items":`, http.StatusBadRequest, 0, 0},
+		{"empty items", http.MethodPost, `{"items":[]}`, http.StatusBadRequest, 0, 0},
+		{"method not allowed", http.MethodGet, "", http.StatusMethodNotAllowed, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, mux, tt.method, "/api/checkout/preview", tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var preview models.CheckoutPreview
+			decode(t, rec, &preview)
+			if preview.Transaction.TotalAmount != tt.wantTotal || len(preview.Warnings) != tt.wantWarnings {
+				t.Errorf("preview = %+v", preview)
+			}
+		})
+	}
+
+	var p models.Product
+	decode(t, doRequest(t, mux, http.MethodGet, "/api/products/1", ""), &p)
+	if p.Stock != 3 {
+		t.Errorf("stock = %d, preview must not change stock", p.Stock)
+	}
+}
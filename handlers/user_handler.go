@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type UserHandler struct {
+	service services.UserService
+	roles   *RoleHandler
+}
+
+func NewUserHandler(service services.UserService, roles services.RoleService) *UserHandler {
+	return &UserHandler{service: service, roles: NewRoleHandler(roles)}
+}
+
+// HandleUsers -> GET /api/users & POST /api/users
+func (h *UserHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// HandleUserByID -> GET/PUT/DELETE /api/users/{id}, GET/PUT /api/users/{id}/roles
+func (h *UserHandler) HandleUserByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/users/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeBadRequest(w, "invalid user ID")
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.Delete(w, r, id)
+	case action == "roles" && r.Method == http.MethodGet:
+		h.roles.GetUserRoles(w, r, id)
+	case action == "roles" && r.Method == http.MethodPut:
+		h.roles.SetUserRoles(w, r, id)
+	case action == "" || action == "roles":
+		writeMethodNotAllowed(w)
+	default:
+		writeError(w, services.NewNotFoundError("route not found"))
+	}
+}
+
+func (h *UserHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	users, err := h.service.GetAll()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	user, err := h.service.GetByID(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user := models.User{Active: true} // default aktif kalau "active" tidak dikirim
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.service.Create(&user); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// Update: password kosong berarti password lama tetap dipakai
+func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	user := models.User{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		writeBadRequest(w, "invalid request body")
+		return
+	}
+	user.ID = id // Pastikan ID sesuai URL
+
+	if err := h.service.Update(&user); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.Delete(id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "User deleted successfully"})
+}
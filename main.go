@@ -1,30 +1,75 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"github.com/spf13/viper"
 	"kasir-api-golang-v1/database"
 	"kasir-api-golang-v1/handlers"
 	"kasir-api-golang-v1/repositories"
 	"kasir-api-golang-v1/services"
 	"log"
 	"net/http"
-	"github.com/spf13/viper"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config struct untuk mapping .env
 type Config struct {
-	Port   string `mapstructure:"PORT"`
-	DBUser string `mapstructure:"DB_USER"`
-	DBPass string `mapstructure:"DB_PASS"`
-	DBHost string `mapstructure:"DB_HOST"`
-	DBPort string `mapstructure:"DB_PORT"`
-	DBName string `mapstructure:"DB_NAME"`
+	Port     string `mapstructure:"PORT"`
+	DBDriver string `mapstructure:"DB_DRIVER"` // mysql (default) atau sqlite
+	DBUser   string `mapstructure:"DB_USER"`
+	DBPass   string `mapstructure:"DB_PASS"`
+	DBHost   string `mapstructure:"DB_HOST"`
+	DBPort   string `mapstructure:"DB_PORT"`
+	DBName   string `mapstructure:"DB_NAME"` // untuk sqlite: path file database
+
+	DBAutoMigrate bool `mapstructure:"DB_AUTO_MIGRATE"` // jalankan migration saat startup (default true)
+
+	// Pajak & service charge, dalam persen (misalnya TAX_RATE=11 untuk PPN 11%)
+	TaxRate             float64 `mapstructure:"TAX_RATE"`
+	TaxInclusive        bool    `mapstructure:"TAX_INCLUSIVE"`         // harga produk sudah termasuk pajak
+	TaxExemptCategories string  `mapstructure:"TAX_EXEMPT_CATEGORIES"` // category ID dipisah koma, misalnya "3,5"
+	ServiceChargeRate   float64 `mapstructure:"SERVICE_CHARGE_RATE"`
+
+	// Cart yang di-parkir: lama reservasi stock, misalnya 15m
+	CartReservationTTL time.Duration `mapstructure:"CART_RESERVATION_TTL"`
+	// Lama Idempotency-Key checkout disimpan, misalnya 24h
+	IdempotencyKeyTTL time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	// Seberapa sering reservasi cart, Idempotency-Key dan session yang kedaluwarsa dibersihkan
+	SweepInterval time.Duration `mapstructure:"SWEEP_INTERVAL"`
+
+	// Masa berlaku token login, misalnya 12h
+	AuthTokenTTL time.Duration `mapstructure:"AUTH_TOKEN_TTL"`
+	// User admin pertama, hanya dibuat kalau tabel users masih kosong
+	AdminUsername string `mapstructure:"ADMIN_USERNAME"`
+	AdminPassword string `mapstructure:"ADMIN_PASSWORD"`
+
+	// Alert low stock saat checkout: log (default), webhook, file atau none.
+	// Target berisi URL untuk webhook atau path file untuk file.
+	StockAlertNotifier string `mapstructure:"STOCK_ALERT_NOTIFIER"`
+	StockAlertTarget   string `mapstructure:"STOCK_ALERT_TARGET"`
 }
 
 func main() {
 	// 1. Setup Viper
 	viper.SetConfigFile(".env")
 	viper.AutomaticEnv()
+	// AutomaticEnv tidak dibaca oleh Unmarshal kecuali key-nya sudah dikenal, jadi bind eksplisit
+	for _, key := range []string{"PORT", "DB_DRIVER", "DB_USER", "DB_PASS", "DB_HOST", "DB_PORT", "DB_NAME",
+		"TAX_RATE", "TAX_INCLUSIVE", "TAX_EXEMPT_CATEGORIES", "SERVICE_CHARGE_RATE", "CART_RESERVATION_TTL", "IDEMPOTENCY_KEY_TTL",
+		"SWEEP_INTERVAL", "AUTH_TOKEN_TTL", "ADMIN_USERNAME", "ADMIN_PASSWORD", "STOCK_ALERT_NOTIFIER", "STOCK_ALERT_TARGET"} {
+		viper.BindEnv(key)
+	}
+	viper.SetDefault("DB_AUTO_MIGRATE", true)
+	viper.SetDefault("CART_RESERVATION_TTL", services.DefaultCartReservationTTL)
+	viper.SetDefault("SWEEP_INTERVAL", time.Minute)
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", services.DefaultIdempotencyKeyTTL)
+	viper.SetDefault("AUTH_TOKEN_TTL", services.DefaultAuthTokenTTL)
+	viper.SetDefault("ADMIN_USERNAME", "admin")
+	viper.SetDefault("STOCK_ALERT_NOTIFIER", "log")
 	if err := viper.ReadInConfig(); err != nil {
 		log.Println("No .env file found, using system env")
 	}
@@ -34,40 +79,174 @@ func main() {
 		log.Fatal("Error loading config:", err)
 	}
 
-	// 2. Connect Database MySQL
-	db, err := database.InitDB(config.DBUser, config.DBPass, config.DBHost, config.DBPort, config.DBName)
+	taxConfig, err := config.taxConfig()
+	if err != nil {
+		log.Fatal("Invalid tax config:", err)
+	}
+	stockNotifier, err := services.NewStockNotifier(config.StockAlertNotifier, config.StockAlertTarget)
+	if err != nil {
+		log.Fatal("Invalid stock alert config:", err)
+	}
+
+	// Subcommand: kasir-api migrate [up|down [n]|status]
+	migrateMode := len(os.Args) > 1 && os.Args[1] == "migrate"
+
+	// 2. Connect Database (MySQL atau SQLite sesuai DB_DRIVER)
+	db, err := database.InitDB(config.DBDriver, config.DBUser, config.DBPass, config.DBHost, config.DBPort, config.DBName,
+		config.DBAutoMigrate && !migrateMode)
 	if err != nil {
 		log.Fatal("Failed to connect database:", err)
 	}
 	defer db.Close()
 
+	if migrateMode {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+		return
+	}
+
 	// 3. Dependency Injection (Wiring)
 	// Repositories
 	productRepo := repositories.NewProductRepository(db)
 	categoryRepo := repositories.NewCategoryRepository(db)
 	transactionRepo := repositories.NewTransactionRepository(db)
+	promoCodeRepo := repositories.NewPromoCodeRepository(db)
+	promotionRepo := repositories.NewPromotionRepository(db)
+	cartRepo := repositories.NewCartRepository(db)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	sessionRepo := repositories.NewSessionRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	shiftRepo := repositories.NewShiftRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	stockMovementRepo := repositories.NewStockMovementRepository(db)
+	stockCountRepo := repositories.NewStockCountRepository(db)
+	supplierRepo := repositories.NewSupplierRepository(db)
+	purchaseOrderRepo := repositories.NewPurchaseOrderRepository(db)
+
+	// Alert low stock dikirim di background; notifier none berarti tanpa alert
+	var stockAlerter *services.StockAlerter
+	if stockNotifier != nil {
+		stockAlerter = services.NewStockAlerter(stockNotifier, services.DefaultStockAlertBuffer)
+	}
 
 	// Services
 	categoryService := services.NewCategoryService(categoryRepo, productRepo)
-	productService := services.NewProductService(productRepo) 
-	transactionService := services.NewTransactionService(transactionRepo)
+	productService := services.NewProductService(productRepo, stockMovementRepo)
+	transactionService := services.NewTransactionService(transactionRepo, promoCodeRepo, promotionRepo, shiftRepo, taxConfig, stockAlerter)
+	promoCodeService := services.NewPromoCodeService(promoCodeRepo)
+	promotionService := services.NewPromotionService(promotionRepo)
+	cartService := services.NewCartService(cartRepo, transactionService, config.CartReservationTTL)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, config.IdempotencyKeyTTL)
+	userService := services.NewUserService(userRepo, sessionRepo, roleRepo, services.PasswordHasher{Iterations: services.DefaultPasswordIterations})
+	roleService := services.NewRoleService(roleRepo, userRepo)
+	authService := services.NewAuthService(userRepo, sessionRepo, config.AuthTokenTTL)
+	shiftService := services.NewShiftService(shiftRepo, roleService)
+	auditService := services.NewAuditService(auditRepo)
+	stockCountService := services.NewStockCountService(stockCountRepo)
+	supplierService := services.NewSupplierService(supplierRepo)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepo, supplierRepo)
+
+	// Tanpa user sama sekali tidak ada yang bisa login, jadi buat admin (owner) pertama dari env
+	if config.AdminPassword != "" {
+		created, err := userService.EnsureAdmin(config.AdminUsername, config.AdminPassword)
+		if err != nil {
+			log.Fatal("Failed to create admin user:", err)
+		}
+		if created {
+			log.Println("Created admin user", config.AdminUsername)
+		}
+	}
 
 	// Handlers
-	categoryHandler := handlers.NewCategoryHandler(categoryService) 
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	productHandler := handlers.NewProductHandler(productService)
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	cartHandler := handlers.NewCartHandler(cartService)
+	authHandler := handlers.NewAuthHandler(authService)
+	userHandler := handlers.NewUserHandler(userService, roleService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	shiftHandler := handlers.NewShiftHandler(shiftService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	stockCountHandler := handlers.NewStockCountHandler(stockCountService)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService)
+	checkoutHandler := handlers.Idempotent(idempotencyService, transactionHandler.HandleCheckout)
 
 	// 4. Routes
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/categories", categoryHandler.HandleCategories)
 	mux.HandleFunc("/api/categories/", categoryHandler.HandleCategoryDelete) // Handle delete by ID
 	mux.HandleFunc("/api/products", productHandler.HandleProducts)
-	mux.HandleFunc("/api/products/", productHandler.HandleProductByID)
-	mux.HandleFunc("/api/checkout", transactionHandler.HandleCheckout)          // POST
-	mux.HandleFunc("/api/report/hari-ini", transactionHandler.HandleReportHariIni) // GET
-	mux.HandleFunc("/api/report", transactionHandler.HandleReport)              // GET with query params
+	mux.HandleFunc("/api/products/", productHandler.HandleProductByID)                // {id}, {id}/stock-history, {id}/adjust-stock, stock-check, low-stock
+	mux.HandleFunc("/api/checkout", checkoutHandler)                                  // POST, dukung Idempotency-Key
+	mux.HandleFunc("/api/checkout/preview", transactionHandler.HandleCheckoutPreview) // POST, tanpa menyimpan
+	mux.HandleFunc("/api/transactions", transactionHandler.HandleTransactions)        // GET riwayat transaksi
+	mux.HandleFunc("/api/transactions/", transactionHandler.HandleTransactionByID)    // GET detail / cetak ulang struk, POST {id}/void & {id}/refund
+	mux.HandleFunc("/api/report/hari-ini", transactionHandler.HandleReportHariIni)    // GET
+	mux.HandleFunc("/api/report/profit", transactionHandler.HandleProfitReport)       // GET ?start_date=&end_date=&group_by=
+	mux.HandleFunc("/api/report", transactionHandler.HandleReport)                    // GET with query params
+	mux.HandleFunc("/api/promo-codes", promoCodeHandler.HandlePromoCodes)
+	mux.HandleFunc("/api/promo-codes/", promoCodeHandler.HandlePromoCodeByID)
+	mux.HandleFunc("/api/promotions", promotionHandler.HandlePromotions)
+	mux.HandleFunc("/api/promotions/", promotionHandler.HandlePromotionByID)
+	mux.HandleFunc("/api/carts", cartHandler.HandleCarts)
+	mux.HandleFunc("/api/carts/", cartHandler.HandleCartByID) // items, checkout
+	mux.HandleFunc("/api/shifts", shiftHandler.HandleShifts)
+	mux.HandleFunc("/api/shifts/", shiftHandler.HandleShiftByID) // current, {id}, {id}/close
+	mux.HandleFunc("/api/audit", auditHandler.HandleAudit)       // GET dengan query params
+	mux.HandleFunc("/api/stock-counts", stockCountHandler.HandleStockCounts)
+	mux.HandleFunc("/api/stock-counts/", stockCountHandler.HandleStockCountByID) // {id}, {id}/items, {id}/finalize, {id}/cancel
+	mux.HandleFunc("/api/suppliers", supplierHandler.HandleSuppliers)
+	mux.HandleFunc("/api/suppliers/", supplierHandler.HandleSupplierByID)
+	mux.HandleFunc("/api/purchase-orders", purchaseOrderHandler.HandlePurchaseOrders)
+	mux.HandleFunc("/api/purchase-orders/", purchaseOrderHandler.HandlePurchaseOrderByID) // {id}, {id}/approve, {id}/receive, {id}/cancel
+
+	mux.HandleFunc("/api/auth/login", authHandler.HandleLogin)     // POST, publik
+	mux.HandleFunc("/api/auth/refresh", authHandler.HandleRefresh) // POST
+	mux.HandleFunc("/api/auth/logout", authHandler.HandleLogout)   // POST
+	mux.HandleFunc("/api/auth/me", authHandler.HandleMe)           // GET
+	mux.HandleFunc("/api/users", userHandler.HandleUsers)
+	mux.HandleFunc("/api/users/", userHandler.HandleUserByID) // {id}/roles
+	mux.HandleFunc("/api/roles", roleHandler.HandleRoles)
+	mux.HandleFunc("/api/roles/", roleHandler.HandleRoleByID)
+
+	// Semua route wajib login kecuali login itu sendiri, lalu permission dicek per
+	// route dan method sesuai handlers.RoutePermissions
+	handler := handlers.RequireAuth(authService,
+		handlers.RequirePermission(roleService, handlers.RoutePermissions, mux), "/api/auth/login")
+
+	// Reservasi stock cart yang lewat TTL dikembalikan, Idempotency-Key dan session lama dihapus di background
+	if config.SweepInterval <= 0 {
+		log.Fatal("Invalid config: SWEEP_INTERVAL must be positive")
+	}
+	go services.RunSweeper(context.Background(), "cart reservation", config.SweepInterval, cartService.ExpireReservations)
+	go services.RunSweeper(context.Background(), "idempotency key", config.SweepInterval, idempotencyService.PurgeExpired)
+	go services.RunSweeper(context.Background(), "session", config.SweepInterval, authService.PurgeExpired)
+	if stockAlerter != nil {
+		go stockAlerter.Run(context.Background())
+	}
 
 	addr := ":" + config.Port
-	fmt.Println("Server running on MySQL at", addr)
-	log.Fatal(http.ListenAndServe(addr, mux))
-}
\ No newline at end of file
+	fmt.Println("Server running on", db.Dialect, "at", addr)
+	log.Fatal(http.ListenAndServe(addr, handler))
+}
+
+// taxConfig membangun services.TaxConfig dari env
+func (c Config) taxConfig() (services.TaxConfig, error) {
+	tax := services.TaxConfig{Rate: c.TaxRate, Inclusive: c.TaxInclusive, ServiceChargeRate: c.ServiceChargeRate}
+	for _, part := range strings.Split(c.TaxExemptCategories, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return tax, fmt.Errorf("TAX_EXEMPT_CATEGORIES: invalid category ID %q", part)
+		}
+		tax.ExemptCategories = append(tax.ExemptCategories, id)
+	}
+	return tax, tax.Validate()
+}
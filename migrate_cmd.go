@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"kasir-api-golang-v1/database"
+	"strconv"
+)
+
+// runMigrate menangani subcommand `migrate`
+func runMigrate(db *database.DB, args []string) error {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "up":
+		return db.MigrateUp()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+			steps = n
+		}
+		return db.MigrateDown(steps)
+	case "status":
+		statuses, err := db.MigrationStatuses()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q (use up, down [n] or status)", cmd)
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Category struct {
 	ID   int    `json:"id"`
@@ -8,35 +11,777 @@ type Category struct {
 }
 
 type Product struct {
-	ID           int    `json:"id"`
-	Name         string `json:"name"`
-	Price        int    `json:"price"`
-	Stock        int    `json:"stock"`
-	CategoryID   int    `json:"category_id"`
-	CategoryName string `json:"category_name,omitempty"` // Untuk respon join (Optional Task)
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Price           int    `json:"price"`
+	CostPrice       int    `json:"cost_price"` // harga pokok rata-rata tertimbang, diperbarui saat penerimaan barang
+	Stock           int    `json:"stock"`
+	ReorderPoint    int    `json:"reorder_point"`    // stock <= reorder_point berarti perlu order ulang, 0 = tidak dipantau
+	ReorderQuantity int    `json:"reorder_quantity"` // jumlah yang disarankan untuk di-order
+	CategoryID      int    `json:"category_id"`
+	CategoryName    string `json:"category_name,omitempty"` // Untuk respon join (Optional Task)
 }
 
+// Status transaksi
+const (
+	TransactionCompleted         = "completed"
+	TransactionPartiallyRefunded = "partially_refunded"
+	TransactionVoided            = "voided"
+)
+
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at"`
-	Details     []TransactionDetail `json:"details"`
+	ID             int                 `json:"id"`
+	TotalAmount    int                 `json:"total_amount"` // grand total yang dibayar pelanggan
+	Status         string              `json:"status"`
+	RefundedAmount int                 `json:"refunded_amount"`
+	RefundedTax    int                 `json:"refunded_tax"`
+	GrossAmount    int                 `json:"gross_amount"`    // sebelum diskon
+	DiscountAmount int                 `json:"discount_amount"` // diskon per baris + diskon transaksi + promo
+	Subtotal       int                 `json:"subtotal"`        // gross - diskon
+	ServiceCharge  int                 `json:"service_charge"`
+	TaxAmount      int                 `json:"tax_amount"`
+	TaxInclusive   bool                `json:"tax_inclusive"` // true: tax_amount sudah termasuk di subtotal + service_charge
+	PromoCode      string              `json:"promo_code,omitempty"`
+	PaidAmount     int                 `json:"paid_amount"`
+	ChangeAmount   int                 `json:"change_amount"`
+	CashierID      *int                `json:"cashier_id,omitempty"` // user yang login saat checkout
+	ShiftID        *int                `json:"shift_id,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	Details        []TransactionDetail `json:"details,omitempty"`
+	Payments       []Payment           `json:"payments,omitempty"`
+	Promotions     []AppliedPromotion  `json:"promotions,omitempty"`
+	Refunds        []Refund            `json:"refunds,omitempty"`
 }
 
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID               int    `json:"id"`
+	TransactionID    int    `json:"transaction_id"`
+	ProductID        int    `json:"product_id"`
+	ProductName      string `json:"product_name,omitempty"`
+	Quantity         int    `json:"quantity"`
+	Subtotal         int    `json:"subtotal"`        // setelah diskon
+	DiscountAmount   int    `json:"discount_amount"` // termasuk bagian diskon transaksi yang dibebankan ke baris ini
+	ServiceCharge    int    `json:"service_charge"`
+	TaxAmount        int    `json:"tax_amount"`
+	Total            int    `json:"total"`     // yang dibayar untuk baris ini, dasar nilai refund
+	UnitCost         int    `json:"unit_cost"` // harga pokok produk saat dijual
+	RefundedQuantity int    `json:"refunded_quantity"`
 }
 
-type CheckoutItem struct {
+// Jenis refund: void membatalkan seluruh sisa transaksi, refund hanya sebagian item
+const (
+	RefundTypeVoid    = "void"
+	RefundTypePartial = "refund"
+)
+
+type Refund struct {
+	ID            int          `json:"id"`
+	TransactionID int          `json:"transaction_id"`
+	Type          string       `json:"type"`
+	Reason        string       `json:"reason"`
+	Amount        int          `json:"amount"`
+	CashAmount    int          `json:"cash_amount"` // bagian Amount yang dibayar tunai dari laci, sisanya ke metode non-tunai
+	CashierID     *int         `json:"cashier_id,omitempty"`
+	ShiftID       *int         `json:"shift_id,omitempty"` // shift yang membayar refund, bukan shift transaksi aslinya
+	CreatedAt     time.Time    `json:"created_at"`
+	Items         []RefundItem `json:"items"`
+}
+
+type RefundItem struct {
+	ID                  int `json:"id"`
+	RefundID            int `json:"refund_id"`
+	TransactionDetailID int `json:"transaction_detail_id"`
+	ProductID           int `json:"product_id"`
+	Quantity            int `json:"quantity"`
+	Amount              int `json:"amount"`
+	TaxAmount           int `json:"tax_amount"` // bagian pajak dari Amount
+}
+
+// RefundLine adalah satu baris permintaan refund: detail transaksi mana dan berapa qty
+type RefundLine struct {
+	DetailID int `json:"detail_id"`
+	Quantity int `json:"quantity"`
+}
+
+// RefundRequest untuk POST /api/transactions/{id}/refund (items) dan /void (items diabaikan)
+type RefundRequest struct {
+	Reason    string       `json:"reason"`
+	Items     []RefundLine `json:"items"`
+	CashierID int          `json:"-"` // diisi handler dari user yang login
+}
+
+// Jenis diskon: persen dari harga atau potongan nominal rupiah
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// Discount adalah diskon manual dari kasir, per baris atau per transaksi
+type Discount struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"` // persen (0-100) atau rupiah
+}
+
+// PromoCode adalah kode promo yang bisa dipakai berulang kali
+type PromoCode struct {
+	ID         int        `json:"id"`
+	Code       string     `json:"code"`
+	Type       string     `json:"type"`
+	Value      int        `json:"value"`
+	MinSpend   int        `json:"min_spend"`
+	UsageLimit int        `json:"usage_limit"` // 0 = tanpa batas
+	UsedCount  int        `json:"used_count"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	Active     bool       `json:"active"`
+}
+
+// Jenis promosi otomatis
+const (
+	PromotionBuyXGetY  = "buy_x_get_y" // beli BuyQty gratis FreeQty untuk satu produk
+	PromotionBundle    = "bundle"      // paket beberapa produk dengan harga BundlePrice
+	PromotionHappyHour = "happy_hour"  // diskon persen pada jam tertentu
+)
+
+// Promotion adalah aturan promosi yang dievaluasi otomatis saat checkout.
+// Promosi dengan Priority lebih besar dievaluasi lebih dulu; promosi yang tidak
+// Stackable tidak digabung dengan promosi lain pada produk yang sama.
+type Promotion struct {
+	ID              int             `json:"id"`
+	Name            string          `json:"name"`
+	Type            string          `json:"type"`
+	Items           []PromotionItem `json:"items"` // happy_hour tanpa items berlaku untuk semua produk
+	BuyQty          int             `json:"buy_qty,omitempty"`
+	FreeQty         int             `json:"free_qty,omitempty"`
+	BundlePrice     int             `json:"bundle_price,omitempty"`
+	DiscountPercent int             `json:"discount_percent,omitempty"`
+	StartTime       string          `json:"start_time,omitempty"` // HH:MM, happy_hour
+	EndTime         string          `json:"end_time,omitempty"`   // HH:MM, boleh melewati tengah malam
+	Priority        int             `json:"priority"`
+	Stackable       bool            `json:"stackable"`
+	Active          bool            `json:"active"`
+	ValidFrom       *time.Time      `json:"valid_from,omitempty"`
+	ValidUntil      *time.Time      `json:"valid_until,omitempty"`
+}
+
+// PromotionItem adalah produk yang terlibat dalam promosi (untuk bundle beserta qty-nya)
+type PromotionItem struct {
 	ProductID int `json:"product_id"`
 	Quantity  int `json:"quantity"`
 }
 
+// AppliedPromotion adalah promosi yang terpakai pada transaksi, untuk ditampilkan di struk
+type AppliedPromotion struct {
+	ID             int    `json:"id"`
+	TransactionID  int    `json:"transaction_id"`
+	PromotionID    int    `json:"promotion_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	DiscountAmount int    `json:"discount_amount"`
+}
+
+type CheckoutItem struct {
+	ProductID int       `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Discount  *Discount `json:"discount,omitempty"`
+}
+
+// Metode pembayaran yang diterima
+const (
+	PaymentCash    = "cash"
+	PaymentCard    = "card"
+	PaymentQRIS    = "qris"
+	PaymentEWallet = "ewallet"
+)
+
+// Payment adalah pembayaran yang tercatat pada transaksi. Amount adalah nilai
+// yang masuk ke tagihan (untuk cash sudah dikurangi kembalian), Tendered adalah
+// uang yang diserahkan pelanggan.
+type Payment struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	Method        string `json:"method"`
+	Amount        int    `json:"amount"`
+	Tendered      int    `json:"tendered"`
+}
+
+// CheckoutPayment adalah input pembayaran di CheckoutRequest
+type CheckoutPayment struct {
+	Method string `json:"method"`
+	Amount int    `json:"amount"` // uang yang diserahkan
+}
+
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
-}
\ No newline at end of file
+	Items     []CheckoutItem    `json:"items"`
+	Discount  *Discount         `json:"discount,omitempty"` // diskon untuk seluruh transaksi
+	PromoCode string            `json:"promo_code,omitempty"`
+	Payments  []CheckoutPayment `json:"payments"`
+	CashierID int               `json:"-"` // diisi handler dari user yang login
+	CartID    int               `json:"-"` // diisi cart service; cart ditutup bersama checkout
+}
+
+// CheckoutWarning adalah kondisi yang akan membuat checkout gagal, dilaporkan
+// oleh preview tanpa menghentikan perhitungan harga
+type CheckoutWarning struct {
+	Field   string `json:"field"` // items[i].quantity (index details), promo_code atau payments
+	Code    string `json:"code"`  // sama dengan code error checkout, misalnya INSUFFICIENT_STOCK
+	Message string `json:"message"`
+}
+
+// CheckoutPreview adalah hasil POST /api/checkout/preview: transaksi yang akan
+// terbentuk (belum disimpan, tanpa ID) beserta warning-nya
+type CheckoutPreview struct {
+	Transaction Transaction       `json:"transaction"`
+	Warnings    []CheckoutWarning `json:"warnings"`
+}
+
+// Status cart yang di-parkir
+const (
+	CartOpen       = "open"
+	CartCheckedOut = "checked_out"
+	CartCancelled  = "cancelled"
+)
+
+// Cart adalah pesanan yang di-parkir dan bisa dilanjutkan nanti. Kalau Reserved,
+// stock item-nya sudah dikurangi sampai ReservedUntil; setelah itu reservasinya
+// dikembalikan oleh sweeper dan cart tetap bisa di-checkout seperti biasa.
+type Cart struct {
+	ID            int        `json:"id"`
+	Note          string     `json:"note"` // nama pelanggan / nomor meja
+	Status        string     `json:"status"`
+	Items         []CartItem `json:"items"`
+	Reserved      bool       `json:"reserved"`
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
+	TransactionID *int       `json:"transaction_id,omitempty"` // diisi setelah checked_out
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// CartItem adalah satu baris cart, satu baris per produk
+type CartItem struct {
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name,omitempty"`
+	Quantity    int       `json:"quantity"`
+	Discount    *Discount `json:"discount,omitempty"`
+}
+
+// IdempotencyRecord menyimpan hasil request yang dikirim dengan header
+// Idempotency-Key supaya retry dari client mendapat response yang sama.
+// StatusCode 0 berarti request pertama masih diproses.
+type IdempotencyRecord struct {
+	Key         string    `json:"key"`
+	RequestHash string    `json:"request_hash"` // sha256 dari path dan body
+	StatusCode  int       `json:"status_code"`
+	Response    []byte    `json:"-"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// User adalah akun yang bisa login ke POS (kasir, admin). Password hanya
+// diterima saat create/update dan tidak pernah dikirim balik.
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	Name         string    `json:"name"`
+	Password     string    `json:"password,omitempty"`
+	PasswordHash string    `json:"-"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Session adalah token login yang disimpan di server. Yang disimpan hanya
+// hash token-nya, token aslinya hanya dipegang client.
+type Session struct {
+	TokenHash string    `json:"-"`
+	UserID    int       `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoginRequest adalah body POST /api/auth/login
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AuthToken adalah response login / refresh
+type AuthToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      User      `json:"user"`
+}
+
+// Permission yang dicek per route dan method (lihat handlers.RoutePermissions)
+const (
+	PermProductRead       = "product:read"
+	PermProductWrite      = "product:write"
+	PermCategoryRead      = "category:read"
+	PermCategoryWrite     = "category:write"
+	PermTransactionCreate = "transaction:create" // checkout, preview dan cart
+	PermTransactionRead   = "transaction:read"
+	PermTransactionRefund = "transaction:refund" // void dan refund
+	PermReportRead        = "report:read"
+	PermPromoRead         = "promo:read" // promo code dan promosi
+	PermPromoWrite        = "promo:write"
+	PermUserManage        = "user:manage"      // user, role dan permission
+	PermShiftRead         = "shift:read"       // laporan rekonsiliasi shift semua kasir
+	PermShiftManage       = "shift:manage"     // menutup shift kasir lain
+	PermAuditRead         = "audit:read"       // audit log perubahan katalog dan transaksi
+	PermStockCount        = "stock:count"      // stock opname: mulai, input hitungan, finalize
+	PermPurchase          = "purchase:manage"  // supplier dan purchase order
+	PermPurchaseApprove   = "purchase:approve" // menyetujui purchase order
+)
+
+// AllPermissions adalah daftar permission yang boleh diberikan ke role
+var AllPermissions = []string{
+	PermProductRead, PermProductWrite, PermCategoryRead, PermCategoryWrite,
+	PermTransactionCreate, PermTransactionRead, PermTransactionRefund, PermReportRead,
+	PermPromoRead, PermPromoWrite, PermUserManage, PermShiftRead, PermAuditRead, PermStockCount,
+	PermPurchase, PermShiftManage, PermPurchaseApprove,
+}
+
+// Role bawaan dari migration 0010
+const (
+	RoleOwner   = "owner"
+	RoleManager = "manager"
+	RoleCashier = "cashier"
+)
+
+type Role struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleAssignment adalah body PUT /api/users/{id}/roles, berisi nama role
+type RoleAssignment struct {
+	Roles []string `json:"roles"`
+}
+
+// Status shift kasir
+const (
+	ShiftOpen   = "open"
+	ShiftClosed = "closed"
+)
+
+// Shift adalah satu sesi kerja kasir dengan laci uangnya sendiri. ExpectedCash
+// dan CountedCash diisi saat shift ditutup.
+type Shift struct {
+	ID           int        `json:"id"`
+	UserID       int        `json:"user_id"`
+	Username     string     `json:"username,omitempty"`
+	Status       string     `json:"status"`
+	OpeningCash  int        `json:"opening_cash"` // modal awal di laci
+	ExpectedCash *int       `json:"expected_cash,omitempty"`
+	CountedCash  *int       `json:"counted_cash,omitempty"`
+	Note         string     `json:"note"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+}
+
+// OpenShiftRequest adalah body POST /api/shifts
+type OpenShiftRequest struct {
+	OpeningCash int    `json:"opening_cash"`
+	Note        string `json:"note"`
+}
+
+// CloseShiftRequest adalah body POST /api/shifts/{id}/close
+type CloseShiftRequest struct {
+	CountedCash *int   `json:"counted_cash"`
+	Note        string `json:"note"`
+}
+
+// ShiftSummary adalah uang yang tercatat selama shift
+type ShiftSummary struct {
+	TransactionCount int            `json:"transaction_count"`
+	SalesByMethod    map[string]int `json:"sales_by_method"` // payments.amount (setelah kembalian) per metode
+	RefundCount      int            `json:"refund_count"`
+	RefundTotal      int            `json:"refund_total"`
+	CashRefundTotal  int            `json:"cash_refund_total"` // bagian refund yang keluar dari laci
+}
+
+// ShiftReport adalah rekonsiliasi kas satu shift. Difference = counted - expected,
+// negatif berarti kas kurang.
+type ShiftReport struct {
+	Shift        Shift        `json:"shift"`
+	Summary      ShiftSummary `json:"summary"`
+	CashSales    int          `json:"cash_sales"`
+	ExpectedCash int          `json:"expected_cash"`
+	CountedCash  *int         `json:"counted_cash"`
+	Difference   *int         `json:"difference"`
+}
+
+// ShiftFilter untuk GET /api/shifts
+type ShiftFilter struct {
+	UserID int    // 0 = semua kasir
+	Status string // "" = semua status
+}
+
+// SalesSummary adalah ringkasan penjualan untuk report. TotalRevenue adalah
+// uang yang diterima (grand total) dikurangi refund, TotalTax adalah pajak
+// setelah dikurangi pajak yang ikut di-refund.
+type SalesSummary struct {
+	TotalRevenue       int
+	TotalTransaksi     int
+	GrossRevenue       int
+	TotalDiscount      int
+	TotalRefund        int
+	Subtotal           int
+	TotalServiceCharge int
+	TotalTax           int
+}
+
+// Pengelompokan laporan laba kotor (GET /api/report/profit?group_by=)
+const (
+	ProfitGroupProduct  = "product"
+	ProfitGroupCategory = "category"
+	ProfitGroupDay      = "day"
+	ProfitGroupMonth    = "month"
+)
+
+var ProfitGroups = []string{ProfitGroupProduct, ProfitGroupCategory, ProfitGroupDay, ProfitGroupMonth}
+
+// ProfitLine adalah satu baris laporan laba kotor. NetSales adalah penjualan
+// setelah diskon dan refund tanpa pajak, COGS dihitung dari unit_cost saat jual.
+// Penjualan yang HPP-nya tidak diketahui (transaksi sebelum harga pokok dicatat)
+// dipisah di UnknownCostSales dan tidak ikut dihitung di GrossProfit dan Margin.
+// ID diisi untuk group product/category, Period untuk day/month.
+type ProfitLine struct {
+	ID               int     `json:"id,omitempty"`
+	Name             string  `json:"name,omitempty"`
+	Period           string  `json:"period,omitempty"`
+	Quantity         int     `json:"quantity"` // terjual dikurangi refund
+	NetSales         int     `json:"net_sales"`
+	COGS             int     `json:"cogs"`
+	UnknownCostSales int     `json:"unknown_cost_sales"`
+	GrossProfit      int     `json:"gross_profit"`
+	Margin           float64 `json:"margin"` // gross_profit / net sales yang HPP-nya diketahui, dalam persen
+}
+
+// ProfitReport untuk GET /api/report/profit
+type ProfitReport struct {
+	StartDate string       `json:"start_date"`
+	EndDate   string       `json:"end_date"`
+	GroupBy   string       `json:"group_by"`
+	Lines     []ProfitLine `json:"lines"`
+	Total     ProfitLine   `json:"total"`
+}
+
+// TransactionFilter untuk list riwayat transaksi (GET /api/transactions)
+type TransactionFilter struct {
+	StartDate string // YYYY-MM-DD, inklusif
+	EndDate   string // YYYY-MM-DD, inklusif
+	MinTotal  *int
+	MaxTotal  *int
+	CashierID int // 0 = semua kasir
+	ShiftID   int // 0 = semua shift
+	Page      int
+	Limit     int
+}
+
+// TransactionPage adalah hasil list transaksi yang dipaginasi
+type TransactionPage struct {
+	Data  []Transaction `json:"data"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+	Total int           `json:"total"`
+}
+
+// Entity dan action di audit log
+const (
+	AuditProduct     = "product"
+	AuditCategory    = "category"
+	AuditTransaction = "transaction"
+
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry adalah satu baris audit log. Before kosong untuk create, After
+// kosong untuk delete. ActorID nil berarti perubahan dari sistem (tanpa login).
+type AuditEntry struct {
+	ID            int             `json:"id"`
+	ActorID       *int            `json:"actor_id"`
+	ActorUsername string          `json:"actor_username,omitempty"`
+	Entity        string          `json:"entity"`
+	EntityID      int             `json:"entity_id"`
+	Action        string          `json:"action"`
+	Before        json.RawMessage `json:"before"`
+	After         json.RawMessage `json:"after"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AuditFilter untuk GET /api/audit
+type AuditFilter struct {
+	Entity    string // "" = semua entity
+	EntityID  int    // 0 = semua
+	ActorID   int    // 0 = semua
+	Action    string
+	StartDate string // YYYY-MM-DD, inklusif
+	EndDate   string // YYYY-MM-DD, inklusif
+	Page      int
+	Limit     int
+}
+
+// AuditPage adalah hasil list audit log yang dipaginasi
+type AuditPage struct {
+	Data  []AuditEntry `json:"data"`
+	Page  int          `json:"page"`
+	Limit int          `json:"limit"`
+	Total int          `json:"total"`
+}
+
+// Jenis stock movement. Quantity positif menambah stock, negatif mengurangi.
+const (
+	StockSale        = "sale"        // checkout, reference = transaction ID
+	StockPurchase    = "purchase"    // barang masuk dari supplier
+	StockAdjustment  = "adjustment"  // stock awal dan perubahan manual lewat update produk
+	StockRefund      = "refund"      // void / refund, reference = refund ID
+	StockTransfer    = "transfer"    // perpindahan stock antar lokasi
+	StockReservation = "reservation" // reservasi cart dan pelepasannya, reference = cart ID
+)
+
+// StockMovementTypes adalah daftar jenis movement yang valid
+var StockMovementTypes = []string{StockSale, StockPurchase, StockAdjustment, StockRefund, StockTransfer, StockReservation}
+
+// StockMovement adalah satu baris ledger stock. products.stock selalu sama
+// dengan jumlah Quantity semua movement produk tersebut.
+type StockMovement struct {
+	ID            int       `json:"id"`
+	ProductID     int       `json:"product_id"`
+	Type          string    `json:"type"`
+	Quantity      int       `json:"quantity"`
+	ReferenceID   *int      `json:"reference_id"`
+	ActorID       *int      `json:"actor_id"`
+	ActorUsername string    `json:"actor_username,omitempty"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// StockMovementFilter untuk GET /api/products/{id}/stock-history
+type StockMovementFilter struct {
+	ProductID int
+	Type      string // "" = semua jenis
+	StartDate string // YYYY-MM-DD, inklusif
+	EndDate   string // YYYY-MM-DD, inklusif
+	Page      int
+	Limit     int
+}
+
+// StockMovementPage adalah riwayat stock satu produk, terbaru dulu
+type StockMovementPage struct {
+	ProductID int             `json:"product_id"`
+	Stock     int             `json:"stock"`
+	Data      []StockMovement `json:"data"`
+	Page      int             `json:"page"`
+	Limit     int             `json:"limit"`
+	Total     int             `json:"total"`
+}
+
+// StockDiscrepancy adalah produk yang stock-nya tidak sama dengan hasil hitung ulang ledger
+type StockDiscrepancy struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Stock       int    `json:"stock"`
+	LedgerStock int    `json:"ledger_stock"`
+	Difference  int    `json:"difference"` // Stock - LedgerStock
+}
+
+// StockCheck adalah hasil GET /api/products/stock-check
+type StockCheck struct {
+	ProductsChecked int                `json:"products_checked"`
+	Consistent      bool               `json:"consistent"`
+	Discrepancies   []StockDiscrepancy `json:"discrepancies"`
+}
+
+// LowStockAlert dikirim ke notifier saat checkout membuat stock produk turun
+// sampai atau di bawah reorder point-nya.
+type LowStockAlert struct {
+	ProductID       int       `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	Stock           int       `json:"stock"`
+	ReorderPoint    int       `json:"reorder_point"`
+	ReorderQuantity int       `json:"reorder_quantity"`
+	TransactionID   int       `json:"transaction_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ProductUpdateRequest adalah body PUT /api/products/{id}. CostPrice dan
+// pengaturan reorder yang tidak dikirim tetap memakai nilai tersimpan, supaya
+// client lama yang belum mengenal field ini tidak mereset harga pokok ke 0 atau
+// mematikan alert stock menipis.
+type ProductUpdateRequest struct {
+	Name            string `json:"name"`
+	Price           int    `json:"price"`
+	CostPrice       *int   `json:"cost_price"`
+	Stock           int    `json:"stock"`
+	ReorderPoint    *int   `json:"reorder_point"`
+	ReorderQuantity *int   `json:"reorder_quantity"`
+	CategoryID      int    `json:"category_id"`
+}
+
+// StockAdjustmentRequest adalah body POST /api/products/{id}/adjust-stock.
+// Quantity adalah selisih (negatif untuk barang rusak / hilang).
+type StockAdjustmentRequest struct {
+	Quantity int    `json:"quantity"`
+	Note     string `json:"note"`
+}
+
+// Status sesi stock opname
+const (
+	StockCountOpen      = "open"
+	StockCountFinalized = "finalized"
+	StockCountCancelled = "cancelled"
+)
+
+// StockCount adalah satu sesi stock opname (hitung fisik). Selama open hasil
+// hitungan bisa dikirim berkali-kali; finalize menerapkan semua selisih sekaligus.
+type StockCount struct {
+	ID        int               `json:"id"`
+	Status    string            `json:"status"`
+	Note      string            `json:"note"`
+	CreatedBy *int              `json:"created_by"`
+	CreatedAt time.Time         `json:"created_at"`
+	ClosedBy  *int              `json:"closed_by,omitempty"` // user yang finalize / cancel
+	ClosedAt  *time.Time        `json:"closed_at,omitempty"`
+	Items     []StockCountItem  `json:"items"`
+	Summary   StockCountSummary `json:"summary"`
+}
+
+// StockCountItem adalah hasil hitung satu produk. SystemStock adalah
+// products.stock ditambah reservasi cart saat hitungan dikirim, jadi penjualan
+// selama opname tidak dianggap selisih: finalize menambahkan Variance ke stock saat itu.
+type StockCountItem struct {
+	ProductID       int       `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	Price           int       `json:"price"`
+	SystemStock     int       `json:"system_stock"`
+	CountedQuantity int       `json:"counted_quantity"`
+	Variance        int       `json:"variance"` // counted - system, negatif berarti barang kurang
+	CountedBy       *int      `json:"counted_by"`
+	CountedAt       time.Time `json:"counted_at"`
+}
+
+// StockCountSummary adalah ringkasan selisih (variance report) satu sesi
+type StockCountSummary struct {
+	ItemsCounted      int `json:"items_counted"`
+	ItemsWithVariance int `json:"items_with_variance"`
+	SurplusQuantity   int `json:"surplus_quantity"`  // jumlah unit lebih
+	ShortageQuantity  int `json:"shortage_quantity"` // jumlah unit kurang (positif)
+	VarianceValue     int `json:"variance_value"`    // sum(variance * price)
+}
+
+// StartStockCountRequest adalah body POST /api/stock-counts
+type StartStockCountRequest struct {
+	Note string `json:"note"`
+}
+
+// StockCountEntry adalah satu baris hitungan di body POST /api/stock-counts/{id}/items
+type StockCountEntry struct {
+	ProductID       int  `json:"product_id"`
+	CountedQuantity *int `json:"counted_quantity"`
+}
+
+// SubmitStockCountRequest adalah satu batch hitungan dari satu device. Produk
+// yang dikirim ulang menimpa hitungan sebelumnya.
+type SubmitStockCountRequest struct {
+	Items []StockCountEntry `json:"items"`
+}
+
+// Supplier adalah pemasok barang untuk purchase order
+type Supplier struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Status purchase order: draft -> approved -> partially_received -> received.
+// Draft dan approved yang belum diterima sama sekali bisa di-cancel.
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderApproved          = "approved"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+// PurchaseOrderStatuses dipakai untuk validasi filter status
+var PurchaseOrderStatuses = []string{
+	PurchaseOrderDraft, PurchaseOrderApproved, PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled,
+}
+
+// PurchaseOrder adalah pesanan barang ke supplier. Stock baru bertambah saat
+// barang diterima (Receipts), bukan saat PO dibuat atau di-approve.
+type PurchaseOrder struct {
+	ID           int                 `json:"id"`
+	SupplierID   int                 `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name"`
+	Status       string              `json:"status"`
+	Note         string              `json:"note"`
+	CreatedBy    *int                `json:"created_by"`
+	CreatedAt    time.Time           `json:"created_at"`
+	ApprovedBy   *int                `json:"approved_by,omitempty"`
+	ApprovedAt   *time.Time          `json:"approved_at,omitempty"`
+	ClosedBy     *int                `json:"closed_by,omitempty"` // user yang menerima batch terakhir / cancel
+	ClosedAt     *time.Time          `json:"closed_at,omitempty"`
+	Items        []PurchaseOrderItem `json:"items"`
+	Receipts     []PurchaseReceipt   `json:"receipts"`
+	TotalCost    int                 `json:"total_cost"`    // sum(quantity * unit_cost) yang dipesan
+	ReceivedCost int                 `json:"received_cost"` // sum(quantity * unit_cost) yang sudah diterima
+}
+
+// PurchaseOrderItem adalah satu produk yang dipesan beserta harga belinya
+type PurchaseOrderItem struct {
+	ProductID         int    `json:"product_id"`
+	ProductName       string `json:"product_name"`
+	Quantity          int    `json:"quantity"`
+	UnitCost          int    `json:"unit_cost"`
+	ReceivedQuantity  int    `json:"received_quantity"`
+	RemainingQuantity int    `json:"remaining_quantity"`
+}
+
+// PurchaseReceipt adalah satu batch barang yang diterima untuk satu produk.
+// UnitCost adalah harga beli aktual batch ini (default harga di PO).
+type PurchaseReceipt struct {
+	ID              int       `json:"id"`
+	PurchaseOrderID int       `json:"purchase_order_id"`
+	ProductID       int       `json:"product_id"`
+	Quantity        int       `json:"quantity"`
+	UnitCost        int       `json:"unit_cost"`
+	ReceivedBy      *int      `json:"received_by"`
+	ReceivedAt      time.Time `json:"received_at"`
+}
+
+// PurchaseOrderFilter adalah query GET /api/purchase-orders
+type PurchaseOrderFilter struct {
+	Status     string
+	SupplierID int
+}
+
+// PurchaseOrderLine adalah satu baris di body POST /api/purchase-orders
+type PurchaseOrderLine struct {
+	ProductID int  `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+	UnitCost  *int `json:"unit_cost"`
+}
+
+// CreatePurchaseOrderRequest adalah body POST /api/purchase-orders (PO dibuat sebagai draft)
+type CreatePurchaseOrderRequest struct {
+	SupplierID int                 `json:"supplier_id"`
+	Note       string              `json:"note"`
+	Items      []PurchaseOrderLine `json:"items"`
+}
+
+// ReceiptLine adalah satu produk di body POST /api/purchase-orders/{id}/receive.
+// UnitCost kosong berarti harga beli sesuai PO.
+type ReceiptLine struct {
+	ProductID int  `json:"product_id"`
+	Quantity  int  `json:"quantity"`
+	UnitCost  *int `json:"unit_cost"`
+}
+
+// ReceivePurchaseOrderRequest mencatat barang yang datang; boleh sebagian
+type ReceivePurchaseOrderRequest struct {
+	Items []ReceiptLine `json:"items"`
+}
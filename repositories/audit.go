@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"kasir-api-golang-v1/models"
+)
+
+// NewAuditEntry menyusun entry audit log; before/after di-marshal ke JSON,
+// nil berarti kosong. actorID 0 berarti perubahan dari sistem. Dipakai oleh
+// implementasi SQL dan in-memory supaya isi audit log-nya sama.
+func NewAuditEntry(actorID int, entity string, entityID int, action string, before, after interface{}) (models.AuditEntry, error) {
+	entry := models.AuditEntry{ActorID: ActorRef(actorID), Entity: entity, EntityID: entityID, Action: action}
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return entry, err
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return entry, err
+		}
+	}
+	return entry, nil
+}
+
+// execer dipenuhi oleh *sql.DB dan *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// jsonArg menyimpan JSON sebagai teks (bukan BLOB), NULL kalau kosong
+func jsonArg(raw json.RawMessage) interface{} {
+	if raw == nil {
+		return nil
+	}
+	return string(raw)
+}
+
+// writeAudit menulis audit log di dalam DB transaction perubahan yang dicatat,
+// jadi perubahan dan audit-nya selalu tersimpan atau batal bersama
+func writeAudit(tx execer, actorID int, entity string, entityID int, action string, before, after interface{}) error {
+	entry, err := NewAuditEntry(actorID, entity, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO audit_logs (actor_id, entity, entity_id, action, before_data, after_data) VALUES (?, ?, ?, ?, ?, ?)",
+		entry.ActorID, entry.Entity, entry.EntityID, entry.Action, jsonArg(entry.Before), jsonArg(entry.After))
+	return err
+}
+
+// TransactionAudit adalah snapshot before/after transaksi di audit log saat
+// void atau refund. Refund hanya diisi di snapshot after.
+type TransactionAudit struct {
+	Status         string         `json:"status"`
+	RefundedAmount int            `json:"refunded_amount"`
+	RefundedTax    int            `json:"refunded_tax"`
+	Refund         *models.Refund `json:"refund,omitempty"`
+}
+
+// AuditActor mengubah ID opsional (CashierID, pemilik shift) menjadi actorID audit
+func AuditActor(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// ActorRef adalah kebalikan AuditActor: actorID 0 (sistem) disimpan sebagai NULL
+func ActorRef(actorID int) *int {
+	if actorID == 0 {
+		return nil
+	}
+	return &actorID
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlAuditRepository membaca audit_logs; penulisannya dilakukan repository
+// lain di dalam DB transaction masing-masing (lihat writeAudit)
+type sqlAuditRepository struct {
+	db *database.DB
+}
+
+func NewAuditRepository(db *database.DB) AuditRepository {
+	return &sqlAuditRepository{db: db}
+}
+
+func (r *sqlAuditRepository) GetAll(filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	var conds []string
+	var args []interface{}
+	if filter.Entity != "" {
+		conds = append(conds, "a.entity = ?")
+		args = append(args, filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		conds = append(conds, "a.entity_id = ?")
+		args = append(args, filter.EntityID)
+	}
+	if filter.ActorID != 0 {
+		conds = append(conds, "a.actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.Action != "" {
+		conds = append(conds, "a.action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.StartDate != "" {
+		conds = append(conds, r.db.Dialect.DateOf("a.created_at")+" >= ?")
+		args = append(args, filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		conds = append(conds, r.db.Dialect.DateOf("a.created_at")+" <= ?")
+		args = append(args, filter.EndDate)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM audit_logs a"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT a.id, a.actor_id, IFNULL(u.username, ''), a.entity, a.entity_id, a.action, a.before_data, a.after_data, a.created_at
+		FROM audit_logs a LEFT JOIN users u ON u.id = a.actor_id` + where + " ORDER BY a.id DESC LIMIT ? OFFSET ?"
+	rows, err := r.db.Query(query, append(args, filter.Limit, (filter.Page-1)*filter.Limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.AuditEntry, 0)
+	for rows.Next() {
+		var e models.AuditEntry
+		var actorID sql.NullInt64
+		var before, after sql.NullString
+		if err := rows.Scan(&e.ID, &actorID, &e.ActorUsername, &e.Entity, &e.EntityID, &e.Action, &before, &after, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		e.ActorID = nullInt(actorID)
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+)
+
+// CartReservation mengembalikan qty yang di-reserve cart per produk, kosong
+// kalau cart tidak di-reserve. Dipakai implementasi SQL dan in-memory untuk
+// menghitung selisih stock saat cart berubah.
+func CartReservation(cart *models.Cart) map[int]int {
+	reserved := map[int]int{}
+	if cart == nil || !cart.Reserved {
+		return reserved
+	}
+	for _, it := range cart.Items {
+		reserved[it.ProductID] += it.Quantity
+	}
+	return reserved
+}
+
+// ReservationDelta mengembalikan perubahan stock (positif = stock dikurangi)
+// per produk dari reservasi lama ke baru, urut product ID seperti urutan lock checkout
+func ReservationDelta(old, new map[int]int) (ids []int, delta map[int]int) {
+	delta = map[int]int{}
+	for id, qty := range new {
+		delta[id] += qty
+	}
+	for id, qty := range old {
+		delta[id] -= qty
+	}
+	for id, d := range delta {
+		if d != 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, delta
+}
+
+// CartCheckout memastikan cart masih open dan items checkout sama dengan isi
+// cart (qty per produk), lalu mengembalikan qty yang sudah di-reserve cart.
+// Stock yang di-reserve langsung dipakai untuk penjualan, tidak dikurangi lagi.
+func CartCheckout(cart *models.Cart, items []models.CheckoutItem) (map[int]int, error) {
+	if cart.Status != models.CartOpen {
+		return nil, ErrCartClosed
+	}
+	want := map[int]int{}
+	for _, it := range cart.Items {
+		want[it.ProductID] += it.Quantity
+	}
+	got := map[int]int{}
+	for _, it := range items {
+		got[it.ProductID] += it.Quantity
+	}
+	if len(got) != len(want) {
+		return nil, ErrCartChanged
+	}
+	for id, qty := range want {
+		if got[id] != qty {
+			return nil, ErrCartChanged
+		}
+	}
+	return CartReservation(cart), nil
+}
+
+// WithReservedStock menambahkan qty yang di-reserve ke snapshot stock produk,
+// supaya cek stock dan alert low stock melihat stock sebelum reservasi dipakai
+func WithReservedStock(products map[int]models.Product, reserved map[int]int) {
+	for id, qty := range reserved {
+		if p, ok := products[id]; ok {
+			p.Stock += qty
+			products[id] = p
+		}
+	}
+}
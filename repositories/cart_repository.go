@@ -0,0 +1,357 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlCartRepository adalah implementasi CartRepository berbasis database/sql (MySQL atau SQLite)
+type sqlCartRepository struct {
+	db *database.DB
+}
+
+func NewCartRepository(db *database.DB) CartRepository {
+	return &sqlCartRepository{db: db}
+}
+
+// querier dipenuhi oleh *sql.DB dan *sql.Tx
+type querier interface {
+	rowQuerier
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+const cartColumns = "id, note, status, reserved_until, transaction_id, created_at"
+
+func scanCart(row rowScanner) (*models.Cart, error) {
+	var c models.Cart
+	var reservedUntil sql.NullTime
+	var transactionID sql.NullInt64
+	if err := row.Scan(&c.ID, &c.Note, &c.Status, &reservedUntil, &transactionID, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	if reservedUntil.Valid {
+		c.Reserved = true
+		c.ReservedUntil = &reservedUntil.Time
+	}
+	if transactionID.Valid {
+		id := int(transactionID.Int64)
+		c.TransactionID = &id
+	}
+	c.Items = []models.CartItem{}
+	return &c, nil
+}
+
+// reservedUntil: cart yang tidak di-reserve selalu disimpan dengan reserved_until NULL
+func reservedUntil(cart *models.Cart) interface{} {
+	if !cart.Reserved {
+		return nil
+	}
+	return nullTime(cart.ReservedUntil)
+}
+
+// loadCartItems mengisi Items (beserta nama produk) untuk cart-cart yang sudah di-load
+func loadCartItems(q querier, carts []models.Cart) error {
+	if len(carts) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(carts))
+	placeholders := make([]string, len(carts))
+	args := make([]interface{}, len(carts))
+	for i, c := range carts {
+		index[c.ID] = i
+		placeholders[i] = "?"
+		args[i] = c.ID
+	}
+
+	rows, err := q.Query(`SELECT ci.cart_id, ci.product_id, p.name, ci.quantity, ci.discount_type, ci.discount_value
+		FROM cart_items ci JOIN products p ON p.id = ci.product_id
+		WHERE ci.cart_id IN (`+strings.Join(placeholders, ", ")+") ORDER BY ci.id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cartID, discountValue int
+		var discountType sql.NullString
+		var it models.CartItem
+		if err := rows.Scan(&cartID, &it.ProductID, &it.ProductName, &it.Quantity, &discountType, &discountValue); err != nil {
+			return err
+		}
+		if discountType.Valid {
+			it.Discount = &models.Discount{Type: discountType.String, Value: discountValue}
+		}
+		c := &carts[index[cartID]]
+		c.Items = append(c.Items, it)
+	}
+	return rows.Err()
+}
+
+func getCart(q querier, id int, suffix string) (*models.Cart, error) {
+	c, err := scanCart(q.QueryRow("SELECT "+cartColumns+" FROM carts WHERE id = ?"+suffix, id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	carts := []models.Cart{*c}
+	if err := loadCartItems(q, carts); err != nil {
+		return nil, err
+	}
+	return &carts[0], nil
+}
+
+func (r *sqlCartRepository) GetAll(status string) ([]models.Cart, error) {
+	query := "SELECT " + cartColumns + " FROM carts"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	rows, err := r.db.Query(query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carts := make([]models.Cart, 0)
+	for rows.Next() {
+		c, err := scanCart(rows)
+		if err != nil {
+			return nil, err
+		}
+		carts = append(carts, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return carts, loadCartItems(r.db, carts)
+}
+
+func (r *sqlCartRepository) GetByID(id int) (*models.Cart, error) {
+	return getCart(r.db, id, "")
+}
+
+// adjustReservation mengurangi / mengembalikan stock sebesar selisih reservasi
+// dan mencatatnya ke ledger dengan reference cart ID. Pengurangan memakai
+// conditional update sehingga stock tidak pernah negatif.
+func adjustReservation(tx *sql.Tx, cartID int, old, new map[int]int) error {
+	ids, delta := ReservationDelta(old, new)
+	for _, id := range ids {
+		d := delta[id]
+		if d < 0 {
+			if _, err := tx.Exec("UPDATE products SET stock = stock + ? WHERE id = ?", -d, id); err != nil {
+				return err
+			}
+		} else if err := reserveStock(tx, id, d); err != nil {
+			return err
+		}
+		if err := writeStockMovement(tx, NewStockMovement(id, models.StockReservation, -d, &cartID, 0, "cart reservation")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reserveStock mengurangi stock produk untuk reservasi, gagal kalau stock tidak cukup
+func reserveStock(tx *sql.Tx, id, d int) error {
+	result, err := tx.Exec("UPDATE products SET stock = stock - ? WHERE id = ? AND stock >= ?", d, id, d)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		var p models.Product
+		err := tx.QueryRow("SELECT id, name, stock FROM products WHERE id = ?", id).Scan(&p.ID, &p.Name, &p.Stock)
+		if err == sql.ErrNoRows {
+			return ErrForeignKey
+		}
+		if err != nil {
+			return err
+		}
+		return &InsufficientStockError{ProductID: p.ID, ProductName: p.Name, Available: p.Stock, Requested: d}
+	}
+	return nil
+}
+
+func insertCartItems(tx *sql.Tx, cartID int, items []models.CartItem) error {
+	for _, it := range items {
+		var discountType interface{}
+		discountValue := 0
+		if it.Discount != nil {
+			discountType, discountValue = it.Discount.Type, it.Discount.Value
+		}
+		if _, err := tx.Exec("INSERT INTO cart_items (cart_id, product_id, quantity, discount_type, discount_value) VALUES (?, ?, ?, ?, ?)",
+			cartID, it.ProductID, it.Quantity, discountType, discountValue); err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+// reload mengisi ulang cart dari database supaya nama produk dan created_at ikut terisi
+func (r *sqlCartRepository) reload(cart *models.Cart) error {
+	saved, err := r.GetByID(cart.ID)
+	if err != nil {
+		return err
+	}
+	*cart = *saved
+	return nil
+}
+
+func (r *sqlCartRepository) Create(cart *models.Cart) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO carts (note, status, reserved_until) VALUES (?, ?, ?)", cart.Note, models.CartOpen, reservedUntil(cart))
+	if err != nil {
+		return translateError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := adjustReservation(tx, int(id), nil, CartReservation(cart)); err != nil {
+		return err
+	}
+	if err := insertCartItems(tx, int(id), cart.Items); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	cart.ID = int(id)
+	return r.reload(cart)
+}
+
+// lockOpenCart mengunci baris cart dan memastikan statusnya masih open
+func (r *sqlCartRepository) lockOpenCart(tx *sql.Tx, id int) (*models.Cart, error) {
+	old, err := getCart(tx, id, r.db.Dialect.ForUpdate())
+	if err != nil {
+		return nil, err
+	}
+	if old.Status != models.CartOpen {
+		return nil, ErrCartClosed
+	}
+	return old, nil
+}
+
+func (r *sqlCartRepository) Update(cart *models.Cart) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	old, err := r.lockOpenCart(tx, cart.ID)
+	if err != nil {
+		return err
+	}
+	if err := adjustReservation(tx, cart.ID, CartReservation(old), CartReservation(cart)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE carts SET note = ?, reserved_until = ? WHERE id = ?", cart.Note, reservedUntil(cart), cart.ID); err != nil {
+		return translateError(err)
+	}
+	if _, err := tx.Exec("DELETE FROM cart_items WHERE cart_id = ?", cart.ID); err != nil {
+		return err
+	}
+	if err := insertCartItems(tx, cart.ID, cart.Items); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return r.reload(cart)
+}
+
+func (r *sqlCartRepository) Close(id int, status string, transactionID *int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	old, err := r.lockOpenCart(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := adjustReservation(tx, old.ID, CartReservation(old), nil); err != nil {
+		return err
+	}
+	var trxID interface{}
+	if transactionID != nil {
+		trxID = *transactionID
+	}
+	if _, err := tx.Exec("UPDATE carts SET status = ?, reserved_until = NULL, transaction_id = ? WHERE id = ?", status, trxID, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ExpireReservations membandingkan waktu di Go (bukan di SQL) supaya format
+// DATETIME MySQL dan SQLite tidak berpengaruh
+func (r *sqlCartRepository) ExpireReservations(now time.Time) (int, error) {
+	rows, err := r.db.Query("SELECT id, reserved_until FROM carts WHERE status = ? AND reserved_until IS NOT NULL", models.CartOpen)
+	if err != nil {
+		return 0, err
+	}
+	var expired []int
+	for rows.Next() {
+		var id int
+		var until time.Time
+		if err := rows.Scan(&id, &until); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if until.Before(now) {
+			expired = append(expired, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, id := range expired {
+		ok, err := r.expire(id, now)
+		if err != nil {
+			return released, err
+		}
+		if ok {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// expire melepas reservasi satu cart kalau (setelah di-lock) masih kedaluwarsa
+func (r *sqlCartRepository) expire(id int, now time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	old, err := getCart(tx, id, r.db.Dialect.ForUpdate())
+	if err != nil {
+		return false, err
+	}
+	if old.Status != models.CartOpen || !old.Reserved || !old.ReservedUntil.Before(now) {
+		return false, nil // sudah diperpanjang, di-checkout atau dibatalkan sejak di-query
+	}
+	if err := adjustReservation(tx, old.ID, CartReservation(old), nil); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec("UPDATE carts SET reserved_until = NULL WHERE id = ?", id); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -1,20 +1,20 @@
 package repositories
 
 import (
-	"database/sql"
-	"errors"
+	"kasir-api-golang-v1/database"
 	"kasir-api-golang-v1/models"
 )
 
-type CategoryRepository struct {
-	db *sql.DB
+// sqlCategoryRepository adalah implementasi CategoryRepository berbasis database/sql (MySQL atau SQLite)
+type sqlCategoryRepository struct {
+	db *database.DB
 }
 
-func NewCategoryRepository(db *sql.DB) *CategoryRepository {
-	return &CategoryRepository{db: db}
+func NewCategoryRepository(db *database.DB) CategoryRepository {
+	return &sqlCategoryRepository{db: db}
 }
 
-func (r *CategoryRepository) GetAll() ([]models.Category, error) {
+func (r *sqlCategoryRepository) GetAll() ([]models.Category, error) {
 	rows, err := r.db.Query("SELECT id, name FROM categories")
 	if err != nil {
 		return nil, err
@@ -32,56 +32,90 @@ func (r *CategoryRepository) GetAll() ([]models.Category, error) {
 	return categories, nil
 }
 
-func (r *CategoryRepository) GetByID(id int) (*models.Category, error) {
+func (r *sqlCategoryRepository) GetByID(id int) (*models.Category, error) {
 	var c models.Category
 	err := r.db.QueryRow("SELECT id, name FROM categories WHERE id = ?", id).Scan(&c.ID, &c.Name)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &c, nil
 }
 
 // GetByName dipakai untuk mencari ID dari 'No Category'
-func (r *CategoryRepository) GetByName(name string) (*models.Category, error) {
+func (r *sqlCategoryRepository) GetByName(name string) (*models.Category, error) {
 	var c models.Category
 	err := r.db.QueryRow("SELECT id, name FROM categories WHERE name = ?", name).Scan(&c.ID, &c.Name)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &c, nil
 }
 
-func (r *CategoryRepository) Create(category *models.Category) error {
-	result, err := r.db.Exec("INSERT INTO categories (name) VALUES (?)", category.Name)
+// lockCategory membaca kategori dan menguncinya untuk snapshot "before" di audit log
+func (r *sqlCategoryRepository) lockCategory(tx rowQuerier, id int) (*models.Category, error) {
+	var c models.Category
+	err := tx.QueryRow("SELECT id, name FROM categories WHERE id = ?"+r.db.Dialect.ForUpdate(), id).Scan(&c.ID, &c.Name)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &c, nil
+}
+
+func (r *sqlCategoryRepository) Create(category *models.Category, actorID int) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO categories (name) VALUES (?)", category.Name)
+	if err != nil {
+		return translateError(err)
+	}
 	id, _ := result.LastInsertId()
 	category.ID = int(id)
-	return nil
+	if err := writeAudit(tx, actorID, models.AuditCategory, category.ID, models.AuditCreate, nil, category); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *CategoryRepository) Update(category *models.Category) error {
-	query := "UPDATE categories SET name = ? WHERE id = ?"
-	result, err := r.db.Exec(query, category.Name, category.ID)
+func (r *sqlCategoryRepository) Update(category *models.Category, actorID int) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return errors.New("category not found")
+	defer tx.Rollback()
+
+	before, err := r.lockCategory(tx, category.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE categories SET name = ? WHERE id = ?", category.Name, category.ID); err != nil {
+		return translateError(err)
 	}
-	return nil
+	if err := writeAudit(tx, actorID, models.AuditCategory, category.ID, models.AuditUpdate, before, category); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *CategoryRepository) Delete(id int) error {
-	result, err := r.db.Exec("DELETE FROM categories WHERE id = ?", id)
+func (r *sqlCategoryRepository) Delete(id, actorID int) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return errors.New("category not found")
+	defer tx.Rollback()
+
+	before, err := r.lockCategory(tx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM categories WHERE id = ?", id); err != nil {
+		return translateError(err)
 	}
-	return nil
-}
\ No newline at end of file
+	if err := writeAudit(tx, actorID, models.AuditCategory, id, models.AuditDelete, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import "kasir-api-golang-v1/models"
+
+// WeightedAverageCost menghitung harga pokok baru setelah menerima qty barang
+// dengan unitCost, dibulatkan ke rupiah terdekat. Stock yang kosong atau minus
+// tidak punya nilai, jadi harga pokok langsung mengikuti batch yang diterima.
+func WeightedAverageCost(stock, cost, qty, unitCost int) int {
+	if stock <= 0 {
+		return unitCost
+	}
+	total := stock + qty
+	return (stock*cost + qty*unitCost + total/2) / total
+}
+
+// CaptureUnitCost menyalin harga pokok dari snapshot produk ke setiap detail
+// transaksi, supaya COGS tidak berubah kalau harga pokok naik setelah dijual
+func CaptureUnitCost(trx *models.Transaction, products map[int]models.Product) {
+	for i := range trx.Details {
+		trx.Details[i].UnitCost = products[trx.Details[i].ProductID].CostPrice
+	}
+}
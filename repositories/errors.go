@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Sentinel error yang dikembalikan semua implementasi repository. Error asli
+// dari driver diterjemahkan lewat translateError supaya service tidak perlu
+// tahu database apa yang dipakai.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrDuplicate  = errors.New("duplicate entry")
+	ErrForeignKey = errors.New("foreign key constraint violated")
+
+	ErrTransactionVoided  = errors.New("transaction already voided")
+	ErrInvalidRefund      = errors.New("invalid refund")
+	ErrPromoUnavailable   = errors.New("promo code inactive or usage limit reached")
+	ErrCartClosed         = errors.New("cart already checked out or cancelled")
+	ErrCartChanged        = errors.New("cart changed during checkout")
+	ErrShiftClosed        = errors.New("shift already closed")
+	ErrShiftAlreadyOpen   = errors.New("user already has an open shift")
+	ErrStockCountClosed   = errors.New("stock count already finalized or cancelled")
+	ErrStockCountOpen     = errors.New("another stock count is still open")
+	ErrPurchaseOrderState = errors.New("purchase order status does not allow this action")
+	ErrInvalidReceipt     = errors.New("invalid receipt")
+	ErrLastOwner          = errors.New("cannot remove the last owner")
+)
+
+// Nomor error MySQL yang relevan
+const (
+	mysqlErrDuplicateEntry  = 1062
+	mysqlErrRowIsReferenced = 1451 // delete/update parent yang masih dipakai child
+	mysqlErrNoReferencedRow = 1452 // insert/update child dengan parent yang tidak ada
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+)
+
+// InsufficientStockError dikembalikan CreateTransaction saat stock tidak cukup
+type InsufficientStockError struct {
+	ProductID   int
+	ProductName string
+	Available   int
+	Requested   int
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %s (available: %d, requested: %d)", e.ProductName, e.Available, e.Requested)
+}
+
+// translateError mengubah error driver menjadi sentinel error, error lain dikembalikan apa adanya
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case mysqlErrDuplicateEntry:
+			return fmt.Errorf("%w: %s", ErrDuplicate, myErr.Message)
+		case mysqlErrRowIsReferenced, mysqlErrNoReferencedRow:
+			return fmt.Errorf("%w: %s", ErrForeignKey, myErr.Message)
+		}
+		return err
+	}
+
+	var liteErr sqlite3.Error
+	if errors.As(err, &liteErr) {
+		switch liteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return fmt.Errorf("%w: %s", ErrDuplicate, liteErr.Error())
+		case sqlite3.ErrConstraintForeignKey:
+			return fmt.Errorf("%w: %s", ErrForeignKey, liteErr.Error())
+		}
+	}
+	return err
+}
+
+// isRetryable true untuk error lock sementara yang aman diulang dengan transaksi baru
+func isRetryable(err error) bool {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return myErr.Number == mysqlErrDeadlock || myErr.Number == mysqlErrLockWaitTimeout
+	}
+	var liteErr sqlite3.Error
+	if errors.As(err, &liteErr) {
+		return liteErr.Code == sqlite3.ErrBusy || liteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
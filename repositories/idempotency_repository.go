@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"time"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlIdempotencyRepository adalah implementasi IdempotencyRepository berbasis database/sql (MySQL atau SQLite)
+type sqlIdempotencyRepository struct {
+	db *database.DB
+}
+
+func NewIdempotencyRepository(db *database.DB) IdempotencyRepository {
+	return &sqlIdempotencyRepository{db: db}
+}
+
+func (r *sqlIdempotencyRepository) Create(rec *models.IdempotencyRecord) error {
+	_, err := r.db.Exec("INSERT INTO idempotency_keys (idempotency_key, request_hash, status_code, expires_at) VALUES (?, ?, 0, ?)",
+		rec.Key, rec.RequestHash, rec.ExpiresAt.Unix())
+	return translateError(err)
+}
+
+func (r *sqlIdempotencyRepository) GetByKey(key string) (*models.IdempotencyRecord, error) {
+	rec := models.IdempotencyRecord{Key: key}
+	var expiresAt int64
+	err := r.db.QueryRow("SELECT request_hash, status_code, response, expires_at FROM idempotency_keys WHERE idempotency_key = ?", key).
+		Scan(&rec.RequestHash, &rec.StatusCode, &rec.Response, &expiresAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	rec.ExpiresAt = time.Unix(expiresAt, 0)
+	return &rec, nil
+}
+
+func (r *sqlIdempotencyRepository) Complete(key string, statusCode int, response []byte) error {
+	result, err := r.db.Exec("UPDATE idempotency_keys SET status_code = ?, response = ? WHERE idempotency_key = ?", statusCode, response, key)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqlIdempotencyRepository) Delete(key string) error {
+	result, err := r.db.Exec("DELETE FROM idempotency_keys WHERE idempotency_key = ?", key)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqlIdempotencyRepository) DeleteExpired(now time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM idempotency_keys WHERE expires_at < ?", now.Unix())
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type auditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(store *Store) repositories.AuditRepository {
+	return &auditRepository{store: store}
+}
+
+func (r *auditRepository) GetAll(filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	matched := make([]models.AuditEntry, 0)
+	// ORDER BY id DESC
+	for i := len(r.store.auditLog) - 1; i >= 0; i-- {
+		e := r.store.auditLog[i]
+		date := e.CreatedAt.Local().Format("2006-01-02")
+		switch {
+		case filter.Entity != "" && e.Entity != filter.Entity,
+			filter.EntityID != 0 && e.EntityID != filter.EntityID,
+			filter.ActorID != 0 && (e.ActorID == nil || *e.ActorID != filter.ActorID),
+			filter.Action != "" && e.Action != filter.Action,
+			filter.StartDate != "" && date < filter.StartDate,
+			filter.EndDate != "" && date > filter.EndDate:
+			continue
+		}
+		// LEFT JOIN users: username kosong kalau user sudah dihapus
+		if e.ActorID != nil {
+			e.ActorUsername = r.store.users[*e.ActorID].Username
+		}
+		matched = append(matched, e)
+	}
+
+	total := len(matched)
+	start := (filter.Page - 1) * filter.Limit
+	if start > total {
+		start = total
+	}
+	end := start + filter.Limit
+	if end > total {
+		end = total
+	}
+	return matched[start:end], total, nil
+}
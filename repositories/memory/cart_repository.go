@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type cartRepository struct {
+	store *Store
+}
+
+func NewCartRepository(store *Store) repositories.CartRepository {
+	return &cartRepository{store: store}
+}
+
+// cloneCart menyalin cart dan mengisi nama produk seperti JOIN di versi SQL
+func (s *Store) cloneCart(c models.Cart) models.Cart {
+	items := make([]models.CartItem, len(c.Items))
+	for i, it := range c.Items {
+		if it.Discount != nil {
+			d := *it.Discount
+			it.Discount = &d
+		}
+		it.ProductName = s.products[it.ProductID].Name
+		items[i] = it
+	}
+	c.Items = items
+	if c.ReservedUntil != nil {
+		until := *c.ReservedUntil
+		c.ReservedUntil = &until
+	}
+	if !c.Reserved {
+		c.ReservedUntil = nil
+	}
+	return c
+}
+
+func (r *cartRepository) GetAll(status string) ([]models.Cart, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	carts := make([]models.Cart, 0)
+	for _, c := range r.store.carts {
+		if status == "" || c.Status == status {
+			carts = append(carts, r.store.cloneCart(c))
+		}
+	}
+	sort.Slice(carts, func(i, j int) bool { return carts[i].ID < carts[j].ID })
+	return carts, nil
+}
+
+func (r *cartRepository) GetByID(id int) (*models.Cart, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.carts[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	c = r.store.cloneCart(c)
+	return &c, nil
+}
+
+// adjustReservation meniru versi SQL: semua selisih dicek dulu baru stock diubah
+// (dan dicatat ke ledger), jadi gagal di tengah tidak meninggalkan perubahan setengah jalan
+func (s *Store) adjustReservation(cartID int, old, new map[int]int) error {
+	ids, delta := repositories.ReservationDelta(old, new)
+	for _, id := range ids {
+		p, ok := s.products[id]
+		if !ok {
+			return repositories.ErrForeignKey
+		}
+		if d := delta[id]; d > 0 && p.Stock < d {
+			return &repositories.InsufficientStockError{ProductID: p.ID, ProductName: p.Name, Available: p.Stock, Requested: d}
+		}
+	}
+	for _, id := range ids {
+		p := s.products[id]
+		p.Stock -= delta[id]
+		s.products[id] = p
+		s.recordMovement(repositories.NewStockMovement(id, models.StockReservation, -delta[id], &cartID, 0, "cart reservation"))
+	}
+	return nil
+}
+
+// checkCartItems meniru FK cart_items.product_id
+// reservedStock adalah jumlah produk yang sedang di-reserve cart open
+func (s *Store) reservedStock(productID int) int {
+	reserved := 0
+	for _, c := range s.carts {
+		if c.Status == models.CartOpen {
+			reserved += repositories.CartReservation(&c)[productID]
+		}
+	}
+	return reserved
+}
+
+func (s *Store) checkCartItems(items []models.CartItem) error {
+	for _, it := range items {
+		if _, ok := s.products[it.ProductID]; !ok {
+			return repositories.ErrForeignKey
+		}
+	}
+	return nil
+}
+
+func (r *cartRepository) Create(cart *models.Cart) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.store.checkCartItems(cart.Items); err != nil {
+		return err
+	}
+	if err := r.store.adjustReservation(r.store.nextCartID, nil, repositories.CartReservation(cart)); err != nil {
+		return err
+	}
+	cart.ID = r.store.nextCartID
+	cart.Status = models.CartOpen
+	cart.TransactionID = nil
+	cart.CreatedAt = r.store.Now()
+	r.store.nextCartID++
+	r.store.carts[cart.ID] = r.store.cloneCart(*cart)
+	*cart = r.store.cloneCart(*cart)
+	return nil
+}
+
+// openCart mengembalikan cart yang masih open
+func (s *Store) openCart(id int) (models.Cart, error) {
+	c, ok := s.carts[id]
+	if !ok {
+		return c, repositories.ErrNotFound
+	}
+	if c.Status != models.CartOpen {
+		return c, repositories.ErrCartClosed
+	}
+	return c, nil
+}
+
+func (r *cartRepository) Update(cart *models.Cart) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	old, err := r.store.openCart(cart.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.store.checkCartItems(cart.Items); err != nil {
+		return err
+	}
+	if err := r.store.adjustReservation(old.ID, repositories.CartReservation(&old), repositories.CartReservation(cart)); err != nil {
+		return err
+	}
+	updated := old
+	updated.Note = cart.Note
+	updated.Items = cart.Items
+	updated.Reserved = cart.Reserved
+	updated.ReservedUntil = cart.ReservedUntil
+	r.store.carts[cart.ID] = r.store.cloneCart(updated)
+	*cart = r.store.cloneCart(updated)
+	return nil
+}
+
+func (r *cartRepository) Close(id int, status string, transactionID *int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, err := r.store.openCart(id)
+	if err != nil {
+		return err
+	}
+	if err := r.store.adjustReservation(c.ID, repositories.CartReservation(&c), nil); err != nil {
+		return err
+	}
+	c.Status = status
+	c.Reserved = false
+	c.ReservedUntil = nil
+	if transactionID != nil {
+		trxID := *transactionID
+		c.TransactionID = &trxID
+	}
+	r.store.carts[id] = c
+	return nil
+}
+
+func (r *cartRepository) ExpireReservations(now time.Time) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	released := 0
+	for id, c := range r.store.carts {
+		if c.Status != models.CartOpen || !c.Reserved || c.ReservedUntil == nil || !c.ReservedUntil.Before(now) {
+			continue
+		}
+		if err := r.store.adjustReservation(c.ID, repositories.CartReservation(&c), nil); err != nil {
+			return released, err
+		}
+		c.Reserved = false
+		c.ReservedUntil = nil
+		r.store.carts[id] = c
+		released++
+	}
+	return released, nil
+}
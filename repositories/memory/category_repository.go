@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type categoryRepository struct {
+	store *Store
+}
+
+func NewCategoryRepository(store *Store) repositories.CategoryRepository {
+	return &categoryRepository{store: store}
+}
+
+func (r *categoryRepository) GetAll() ([]models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var categories []models.Category
+	for _, c := range r.store.categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories, nil
+}
+
+func (r *categoryRepository) GetByID(id int) (*models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.categories[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (r *categoryRepository) GetByName(name string) (*models.Category, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, c := range r.store.categories {
+		if c.Name == name {
+			return &c, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+// nameTaken meniru UNIQUE constraint pada categories.name
+func (r *categoryRepository) nameTaken(name string, exceptID int) bool {
+	for _, c := range r.store.categories {
+		if c.Name == name && c.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *categoryRepository) Create(category *models.Category, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.nameTaken(category.Name, 0) {
+		return repositories.ErrDuplicate
+	}
+
+	stored := *category
+	stored.ID = r.store.nextCategoryID
+	if err := r.store.audit(actorID, models.AuditCategory, stored.ID, models.AuditCreate, nil, stored); err != nil {
+		return err
+	}
+	r.store.nextCategoryID++
+	category.ID = stored.ID
+	r.store.categories[category.ID] = stored
+	return nil
+}
+
+func (r *categoryRepository) Update(category *models.Category, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.categories[category.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if r.nameTaken(category.Name, category.ID) {
+		return repositories.ErrDuplicate
+	}
+	if err := r.store.audit(actorID, models.AuditCategory, category.ID, models.AuditUpdate, before, *category); err != nil {
+		return err
+	}
+	r.store.categories[category.ID] = *category
+	return nil
+}
+
+func (r *categoryRepository) Delete(id, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.categories[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	for _, p := range r.store.products {
+		if p.CategoryID == id {
+			return repositories.ErrForeignKey
+		}
+	}
+	if err := r.store.audit(actorID, models.AuditCategory, id, models.AuditDelete, before, nil); err != nil {
+		return err
+	}
+	delete(r.store.categories, id)
+	return nil
+}
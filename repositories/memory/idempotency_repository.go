@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type idempotencyRepository struct {
+	store *Store
+}
+
+func NewIdempotencyRepository(store *Store) repositories.IdempotencyRepository {
+	return &idempotencyRepository{store: store}
+}
+
+func (r *idempotencyRepository) Create(rec *models.IdempotencyRecord) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.idempotency[rec.Key]; ok {
+		return repositories.ErrDuplicate
+	}
+	stored := *rec
+	stored.StatusCode = 0
+	stored.Response = nil
+	stored.ExpiresAt = time.Unix(rec.ExpiresAt.Unix(), 0) // presisi detik seperti kolom expires_at
+	r.store.idempotency[rec.Key] = stored
+	return nil
+}
+
+func (r *idempotencyRepository) GetByKey(key string) (*models.IdempotencyRecord, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.idempotency[key]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	rec.Response = append([]byte(nil), rec.Response...)
+	return &rec, nil
+}
+
+func (r *idempotencyRepository) Complete(key string, statusCode int, response []byte) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.idempotency[key]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	rec.StatusCode = statusCode
+	rec.Response = append([]byte(nil), response...)
+	r.store.idempotency[key] = rec
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.idempotency[key]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.store.idempotency, key)
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(now time.Time) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deleted := 0
+	for key, rec := range r.store.idempotency {
+		if rec.ExpiresAt.Unix() < now.Unix() {
+			delete(r.store.idempotency, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
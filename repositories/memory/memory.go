@@ -0,0 +1,131 @@
+// Package memory berisi implementasi in-memory dari interface di package
+// repositories. Semantiknya mengikuti implementasi SQL (kategori default,
+// pengurangan stock, pemindahan kategori) dan dipakai untuk unit test.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+// DefaultCategoryName sama dengan data seed di migration 0001
+const DefaultCategoryName = "No Category"
+
+// Store adalah "database" bersama untuk semua repository in-memory
+type Store struct {
+	mu sync.Mutex
+
+	categories   map[int]models.Category
+	products     map[int]models.Product
+	transactions []models.Transaction
+	promoCodes   map[int]models.PromoCode
+	promotions   map[int]models.Promotion
+	carts        map[int]models.Cart
+	idempotency  map[string]models.IdempotencyRecord
+	users        map[int]models.User
+	sessions     map[string]models.Session
+	roles        map[int]models.Role
+	userRoles    map[int][]int // user ID -> role ID
+	shifts       map[int]models.Shift
+	auditLog     []models.AuditEntry
+	movements    []models.StockMovement
+	stockCounts  map[int]models.StockCount
+	suppliers    map[int]models.Supplier
+	purchases    map[int]models.PurchaseOrder
+
+	nextCategoryID     int
+	nextProductID      int
+	nextTransactionID  int
+	nextDetailID       int
+	nextRefundID       int
+	nextRefundItemID   int
+	nextPaymentID      int
+	nextPromoCodeID    int
+	nextPromotionID    int
+	nextAppliedPromoID int
+	nextCartID         int
+	nextUserID         int
+	nextShiftID        int
+	nextAuditID        int
+	nextMovementID     int
+	nextStockCountID   int
+	nextSupplierID     int
+	nextPurchaseID     int
+	nextReceiptID      int
+
+	// Now bisa diganti di test untuk mengatur created_at transaksi
+	Now func() time.Time
+}
+
+// NewStore membuat store kosong yang sudah berisi kategori default (id 1)
+// dan role bawaan seperti migration 0010
+func NewStore() *Store {
+	return &Store{
+		categories:         map[int]models.Category{1: {ID: 1, Name: DefaultCategoryName}},
+		products:           map[int]models.Product{},
+		promoCodes:         map[int]models.PromoCode{},
+		promotions:         map[int]models.Promotion{},
+		carts:              map[int]models.Cart{},
+		idempotency:        map[string]models.IdempotencyRecord{},
+		users:              map[int]models.User{},
+		sessions:           map[string]models.Session{},
+		roles:              defaultRoles(),
+		userRoles:          map[int][]int{},
+		shifts:             map[int]models.Shift{},
+		stockCounts:        map[int]models.StockCount{},
+		suppliers:          map[int]models.Supplier{},
+		purchases:          map[int]models.PurchaseOrder{},
+		nextCategoryID:     2,
+		nextProductID:      1,
+		nextTransactionID:  1,
+		nextDetailID:       1,
+		nextRefundID:       1,
+		nextRefundItemID:   1,
+		nextPaymentID:      1,
+		nextPromoCodeID:    1,
+		nextPromotionID:    1,
+		nextAppliedPromoID: 1,
+		nextCartID:         1,
+		nextUserID:         1,
+		nextShiftID:        1,
+		nextAuditID:        1,
+		nextMovementID:     1,
+		nextStockCountID:   1,
+		nextSupplierID:     1,
+		nextPurchaseID:     1,
+		nextReceiptID:      1,
+		Now:                time.Now,
+	}
+}
+
+// audit menambah entry audit log, caller sudah memegang lock
+func (s *Store) audit(actorID int, entity string, entityID int, action string, before, after interface{}) error {
+	entry, err := repositories.NewAuditEntry(actorID, entity, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+	entry.ID = s.nextAuditID
+	entry.CreatedAt = s.Now()
+	s.nextAuditID++
+	s.auditLog = append(s.auditLog, entry)
+	return nil
+}
+
+// recordMovement menambah baris ledger stock, caller sudah memegang lock
+// dan mengubah stock produknya sendiri
+func (s *Store) recordMovement(m models.StockMovement) {
+	m.ID = s.nextMovementID
+	m.CreatedAt = s.Now()
+	s.nextMovementID++
+	s.movements = append(s.movements, m)
+}
+
+func (s *Store) categoryName(id int) string {
+	if c, ok := s.categories[id]; ok {
+		return c.Name
+	}
+	return DefaultCategoryName
+}
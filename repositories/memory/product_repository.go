@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type productRepository struct {
+	store *Store
+}
+
+func NewProductRepository(store *Store) repositories.ProductRepository {
+	return &productRepository{store: store}
+}
+
+func (r *productRepository) GetAll(nameFilter string) ([]models.Product, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var products []models.Product
+	for _, p := range r.store.products {
+		// LIKE di MySQL default case-insensitive
+		if nameFilter != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(nameFilter)) {
+			continue
+		}
+		p.CategoryName = r.store.categoryName(p.CategoryID)
+		products = append(products, p)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+func (r *productRepository) GetByID(id int) (*models.Product, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.products[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	p.CategoryName = r.store.categoryName(p.CategoryID)
+	return &p, nil
+}
+
+func (r *productRepository) Create(p *models.Product, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if p.CategoryID == 0 {
+		p.CategoryID = 1
+	}
+	if _, ok := r.store.categories[p.CategoryID]; !ok {
+		return repositories.ErrForeignKey
+	}
+	stored := *p
+	stored.ID = r.store.nextProductID
+	stored.CategoryName = ""
+	if err := r.store.audit(actorID, models.AuditProduct, stored.ID, models.AuditCreate, nil, stored); err != nil {
+		return err
+	}
+	r.store.nextProductID++
+	p.ID = stored.ID
+	r.store.products[p.ID] = stored
+	if p.Stock != 0 {
+		r.store.recordMovement(repositories.NewStockMovement(p.ID, models.StockAdjustment, p.Stock, nil, actorID, "initial stock"))
+	}
+	return nil
+}
+
+func (r *productRepository) Update(id int, req models.ProductUpdateRequest, actorID int) (*models.Product, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.products[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	if _, ok := r.store.categories[req.CategoryID]; !ok {
+		return nil, repositories.ErrForeignKey
+	}
+	p := repositories.ApplyProductUpdate(before, req)
+	if err := r.store.audit(actorID, models.AuditProduct, id, models.AuditUpdate, before, p); err != nil {
+		return nil, err
+	}
+	r.store.products[id] = p
+	if delta := p.Stock - before.Stock; delta != 0 {
+		r.store.recordMovement(repositories.NewStockMovement(id, models.StockAdjustment, delta, nil, actorID, "product update"))
+	}
+
+	p.CategoryName = r.store.categoryName(p.CategoryID)
+	return &p, nil
+}
+
+func (r *productRepository) Delete(id, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.products[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	// transaction_details.product_id mereferensikan produk
+	for _, t := range r.store.transactions {
+		for _, d := range t.Details {
+			if d.ProductID == id {
+				return repositories.ErrForeignKey
+			}
+		}
+	}
+	// purchase_order_items.product_id juga tanpa cascade
+	for _, po := range r.store.purchases {
+		for _, it := range po.Items {
+			if it.ProductID == id {
+				return repositories.ErrForeignKey
+			}
+		}
+	}
+	if err := r.store.audit(actorID, models.AuditProduct, id, models.AuditDelete, before, nil); err != nil {
+		return err
+	}
+	delete(r.store.products, id)
+
+	// promotion_items.product_id ON DELETE CASCADE
+	for pid, promo := range r.store.promotions {
+		items := promo.Items[:0:0]
+		for _, it := range promo.Items {
+			if it.ProductID != id {
+				items = append(items, it)
+			}
+		}
+		promo.Items = items
+		r.store.promotions[pid] = promo
+	}
+	// cart_items.product_id ON DELETE CASCADE
+	for cid, cart := range r.store.carts {
+		items := cart.Items[:0:0]
+		for _, it := range cart.Items {
+			if it.ProductID != id {
+				items = append(items, it)
+			}
+		}
+		cart.Items = items
+		r.store.carts[cid] = cart
+	}
+	// stock_count_items.product_id ON DELETE CASCADE
+	for cid, count := range r.store.stockCounts {
+		items := count.Items[:0:0]
+		for _, it := range count.Items {
+			if it.ProductID != id {
+				items = append(items, it)
+			}
+		}
+		count.Items = items
+		r.store.stockCounts[cid] = count
+	}
+	return nil
+}
+
+func (r *productRepository) BulkUpdateCategory(oldCatID, newCatID, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Urut ID seperti ORDER BY id di versi SQL, supaya urutan audit log sama
+	var moved []models.Product
+	for _, p := range r.store.products {
+		if p.CategoryID == oldCatID {
+			moved = append(moved, p)
+		}
+	}
+	sort.Slice(moved, func(i, j int) bool { return moved[i].ID < moved[j].ID })
+	for _, before := range moved {
+		after := before
+		after.CategoryID = newCatID
+		if err := r.store.audit(actorID, models.AuditProduct, before.ID, models.AuditUpdate, before, after); err != nil {
+			return err
+		}
+		r.store.products[after.ID] = after
+	}
+	return nil
+}
+
+func (r *productRepository) AdjustStock(id, quantity, actorID int, note string) (*models.Product, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.products[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	if p.Stock+quantity < 0 {
+		return nil, &repositories.InsufficientStockError{ProductID: p.ID, ProductName: p.Name, Available: p.Stock, Requested: -quantity}
+	}
+	before := p
+	p.Stock += quantity
+	if err := r.store.audit(actorID, models.AuditProduct, id, models.AuditUpdate, before, p); err != nil {
+		return nil, err
+	}
+	r.store.products[id] = p
+	r.store.recordMovement(repositories.NewStockMovement(id, models.StockAdjustment, quantity, nil, actorID, note))
+
+	p.CategoryName = r.store.categoryName(p.CategoryID)
+	return &p, nil
+}
+
+func (r *productRepository) GetLowStock() ([]models.Product, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	products := make([]models.Product, 0)
+	for _, p := range r.store.products {
+		if p.ReorderPoint > 0 && p.Stock <= p.ReorderPoint {
+			p.CategoryName = r.store.categoryName(p.CategoryID)
+			products = append(products, p)
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type promoCodeRepository struct {
+	store *Store
+}
+
+func NewPromoCodeRepository(store *Store) repositories.PromoCodeRepository {
+	return &promoCodeRepository{store: store}
+}
+
+func (s *Store) promoByCode(code string) (models.PromoCode, bool) {
+	for _, p := range s.promoCodes {
+		if p.Code == code {
+			return p, true
+		}
+	}
+	return models.PromoCode{}, false
+}
+
+func (r *promoCodeRepository) GetAll() ([]models.PromoCode, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var promos []models.PromoCode
+	for _, p := range r.store.promoCodes {
+		promos = append(promos, p)
+	}
+	sort.Slice(promos, func(i, j int) bool { return promos[i].ID < promos[j].ID })
+	return promos, nil
+}
+
+func (r *promoCodeRepository) GetByID(id int) (*models.PromoCode, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.promoCodes[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *promoCodeRepository) GetByCode(code string) (*models.PromoCode, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.promoByCode(code)
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *promoCodeRepository) Create(p *models.PromoCode) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// UNIQUE constraint pada promo_codes.code
+	if _, taken := r.store.promoByCode(p.Code); taken {
+		return repositories.ErrDuplicate
+	}
+	p.ID = r.store.nextPromoCodeID
+	p.UsedCount = 0
+	r.store.nextPromoCodeID++
+	r.store.promoCodes[p.ID] = *p
+	return nil
+}
+
+func (r *promoCodeRepository) Update(p *models.PromoCode) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	old, ok := r.store.promoCodes[p.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if other, taken := r.store.promoByCode(p.Code); taken && other.ID != p.ID {
+		return repositories.ErrDuplicate
+	}
+	p.UsedCount = old.UsedCount
+	r.store.promoCodes[p.ID] = *p
+	return nil
+}
+
+func (r *promoCodeRepository) Delete(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.promoCodes[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.store.promoCodes, id)
+	return nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type promotionRepository struct {
+	store *Store
+}
+
+func NewPromotionRepository(store *Store) repositories.PromotionRepository {
+	return &promotionRepository{store: store}
+}
+
+func clonePromotion(p models.Promotion) models.Promotion {
+	p.Items = append([]models.PromotionItem{}, p.Items...)
+	return p
+}
+
+func (r *promotionRepository) GetAll(activeOnly bool) ([]models.Promotion, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	promos := make([]models.Promotion, 0)
+	for _, p := range r.store.promotions {
+		if activeOnly && !p.Active {
+			continue
+		}
+		promos = append(promos, clonePromotion(p))
+	}
+	// ORDER BY priority DESC, id
+	sort.Slice(promos, func(i, j int) bool {
+		if promos[i].Priority != promos[j].Priority {
+			return promos[i].Priority > promos[j].Priority
+		}
+		return promos[i].ID < promos[j].ID
+	})
+	return promos, nil
+}
+
+func (r *promotionRepository) GetByID(id int) (*models.Promotion, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.promotions[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	p = clonePromotion(p)
+	return &p, nil
+}
+
+// checkItems meniru FK promotion_items.product_id
+func (r *promotionRepository) checkItems(items []models.PromotionItem) error {
+	for _, it := range items {
+		if _, ok := r.store.products[it.ProductID]; !ok {
+			return repositories.ErrForeignKey
+		}
+	}
+	return nil
+}
+
+func (r *promotionRepository) Create(p *models.Promotion) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.checkItems(p.Items); err != nil {
+		return err
+	}
+	p.ID = r.store.nextPromotionID
+	r.store.nextPromotionID++
+	r.store.promotions[p.ID] = clonePromotion(*p)
+	return nil
+}
+
+func (r *promotionRepository) Update(p *models.Promotion) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.promotions[p.ID]; !ok {
+		return repositories.ErrNotFound
+	}
+	if err := r.checkItems(p.Items); err != nil {
+		return err
+	}
+	r.store.promotions[p.ID] = clonePromotion(*p)
+	return nil
+}
+
+func (r *promotionRepository) Delete(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.promotions[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.store.promotions, id)
+	return nil
+}
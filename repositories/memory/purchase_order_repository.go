@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type purchaseOrderRepository struct {
+	store *Store
+}
+
+func NewPurchaseOrderRepository(store *Store) repositories.PurchaseOrderRepository {
+	return &purchaseOrderRepository{store: store}
+}
+
+// clonePurchaseOrder menyalin PO dengan nama supplier/produk terbaru (JOIN)
+// dan items urut product ID seperti versi SQL
+func (s *Store) clonePurchaseOrder(po models.PurchaseOrder) models.PurchaseOrder {
+	po.SupplierName = s.suppliers[po.SupplierID].Name
+	items := make([]models.PurchaseOrderItem, 0, len(po.Items))
+	for _, it := range po.Items {
+		it.ProductName = s.products[it.ProductID].Name
+		it.RemainingQuantity = it.Quantity - it.ReceivedQuantity
+		items = append(items, it)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+	po.Items = items
+	po.Receipts = append([]models.PurchaseReceipt{}, po.Receipts...)
+	return po
+}
+
+func (r *purchaseOrderRepository) GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for _, po := range r.store.purchases {
+		if filter.Status != "" && po.Status != filter.Status {
+			continue
+		}
+		if filter.SupplierID != 0 && po.SupplierID != filter.SupplierID {
+			continue
+		}
+		orders = append(orders, r.store.clonePurchaseOrder(po))
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID > orders[j].ID })
+	return orders, nil
+}
+
+func (r *purchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	po, ok := r.store.purchases[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	po = r.store.clonePurchaseOrder(po)
+	return &po, nil
+}
+
+func (r *purchaseOrderRepository) Create(po *models.PurchaseOrder) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.suppliers[po.SupplierID]; !ok {
+		return repositories.ErrForeignKey
+	}
+	items := make([]models.PurchaseOrderItem, 0, len(po.Items))
+	for _, it := range po.Items {
+		if _, ok := r.store.products[it.ProductID]; !ok {
+			return repositories.ErrForeignKey
+		}
+		items = append(items, models.PurchaseOrderItem{ProductID: it.ProductID, Quantity: it.Quantity, UnitCost: it.UnitCost})
+	}
+	stored := models.PurchaseOrder{
+		ID:         r.store.nextPurchaseID,
+		SupplierID: po.SupplierID,
+		Status:     models.PurchaseOrderDraft,
+		Note:       po.Note,
+		CreatedBy:  po.CreatedBy,
+		CreatedAt:  r.store.Now(),
+		Items:      items,
+	}
+	r.store.nextPurchaseID++
+	r.store.purchases[stored.ID] = stored
+	*po = r.store.clonePurchaseOrder(stored)
+	return nil
+}
+
+func (r *purchaseOrderRepository) Approve(id, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	po, ok := r.store.purchases[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if po.Status != models.PurchaseOrderDraft {
+		return repositories.ErrPurchaseOrderState
+	}
+	now := r.store.Now()
+	po.Status = models.PurchaseOrderApproved
+	po.ApprovedBy = repositories.ActorRef(actorID)
+	po.ApprovedAt = &now
+	r.store.purchases[id] = po
+	return nil
+}
+
+func (r *purchaseOrderRepository) Receive(id int, receipts []models.PurchaseReceipt, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	po, ok := r.store.purchases[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if po.Status != models.PurchaseOrderApproved && po.Status != models.PurchaseOrderPartiallyReceived {
+		return repositories.ErrPurchaseOrderState
+	}
+
+	// Semua baris dicek dulu supaya receipt yang gagal tidak tersimpan sebagian
+	index := map[int]int{}
+	for i, it := range po.Items {
+		index[it.ProductID] = i
+	}
+	items := append([]models.PurchaseOrderItem(nil), po.Items...)
+	for _, rc := range receipts {
+		i, ok := index[rc.ProductID]
+		if !ok {
+			return fmt.Errorf("%w: product %d is not on this purchase order", repositories.ErrInvalidReceipt, rc.ProductID)
+		}
+		if remaining := items[i].Quantity - items[i].ReceivedQuantity; rc.Quantity > remaining {
+			return fmt.Errorf("%w: product %d has only %d left to receive, got %d", repositories.ErrInvalidReceipt, rc.ProductID, remaining, rc.Quantity)
+		}
+		items[i].ReceivedQuantity += rc.Quantity
+	}
+
+	sorted := append([]models.PurchaseReceipt(nil), receipts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+	orderID := id
+	now := r.store.Now()
+	for _, rc := range sorted {
+		before := r.store.products[rc.ProductID]
+		p := before
+		p.CostPrice = repositories.WeightedAverageCost(p.Stock, p.CostPrice, rc.Quantity, rc.UnitCost)
+		p.Stock += rc.Quantity
+		if err := r.store.audit(actorID, models.AuditProduct, p.ID, models.AuditUpdate, before, p); err != nil {
+			return err
+		}
+		r.store.products[p.ID] = p
+		r.store.recordMovement(repositories.NewStockMovement(p.ID, models.StockPurchase, rc.Quantity, &orderID, actorID, "purchase order"))
+
+		rc.ID = r.store.nextReceiptID
+		rc.PurchaseOrderID = id
+		rc.ReceivedBy = repositories.ActorRef(actorID)
+		rc.ReceivedAt = now
+		r.store.nextReceiptID++
+		po.Receipts = append(po.Receipts, rc)
+	}
+
+	po.Items = items
+	po.Status = models.PurchaseOrderReceived
+	for _, it := range items {
+		if it.ReceivedQuantity < it.Quantity {
+			po.Status = models.PurchaseOrderPartiallyReceived
+		}
+	}
+	if po.Status == models.PurchaseOrderReceived {
+		po.ClosedBy = repositories.ActorRef(actorID)
+		po.ClosedAt = &now
+	}
+	r.store.purchases[id] = po
+	return nil
+}
+
+func (r *purchaseOrderRepository) Cancel(id, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	po, ok := r.store.purchases[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if po.Status != models.PurchaseOrderDraft && po.Status != models.PurchaseOrderApproved {
+		return repositories.ErrPurchaseOrderState
+	}
+	now := r.store.Now()
+	po.Status = models.PurchaseOrderCancelled
+	po.ClosedBy = repositories.ActorRef(actorID)
+	po.ClosedAt = &now
+	r.store.purchases[id] = po
+	return nil
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"slices"
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+// defaultRoles sama dengan data seed di migration 0010 (ditambah shift:read dari 0011)
+func defaultRoles() map[int]models.Role {
+	return map[int]models.Role{
+		1: {ID: 1, Name: models.RoleOwner, Permissions: append([]string(nil), models.AllPermissions...)},
+		2: {ID: 2, Name: models.RoleManager, Permissions: []string{
+			models.PermProductRead, models.PermProductWrite, models.PermCategoryRead, models.PermCategoryWrite,
+			models.PermTransactionCreate, models.PermTransactionRead, models.PermTransactionRefund,
+			models.PermPromoRead, models.PermPromoWrite, models.PermShiftRead, models.PermStockCount,
+			models.PermPurchase, models.PermShiftManage, models.PermPurchaseApprove,
+		}},
+		3: {ID: 3, Name: models.RoleCashier, Permissions: []string{
+			models.PermProductRead, models.PermCategoryRead, models.PermTransactionCreate, models.PermTransactionRead,
+			models.PermPromoRead,
+		}},
+	}
+}
+
+type roleRepository struct {
+	store *Store
+}
+
+func NewRoleRepository(store *Store) repositories.RoleRepository {
+	return &roleRepository{store: store}
+}
+
+// cloneRole menyalin role dengan permission terurut seperti ORDER BY permission
+func cloneRole(role models.Role) models.Role {
+	role.Permissions = append([]string{}, role.Permissions...)
+	sort.Strings(role.Permissions)
+	return role
+}
+
+func (r *roleRepository) GetAll() ([]models.Role, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var roles []models.Role
+	for _, role := range r.store.roles {
+		roles = append(roles, cloneRole(role))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ID < roles[j].ID })
+	return roles, nil
+}
+
+func (r *roleRepository) GetByID(id int) (*models.Role, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	role, ok := r.store.roles[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	role = cloneRole(role)
+	return &role, nil
+}
+
+func (r *roleRepository) SetPermissions(roleID int, permissions []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	role, ok := r.store.roles[roleID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	// PRIMARY KEY (role_id, permission)
+	seen := map[string]bool{}
+	for _, p := range permissions {
+		if seen[p] {
+			return repositories.ErrDuplicate
+		}
+		seen[p] = true
+	}
+	role.Permissions = append([]string{}, permissions...)
+	r.store.roles[roleID] = role
+	return nil
+}
+
+func (r *roleRepository) GetUserRoles(userID int) ([]models.Role, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var roles []models.Role
+	for _, id := range r.store.userRoles[userID] {
+		roles = append(roles, cloneRole(r.store.roles[id]))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ID < roles[j].ID })
+	return roles, nil
+}
+
+// checkNotLastOwner menolak kalau userID adalah satu-satunya owner aktif, sama seperti versi SQL
+func (s *Store) checkNotLastOwner(userID int) error {
+	var owners []int
+	for id, u := range s.users {
+		if !u.Active {
+			continue
+		}
+		for _, roleID := range s.userRoles[id] {
+			if s.roles[roleID].Name == models.RoleOwner {
+				owners = append(owners, id)
+			}
+		}
+	}
+	if len(owners) == 1 && owners[0] == userID {
+		return repositories.ErrLastOwner
+	}
+	return nil
+}
+
+func (r *roleRepository) SetUserRoles(userID int, roleIDs []int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userID]; !ok && len(roleIDs) > 0 {
+		return repositories.ErrForeignKey
+	}
+	seen := map[int]bool{}
+	for _, id := range roleIDs {
+		if _, ok := r.store.roles[id]; !ok {
+			return repositories.ErrForeignKey
+		}
+		if seen[id] {
+			return repositories.ErrDuplicate
+		}
+		seen[id] = true
+	}
+	if !slices.ContainsFunc(roleIDs, func(id int) bool { return r.store.roles[id].Name == models.RoleOwner }) {
+		if err := r.store.checkNotLastOwner(userID); err != nil {
+			return err
+		}
+	}
+	if len(roleIDs) == 0 {
+		delete(r.store.userRoles, userID)
+		return nil
+	}
+	r.store.userRoles[userID] = append([]int(nil), roleIDs...)
+	return nil
+}
+
+func (r *roleRepository) CountUsersWithRole(roleID int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	n := 0
+	for _, ids := range r.store.userRoles {
+		for _, id := range ids {
+			if id == roleID {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+func (r *roleRepository) UserPermissions(userID int) ([]string, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	seen := map[string]bool{}
+	perms := []string{}
+	for _, id := range r.store.userRoles[userID] {
+		for _, p := range r.store.roles[id].Permissions {
+			if !seen[p] {
+				seen[p] = true
+				perms = append(perms, p)
+			}
+		}
+	}
+	sort.Strings(perms)
+	return perms, nil
+}
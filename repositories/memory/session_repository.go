@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type sessionRepository struct {
+	store *Store
+}
+
+func NewSessionRepository(store *Store) repositories.SessionRepository {
+	return &sessionRepository{store: store}
+}
+
+func (s *Store) deleteSessions(userID int) {
+	for hash, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, hash)
+		}
+	}
+}
+
+func (r *sessionRepository) Create(s *models.Session) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[s.UserID]; !ok {
+		return repositories.ErrForeignKey
+	}
+	if _, ok := r.store.sessions[s.TokenHash]; ok {
+		return repositories.ErrDuplicate
+	}
+	stored := *s
+	stored.ExpiresAt = time.Unix(s.ExpiresAt.Unix(), 0) // presisi detik seperti kolom expires_at
+	r.store.sessions[s.TokenHash] = stored
+	return nil
+}
+
+func (r *sessionRepository) GetByTokenHash(tokenHash string) (*models.Session, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.sessions[tokenHash]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *sessionRepository) Delete(tokenHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.sessions[tokenHash]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.store.sessions, tokenHash)
+	return nil
+}
+
+func (r *sessionRepository) DeleteByUser(userID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.deleteSessions(userID)
+	return nil
+}
+
+func (r *sessionRepository) DeleteExpired(now time.Time) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deleted := 0
+	for hash, s := range r.store.sessions {
+		if s.ExpiresAt.Unix() < now.Unix() {
+			delete(r.store.sessions, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type shiftRepository struct {
+	store *Store
+}
+
+func NewShiftRepository(store *Store) repositories.ShiftRepository {
+	return &shiftRepository{store: store}
+}
+
+// checkOpenShift dipakai checkout dan refund, caller sudah memegang lock
+func (s *Store) checkOpenShift(id int) error {
+	sh, ok := s.shifts[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if sh.Status != models.ShiftOpen {
+		return repositories.ErrShiftClosed
+	}
+	return nil
+}
+
+// shift mengisi username seperti JOIN users di versi SQL
+func (s *Store) shift(sh models.Shift) *models.Shift {
+	sh.Username = s.users[sh.UserID].Username
+	return &sh
+}
+
+func (s *Store) shiftSummary(id int) models.ShiftSummary {
+	summary := models.ShiftSummary{SalesByMethod: map[string]int{}}
+	for _, t := range s.transactions {
+		if t.ShiftID != nil && *t.ShiftID == id {
+			summary.TransactionCount++
+			for _, pay := range t.Payments {
+				summary.SalesByMethod[pay.Method] += pay.Amount
+			}
+		}
+		for _, rf := range t.Refunds {
+			if rf.ShiftID != nil && *rf.ShiftID == id {
+				summary.RefundCount++
+				summary.RefundTotal += rf.Amount
+				summary.CashRefundTotal += rf.CashAmount
+			}
+		}
+	}
+	return summary
+}
+
+func (r *shiftRepository) GetAll(filter models.ShiftFilter) ([]models.Shift, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	shifts := []models.Shift{}
+	for _, sh := range r.store.shifts {
+		if filter.UserID != 0 && sh.UserID != filter.UserID {
+			continue
+		}
+		if filter.Status != "" && sh.Status != filter.Status {
+			continue
+		}
+		shifts = append(shifts, *r.store.shift(sh))
+	}
+	sort.Slice(shifts, func(i, j int) bool { return shifts[i].ID > shifts[j].ID })
+	return shifts, nil
+}
+
+func (r *shiftRepository) GetByID(id int) (*models.Shift, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sh, ok := r.store.shifts[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return r.store.shift(sh), nil
+}
+
+func (r *shiftRepository) GetOpenByUser(userID int) (*models.Shift, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, sh := range r.store.shifts {
+		if sh.UserID == userID && sh.Status == models.ShiftOpen {
+			return r.store.shift(sh), nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r *shiftRepository) Open(shift *models.Shift) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[shift.UserID]; !ok {
+		return repositories.ErrForeignKey
+	}
+	for _, sh := range r.store.shifts {
+		if sh.UserID == shift.UserID && sh.Status == models.ShiftOpen {
+			return repositories.ErrShiftAlreadyOpen
+		}
+	}
+	stored := models.Shift{
+		ID:          r.store.nextShiftID,
+		UserID:      shift.UserID,
+		Status:      models.ShiftOpen,
+		OpeningCash: shift.OpeningCash,
+		Note:        shift.Note,
+		OpenedAt:    r.store.Now(),
+	}
+	r.store.nextShiftID++
+	r.store.shifts[stored.ID] = stored
+	*shift = *r.store.shift(stored)
+	return nil
+}
+
+func (r *shiftRepository) Summary(shiftID int) (models.ShiftSummary, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.shiftSummary(shiftID), nil
+}
+
+func (r *shiftRepository) Close(id, countedCash int, note string) (*models.Shift, models.ShiftSummary, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.store.checkOpenShift(id); err != nil {
+		return nil, models.ShiftSummary{}, err
+	}
+	sh := r.store.shifts[id]
+	summary := r.store.shiftSummary(id)
+	expected := repositories.ExpectedCash(sh.OpeningCash, summary)
+	closedAt := r.store.Now()
+	sh.Status = models.ShiftClosed
+	sh.ExpectedCash, sh.CountedCash, sh.ClosedAt = &expected, &countedCash, &closedAt
+	if note != "" {
+		sh.Note = note
+	}
+	r.store.shifts[id] = sh
+	return r.store.shift(sh), summary, nil
+}
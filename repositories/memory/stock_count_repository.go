@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type stockCountRepository struct {
+	store *Store
+}
+
+func NewStockCountRepository(store *Store) repositories.StockCountRepository {
+	return &stockCountRepository{store: store}
+}
+
+// cloneStockCount menyalin sesi dengan nama/harga produk terbaru (JOIN products)
+// dan items urut product ID seperti versi SQL
+func (s *Store) cloneStockCount(c models.StockCount) models.StockCount {
+	items := make([]models.StockCountItem, 0, len(c.Items))
+	for _, it := range c.Items {
+		p := s.products[it.ProductID]
+		it.ProductName, it.Price = p.Name, p.Price
+		it.Variance = it.CountedQuantity - it.SystemStock
+		items = append(items, it)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+	c.Items = items
+	return c
+}
+
+func (r *stockCountRepository) GetAll(status string) ([]models.StockCount, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	counts := make([]models.StockCount, 0)
+	for _, c := range r.store.stockCounts {
+		if status == "" || c.Status == status {
+			counts = append(counts, r.store.cloneStockCount(c))
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].ID > counts[j].ID })
+	return counts, nil
+}
+
+func (r *stockCountRepository) GetByID(id int) (*models.StockCount, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.stockCounts[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	c = r.store.cloneStockCount(c)
+	return &c, nil
+}
+
+func (r *stockCountRepository) Create(count *models.StockCount) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, c := range r.store.stockCounts {
+		if c.Status == models.StockCountOpen {
+			return repositories.ErrStockCountOpen
+		}
+	}
+	stored := models.StockCount{
+		ID:        r.store.nextStockCountID,
+		Status:    models.StockCountOpen,
+		Note:      count.Note,
+		CreatedBy: count.CreatedBy,
+		CreatedAt: r.store.Now(),
+	}
+	r.store.nextStockCountID++
+	r.store.stockCounts[stored.ID] = stored
+	*count = r.store.cloneStockCount(stored)
+	return nil
+}
+
+// openStockCount mengembalikan sesi yang masih open
+func (s *Store) openStockCount(id int) (models.StockCount, error) {
+	c, ok := s.stockCounts[id]
+	if !ok {
+		return c, repositories.ErrNotFound
+	}
+	if c.Status != models.StockCountOpen {
+		return c, repositories.ErrStockCountClosed
+	}
+	return c, nil
+}
+
+func (r *stockCountRepository) SubmitItems(id int, items []models.StockCountItem, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, err := r.store.openStockCount(id)
+	if err != nil {
+		return err
+	}
+	// Semua produk dicek dulu supaya batch yang gagal tidak tersimpan sebagian
+	for _, it := range items {
+		if _, ok := r.store.products[it.ProductID]; !ok {
+			return repositories.ErrForeignKey
+		}
+	}
+	counted := make(map[int]models.StockCountItem, len(c.Items)+len(items))
+	for _, it := range c.Items {
+		counted[it.ProductID] = it
+	}
+	for _, it := range items {
+		counted[it.ProductID] = models.StockCountItem{
+			ProductID:       it.ProductID,
+			SystemStock:     r.store.products[it.ProductID].Stock + r.store.reservedStock(it.ProductID),
+			CountedQuantity: it.CountedQuantity,
+			CountedBy:       repositories.ActorRef(actorID),
+			CountedAt:       r.store.Now(),
+		}
+	}
+	c.Items = make([]models.StockCountItem, 0, len(counted))
+	for _, it := range counted {
+		c.Items = append(c.Items, it)
+	}
+	r.store.stockCounts[id] = c
+	return nil
+}
+
+func (r *stockCountRepository) Finalize(id, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, err := r.store.openStockCount(id)
+	if err != nil {
+		return err
+	}
+	c = r.store.cloneStockCount(c)
+	for _, it := range c.Items {
+		if p := r.store.products[it.ProductID]; p.Stock+it.Variance < 0 {
+			return &repositories.InsufficientStockError{ProductID: p.ID, ProductName: p.Name, Available: p.Stock, Requested: -it.Variance}
+		}
+	}
+	countID := id
+	for _, it := range c.Items {
+		if it.Variance == 0 {
+			continue
+		}
+		before := r.store.products[it.ProductID]
+		p := before
+		p.Stock += it.Variance
+		if err := r.store.audit(actorID, models.AuditProduct, p.ID, models.AuditUpdate, before, p); err != nil {
+			return err
+		}
+		r.store.products[p.ID] = p
+		r.store.recordMovement(repositories.NewStockMovement(p.ID, models.StockAdjustment, it.Variance, &countID, actorID, "stock opname"))
+	}
+	r.store.closeStockCount(&c, models.StockCountFinalized, actorID)
+	return nil
+}
+
+func (r *stockCountRepository) Cancel(id, actorID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, err := r.store.openStockCount(id)
+	if err != nil {
+		return err
+	}
+	r.store.closeStockCount(&c, models.StockCountCancelled, actorID)
+	return nil
+}
+
+func (s *Store) closeStockCount(c *models.StockCount, status string, actorID int) {
+	now := s.Now()
+	c.Status = status
+	c.ClosedBy = repositories.ActorRef(actorID)
+	c.ClosedAt = &now
+	s.stockCounts[c.ID] = *c
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type stockMovementRepository struct {
+	store *Store
+}
+
+func NewStockMovementRepository(store *Store) repositories.StockMovementRepository {
+	return &stockMovementRepository{store: store}
+}
+
+func (r *stockMovementRepository) GetByProduct(filter models.StockMovementFilter) ([]models.StockMovement, int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	matched := make([]models.StockMovement, 0)
+	// ORDER BY id DESC
+	for i := len(r.store.movements) - 1; i >= 0; i-- {
+		m := r.store.movements[i]
+		date := m.CreatedAt.Local().Format("2006-01-02")
+		switch {
+		case m.ProductID != filter.ProductID,
+			filter.Type != "" && m.Type != filter.Type,
+			filter.StartDate != "" && date < filter.StartDate,
+			filter.EndDate != "" && date > filter.EndDate:
+			continue
+		}
+		// LEFT JOIN users: username kosong kalau user sudah dihapus
+		if m.ActorID != nil {
+			m.ActorUsername = r.store.users[*m.ActorID].Username
+		}
+		matched = append(matched, m)
+	}
+
+	total := len(matched)
+	start := min((filter.Page-1)*filter.Limit, total)
+	end := min(start+filter.Limit, total)
+	return matched[start:end], total, nil
+}
+
+func (r *stockMovementRepository) CheckConsistency() (int, []models.StockDiscrepancy, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ledger := map[int]int{}
+	for _, m := range r.store.movements {
+		ledger[m.ProductID] += m.Quantity
+	}
+	discrepancies := make([]models.StockDiscrepancy, 0)
+	for _, p := range r.store.products {
+		if p.Stock != ledger[p.ID] {
+			discrepancies = append(discrepancies, models.StockDiscrepancy{
+				ProductID:   p.ID,
+				ProductName: p.Name,
+				Stock:       p.Stock,
+				LedgerStock: ledger[p.ID],
+				Difference:  p.Stock - ledger[p.ID],
+			})
+		}
+	}
+	sort.Slice(discrepancies, func(i, j int) bool { return discrepancies[i].ProductID < discrepancies[j].ProductID })
+	return len(r.store.products), discrepancies, nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type supplierRepository struct {
+	store *Store
+}
+
+func NewSupplierRepository(store *Store) repositories.SupplierRepository {
+	return &supplierRepository{store: store}
+}
+
+func (r *supplierRepository) GetAll() ([]models.Supplier, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	suppliers := make([]models.Supplier, 0, len(r.store.suppliers))
+	for _, s := range r.store.suppliers {
+		suppliers = append(suppliers, s)
+	}
+	sort.Slice(suppliers, func(i, j int) bool { return suppliers[i].Name < suppliers[j].Name })
+	return suppliers, nil
+}
+
+func (r *supplierRepository) GetByID(id int) (*models.Supplier, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.suppliers[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &s, nil
+}
+
+// nameTaken meniru UNIQUE constraint pada suppliers.name
+func (r *supplierRepository) nameTaken(name string, exceptID int) bool {
+	for _, s := range r.store.suppliers {
+		if s.Name == name && s.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *supplierRepository) Create(s *models.Supplier) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.nameTaken(s.Name, 0) {
+		return repositories.ErrDuplicate
+	}
+	s.ID = r.store.nextSupplierID
+	s.CreatedAt = r.store.Now()
+	r.store.nextSupplierID++
+	r.store.suppliers[s.ID] = *s
+	return nil
+}
+
+func (r *supplierRepository) Update(s *models.Supplier) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	before, ok := r.store.suppliers[s.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if r.nameTaken(s.Name, s.ID) {
+		return repositories.ErrDuplicate
+	}
+	s.CreatedAt = before.CreatedAt
+	r.store.suppliers[s.ID] = *s
+	return nil
+}
+
+func (r *supplierRepository) Delete(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.suppliers[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	// purchase_orders.supplier_id tanpa cascade
+	for _, po := range r.store.purchases {
+		if po.SupplierID == id {
+			return repositories.ErrForeignKey
+		}
+	}
+	delete(r.store.suppliers, id)
+	return nil
+}
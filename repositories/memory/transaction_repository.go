@@ -0,0 +1,573 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type transactionRepository struct {
+	store *Store
+}
+
+func NewTransactionRepository(store *Store) repositories.TransactionRepository {
+	return &transactionRepository{store: store}
+}
+
+// CreateTransaction bersifat atomik seperti versi SQL: stock dicek dan harga
+// dihitung dulu, stock baru dikurangi kalau tidak ada error.
+func (r *transactionRepository) CreateTransaction(items []models.CheckoutItem, price repositories.PriceFunc) (*models.Transaction, error) {
+	return r.checkout(0, items, price)
+}
+
+func (r *transactionRepository) CreateCartTransaction(cartID int, items []models.CheckoutItem, price repositories.PriceFunc) (*models.Transaction, error) {
+	return r.checkout(cartID, items, price)
+}
+
+// checkout: cartID 0 berarti checkout biasa; selain itu stock yang di-reserve
+// cart dipakai untuk penjualan dan cart ditutup di bawah lock yang sama
+func (r *transactionRepository) checkout(cartID int, items []models.CheckoutItem, price repositories.PriceFunc) (*models.Transaction, error) {
+	if price == nil {
+		price = repositories.DefaultPricing
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var reserved map[int]int
+	if cartID != 0 {
+		cart, ok := r.store.carts[cartID]
+		if !ok {
+			return nil, repositories.ErrNotFound
+		}
+		var err error
+		if reserved, err = repositories.CartCheckout(&cart, items); err != nil {
+			return nil, err
+		}
+	}
+
+	products, err := r.store.checkoutProducts(items)
+	if err != nil {
+		return nil, err
+	}
+	repositories.WithReservedStock(products, reserved)
+	if err := repositories.CheckStock(items, products); err != nil {
+		return nil, err
+	}
+
+	trx, err := price(items, products)
+	if err != nil {
+		return nil, err
+	}
+	repositories.CaptureUnitCost(trx, products)
+
+	if trx.ShiftID != nil {
+		if err := r.store.checkOpenShift(*trx.ShiftID); err != nil {
+			return nil, err
+		}
+	}
+
+	if trx.PromoCode != "" {
+		promo, ok := r.store.promoByCode(trx.PromoCode)
+		if !ok || !promo.Active || (promo.UsageLimit > 0 && promo.UsedCount >= promo.UsageLimit) {
+			return nil, repositories.ErrPromoUnavailable
+		}
+		promo.UsedCount++
+		r.store.promoCodes[promo.ID] = promo
+	}
+
+	for _, item := range items {
+		p := r.store.products[item.ProductID]
+		p.Stock -= item.Quantity - reserved[item.ProductID]
+		r.store.products[item.ProductID] = p
+	}
+
+	trx.ID = r.store.nextTransactionID
+	trx.Status = models.TransactionCompleted
+	trx.CreatedAt = r.store.Now()
+	r.store.nextTransactionID++
+	stored := *trx
+	stored.Details = cloneDetails(trx.Details, false)
+	for i := range stored.Details {
+		stored.Details[i].ID = r.store.nextDetailID
+		stored.Details[i].TransactionID = trx.ID
+		trx.Details[i].TransactionID = trx.ID
+		r.store.nextDetailID++
+	}
+	for i := range trx.Payments {
+		trx.Payments[i].ID = r.store.nextPaymentID
+		trx.Payments[i].TransactionID = trx.ID
+		r.store.nextPaymentID++
+	}
+	stored.Payments = append([]models.Payment(nil), trx.Payments...)
+	for i := range trx.Promotions {
+		trx.Promotions[i].ID = r.store.nextAppliedPromoID
+		trx.Promotions[i].TransactionID = trx.ID
+		r.store.nextAppliedPromoID++
+	}
+	stored.Promotions = append([]models.AppliedPromotion(nil), trx.Promotions...)
+	if err := r.store.audit(repositories.AuditActor(trx.CashierID), models.AuditTransaction, trx.ID, models.AuditCreate, nil, trx); err != nil {
+		return nil, err
+	}
+	r.store.transactions = append(r.store.transactions, stored)
+	trxID := trx.ID
+	if cartID != 0 {
+		ids, _ := repositories.ReservationDelta(nil, reserved)
+		for _, id := range ids {
+			r.store.recordMovement(repositories.NewStockMovement(id, models.StockReservation, reserved[id], &cartID, repositories.AuditActor(trx.CashierID), "cart checkout"))
+		}
+		cart := r.store.carts[cartID]
+		cart.Status = models.CartCheckedOut
+		cart.Reserved, cart.ReservedUntil = false, nil
+		cart.TransactionID = &trxID
+		r.store.carts[cartID] = cart
+	}
+	for _, item := range items {
+		r.store.recordMovement(repositories.NewStockMovement(item.ProductID, models.StockSale, -item.Quantity, &trxID, repositories.AuditActor(trx.CashierID), ""))
+	}
+
+	// Response checkout versi SQL tidak mengisi created_at dan detail ID
+	return trx, nil
+}
+
+func (r *transactionRepository) QuoteTransaction(items []models.CheckoutItem, price repositories.PriceFunc) (*models.Transaction, error) {
+	if price == nil {
+		price = repositories.DefaultPricing
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	products, err := r.store.checkoutProducts(items)
+	if err != nil {
+		return nil, err
+	}
+	return price(items, products)
+}
+
+// checkoutProducts mengambil snapshot produk untuk items, caller sudah memegang lock
+func (s *Store) checkoutProducts(items []models.CheckoutItem) (map[int]models.Product, error) {
+	products := map[int]models.Product{}
+	for _, item := range items {
+		p, ok := s.products[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("product id %d: %w", item.ProductID, repositories.ErrNotFound)
+		}
+		products[p.ID] = p
+	}
+	return products, nil
+}
+
+func cloneDetails(details []models.TransactionDetail, keepIDs bool) []models.TransactionDetail {
+	out := make([]models.TransactionDetail, len(details))
+	copy(out, details)
+	if !keepIDs {
+		for i := range out {
+			out[i].ID = 0
+		}
+	}
+	return out
+}
+
+func (r *transactionRepository) salesWhere(match func(date string) bool) (sum models.SalesSummary) {
+	for _, t := range r.store.transactions {
+		if t.Status != models.TransactionVoided && match(t.CreatedAt.Local().Format("2006-01-02")) {
+			sum.TotalRevenue += t.TotalAmount - t.RefundedAmount
+			sum.TotalTransaksi++
+			sum.GrossRevenue += t.GrossAmount
+			sum.TotalDiscount += t.DiscountAmount
+			sum.TotalRefund += t.RefundedAmount
+			sum.Subtotal += t.Subtotal
+			sum.TotalServiceCharge += t.ServiceCharge
+			sum.TotalTax += t.TaxAmount - t.RefundedTax
+		}
+	}
+	return
+}
+
+func (r *transactionRepository) topProductWhere(match func(date string) bool) (productName string, qtySold int) {
+	qty := map[int]int{}
+	for _, t := range r.store.transactions {
+		if t.Status == models.TransactionVoided || !match(t.CreatedAt.Local().Format("2006-01-02")) {
+			continue
+		}
+		for _, d := range t.Details {
+			qty[d.ProductID] += d.Quantity - d.RefundedQuantity
+		}
+	}
+
+	bestID := 0
+	for id, q := range qty {
+		// JOIN products: produk yang sudah dihapus tidak ikut dihitung
+		if _, ok := r.store.products[id]; !ok {
+			continue
+		}
+		if q > qtySold || (q == qtySold && id < bestID) {
+			bestID, qtySold = id, q
+		}
+	}
+	if bestID == 0 {
+		return "", 0
+	}
+	return r.store.products[bestID].Name, qtySold
+}
+
+func (r *transactionRepository) paymentsWhere(match func(date string) bool) map[string]int {
+	byMethod := map[string]int{}
+	for _, t := range r.store.transactions {
+		if t.Status == models.TransactionVoided || !match(t.CreatedAt.Local().Format("2006-01-02")) {
+			continue
+		}
+		for _, pay := range t.Payments {
+			byMethod[pay.Method] += pay.Amount
+		}
+	}
+	return byMethod
+}
+
+// profitWhere mengikuti query SQL: baris transaksi yang tidak di-void dikelompokkan
+// per groupBy, refund dikurangi dari net sales tanpa bagian pajaknya
+func (r *transactionRepository) profitWhere(groupBy string, match func(date string) bool) []models.ProfitLine {
+	type key struct {
+		id     int
+		period string
+	}
+	sums := map[key]*models.ProfitLine{}
+	var order []key
+	for _, t := range r.store.transactions {
+		date := t.CreatedAt.Local().Format("2006-01-02")
+		if t.Status == models.TransactionVoided || !match(date) {
+			continue
+		}
+		refunded := map[int]int{}
+		for _, rf := range t.Refunds {
+			for _, it := range rf.Items {
+				refunded[it.TransactionDetailID] += it.Amount - it.TaxAmount
+			}
+		}
+		for _, d := range t.Details {
+			var k key
+			var name string
+			switch groupBy {
+			case models.ProfitGroupProduct:
+				k.id, name = d.ProductID, r.store.products[d.ProductID].Name
+			case models.ProfitGroupCategory:
+				catID := r.store.products[d.ProductID].CategoryID
+				if _, ok := r.store.categories[catID]; ok {
+					k.id = catID
+				}
+				name = r.store.categoryName(catID)
+			case models.ProfitGroupDay:
+				k.period = date
+			case models.ProfitGroupMonth:
+				k.period = date[:7]
+			}
+			l, ok := sums[k]
+			if !ok {
+				l = &models.ProfitLine{ID: k.id, Name: name, Period: k.period}
+				sums[k] = l
+				order = append(order, k)
+			}
+			qty := d.Quantity - d.RefundedQuantity
+			l.Quantity += qty
+			l.NetSales += d.Total - d.TaxAmount - refunded[d.ID]
+			l.COGS += d.UnitCost * qty
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].id != order[j].id {
+			return order[i].id < order[j].id
+		}
+		return order[i].period < order[j].period
+	})
+	lines := make([]models.ProfitLine, 0, len(order))
+	for _, k := range order {
+		lines = append(lines, *sums[k])
+	}
+	// Tanpa GROUP BY, SQL selalu mengembalikan satu baris total
+	if groupBy == "" && len(lines) == 0 {
+		lines = append(lines, models.ProfitLine{})
+	}
+	return lines
+}
+
+func (r *transactionRepository) today() func(date string) bool {
+	today := r.store.Now().Local().Format("2006-01-02")
+	return func(date string) bool { return date == today }
+}
+
+func between(startDate, endDate string) func(date string) bool {
+	return func(date string) bool { return date >= startDate && date <= endDate }
+}
+
+func (r *transactionRepository) GetSalesToday() (models.SalesSummary, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.salesWhere(r.today()), nil
+}
+
+func (r *transactionRepository) GetTopProductToday() (productName string, qtySold int, err error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	productName, qtySold = r.topProductWhere(r.today())
+	return
+}
+
+func (r *transactionRepository) GetSalesInRange(startDate, endDate string) (models.SalesSummary, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.salesWhere(between(startDate, endDate)), nil
+}
+
+func (r *transactionRepository) GetTopProductInRange(startDate, endDate string) (productName string, qtySold int, err error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	productName, qtySold = r.topProductWhere(between(startDate, endDate))
+	return
+}
+
+func (r *transactionRepository) GetPaymentsToday() (map[string]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.paymentsWhere(r.today()), nil
+}
+
+func (r *transactionRepository) GetPaymentsInRange(startDate, endDate string) (map[string]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.paymentsWhere(between(startDate, endDate)), nil
+}
+
+func (r *transactionRepository) GetProfitToday(groupBy string) ([]models.ProfitLine, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.profitWhere(groupBy, r.today()), nil
+}
+
+func (r *transactionRepository) GetProfitInRange(startDate, endDate, groupBy string) ([]models.ProfitLine, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.profitWhere(groupBy, between(startDate, endDate)), nil
+}
+
+func (r *transactionRepository) GetAll(filter models.TransactionFilter) ([]models.Transaction, int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	matched := make([]models.Transaction, 0)
+	for _, t := range r.store.transactions {
+		date := t.CreatedAt.Local().Format("2006-01-02")
+		if filter.StartDate != "" && date < filter.StartDate {
+			continue
+		}
+		if filter.EndDate != "" && date > filter.EndDate {
+			continue
+		}
+		if filter.MinTotal != nil && t.TotalAmount < *filter.MinTotal {
+			continue
+		}
+		if filter.MaxTotal != nil && t.TotalAmount > *filter.MaxTotal {
+			continue
+		}
+		if filter.CashierID != 0 && (t.CashierID == nil || *t.CashierID != filter.CashierID) {
+			continue
+		}
+		if filter.ShiftID != 0 && (t.ShiftID == nil || *t.ShiftID != filter.ShiftID) {
+			continue
+		}
+		matched = append(matched, models.Transaction{
+			ID:             t.ID,
+			TotalAmount:    t.TotalAmount,
+			Status:         t.Status,
+			RefundedAmount: t.RefundedAmount,
+			RefundedTax:    t.RefundedTax,
+			GrossAmount:    t.GrossAmount,
+			DiscountAmount: t.DiscountAmount,
+			PromoCode:      t.PromoCode,
+			Subtotal:       t.Subtotal,
+			ServiceCharge:  t.ServiceCharge,
+			TaxAmount:      t.TaxAmount,
+			TaxInclusive:   t.TaxInclusive,
+			PaidAmount:     t.PaidAmount,
+			ChangeAmount:   t.ChangeAmount,
+			CashierID:      t.CashierID,
+			ShiftID:        t.ShiftID,
+			CreatedAt:      t.CreatedAt,
+		})
+	}
+
+	// ORDER BY created_at DESC, id DESC
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	total := len(matched)
+	start := (filter.Page - 1) * filter.Limit
+	if start > total {
+		start = total
+	}
+	end := start + filter.Limit
+	if end > total {
+		end = total
+	}
+	return matched[start:end], total, nil
+}
+
+func (r *transactionRepository) GetByID(id int) (*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t := r.find(id)
+	if t == nil {
+		return nil, repositories.ErrNotFound
+	}
+	details := cloneDetails(t.Details, true)
+	for i := range details {
+		details[i].ProductName = r.store.products[details[i].ProductID].Name
+	}
+	var refunds []models.Refund
+	for _, rf := range t.Refunds {
+		rf.Items = append([]models.RefundItem(nil), rf.Items...)
+		refunds = append(refunds, rf)
+	}
+	return &models.Transaction{
+		ID:             t.ID,
+		TotalAmount:    t.TotalAmount,
+		Status:         t.Status,
+		RefundedAmount: t.RefundedAmount,
+		RefundedTax:    t.RefundedTax,
+		GrossAmount:    t.GrossAmount,
+		DiscountAmount: t.DiscountAmount,
+		PromoCode:      t.PromoCode,
+		Subtotal:       t.Subtotal,
+		ServiceCharge:  t.ServiceCharge,
+		TaxAmount:      t.TaxAmount,
+		TaxInclusive:   t.TaxInclusive,
+		PaidAmount:     t.PaidAmount,
+		ChangeAmount:   t.ChangeAmount,
+		CashierID:      t.CashierID,
+		ShiftID:        t.ShiftID,
+		CreatedAt:      t.CreatedAt,
+		Details:        details,
+		Payments:       append([]models.Payment(nil), t.Payments...),
+		Promotions:     append([]models.AppliedPromotion(nil), t.Promotions...),
+		Refunds:        refunds,
+	}, nil
+}
+
+func (r *transactionRepository) find(id int) *models.Transaction {
+	for i := range r.store.transactions {
+		if r.store.transactions[i].ID == id {
+			return &r.store.transactions[i]
+		}
+	}
+	return nil
+}
+
+func (r *transactionRepository) CreateRefund(transactionID int, refundType, reason string, lines []models.RefundLine, shift *models.Shift) (*models.Refund, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	t := r.find(transactionID)
+	if t == nil {
+		return nil, repositories.ErrNotFound
+	}
+	if t.Status == models.TransactionVoided {
+		return nil, repositories.ErrTransactionVoided
+	}
+
+	items, newStatus, err := repositories.PlanRefund(t.Details, refundType, lines)
+	if err != nil {
+		return nil, err
+	}
+	var cashierID, shiftID *int
+	if shift != nil {
+		if err := r.store.checkOpenShift(shift.ID); err != nil {
+			return nil, err
+		}
+		cashierID, shiftID = &shift.UserID, &shift.ID
+	}
+	before := repositories.TransactionAudit{Status: t.Status, RefundedAmount: t.RefundedAmount, RefundedTax: t.RefundedTax}
+
+	refund := models.Refund{
+		ID:            r.store.nextRefundID,
+		TransactionID: transactionID,
+		Type:          refundType,
+		Reason:        reason,
+		CashierID:     cashierID,
+		ShiftID:       shiftID,
+		CreatedAt:     r.store.Now(),
+	}
+	r.store.nextRefundID++
+
+	refundedTax := 0
+	for i := range items {
+		it := &items[i]
+		it.ID = r.store.nextRefundItemID
+		it.RefundID = refund.ID
+		r.store.nextRefundItemID++
+		refund.Amount += it.Amount
+		refundedTax += it.TaxAmount
+
+		for j := range t.Details {
+			if t.Details[j].ID == it.TransactionDetailID {
+				t.Details[j].RefundedQuantity += it.Quantity
+			}
+		}
+		p := r.store.products[it.ProductID]
+		p.Stock += it.Quantity
+		r.store.products[it.ProductID] = p
+	}
+	refund.Items = items
+	paid, cashPaid := 0, 0
+	for _, pay := range t.Payments {
+		paid += pay.Amount
+		if pay.Method == models.PaymentCash {
+			cashPaid += pay.Amount
+		}
+	}
+	refund.CashAmount = repositories.RefundCash(cashPaid, paid, t.RefundedAmount, refund.Amount)
+
+	after := repositories.TransactionAudit{
+		Status:         newStatus,
+		RefundedAmount: before.RefundedAmount + refund.Amount,
+		RefundedTax:    before.RefundedTax + refundedTax,
+		Refund:         &refund,
+	}
+	if err := r.store.audit(repositories.AuditActor(cashierID), models.AuditTransaction, transactionID, models.AuditUpdate, before, after); err != nil {
+		return nil, err
+	}
+	t.Status = newStatus
+	t.RefundedAmount += refund.Amount
+	t.RefundedTax += refundedTax
+	t.Refunds = append(t.Refunds, refund)
+	// Transaksi yang di-void tidak lagi memakai kuota kode promonya
+	if newStatus == models.TransactionVoided && t.PromoCode != "" {
+		if promo, ok := r.store.promoByCode(t.PromoCode); ok && promo.UsedCount > 0 {
+			promo.UsedCount--
+			r.store.promoCodes[promo.ID] = promo
+		}
+	}
+	refundID := refund.ID
+	for _, it := range items {
+		r.store.recordMovement(repositories.NewStockMovement(it.ProductID, models.StockRefund, it.Quantity, &refundID, repositories.AuditActor(cashierID), refundType))
+	}
+
+	out := refund
+	out.Items = append([]models.RefundItem(nil), items...)
+	return &out, nil
+}
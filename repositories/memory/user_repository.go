@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"sort"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repositories.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (s *Store) userByUsername(username string) (models.User, bool) {
+	for _, u := range s.users {
+		if u.Username == username {
+			return u, true
+		}
+	}
+	return models.User{}, false
+}
+
+func (r *userRepository) GetAll() ([]models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var users []models.User
+	for _, u := range r.store.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *userRepository) GetByID(id int) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (r *userRepository) GetByUsername(username string) (*models.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	u, ok := r.store.userByUsername(username)
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (r *userRepository) Count() (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return len(r.store.users), nil
+}
+
+func (r *userRepository) Create(u *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// UNIQUE constraint pada users.username
+	if _, taken := r.store.userByUsername(u.Username); taken {
+		return repositories.ErrDuplicate
+	}
+	u.ID = r.store.nextUserID
+	u.CreatedAt = r.store.Now()
+	r.store.nextUserID++
+	stored := *u
+	stored.Password = ""
+	r.store.users[u.ID] = stored
+	return nil
+}
+
+func (r *userRepository) Update(u *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	old, ok := r.store.users[u.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if other, taken := r.store.userByUsername(u.Username); taken && other.ID != u.ID {
+		return repositories.ErrDuplicate
+	}
+	if !u.Active {
+		if err := r.store.checkNotLastOwner(u.ID); err != nil {
+			return err
+		}
+	}
+	if u.PasswordHash == "" {
+		u.PasswordHash = old.PasswordHash
+	}
+	u.CreatedAt = old.CreatedAt
+	stored := *u
+	stored.Password = ""
+	r.store.users[u.ID] = stored
+	return nil
+}
+
+func (r *userRepository) Delete(id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	if err := r.store.checkNotLastOwner(id); err != nil {
+		return err
+	}
+	// shifts.user_id tanpa ON DELETE: user yang pernah membuka shift tidak bisa dihapus
+	for _, sh := range r.store.shifts {
+		if sh.UserID == id {
+			return repositories.ErrForeignKey
+		}
+	}
+	delete(r.store.users, id)
+	// ON DELETE CASCADE ke sessions dan user_roles
+	r.store.deleteSessions(id)
+	delete(r.store.userRoles, id)
+	return nil
+}
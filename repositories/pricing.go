@@ -0,0 +1,58 @@
+package repositories
+
+import "kasir-api-golang-v1/models"
+
+// PriceFunc menghitung isi transaksi (details, total, pembayaran) dari item
+// checkout dan snapshot produk yang sudah di-lock di dalam DB transaction.
+// Logic harga ada di layer service; repository hanya menyimpan hasilnya.
+type PriceFunc func(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error)
+
+// DefaultPricing adalah harga dasar: subtotal = price * quantity, tanpa diskon, pajak dan pembayaran.
+// Dipakai kalau CreateTransaction dipanggil dengan PriceFunc nil.
+func DefaultPricing(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+	trx := &models.Transaction{Details: make([]models.TransactionDetail, 0, len(items))}
+	for _, item := range items {
+		p := products[item.ProductID]
+		subtotal := p.Price * item.Quantity
+		trx.GrossAmount += subtotal
+		trx.Subtotal += subtotal
+		trx.TotalAmount += subtotal
+		trx.Details = append(trx.Details, models.TransactionDetail{
+			ProductID:   item.ProductID,
+			ProductName: p.Name,
+			Quantity:    item.Quantity,
+			Subtotal:    subtotal,
+			Total:       subtotal,
+		})
+	}
+	return trx, nil
+}
+
+// CheckStock memastikan stock cukup untuk semua item (qty produk yang sama dijumlahkan)
+func CheckStock(items []models.CheckoutItem, products map[int]models.Product) error {
+	if shortages := StockShortages(items, products); len(shortages) > 0 {
+		return &shortages[0]
+	}
+	return nil
+}
+
+// StockShortages mengembalikan semua produk yang stock-nya tidak cukup, urut
+// sesuai kemunculan pertama di items. Requested adalah total qty produk tersebut.
+func StockShortages(items []models.CheckoutItem, products map[int]models.Product) []InsufficientStockError {
+	requested := map[int]int{}
+	var order []int
+	for _, item := range items {
+		if _, seen := requested[item.ProductID]; !seen {
+			order = append(order, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	var shortages []InsufficientStockError
+	for _, id := range order {
+		if p := products[id]; p.Stock < requested[id] {
+			shortages = append(shortages, InsufficientStockError{ProductID: p.ID, ProductName: p.Name, Available: p.Stock, Requested: requested[id]})
+		}
+	}
+	return shortages
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import "kasir-api-golang-v1/models"
+
+// ApplyProductUpdate menerapkan body PUT produk ke data yang tersimpan. Field
+// opsional yang tidak dikirim tetap memakai nilai lama.
+func ApplyProductUpdate(p models.Product, req models.ProductUpdateRequest) models.Product {
+	p.Name = req.Name
+	p.Price = req.Price
+	if req.CostPrice != nil {
+		p.CostPrice = *req.CostPrice
+	}
+	p.Stock = req.Stock
+	if req.ReorderPoint != nil {
+		p.ReorderPoint = *req.ReorderPoint
+	}
+	if req.ReorderQuantity != nil {
+		p.ReorderQuantity = *req.ReorderQuantity
+	}
+	p.CategoryID = req.CategoryID
+	return p
+}
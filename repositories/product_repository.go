@@ -1,32 +1,43 @@
 package repositories
 
 import (
-	"database/sql"
-	"errors"
+	"kasir-api-golang-v1/database"
 	"kasir-api-golang-v1/models"
 )
 
-type ProductRepository struct {
-	db *sql.DB
+// sqlProductRepository adalah implementasi ProductRepository berbasis database/sql (MySQL atau SQLite)
+type sqlProductRepository struct {
+	db *database.DB
 }
 
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+func NewProductRepository(db *database.DB) ProductRepository {
+	return &sqlProductRepository{db: db}
 }
 
-// GetAll dengan JOIN dan Search by Name
-func (r *ProductRepository) GetAll(nameFilter string) ([]models.Product, error) {
-	query := `
-		SELECT p.id, p.name, p.price, p.stock, p.category_id, IFNULL(c.name, 'No Category') 
+// productColumns dibaca bersama productDest, tanpa category_name
+const productColumns = "p.id, p.name, p.price, p.cost_price, p.stock, p.reorder_point, p.reorder_quantity, p.category_id"
+
+// productSelect membaca produk beserta nama kategorinya
+const productSelect = `
+		SELECT ` + productColumns + `, IFNULL(c.name, 'No Category')
 		FROM products p
 		LEFT JOIN categories c ON p.category_id = c.id`
-	
+
+// productDest adalah tujuan Scan untuk productColumns
+func productDest(p *models.Product) []interface{} {
+	return []interface{}{&p.ID, &p.Name, &p.Price, &p.CostPrice, &p.Stock, &p.ReorderPoint, &p.ReorderQuantity, &p.CategoryID}
+}
+
+// GetAll dengan JOIN dan Search by Name
+func (r *sqlProductRepository) GetAll(nameFilter string) ([]models.Product, error) {
+	query := productSelect
+
 	args := []interface{}{}
 	if nameFilter != "" {
 		query += " WHERE p.name LIKE ?"
 		args = append(args, "%"+nameFilter+"%")
 	}
-	
+
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
@@ -36,7 +47,7 @@ func (r *ProductRepository) GetAll(nameFilter string) ([]models.Product, error)
 	var products []models.Product
 	for rows.Next() {
 		var p models.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName); err != nil {
+		if err := rows.Scan(append(productDest(&p), &p.CategoryName)...); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
@@ -45,63 +56,197 @@ func (r *ProductRepository) GetAll(nameFilter string) ([]models.Product, error)
 }
 
 // GetByID dengan JOIN juga
-func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
-	query := `
-		SELECT p.id, p.name, p.price, p.stock, p.category_id, IFNULL(c.name, 'No Category') 
-		FROM products p
-		LEFT JOIN categories c ON p.category_id = c.id
-		WHERE p.id = ?`
+func (r *sqlProductRepository) GetByID(id int) (*models.Product, error) {
+	var p models.Product
+	err := r.db.QueryRow(productSelect+" WHERE p.id = ?", id).Scan(append(productDest(&p), &p.CategoryName)...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &p, nil
+}
 
+// lockProduct membaca produk (tanpa category_name) dan menguncinya untuk
+// snapshot "before" di audit log
+func lockProduct(tx rowQuerier, dialect database.Dialect, id int) (*models.Product, error) {
 	var p models.Product
-	err := r.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.CategoryID, &p.CategoryName)
+	err := tx.QueryRow("SELECT "+productColumns+" FROM products p WHERE p.id = ?"+dialect.ForUpdate(), id).Scan(productDest(&p)...)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &p, nil
 }
 
-func (r *ProductRepository) Create(p *models.Product) error {
+// auditProduct adalah snapshot produk untuk audit log, tanpa category_name hasil JOIN
+func auditProduct(p models.Product) models.Product {
+	p.CategoryName = ""
+	return p
+}
+
+func (r *sqlProductRepository) Create(p *models.Product, actorID int) error {
 	// Logic default category ID 1 jika kosong
 	if p.CategoryID == 0 {
 		p.CategoryID = 1
 	}
-	result, err := r.db.Exec("INSERT INTO products (name, price, stock, category_id) VALUES (?, ?, ?, ?)", 
-		p.Name, p.Price, p.Stock, p.CategoryID)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO products (name, price, cost_price, stock, reorder_point, reorder_quantity, category_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		p.Name, p.Price, p.CostPrice, p.Stock, p.ReorderPoint, p.ReorderQuantity, p.CategoryID)
+	if err != nil {
+		return translateError(err)
+	}
 	id, _ := result.LastInsertId()
 	p.ID = int(id)
-	return nil
+	if err := writeAudit(tx, actorID, models.AuditProduct, p.ID, models.AuditCreate, nil, auditProduct(*p)); err != nil {
+		return err
+	}
+	if p.Stock != 0 {
+		if err := writeStockMovement(tx, NewStockMovement(p.ID, models.StockAdjustment, p.Stock, nil, actorID, "initial stock")); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-func (r *ProductRepository) Update(p *models.Product) error {
-	query := "UPDATE products SET name = ?, price = ?, stock = ?, category_id = ? WHERE id = ?"
-	result, err := r.db.Exec(query, p.Name, p.Price, p.Stock, p.CategoryID, p.ID)
+func (r *sqlProductRepository) Update(id int, req models.ProductUpdateRequest, actorID int) (*models.Product, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockProduct(tx, r.db.Dialect, id)
+	if err != nil {
+		return nil, err
+	}
+	p := ApplyProductUpdate(*before, req)
+	query := "UPDATE products SET name = ?, price = ?, cost_price = ?, stock = ?, reorder_point = ?, reorder_quantity = ?, category_id = ? WHERE id = ?"
+	if _, err := tx.Exec(query, p.Name, p.Price, p.CostPrice, p.Stock, p.ReorderPoint, p.ReorderQuantity, p.CategoryID, id); err != nil {
+		return nil, translateError(err)
+	}
+	if err := writeAudit(tx, actorID, models.AuditProduct, id, models.AuditUpdate, before, p); err != nil {
+		return nil, err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return errors.New("product not found")
+	// Stock yang ditimpa lewat update dicatat sebagai adjustment sebesar selisihnya
+	if delta := p.Stock - before.Stock; delta != 0 {
+		if err := writeStockMovement(tx, NewStockMovement(id, models.StockAdjustment, delta, nil, actorID, "product update")); err != nil {
+			return nil, err
+		}
 	}
-	return nil
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
 }
 
-func (r *ProductRepository) Delete(id int) error {
-	result, err := r.db.Exec("DELETE FROM products WHERE id = ?", id)
+func (r *sqlProductRepository) Delete(id, actorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockProduct(tx, r.db.Dialect, id)
 	if err != nil {
 		return err
 	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return errors.New("product not found")
+	if _, err := tx.Exec("DELETE FROM products WHERE id = ?", id); err != nil {
+		return translateError(err)
 	}
-	return nil
+	if err := writeAudit(tx, actorID, models.AuditProduct, id, models.AuditDelete, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // BulkUpdateCategory untuk Safe Delete logic
-func (r *ProductRepository) BulkUpdateCategory(oldCatID, newCatID int) error {
-	_, err := r.db.Exec("UPDATE products SET category_id = ? WHERE category_id = ?", newCatID, oldCatID)
-	return err
-}
\ No newline at end of file
+func (r *sqlProductRepository) BulkUpdateCategory(oldCatID, newCatID, actorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT "+productColumns+" FROM products p WHERE p.category_id = ? ORDER BY p.id"+r.db.Dialect.ForUpdate(), oldCatID)
+	if err != nil {
+		return err
+	}
+	var moved []models.Product
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(productDest(&p)...); err != nil {
+			rows.Close()
+			return err
+		}
+		moved = append(moved, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE products SET category_id = ? WHERE category_id = ?", newCatID, oldCatID); err != nil {
+		return translateError(err)
+	}
+	for _, before := range moved {
+		after := before
+		after.CategoryID = newCatID
+		if err := writeAudit(tx, actorID, models.AuditProduct, before.ID, models.AuditUpdate, before, after); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *sqlProductRepository) AdjustStock(id, quantity, actorID int, note string) (*models.Product, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	p, err := lockProduct(tx, r.db.Dialect, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Stock+quantity < 0 {
+		return nil, &InsufficientStockError{ProductID: p.ID, ProductName: p.Name, Available: p.Stock, Requested: -quantity}
+	}
+	if _, err := tx.Exec("UPDATE products SET stock = stock + ? WHERE id = ?", quantity, id); err != nil {
+		return nil, err
+	}
+	if err := writeStockMovement(tx, NewStockMovement(id, models.StockAdjustment, quantity, nil, actorID, note)); err != nil {
+		return nil, err
+	}
+	after := *p
+	after.Stock += quantity
+	if err := writeAudit(tx, actorID, models.AuditProduct, id, models.AuditUpdate, auditProduct(*p), auditProduct(after)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// GetLowStock mengembalikan produk yang stock-nya sudah di atau di bawah reorder point
+func (r *sqlProductRepository) GetLowStock() ([]models.Product, error) {
+	rows, err := r.db.Query(productSelect + " WHERE p.reorder_point > 0 AND p.stock <= p.reorder_point ORDER BY p.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(append(productDest(&p), &p.CategoryName)...); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlPromoCodeRepository adalah implementasi PromoCodeRepository berbasis database/sql (MySQL atau SQLite)
+type sqlPromoCodeRepository struct {
+	db *database.DB
+}
+
+func NewPromoCodeRepository(db *database.DB) PromoCodeRepository {
+	return &sqlPromoCodeRepository{db: db}
+}
+
+const promoCodeColumns = "id, code, type, value, min_spend, usage_limit, used_count, valid_from, valid_until, active"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromoCode(row rowScanner) (*models.PromoCode, error) {
+	var p models.PromoCode
+	var validFrom, validUntil sql.NullTime
+	if err := row.Scan(&p.ID, &p.Code, &p.Type, &p.Value, &p.MinSpend, &p.UsageLimit, &p.UsedCount, &validFrom, &validUntil, &p.Active); err != nil {
+		return nil, err
+	}
+	if validFrom.Valid {
+		p.ValidFrom = &validFrom.Time
+	}
+	if validUntil.Valid {
+		p.ValidUntil = &validUntil.Time
+	}
+	return &p, nil
+}
+
+// nullTime mengubah *time.Time menjadi nilai yang bisa disimpan ke kolom DATETIME NULL
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func (r *sqlPromoCodeRepository) GetAll() ([]models.PromoCode, error) {
+	rows, err := r.db.Query("SELECT " + promoCodeColumns + " FROM promo_codes ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promos []models.PromoCode
+	for rows.Next() {
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		promos = append(promos, *p)
+	}
+	return promos, rows.Err()
+}
+
+func (r *sqlPromoCodeRepository) GetByID(id int) (*models.PromoCode, error) {
+	p, err := scanPromoCode(r.db.QueryRow("SELECT "+promoCodeColumns+" FROM promo_codes WHERE id = ?", id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return p, nil
+}
+
+func (r *sqlPromoCodeRepository) GetByCode(code string) (*models.PromoCode, error) {
+	p, err := scanPromoCode(r.db.QueryRow("SELECT "+promoCodeColumns+" FROM promo_codes WHERE code = ?", code))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return p, nil
+}
+
+func (r *sqlPromoCodeRepository) Create(p *models.PromoCode) error {
+	result, err := r.db.Exec("INSERT INTO promo_codes (code, type, value, min_spend, usage_limit, valid_from, valid_until, active) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		p.Code, p.Type, p.Value, p.MinSpend, p.UsageLimit, nullTime(p.ValidFrom), nullTime(p.ValidUntil), p.Active)
+	if err != nil {
+		return translateError(err)
+	}
+	id, _ := result.LastInsertId()
+	p.ID = int(id)
+	p.UsedCount = 0
+	return nil
+}
+
+// Update tidak mengubah used_count, kuota yang sudah terpakai tetap tercatat
+func (r *sqlPromoCodeRepository) Update(p *models.PromoCode) error {
+	result, err := r.db.Exec("UPDATE promo_codes SET code = ?, type = ?, value = ?, min_spend = ?, usage_limit = ?, valid_from = ?, valid_until = ?, active = ? WHERE id = ?",
+		p.Code, p.Type, p.Value, p.MinSpend, p.UsageLimit, nullTime(p.ValidFrom), nullTime(p.ValidUntil), p.Active, p.ID)
+	if err != nil {
+		return translateError(err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return r.db.QueryRow("SELECT used_count FROM promo_codes WHERE id = ?", p.ID).Scan(&p.UsedCount)
+}
+
+func (r *sqlPromoCodeRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM promo_codes WHERE id = ?", id)
+	if err != nil {
+		return translateError(err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
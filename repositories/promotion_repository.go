@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlPromotionRepository adalah implementasi PromotionRepository berbasis database/sql (MySQL atau SQLite)
+type sqlPromotionRepository struct {
+	db *database.DB
+}
+
+func NewPromotionRepository(db *database.DB) PromotionRepository {
+	return &sqlPromotionRepository{db: db}
+}
+
+const promotionColumns = `id, name, type, buy_qty, free_qty, bundle_price, discount_percent, start_time, end_time,
+	priority, stackable, active, valid_from, valid_until`
+
+func scanPromotion(row rowScanner) (*models.Promotion, error) {
+	var p models.Promotion
+	var validFrom, validUntil sql.NullTime
+	err := row.Scan(&p.ID, &p.Name, &p.Type, &p.BuyQty, &p.FreeQty, &p.BundlePrice, &p.DiscountPercent, &p.StartTime, &p.EndTime,
+		&p.Priority, &p.Stackable, &p.Active, &validFrom, &validUntil)
+	if err != nil {
+		return nil, err
+	}
+	if validFrom.Valid {
+		p.ValidFrom = &validFrom.Time
+	}
+	if validUntil.Valid {
+		p.ValidUntil = &validUntil.Time
+	}
+	p.Items = []models.PromotionItem{}
+	return &p, nil
+}
+
+// loadItems mengisi Items untuk promosi-promosi yang sudah di-load
+func (r *sqlPromotionRepository) loadItems(promos []models.Promotion) error {
+	if len(promos) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(promos))
+	placeholders := make([]string, len(promos))
+	args := make([]interface{}, len(promos))
+	for i, p := range promos {
+		index[p.ID] = i
+		placeholders[i] = "?"
+		args[i] = p.ID
+	}
+
+	rows, err := r.db.Query("SELECT promotion_id, product_id, quantity FROM promotion_items WHERE promotion_id IN ("+
+		strings.Join(placeholders, ", ")+") ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var promotionID int
+		var it models.PromotionItem
+		if err := rows.Scan(&promotionID, &it.ProductID, &it.Quantity); err != nil {
+			return err
+		}
+		p := &promos[index[promotionID]]
+		p.Items = append(p.Items, it)
+	}
+	return rows.Err()
+}
+
+// GetAll mengembalikan promosi urut prioritas (tertinggi dulu); activeOnly untuk checkout
+func (r *sqlPromotionRepository) GetAll(activeOnly bool) ([]models.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions"
+	if activeOnly {
+		query += " WHERE active"
+	}
+	rows, err := r.db.Query(query + " ORDER BY priority DESC, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promos := make([]models.Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promos = append(promos, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return promos, r.loadItems(promos)
+}
+
+func (r *sqlPromotionRepository) GetByID(id int) (*models.Promotion, error) {
+	p, err := scanPromotion(r.db.QueryRow("SELECT "+promotionColumns+" FROM promotions WHERE id = ?", id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	promos := []models.Promotion{*p}
+	if err := r.loadItems(promos); err != nil {
+		return nil, err
+	}
+	return &promos[0], nil
+}
+
+func insertPromotionItems(tx *sql.Tx, promotionID int, items []models.PromotionItem) error {
+	for _, it := range items {
+		if _, err := tx.Exec("INSERT INTO promotion_items (promotion_id, product_id, quantity) VALUES (?, ?, ?)", promotionID, it.ProductID, it.Quantity); err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+func (r *sqlPromotionRepository) Create(p *models.Promotion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO promotions (name, type, buy_qty, free_qty, bundle_price, discount_percent, start_time, end_time,
+		priority, stackable, active, valid_from, valid_until) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.Name, p.Type, p.BuyQty, p.FreeQty, p.BundlePrice, p.DiscountPercent, p.StartTime, p.EndTime,
+		p.Priority, p.Stackable, p.Active, nullTime(p.ValidFrom), nullTime(p.ValidUntil))
+	if err != nil {
+		return translateError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := insertPromotionItems(tx, int(id), p.Items); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	p.ID = int(id)
+	return nil
+}
+
+// Update mengganti seluruh isi promosi termasuk items
+func (r *sqlPromotionRepository) Update(p *models.Promotion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE promotions SET name = ?, type = ?, buy_qty = ?, free_qty = ?, bundle_price = ?, discount_percent = ?,
+		start_time = ?, end_time = ?, priority = ?, stackable = ?, active = ?, valid_from = ?, valid_until = ? WHERE id = ?`,
+		p.Name, p.Type, p.BuyQty, p.FreeQty, p.BundlePrice, p.DiscountPercent, p.StartTime, p.EndTime,
+		p.Priority, p.Stackable, p.Active, nullTime(p.ValidFrom), nullTime(p.ValidUntil), p.ID)
+	if err != nil {
+		return translateError(err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec("DELETE FROM promotion_items WHERE promotion_id = ?", p.ID); err != nil {
+		return err
+	}
+	if err := insertPromotionItems(tx, p.ID, p.Items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqlPromotionRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM promotions WHERE id = ?", id)
+	if err != nil {
+		return translateError(err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
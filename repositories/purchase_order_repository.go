@@ -0,0 +1,334 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlPurchaseOrderRepository adalah implementasi PurchaseOrderRepository berbasis database/sql (MySQL atau SQLite)
+type sqlPurchaseOrderRepository struct {
+	db *database.DB
+}
+
+func NewPurchaseOrderRepository(db *database.DB) PurchaseOrderRepository {
+	return &sqlPurchaseOrderRepository{db: db}
+}
+
+const purchaseOrderColumns = "po.id, po.supplier_id, s.name, po.status, po.note, po.created_by, po.created_at, po.approved_by, po.approved_at, po.closed_by, po.closed_at"
+
+const purchaseOrderSelect = "SELECT " + purchaseOrderColumns + " FROM purchase_orders po JOIN suppliers s ON s.id = po.supplier_id"
+
+// purchaseReceiptNote adalah keterangan movement purchase di ledger
+const purchaseReceiptNote = "purchase order"
+
+func scanPurchaseOrder(row rowScanner) (*models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	var createdBy, approvedBy, closedBy sql.NullInt64
+	var approvedAt, closedAt sql.NullTime
+	if err := row.Scan(&po.ID, &po.SupplierID, &po.SupplierName, &po.Status, &po.Note, &createdBy, &po.CreatedAt,
+		&approvedBy, &approvedAt, &closedBy, &closedAt); err != nil {
+		return nil, err
+	}
+	po.CreatedBy, po.ApprovedBy, po.ClosedBy = nullInt(createdBy), nullInt(approvedBy), nullInt(closedBy)
+	if approvedAt.Valid {
+		po.ApprovedAt = &approvedAt.Time
+	}
+	if closedAt.Valid {
+		po.ClosedAt = &closedAt.Time
+	}
+	po.Items = []models.PurchaseOrderItem{}
+	po.Receipts = []models.PurchaseReceipt{}
+	return &po, nil
+}
+
+// loadPurchaseOrderDetails mengisi Items dan Receipts untuk PO yang sudah di-load
+func loadPurchaseOrderDetails(q querier, orders []models.PurchaseOrder) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(orders))
+	placeholders := make([]string, len(orders))
+	args := make([]interface{}, len(orders))
+	for i, po := range orders {
+		index[po.ID] = i
+		placeholders[i] = "?"
+		args[i] = po.ID
+	}
+	in := "(" + strings.Join(placeholders, ", ") + ")"
+
+	rows, err := q.Query(`SELECT i.purchase_order_id, i.product_id, p.name, i.quantity, i.unit_cost, i.received_quantity
+		FROM purchase_order_items i JOIN products p ON p.id = i.product_id
+		WHERE i.purchase_order_id IN `+in+" ORDER BY i.product_id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var orderID int
+		var it models.PurchaseOrderItem
+		if err := rows.Scan(&orderID, &it.ProductID, &it.ProductName, &it.Quantity, &it.UnitCost, &it.ReceivedQuantity); err != nil {
+			return err
+		}
+		it.RemainingQuantity = it.Quantity - it.ReceivedQuantity
+		po := &orders[index[orderID]]
+		po.Items = append(po.Items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	receipts, err := q.Query(`SELECT id, purchase_order_id, product_id, quantity, unit_cost, received_by, received_at
+		FROM purchase_receipts WHERE purchase_order_id IN `+in+" ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
+	defer receipts.Close()
+	for receipts.Next() {
+		var rc models.PurchaseReceipt
+		var receivedBy sql.NullInt64
+		if err := receipts.Scan(&rc.ID, &rc.PurchaseOrderID, &rc.ProductID, &rc.Quantity, &rc.UnitCost, &receivedBy, &rc.ReceivedAt); err != nil {
+			return err
+		}
+		rc.ReceivedBy = nullInt(receivedBy)
+		po := &orders[index[rc.PurchaseOrderID]]
+		po.Receipts = append(po.Receipts, rc)
+	}
+	return receipts.Err()
+}
+
+func (r *sqlPurchaseOrderRepository) GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	var where []string
+	var args []interface{}
+	if filter.Status != "" {
+		where = append(where, "po.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.SupplierID != 0 {
+		where = append(where, "po.supplier_id = ?")
+		args = append(args, filter.SupplierID)
+	}
+	query := purchaseOrderSelect
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	rows, err := r.db.Query(query+" ORDER BY po.id DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *po)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, loadPurchaseOrderDetails(r.db, orders)
+}
+
+func (r *sqlPurchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	po, err := scanPurchaseOrder(r.db.QueryRow(purchaseOrderSelect+" WHERE po.id = ?", id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	orders := []models.PurchaseOrder{*po}
+	if err := loadPurchaseOrderDetails(r.db, orders); err != nil {
+		return nil, err
+	}
+	return &orders[0], nil
+}
+
+func (r *sqlPurchaseOrderRepository) Create(po *models.PurchaseOrder) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO purchase_orders (supplier_id, status, note, created_by) VALUES (?, ?, ?, ?)",
+		po.SupplierID, models.PurchaseOrderDraft, po.Note, po.CreatedBy)
+	if err != nil {
+		return translateError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for _, it := range po.Items {
+		if _, err := tx.Exec("INSERT INTO purchase_order_items (purchase_order_id, product_id, quantity, unit_cost) VALUES (?, ?, ?, ?)",
+			id, it.ProductID, it.Quantity, it.UnitCost); err != nil {
+			return translateError(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	saved, err := r.GetByID(int(id))
+	if err != nil {
+		return err
+	}
+	*po = *saved
+	return nil
+}
+
+// lockPurchaseOrder mengunci PO dan mengembalikan statusnya
+func (r *sqlPurchaseOrderRepository) lockPurchaseOrder(tx *sql.Tx, id int) (string, error) {
+	var status string
+	if err := tx.QueryRow("SELECT status FROM purchase_orders WHERE id = ?"+r.db.Dialect.ForUpdate(), id).Scan(&status); err != nil {
+		return "", translateError(err)
+	}
+	return status, nil
+}
+
+func (r *sqlPurchaseOrderRepository) Approve(id, actorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := r.lockPurchaseOrder(tx, id)
+	if err != nil {
+		return err
+	}
+	if status != models.PurchaseOrderDraft {
+		return ErrPurchaseOrderState
+	}
+	if _, err := tx.Exec("UPDATE purchase_orders SET status = ?, approved_by = ?, approved_at = ? WHERE id = ?",
+		models.PurchaseOrderApproved, ActorRef(actorID), time.Now().UTC(), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqlPurchaseOrderRepository) Receive(id int, receipts []models.PurchaseReceipt, actorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := r.lockPurchaseOrder(tx, id)
+	if err != nil {
+		return err
+	}
+	if status != models.PurchaseOrderApproved && status != models.PurchaseOrderPartiallyReceived {
+		return ErrPurchaseOrderState
+	}
+
+	// Item tidak perlu di-lock sendiri: semua perubahan item lewat PO yang sudah di-lock
+	rows, err := tx.Query("SELECT product_id, quantity, received_quantity FROM purchase_order_items WHERE purchase_order_id = ?", id)
+	if err != nil {
+		return err
+	}
+	items := map[int]models.PurchaseOrderItem{}
+	for rows.Next() {
+		var it models.PurchaseOrderItem
+		if err := rows.Scan(&it.ProductID, &it.Quantity, &it.ReceivedQuantity); err != nil {
+			rows.Close()
+			return err
+		}
+		items[it.ProductID] = it
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Produk di-update urut product ID seperti checkout supaya tidak deadlock
+	sorted := append([]models.PurchaseReceipt(nil), receipts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+	orderID := id
+	now := time.Now().UTC()
+	for _, rc := range sorted {
+		it, ok := items[rc.ProductID]
+		if !ok {
+			return fmt.Errorf("%w: product %d is not on this purchase order", ErrInvalidReceipt, rc.ProductID)
+		}
+		if remaining := it.Quantity - it.ReceivedQuantity; rc.Quantity > remaining {
+			return fmt.Errorf("%w: product %d has only %d left to receive, got %d", ErrInvalidReceipt, rc.ProductID, remaining, rc.Quantity)
+		}
+		it.ReceivedQuantity += rc.Quantity
+		items[rc.ProductID] = it
+
+		if _, err := tx.Exec("UPDATE purchase_order_items SET received_quantity = ? WHERE purchase_order_id = ? AND product_id = ?",
+			it.ReceivedQuantity, id, rc.ProductID); err != nil {
+			return err
+		}
+		// Harga pokok dihitung ulang rata-rata tertimbang dengan stock yang ada
+		before, err := lockProduct(tx, r.db.Dialect, rc.ProductID)
+		if err != nil {
+			return err
+		}
+		after := *before
+		after.Stock += rc.Quantity
+		after.CostPrice = WeightedAverageCost(before.Stock, before.CostPrice, rc.Quantity, rc.UnitCost)
+		if _, err := tx.Exec("UPDATE products SET stock = stock + ?, cost_price = ? WHERE id = ?", rc.Quantity, after.CostPrice, rc.ProductID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO purchase_receipts (purchase_order_id, product_id, quantity, unit_cost, received_by, received_at) VALUES (?, ?, ?, ?, ?, ?)",
+			id, rc.ProductID, rc.Quantity, rc.UnitCost, ActorRef(actorID), now); err != nil {
+			return translateError(err)
+		}
+		if err := writeStockMovement(tx, NewStockMovement(rc.ProductID, models.StockPurchase, rc.Quantity, &orderID, actorID, purchaseReceiptNote)); err != nil {
+			return err
+		}
+		if err := writeAudit(tx, actorID, models.AuditProduct, rc.ProductID, models.AuditUpdate, *before, after); err != nil {
+			return err
+		}
+	}
+
+	if purchaseOrderComplete(items) {
+		_, err = tx.Exec("UPDATE purchase_orders SET status = ?, closed_by = ?, closed_at = ? WHERE id = ?",
+			models.PurchaseOrderReceived, ActorRef(actorID), now, id)
+	} else {
+		_, err = tx.Exec("UPDATE purchase_orders SET status = ? WHERE id = ?", models.PurchaseOrderPartiallyReceived, id)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// purchaseOrderComplete true kalau semua item sudah diterima penuh
+func purchaseOrderComplete(items map[int]models.PurchaseOrderItem) bool {
+	for _, it := range items {
+		if it.ReceivedQuantity < it.Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *sqlPurchaseOrderRepository) Cancel(id, actorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := r.lockPurchaseOrder(tx, id)
+	if err != nil {
+		return err
+	}
+	if status != models.PurchaseOrderDraft && status != models.PurchaseOrderApproved {
+		return ErrPurchaseOrderState
+	}
+	if _, err := tx.Exec("UPDATE purchase_orders SET status = ?, closed_by = ?, closed_at = ? WHERE id = ?",
+		models.PurchaseOrderCancelled, ActorRef(actorID), time.Now().UTC(), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"fmt"
+
+	"kasir-api-golang-v1/models"
+)
+
+// RefundAmount menghitung nilai uang untuk refund qty unit dari satu baris detail.
+// Dihitung sebagai selisih proporsi kumulatif supaya total semua refund pada
+// baris yang sama selalu tepat sama dengan amount (tidak ada sisa pembulatan).
+// amount adalah total baris, atau bagian pajaknya untuk menghitung pajak yang di-refund.
+func RefundAmount(amount, quantity, refundedBefore, qty int) int {
+	if quantity == 0 {
+		return 0
+	}
+	return amount*(refundedBefore+qty)/quantity - amount*refundedBefore/quantity
+}
+
+// RefundCash menghitung bagian refund yang dibayar tunai, proporsional terhadap
+// pembayaran cash transaksi (cashPaid dari totalPaid). Seperti RefundAmount,
+// refund berturut-turut tidak meninggalkan sisa pembulatan. Transaksi tanpa data
+// pembayaran dianggap dibayar tunai.
+func RefundCash(cashPaid, totalPaid, refundedBefore, amount int) int {
+	if totalPaid == 0 {
+		return amount
+	}
+	return RefundAmount(cashPaid, totalPaid, refundedBefore, amount)
+}
+
+// PlanRefund memvalidasi permintaan refund terhadap detail transaksi dan
+// menghasilkan item refund beserta status transaksi setelah refund.
+// Dipakai oleh implementasi SQL dan in-memory supaya aturannya sama.
+func PlanRefund(details []models.TransactionDetail, refundType string, lines []models.RefundLine) ([]models.RefundItem, string, error) {
+	byID := make(map[int]models.TransactionDetail, len(details))
+	for _, d := range details {
+		byID[d.ID] = d
+	}
+
+	requested := map[int]int{}
+	var order []int
+	if refundType == models.RefundTypeVoid {
+		for _, d := range details {
+			if remaining := d.Quantity - d.RefundedQuantity; remaining > 0 {
+				requested[d.ID] = remaining
+				order = append(order, d.ID)
+			}
+		}
+	} else {
+		for _, l := range lines {
+			if _, ok := byID[l.DetailID]; !ok {
+				return nil, "", fmt.Errorf("%w: detail %d does not belong to this transaction", ErrInvalidRefund, l.DetailID)
+			}
+			if l.Quantity <= 0 {
+				return nil, "", fmt.Errorf("%w: quantity for detail %d must be positive", ErrInvalidRefund, l.DetailID)
+			}
+			if _, seen := requested[l.DetailID]; !seen {
+				order = append(order, l.DetailID)
+			}
+			requested[l.DetailID] += l.Quantity
+		}
+	}
+	if len(order) == 0 {
+		return nil, "", fmt.Errorf("%w: nothing to refund", ErrInvalidRefund)
+	}
+
+	items := make([]models.RefundItem, 0, len(order))
+	for _, id := range order {
+		d, qty := byID[id], requested[id]
+		if remaining := d.Quantity - d.RefundedQuantity; qty > remaining {
+			return nil, "", fmt.Errorf("%w: detail %d has only %d refundable, requested %d", ErrInvalidRefund, id, remaining, qty)
+		}
+		items = append(items, models.RefundItem{
+			TransactionDetailID: id,
+			ProductID:           d.ProductID,
+			Quantity:            qty,
+			Amount:              RefundAmount(d.Total, d.Quantity, d.RefundedQuantity, qty),
+			TaxAmount:           RefundAmount(d.TaxAmount, d.Quantity, d.RefundedQuantity, qty),
+		})
+	}
+
+	// Status baru: voided kalau semua qty sudah dikembalikan
+	status := models.TransactionVoided
+	for _, d := range details {
+		if d.Quantity-d.RefundedQuantity-requested[d.ID] > 0 {
+			status = models.TransactionPartiallyRefunded
+			break
+		}
+	}
+	return items, status, nil
+}
@@ -0,0 +1,248 @@
+package repositories
+
+import (
+	"time"
+
+	"kasir-api-golang-v1/models"
+)
+
+// ProductRepository adalah kontrak penyimpanan produk. Implementasi SQL ada di
+// product_repository.go dan bisa berjalan di atas MySQL maupun SQLite.
+// Setiap perubahan dicatat ke audit log atas nama actorID (0 = sistem);
+// perubahan stock lewat Create/Update juga dicatat ke ledger sebagai adjustment.
+type ProductRepository interface {
+	GetAll(nameFilter string) ([]models.Product, error)
+	GetByID(id int) (*models.Product, error)
+	Create(p *models.Product, actorID int) error
+	// Update menerapkan req ke produk yang di-lock, lihat ApplyProductUpdate
+	Update(id int, req models.ProductUpdateRequest, actorID int) (*models.Product, error)
+	Delete(id, actorID int) error
+	// BulkUpdateCategory mencatat satu audit update per produk yang dipindah
+	BulkUpdateCategory(oldCatID, newCatID, actorID int) error
+	// AdjustStock menambah stock sebesar quantity (boleh negatif) dan mencatatnya
+	// ke ledger; InsufficientStockError kalau stock akan menjadi negatif
+	AdjustStock(id, quantity, actorID int, note string) (*models.Product, error)
+	// GetLowStock mengembalikan produk dengan reorder_point > 0 dan stock <= reorder_point
+	GetLowStock() ([]models.Product, error)
+}
+
+// CategoryRepository adalah kontrak penyimpanan kategori; perubahan dicatat ke audit log
+type CategoryRepository interface {
+	GetAll() ([]models.Category, error)
+	GetByID(id int) (*models.Category, error)
+	GetByName(name string) (*models.Category, error)
+	Create(category *models.Category, actorID int) error
+	Update(category *models.Category, actorID int) error
+	Delete(id, actorID int) error
+}
+
+// TransactionRepository adalah kontrak penyimpanan transaksi dan query report
+type TransactionRepository interface {
+	// CreateTransaction mengunci produk, cek stock, memanggil price (nil = DefaultPricing)
+	// lalu menyimpan transaksi, details dan payments hasil price secara atomik. Kalau hasil
+	// price memakai PromoCode, kuotanya ikut dipakai (ErrPromoUnavailable kalau habis/nonaktif).
+	// Kalau ShiftID diisi, shift-nya dikunci dan harus masih open (ErrShiftClosed).
+	// Audit log create dicatat atas nama CashierID.
+	CreateTransaction(items []models.CheckoutItem, price PriceFunc) (*models.Transaction, error)
+	// CreateCartTransaction seperti CreateTransaction untuk cart yang di-parkir: cart dikunci
+	// dan harus masih open (ErrCartClosed), items harus sama dengan isi cart (ErrCartChanged),
+	// stock yang di-reserve cart dipakai untuk penjualan, dan cart ditutup (checked_out)
+	// dalam DB transaction yang sama.
+	CreateCartTransaction(cartID int, items []models.CheckoutItem, price PriceFunc) (*models.Transaction, error)
+	// QuoteTransaction memanggil price dengan data produk terbaru tanpa lock, tanpa cek
+	// stock dan tanpa menyimpan apa pun. Dipakai untuk preview checkout.
+	QuoteTransaction(items []models.CheckoutItem, price PriceFunc) (*models.Transaction, error)
+	// GetAll mengembalikan transaksi (tanpa details) sesuai filter beserta jumlah total yang match
+	GetAll(filter models.TransactionFilter) ([]models.Transaction, int, error)
+	// GetByID mengembalikan transaksi lengkap dengan details dan nama produk
+	GetByID(id int) (*models.Transaction, error)
+	// CreateRefund mencatat void/refund sebagian dan mengembalikan stock secara atomik.
+	// shift adalah shift kasir yang membayar refund (harus masih open), nil tanpa kasir.
+	// Audit log update dicatat atas nama pemilik shift.
+	CreateRefund(transactionID int, refundType, reason string, lines []models.RefundLine, shift *models.Shift) (*models.Refund, error)
+	GetSalesToday() (models.SalesSummary, error)
+	GetTopProductToday() (productName string, qtySold int, err error)
+	GetSalesInRange(startDate, endDate string) (models.SalesSummary, error)
+	GetTopProductInRange(startDate, endDate string) (productName string, qtySold int, err error)
+	// GetPaymentsToday / GetPaymentsInRange mengembalikan revenue per metode pembayaran
+	GetPaymentsToday() (map[string]int, error)
+	GetPaymentsInRange(startDate, endDate string) (map[string]int, error)
+	// GetProfitToday / GetProfitInRange mengembalikan quantity, net sales dan COGS per
+	// groupBy (models.ProfitGroup*). groupBy kosong berarti satu baris total.
+	GetProfitToday(groupBy string) ([]models.ProfitLine, error)
+	GetProfitInRange(startDate, endDate, groupBy string) ([]models.ProfitLine, error)
+}
+
+// PromoCodeRepository adalah kontrak penyimpanan kode promo. Pemakaian kuota
+// (used_count) dilakukan oleh TransactionRepository.CreateTransaction.
+type PromoCodeRepository interface {
+	GetAll() ([]models.PromoCode, error)
+	GetByID(id int) (*models.PromoCode, error)
+	GetByCode(code string) (*models.PromoCode, error)
+	Create(promo *models.PromoCode) error
+	Update(promo *models.PromoCode) error
+	Delete(id int) error
+}
+
+// PromotionRepository adalah kontrak penyimpanan aturan promosi beserta produknya
+type PromotionRepository interface {
+	// GetAll mengembalikan promosi urut priority tertinggi dulu; activeOnly untuk checkout
+	GetAll(activeOnly bool) ([]models.Promotion, error)
+	GetByID(id int) (*models.Promotion, error)
+	Create(promo *models.Promotion) error
+	Update(promo *models.Promotion) error
+	Delete(id int) error
+}
+
+// CartRepository adalah kontrak penyimpanan cart yang di-parkir. Selama cart
+// di-reserve, stock item-nya sudah dikurangi dari products.stock; stock
+// dikembalikan saat reservasi dilepas, kedaluwarsa, atau cart ditutup.
+type CartRepository interface {
+	// GetAll mengembalikan cart dengan status tertentu, "" untuk semua
+	GetAll(status string) ([]models.Cart, error)
+	GetByID(id int) (*models.Cart, error)
+	// Create menyimpan cart baru; kalau cart.Reserved, stock item langsung di-reserve
+	Create(cart *models.Cart) error
+	// Update mengganti note, items dan reservasi cart yang masih open (ErrCartClosed
+	// kalau tidak). Selisih reservasi lama dan baru dikurangi/dikembalikan ke stock
+	// secara atomik; InsufficientStockError kalau stock tidak cukup.
+	Update(cart *models.Cart) error
+	// Close mengembalikan reservasi lalu mengubah status cart (checked_out / cancelled)
+	Close(id int, status string, transactionID *int) error
+	// ExpireReservations melepas reservasi yang ReservedUntil-nya sebelum now
+	ExpireReservations(now time.Time) (int, error)
+}
+
+// IdempotencyRepository menyimpan hasil request ber-Idempotency-Key
+type IdempotencyRepository interface {
+	// Create mencadangkan key (StatusCode 0); ErrDuplicate kalau key sudah ada
+	Create(rec *models.IdempotencyRecord) error
+	GetByKey(key string) (*models.IdempotencyRecord, error)
+	// Complete menyimpan status dan body response untuk key yang sudah dicadangkan
+	Complete(key string, statusCode int, response []byte) error
+	Delete(key string) error
+	// DeleteExpired menghapus key yang ExpiresAt-nya sebelum now
+	DeleteExpired(now time.Time) (int, error)
+}
+
+// UserRepository adalah kontrak penyimpanan akun user
+type UserRepository interface {
+	GetAll() ([]models.User, error)
+	GetByID(id int) (*models.User, error)
+	GetByUsername(username string) (*models.User, error)
+	// Count dipakai untuk bootstrap admin pertama
+	Count() (int, error)
+	// Create menyimpan user dengan PasswordHash; ErrDuplicate kalau username sudah dipakai
+	Create(user *models.User) error
+	// Update mengganti username, name dan active; PasswordHash ikut diganti kalau tidak kosong.
+	// ErrLastOwner kalau yang dinonaktifkan adalah owner aktif terakhir.
+	Update(user *models.User) error
+	// Delete menghapus user beserta semua session-nya; ErrLastOwner untuk owner aktif terakhir
+	Delete(id int) error
+}
+
+// SessionRepository menyimpan token login (hash-nya saja)
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByTokenHash(tokenHash string) (*models.Session, error)
+	Delete(tokenHash string) error
+	// DeleteByUser mencabut semua token user, misalnya setelah ganti password
+	DeleteByUser(userID int) error
+	// DeleteExpired menghapus session yang ExpiresAt-nya sebelum now
+	DeleteExpired(now time.Time) (int, error)
+}
+
+// RoleRepository menyimpan role, permission per role dan role milik user
+type RoleRepository interface {
+	// GetAll mengembalikan semua role beserta permission-nya, urut ID
+	GetAll() ([]models.Role, error)
+	GetByID(id int) (*models.Role, error)
+	// SetPermissions mengganti seluruh permission role
+	SetPermissions(roleID int, permissions []string) error
+	GetUserRoles(userID int) ([]models.Role, error)
+	// SetUserRoles mengganti seluruh role user; ErrForeignKey kalau user atau role tidak ada,
+	// ErrLastOwner kalau role owner dicabut dari owner aktif terakhir
+	SetUserRoles(userID int, roleIDs []int) error
+	// CountUsersWithRole menghitung user yang punya role tertentu
+	CountUsersWithRole(roleID int) (int, error)
+	// UserPermissions mengembalikan gabungan permission semua role user, tanpa duplikat
+	UserPermissions(userID int) ([]string, error)
+}
+
+// ShiftRepository menyimpan shift kasir. Transaksi dan refund menunjuk ke shift
+// lewat shift_id, lihat TransactionRepository.
+type ShiftRepository interface {
+	GetAll(filter models.ShiftFilter) ([]models.Shift, error)
+	GetByID(id int) (*models.Shift, error)
+	// GetOpenByUser mengembalikan shift open milik user, ErrNotFound kalau tidak ada
+	GetOpenByUser(userID int) (*models.Shift, error)
+	// Open menyimpan shift baru; ErrShiftAlreadyOpen kalau user masih punya shift open
+	Open(shift *models.Shift) error
+	// Summary menjumlahkan transaksi, pembayaran per metode dan refund shift
+	Summary(shiftID int) (models.ShiftSummary, error)
+	// Close mengunci shift, menghitung ExpectedCash dari summary lalu menutupnya
+	// secara atomik, jadi tidak ada checkout yang masuk setelah kas dihitung.
+	// ErrShiftClosed kalau shift sudah ditutup.
+	Close(id, countedCash int, note string) (*models.Shift, models.ShiftSummary, error)
+}
+
+// AuditRepository membaca audit log. Entry ditulis oleh repository produk,
+// kategori dan transaksi di dalam DB transaction perubahannya sendiri.
+type AuditRepository interface {
+	// GetAll mengembalikan entry terbaru dulu beserta jumlah total yang match
+	GetAll(filter models.AuditFilter) ([]models.AuditEntry, int, error)
+}
+
+// StockMovementRepository membaca ledger stock. Movement ditulis oleh setiap
+// repository yang mengubah products.stock (produk, transaksi, cart).
+type StockMovementRepository interface {
+	// GetByProduct mengembalikan movement terbaru dulu beserta jumlah total yang match
+	GetByProduct(filter models.StockMovementFilter) ([]models.StockMovement, int, error)
+	// CheckConsistency menghitung ulang stock semua produk dari ledger dan
+	// mengembalikan jumlah produk yang dicek serta produk yang selisih
+	CheckConsistency() (int, []models.StockDiscrepancy, error)
+}
+
+// StockCountRepository menyimpan sesi stock opname dan hasil hitungannya
+type StockCountRepository interface {
+	GetAll(status string) ([]models.StockCount, error)
+	GetByID(id int) (*models.StockCount, error)
+	// Create memulai sesi baru; ErrStockCountOpen kalau masih ada sesi open
+	Create(count *models.StockCount) error
+	// SubmitItems menyimpan hitungan beserta snapshot products.stock saat itu.
+	// Produk yang sudah pernah dihitung di sesi ini ditimpa. ErrStockCountClosed
+	// kalau sesi sudah ditutup, ErrForeignKey kalau produk tidak ada.
+	SubmitItems(id int, items []models.StockCountItem, actorID int) error
+	// Finalize menerapkan semua variance ke products.stock (dicatat ke ledger
+	// sebagai adjustment) dan menutup sesi dalam satu DB transaction
+	Finalize(id, actorID int) error
+	Cancel(id, actorID int) error
+}
+
+// SupplierRepository adalah kontrak penyimpanan supplier
+type SupplierRepository interface {
+	GetAll() ([]models.Supplier, error)
+	GetByID(id int) (*models.Supplier, error)
+	// Create / Update: ErrDuplicate kalau nama sudah dipakai supplier lain
+	Create(s *models.Supplier) error
+	Update(s *models.Supplier) error
+	// Delete: ErrForeignKey kalau supplier sudah punya purchase order
+	Delete(id int) error
+}
+
+// PurchaseOrderRepository menyimpan purchase order, item dan penerimaan barangnya
+type PurchaseOrderRepository interface {
+	GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error)
+	GetByID(id int) (*models.PurchaseOrder, error)
+	// Create menyimpan PO draft beserta item-nya; ErrForeignKey kalau supplier atau produk tidak ada
+	Create(po *models.PurchaseOrder) error
+	// Approve: ErrPurchaseOrderState kalau PO bukan draft
+	Approve(id, actorID int) error
+	// Receive menambah products.stock sesuai receipts (dicatat ke ledger sebagai
+	// purchase), menyimpan batch beserta harga belinya dan memperbarui status PO
+	// dalam satu DB transaction. ErrPurchaseOrderState kalau PO belum approved atau
+	// sudah selesai, ErrInvalidReceipt kalau produk tidak ada di PO atau melebihi sisa.
+	Receive(id int, receipts []models.PurchaseReceipt, actorID int) error
+	// Cancel: ErrPurchaseOrderState kalau sudah ada barang yang diterima
+	Cancel(id, actorID int) error
+}
@@ -0,0 +1,2117 @@
+package repositories_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+	"kasir-api-golang-v1/repositories/memory"
+)
+
+type repoSet struct {
+	products     repositories.ProductRepository
+	categories   repositories.CategoryRepository
+	transactions repositories.TransactionRepository
+	promoCodes   repositories.PromoCodeRepository
+	promotions   repositories.PromotionRepository
+	carts        repositories.CartRepository
+	idempotency  repositories.IdempotencyRepository
+	users        repositories.UserRepository
+	sessions     repositories.SessionRepository
+	roles        repositories.RoleRepository
+	shifts       repositories.ShiftRepository
+	audit        repositories.AuditRepository
+	stock        repositories.StockMovementRepository
+	stockCounts  repositories.StockCountRepository
+	suppliers    repositories.SupplierRepository
+	purchases    repositories.PurchaseOrderRepository
+}
+
+// openSQLite membuka database SQLite file baru (sudah di-migrate) di temp dir
+func openSQLite(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.InitDB("sqlite", "", "", "", "", filepath.Join(t.TempDir(), "kasir.db"), true)
+	if err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// backends menjalankan test yang sama di atas SQLite dan in-memory,
+// supaya semantik kedua implementasi tetap sama
+func backends(t *testing.T, fn func(t *testing.T, r repoSet)) {
+	t.Run("sqlite", func(t *testing.T) {
+		db := openSQLite(t)
+		fn(t, repoSet{
+			products:     repositories.NewProductRepository(db),
+			categories:   repositories.NewCategoryRepository(db),
+			transactions: repositories.NewTransactionRepository(db),
+			promoCodes:   repositories.NewPromoCodeRepository(db),
+			promotions:   repositories.NewPromotionRepository(db),
+			carts:        repositories.NewCartRepository(db),
+			idempotency:  repositories.NewIdempotencyRepository(db),
+			users:        repositories.NewUserRepository(db),
+			sessions:     repositories.NewSessionRepository(db),
+			roles:        repositories.NewRoleRepository(db),
+			shifts:       repositories.NewShiftRepository(db),
+			audit:        repositories.NewAuditRepository(db),
+			stock:        repositories.NewStockMovementRepository(db),
+			stockCounts:  repositories.NewStockCountRepository(db),
+			suppliers:    repositories.NewSupplierRepository(db),
+			purchases:    repositories.NewPurchaseOrderRepository(db),
+		})
+	})
+	t.Run("memory", func(t *testing.T) {
+		store := memory.NewStore()
+		fn(t, repoSet{
+			products:     memory.NewProductRepository(store),
+			categories:   memory.NewCategoryRepository(store),
+			transactions: memory.NewTransactionRepository(store),
+			promoCodes:   memory.NewPromoCodeRepository(store),
+			promotions:   memory.NewPromotionRepository(store),
+			carts:        memory.NewCartRepository(store),
+			idempotency:  memory.NewIdempotencyRepository(store),
+			users:        memory.NewUserRepository(store),
+			sessions:     memory.NewSessionRepository(store),
+			roles:        memory.NewRoleRepository(store),
+			shifts:       memory.NewShiftRepository(store),
+			audit:        memory.NewAuditRepository(store),
+			stock:        memory.NewStockMovementRepository(store),
+			stockCounts:  memory.NewStockCountRepository(store),
+			suppliers:    memory.NewSupplierRepository(store),
+			purchases:    memory.NewPurchaseOrderRepository(store),
+		})
+	})
+}
+
+// productUpdate membuat body PUT yang mengirim semua field produk
+func productUpdate(p models.Product) models.ProductUpdateRequest {
+	return models.ProductUpdateRequest{
+		Name: p.Name, Price: p.Price, CostPrice: &p.CostPrice, Stock: p.Stock,
+		ReorderPoint: &p.ReorderPoint, ReorderQuantity: &p.ReorderQuantity, CategoryID: p.CategoryID,
+	}
+}
+
+func TestCategoryRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		def, err := r.categories.GetByName("No Category")
+		if err != nil || def.ID != 1 {
+			t.Fatalf("default category = %+v, %v", def, err)
+		}
+
+		c := models.Category{Name: "Minuman"}
+		if err := r.categories.Create(&c, 0); err != nil {
+			t.Fatal(err)
+		}
+		c.Name = "Minuman Dingin"
+		if err := r.categories.Update(&c, 0); err != nil {
+			t.Fatal(err)
+		}
+		got, err := r.categories.GetByID(c.ID)
+		if err != nil || got.Name != "Minuman Dingin" {
+			t.Fatalf("GetByID = %+v, %v", got, err)
+		}
+		if err := r.categories.Update(&models.Category{ID: 999, Name: "x"}, 0); err == nil {
+			t.Error("Update on missing category should fail")
+		}
+		if err := r.categories.Delete(c.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.categories.Delete(c.ID, 0); err == nil {
+			t.Error("second Delete should fail")
+		}
+	})
+}
+
+func TestProductRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		c := models.Category{Name: "Minuman"}
+		if err := r.categories.Create(&c, 0); err != nil {
+			t.Fatal(err)
+		}
+		teh := models.Product{Name: "Teh Manis", Price: 5000, Stock: 10, CategoryID: c.ID}
+		roti := models.Product{Name: "Roti", Price: 8000, Stock: 3}
+		for _, p := range []*models.Product{&teh, &roti} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if roti.CategoryID != 1 {
+			t.Errorf("default category_id = %d, want 1", roti.CategoryID)
+		}
+
+		filtered, err := r.products.GetAll("teh")
+		if err != nil || len(filtered) != 1 || filtered[0].CategoryName != "Minuman" {
+			t.Fatalf("GetAll(teh) = %+v, %v", filtered, err)
+		}
+
+		if err := r.products.BulkUpdateCategory(c.ID, 1, 0); err != nil {
+			t.Fatal(err)
+		}
+		got, err := r.products.GetByID(teh.ID)
+		if err != nil || got.CategoryName != "No Category" {
+			t.Fatalf("after BulkUpdateCategory = %+v, %v", got, err)
+		}
+
+		if _, err := r.products.Update(999, models.ProductUpdateRequest{Name: "x", CategoryID: 1}, 0); err == nil {
+			t.Error("Update on missing product should fail")
+		}
+		if err := r.products.Delete(roti.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.products.GetByID(roti.ID); err == nil {
+			t.Error("GetByID after Delete should fail")
+		}
+	})
+}
+
+func TestTransactionRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 5}
+		kopi := models.Product{Name: "Kopi", Price: 8000, Stock: 1}
+		for _, p := range []*models.Product{&teh, &kopi} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 2}, {ProductID: kopi.ID, Quantity: 1}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trx.TotalAmount != 18000 || len(trx.Details) != 2 || trx.Details[0].TransactionID != trx.ID || trx.CreatedAt.IsZero() {
+			t.Errorf("transaction = %+v", trx)
+		}
+
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}, {ProductID: kopi.ID, Quantity: 1}}, nil); err == nil {
+			t.Error("checkout exceeding stock should fail")
+		}
+		got, _ := r.products.GetByID(teh.ID)
+		if got.Stock != 3 {
+			t.Errorf("teh stock = %d, want 3 (failed checkout must roll back)", got.Stock)
+		}
+
+		sales, err := r.transactions.GetSalesToday()
+		if err != nil || sales.TotalRevenue != 18000 || sales.TotalTransaksi != 1 || sales.GrossRevenue != 18000 {
+			t.Errorf("GetSalesToday = %+v, %v", sales, err)
+		}
+		name, qty, err := r.transactions.GetTopProductToday()
+		if err != nil || name != "Teh" || qty != 2 {
+			t.Errorf("GetTopProductToday = %q, %d, %v", name, qty, err)
+		}
+		sales, err = r.transactions.GetSalesInRange("2000-01-01", "2000-12-31")
+		if err != nil || sales != (models.SalesSummary{}) {
+			t.Errorf("GetSalesInRange(empty) = %+v, %v", sales, err)
+		}
+		name, _, err = r.transactions.GetTopProductInRange("2000-01-01", "2000-12-31")
+		if err != nil || name != "" {
+			t.Errorf("GetTopProductInRange(empty) = %q, %v", name, err)
+		}
+	})
+}
+
+func TestQuoteTransaction(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 1}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		// Quote tidak cek stock dan tidak menyimpan apa pun
+		trx, err := r.transactions.QuoteTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 3}}, nil)
+		if err != nil || trx.ID != 0 || trx.TotalAmount != 15000 {
+			t.Fatalf("QuoteTransaction = %+v, %v", trx, err)
+		}
+		if p, _ := r.products.GetByID(teh.ID); p.Stock != 1 {
+			t.Errorf("stock = %d, quote must not deduct stock", p.Stock)
+		}
+		if _, total, _ := r.transactions.GetAll(models.TransactionFilter{Page: 1, Limit: 10}); total != 0 {
+			t.Errorf("transactions = %d, quote must not save", total)
+		}
+
+		if _, err := r.transactions.QuoteTransaction([]models.CheckoutItem{{ProductID: 99, Quantity: 1}}, nil); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("unknown product err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestTransactionPayments(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 5}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		withPayments := func(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+			trx, err := repositories.DefaultPricing(items, products)
+			trx.PaidAmount, trx.ChangeAmount = 20000, 10000
+			trx.Payments = []models.Payment{
+				{Method: models.PaymentCard, Amount: 4000, Tendered: 4000},
+				{Method: models.PaymentCash, Amount: 6000, Tendered: 16000},
+			}
+			return trx, err
+		}
+		trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 2}}, withPayments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trx.Payments) != 2 || trx.Payments[0].ID == 0 || trx.Payments[1].TransactionID != trx.ID {
+			t.Errorf("payments = %+v", trx.Payments)
+		}
+
+		got, err := r.transactions.GetByID(trx.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.PaidAmount != 20000 || got.ChangeAmount != 10000 || len(got.Payments) != 2 || got.Payments[1].Tendered != 16000 {
+			t.Errorf("GetByID = %+v", got)
+		}
+
+		// Error dari PriceFunc membatalkan checkout
+		failing := func([]models.CheckoutItem, map[int]models.Product) (*models.Transaction, error) {
+			return nil, errors.New("underpaid")
+		}
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, failing); err == nil {
+			t.Error("checkout with failing PriceFunc should fail")
+		}
+		if p, _ := r.products.GetByID(teh.ID); p.Stock != 3 {
+			t.Errorf("stock = %d, want 3", p.Stock)
+		}
+
+		byMethod, err := r.transactions.GetPaymentsToday()
+		if err != nil || byMethod[models.PaymentCard] != 4000 || byMethod[models.PaymentCash] != 6000 {
+			t.Errorf("GetPaymentsToday = %v, %v", byMethod, err)
+		}
+
+		if _, err := r.transactions.CreateRefund(trx.ID, models.RefundTypeVoid, "salah input", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		today := time.Now().Format("2006-01-02")
+		byMethod, err = r.transactions.GetPaymentsInRange(today, today)
+		if err != nil || len(byMethod) != 0 {
+			t.Errorf("GetPaymentsInRange after void = %v, %v", byMethod, err)
+		}
+	})
+}
+
+func TestPromoCodeRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		until := time.Date(2030, 1, 31, 23, 59, 0, 0, time.UTC)
+		promo := models.PromoCode{Code: "HEMAT10", Type: models.DiscountPercent, Value: 10, UsageLimit: 1, ValidUntil: &until, Active: true}
+		if err := r.promoCodes.Create(&promo); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.promoCodes.Create(&models.PromoCode{Code: "HEMAT10", Type: models.DiscountFixed, Value: 1}); !errors.Is(err, repositories.ErrDuplicate) {
+			t.Errorf("duplicate Create err = %v, want ErrDuplicate", err)
+		}
+
+		got, err := r.promoCodes.GetByCode("HEMAT10")
+		if err != nil || got.ID != promo.ID || got.ValidFrom != nil || got.ValidUntil == nil || !got.ValidUntil.Equal(until) || !got.Active {
+			t.Errorf("GetByCode = %+v, %v", got, err)
+		}
+
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 5}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+		withPromo := func(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+			trx, err := repositories.DefaultPricing(items, products)
+			trx.Details[0].Subtotal -= 1000
+			trx.Details[0].DiscountAmount = 1000
+			trx.TotalAmount -= 1000
+			trx.DiscountAmount = 1000
+			trx.PromoCode = "HEMAT10"
+			return trx, err
+		}
+		trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 2}}, withPromo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved, err := r.transactions.GetByID(trx.ID)
+		if err != nil || saved.PromoCode != "HEMAT10" || saved.GrossAmount != 10000 || saved.DiscountAmount != 1000 || saved.Details[0].DiscountAmount != 1000 {
+			t.Errorf("GetByID = %+v, %v", saved, err)
+		}
+		if got, _ := r.promoCodes.GetByID(promo.ID); got.UsedCount != 1 {
+			t.Errorf("used_count = %d, want 1", got.UsedCount)
+		}
+
+		// Kuota habis: checkout dibatalkan dan stock tidak berkurang
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, withPromo); !errors.Is(err, repositories.ErrPromoUnavailable) {
+			t.Errorf("err = %v, want ErrPromoUnavailable", err)
+		}
+		if p, _ := r.products.GetByID(teh.ID); p.Stock != 3 {
+			t.Errorf("stock = %d, want 3", p.Stock)
+		}
+
+		promo.UsageLimit = 5
+		if err := r.promoCodes.Update(&promo); err != nil || promo.UsedCount != 1 {
+			t.Errorf("Update = %+v, %v (used_count must be kept)", promo, err)
+		}
+
+		sales, err := r.transactions.GetSalesToday()
+		if err != nil || sales.GrossRevenue != 10000 || sales.TotalDiscount != 1000 || sales.TotalRevenue != 9000 {
+			t.Errorf("GetSalesToday = %+v, %v", sales, err)
+		}
+
+		// Void mengembalikan kuota yang dipakai transaksi
+		if _, err := r.transactions.CreateRefund(trx.ID, models.RefundTypeVoid, "batal", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := r.promoCodes.GetByID(promo.ID); got.UsedCount != 0 {
+			t.Errorf("used_count after void = %d, want 0", got.UsedCount)
+		}
+
+		if err := r.promoCodes.Delete(promo.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.promoCodes.GetByID(promo.ID); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("GetByID after Delete err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestPromotionRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 10}
+		roti := models.Product{Name: "Roti", Price: 7000, Stock: 10}
+		for _, p := range []*models.Product{&teh, &roti} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		bundle := models.Promotion{Name: "Paket Sarapan", Type: models.PromotionBundle, BundlePrice: 10000, Priority: 5, Active: true,
+			Items: []models.PromotionItem{{ProductID: teh.ID, Quantity: 1}, {ProductID: roti.ID, Quantity: 1}}}
+		bogo := models.Promotion{Name: "Teh 2+1", Type: models.PromotionBuyXGetY, BuyQty: 2, FreeQty: 1, Priority: 10, Active: true,
+			Items: []models.PromotionItem{{ProductID: teh.ID, Quantity: 1}}}
+		inactive := models.Promotion{Name: "Lama", Type: models.PromotionHappyHour, DiscountPercent: 10, StartTime: "15:00", EndTime: "17:00"}
+		for _, p := range []*models.Promotion{&bundle, &bogo, &inactive} {
+			if err := r.promotions.Create(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.promotions.Create(&models.Promotion{Name: "X", Type: models.PromotionBundle, Items: []models.PromotionItem{{ProductID: 999, Quantity: 1}}}); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("Create with unknown product err = %v, want ErrForeignKey", err)
+		}
+
+		all, err := r.promotions.GetAll(false)
+		if err != nil || len(all) != 3 || all[0].ID != bogo.ID || all[1].ID != bundle.ID {
+			t.Fatalf("GetAll(false) = %+v, %v (want priority order)", all, err)
+		}
+		active, err := r.promotions.GetAll(true)
+		if err != nil || len(active) != 2 || len(active[1].Items) != 2 || active[1].Items[1].ProductID != roti.ID {
+			t.Errorf("GetAll(true) = %+v, %v", active, err)
+		}
+
+		bundle.Items = bundle.Items[:1]
+		bundle.BundlePrice = 4000
+		if err := r.promotions.Update(&bundle); err != nil {
+			t.Fatal(err)
+		}
+		got, err := r.promotions.GetByID(bundle.ID)
+		if err != nil || got.BundlePrice != 4000 || len(got.Items) != 1 {
+			t.Errorf("GetByID after Update = %+v, %v", got, err)
+		}
+
+		withPromotion := func(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+			trx, err := repositories.DefaultPricing(items, products)
+			trx.Details[0].Subtotal -= 5000
+			trx.Details[0].DiscountAmount = 5000
+			trx.TotalAmount -= 5000
+			trx.DiscountAmount = 5000
+			trx.Promotions = []models.AppliedPromotion{{PromotionID: bogo.ID, Name: bogo.Name, Type: bogo.Type, DiscountAmount: 5000}}
+			return trx, err
+		}
+		trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 3}}, withPromotion)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := r.promotions.Delete(bogo.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.promotions.GetByID(bogo.ID); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("GetByID after Delete err = %v, want ErrNotFound", err)
+		}
+		// Struk tetap menampilkan promosi walaupun aturannya sudah dihapus
+		saved, err := r.transactions.GetByID(trx.ID)
+		if err != nil || len(saved.Promotions) != 1 || saved.Promotions[0].Name != "Teh 2+1" || saved.Promotions[0].DiscountAmount != 5000 {
+			t.Errorf("GetByID promotions = %+v, %v", saved, err)
+		}
+	})
+}
+
+func TestCartRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 10}
+		kopi := models.Product{Name: "Kopi", Price: 8000, Stock: 2}
+		for _, p := range []*models.Product{&teh, &kopi} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stock := func(id int) int {
+			p, _ := r.products.GetByID(id)
+			return p.Stock
+		}
+
+		until := time.Now().Add(time.Hour)
+		cart := models.Cart{Note: "Meja 3", Reserved: true, ReservedUntil: &until, Items: []models.CartItem{
+			{ProductID: teh.ID, Quantity: 3, Discount: &models.Discount{Type: models.DiscountPercent, Value: 10}},
+		}}
+		if err := r.carts.Create(&cart); err != nil {
+			t.Fatal(err)
+		}
+		if cart.ID == 0 || cart.Status != models.CartOpen || cart.Items[0].ProductName != "Teh" || cart.Items[0].Discount.Value != 10 {
+			t.Errorf("Create = %+v", cart)
+		}
+		if stock(teh.ID) != 7 {
+			t.Errorf("teh stock = %d, want 7 after reservation", stock(teh.ID))
+		}
+
+		// Reservasi mengikuti perubahan items: teh dikurangi, kopi ditambah
+		cart.Items = []models.CartItem{{ProductID: teh.ID, Quantity: 1}, {ProductID: kopi.ID, Quantity: 2}}
+		if err := r.carts.Update(&cart); err != nil {
+			t.Fatal(err)
+		}
+		if stock(teh.ID) != 9 || stock(kopi.ID) != 0 {
+			t.Errorf("stock = %d/%d, want 9/0", stock(teh.ID), stock(kopi.ID))
+		}
+
+		cart.Items = []models.CartItem{{ProductID: teh.ID, Quantity: 1}, {ProductID: kopi.ID, Quantity: 3}}
+		var stockErr *repositories.InsufficientStockError
+		if err := r.carts.Update(&cart); !errors.As(err, &stockErr) || stockErr.Available != 0 || stockErr.Requested != 1 {
+			t.Errorf("Update over stock err = %v", err)
+		}
+		if got, _ := r.carts.GetByID(cart.ID); len(got.Items) != 2 || got.Items[1].Quantity != 2 || stock(kopi.ID) != 0 {
+			t.Errorf("failed Update must not change cart or stock: %+v", got)
+		}
+
+		if err := r.carts.Create(&models.Cart{Items: []models.CartItem{{ProductID: 999, Quantity: 1}}}); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("Create with unknown product err = %v, want ErrForeignKey", err)
+		}
+
+		if n, err := r.carts.ExpireReservations(time.Now()); err != nil || n != 0 {
+			t.Errorf("ExpireReservations(now) = %d, %v, want 0", n, err)
+		}
+		if n, err := r.carts.ExpireReservations(time.Now().Add(2 * time.Hour)); err != nil || n != 1 {
+			t.Errorf("ExpireReservations(later) = %d, %v, want 1", n, err)
+		}
+		got, err := r.carts.GetByID(cart.ID)
+		if err != nil || got.Reserved || got.ReservedUntil != nil || got.Status != models.CartOpen || stock(teh.ID) != 10 || stock(kopi.ID) != 2 {
+			t.Errorf("after expiry cart = %+v, stock %d/%d", got, stock(teh.ID), stock(kopi.ID))
+		}
+
+		trxID := 42
+		if err := r.carts.Close(cart.ID, models.CartCheckedOut, &trxID); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.carts.Update(&cart); !errors.Is(err, repositories.ErrCartClosed) {
+			t.Errorf("Update closed cart err = %v, want ErrCartClosed", err)
+		}
+		if err := r.carts.Close(99, models.CartCancelled, nil); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("Close missing cart err = %v, want ErrNotFound", err)
+		}
+
+		open, err := r.carts.GetAll(models.CartOpen)
+		if err != nil || len(open) != 0 {
+			t.Errorf("GetAll(open) = %+v, %v", open, err)
+		}
+		all, err := r.carts.GetAll("")
+		if err != nil || len(all) != 1 || all[0].TransactionID == nil || *all[0].TransactionID != 42 {
+			t.Errorf("GetAll() = %+v, %v", all, err)
+		}
+	})
+}
+
+func TestCreateCartTransaction(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 3}
+		kopi := models.Product{Name: "Kopi", Price: 8000, Stock: 5}
+		for _, p := range []*models.Product{&teh, &kopi} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stock := func(id int) int {
+			p, _ := r.products.GetByID(id)
+			return p.Stock
+		}
+
+		until := time.Now().Add(time.Hour)
+		reserved := models.Cart{Reserved: true, ReservedUntil: &until, Items: []models.CartItem{{ProductID: teh.ID, Quantity: 3}}}
+		plain := models.Cart{Items: []models.CartItem{{ProductID: kopi.ID, Quantity: 2}}}
+		for _, c := range []*models.Cart{&reserved, &plain} {
+			if err := r.carts.Create(c); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Stock yang di-reserve tidak bisa diambil checkout lain
+		var stockErr *repositories.InsufficientStockError
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, nil); !errors.As(err, &stockErr) {
+			t.Errorf("checkout of reserved stock err = %v", err)
+		}
+		if _, err := r.transactions.CreateCartTransaction(reserved.ID, []models.CheckoutItem{{ProductID: teh.ID, Quantity: 2}}, nil); !errors.Is(err, repositories.ErrCartChanged) {
+			t.Errorf("mismatched items err = %v, want ErrCartChanged", err)
+		}
+
+		// Dua checkout cart yang sama bersamaan: hanya satu yang terjual
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var sold []*models.Transaction
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				trx, err := r.transactions.CreateCartTransaction(reserved.ID, []models.CheckoutItem{{ProductID: teh.ID, Quantity: 3}}, nil)
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					sold = append(sold, trx)
+				case !errors.Is(err, repositories.ErrCartClosed):
+					t.Errorf("second cart checkout err = %v, want ErrCartClosed", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if len(sold) != 1 || stock(teh.ID) != 0 {
+			t.Fatalf("sold = %d, teh stock = %d, want 1 sale and stock 0", len(sold), stock(teh.ID))
+		}
+		got, _ := r.carts.GetByID(reserved.ID)
+		if got.Status != models.CartCheckedOut || got.Reserved || got.TransactionID == nil || *got.TransactionID != sold[0].ID {
+			t.Errorf("checked out cart = %+v", got)
+		}
+
+		// Cart tanpa reservasi mengurangi stock saat checkout
+		if _, err := r.transactions.CreateCartTransaction(plain.ID, []models.CheckoutItem{{ProductID: kopi.ID, Quantity: 2}}, nil); err != nil {
+			t.Fatal(err)
+		}
+		if stock(kopi.ID) != 3 {
+			t.Errorf("kopi stock = %d, want 3", stock(kopi.ID))
+		}
+		if _, discrepancies, _ := r.stock.CheckConsistency(); len(discrepancies) != 0 {
+			t.Errorf("discrepancies = %+v", discrepancies)
+		}
+	})
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		now := time.Now()
+		rec := models.IdempotencyRecord{Key: "kasir-1-0001", RequestHash: "abc", ExpiresAt: now.Add(time.Hour)}
+		if err := r.idempotency.Create(&rec); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.idempotency.Create(&rec); !errors.Is(err, repositories.ErrDuplicate) {
+			t.Errorf("duplicate Create err = %v, want ErrDuplicate", err)
+		}
+
+		got, err := r.idempotency.GetByKey(rec.Key)
+		if err != nil || got.StatusCode != 0 || got.RequestHash != "abc" || got.ExpiresAt.Unix() != rec.ExpiresAt.Unix() {
+			t.Errorf("GetByKey = %+v, %v", got, err)
+		}
+		if err := r.idempotency.Complete(rec.Key, 200, []byte(`{"id":1}`)); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := r.idempotency.GetByKey(rec.Key); got.StatusCode != 200 || string(got.Response) != `{"id":1}` {
+			t.Errorf("after Complete = %+v", got)
+		}
+		if err := r.idempotency.Complete("missing", 200, nil); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("Complete missing err = %v, want ErrNotFound", err)
+		}
+
+		old := models.IdempotencyRecord{Key: "kasir-1-0000", RequestHash: "def", ExpiresAt: now.Add(-time.Minute)}
+		if err := r.idempotency.Create(&old); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := r.idempotency.DeleteExpired(now); err != nil || n != 1 {
+			t.Errorf("DeleteExpired = %d, %v, want 1", n, err)
+		}
+		if _, err := r.idempotency.GetByKey(old.Key); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("expired key err = %v, want ErrNotFound", err)
+		}
+
+		if err := r.idempotency.Delete(rec.Key); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.idempotency.Delete(rec.Key); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("Delete twice err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		if n, err := r.users.Count(); err != nil || n != 0 {
+			t.Fatalf("Count = %d, %v, want 0", n, err)
+		}
+		u := models.User{Username: "ani", Name: "Ani", PasswordHash: "hash-1", Active: true}
+		if err := r.users.Create(&u); err != nil {
+			t.Fatal(err)
+		}
+		if u.ID == 0 || u.CreatedAt.IsZero() {
+			t.Errorf("Create did not fill ID/CreatedAt: %+v", u)
+		}
+		if err := r.users.Create(&models.User{Username: "ani", PasswordHash: "x"}); !errors.Is(err, repositories.ErrDuplicate) {
+			t.Errorf("duplicate username err = %v, want ErrDuplicate", err)
+		}
+
+		// PasswordHash kosong berarti hash lama tetap dipakai
+		u.Name, u.PasswordHash, u.Active = "Ani S", "", false
+		if err := r.users.Update(&u); err != nil {
+			t.Fatal(err)
+		}
+		got, err := r.users.GetByUsername("ani")
+		if err != nil || got.Name != "Ani S" || got.PasswordHash != "hash-1" || got.Active {
+			t.Errorf("after Update = %+v, %v", got, err)
+		}
+		if err := r.users.Update(&models.User{ID: 99, Username: "x"}); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("Update missing err = %v, want ErrNotFound", err)
+		}
+
+		now := time.Now()
+		live := models.Session{TokenHash: "live", UserID: u.ID, ExpiresAt: now.Add(time.Hour)}
+		old := models.Session{TokenHash: "old", UserID: u.ID, ExpiresAt: now.Add(-time.Hour)}
+		for _, s := range []*models.Session{&live, &old} {
+			if err := r.sessions.Create(s); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := r.sessions.Create(&models.Session{TokenHash: "orphan", UserID: 99, ExpiresAt: now}); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("session for missing user err = %v, want ErrForeignKey", err)
+		}
+		if s, err := r.sessions.GetByTokenHash("live"); err != nil || s.UserID != u.ID || s.ExpiresAt.Unix() != live.ExpiresAt.Unix() {
+			t.Errorf("GetByTokenHash = %+v, %v", s, err)
+		}
+		if n, err := r.sessions.DeleteExpired(now); err != nil || n != 1 {
+			t.Errorf("DeleteExpired = %d, %v, want 1", n, err)
+		}
+
+		// Hapus user ikut menghapus session-nya
+		if err := r.users.Delete(u.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.sessions.GetByTokenHash("live"); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("session after user delete err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestRoleRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		// Seed memory harus sama dengan migration 0010
+		roles, err := r.roles.GetAll()
+		if err != nil || len(roles) != 3 {
+			t.Fatalf("GetAll = %+v, %v", roles, err)
+		}
+		owner, cashier := roles[0], roles[2]
+		allPerms := slices.Sorted(slices.Values(models.AllPermissions))
+		if owner.Name != models.RoleOwner || !reflect.DeepEqual(owner.Permissions, allPerms) {
+			t.Errorf("owner = %+v, want all permissions", owner)
+		}
+		wantCashier := []string{"category:read", "product:read", "promo:read", "transaction:create", "transaction:read"}
+		if cashier.Name != models.RoleCashier || !reflect.DeepEqual(cashier.Permissions, wantCashier) {
+			t.Errorf("cashier = %+v, want %v", cashier, wantCashier)
+		}
+		if roles[1].Name != models.RoleManager || slices.Contains(roles[1].Permissions, models.PermReportRead) {
+			t.Errorf("manager = %+v, want no report:read", roles[1])
+		}
+
+		u := models.User{Username: "budi", PasswordHash: "x", Active: true}
+		if err := r.users.Create(&u); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.roles.SetUserRoles(u.ID, []int{99}); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("unknown role err = %v, want ErrForeignKey", err)
+		}
+		if err := r.roles.SetUserRoles(u.ID, []int{cashier.ID, roles[1].ID}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := r.roles.GetUserRoles(u.ID); len(got) != 2 || got[0].Name != models.RoleManager {
+			t.Errorf("GetUserRoles = %+v", got)
+		}
+		perms, err := r.roles.UserPermissions(u.ID)
+		if err != nil || !slices.Contains(perms, models.PermProductWrite) || slices.Contains(perms, models.PermReportRead) {
+			t.Errorf("UserPermissions = %v, %v", perms, err)
+		}
+		if len(perms) != len(slices.Compact(slices.Clone(perms))) {
+			t.Errorf("UserPermissions has duplicates: %v", perms)
+		}
+
+		if err := r.roles.SetPermissions(cashier.ID, []string{models.PermProductRead}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := r.roles.GetByID(cashier.ID); !reflect.DeepEqual(got.Permissions, []string{models.PermProductRead}) {
+			t.Errorf("after SetPermissions = %+v", got)
+		}
+		if err := r.roles.SetPermissions(99, nil); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("SetPermissions missing role err = %v, want ErrNotFound", err)
+		}
+		if n, _ := r.roles.CountUsersWithRole(cashier.ID); n != 1 {
+			t.Errorf("CountUsersWithRole = %d, want 1", n)
+		}
+
+		// Hapus user ikut menghapus role-nya
+		if err := r.users.Delete(u.ID); err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := r.roles.CountUsersWithRole(cashier.ID); n != 0 {
+			t.Errorf("CountUsersWithRole after delete = %d, want 0", n)
+		}
+	})
+}
+
+func TestLastOwner(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		const ownerRole, managerRole = 1, 2
+		ani := models.User{Username: "ani", PasswordHash: "x", Active: true}
+		budi := models.User{Username: "budi", PasswordHash: "x"}
+		for _, u := range []*models.User{&ani, &budi} {
+			if err := r.users.Create(u); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.roles.SetUserRoles(u.ID, []int{ownerRole}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// budi owner tapi nonaktif, jadi ani adalah owner aktif terakhir
+		deactivated := ani
+		deactivated.Active = false
+		for name, err := range map[string]error{
+			"set roles":  r.roles.SetUserRoles(ani.ID, []int{managerRole}),
+			"deactivate": r.users.Update(&deactivated),
+			"delete":     r.users.Delete(ani.ID),
+		} {
+			if !errors.Is(err, repositories.ErrLastOwner) {
+				t.Errorf("%s err = %v, want ErrLastOwner", name, err)
+			}
+		}
+		if got, _ := r.users.GetByID(ani.ID); got == nil || !got.Active {
+			t.Errorf("last owner changed: %+v", got)
+		}
+		if got, _ := r.roles.GetUserRoles(ani.ID); len(got) != 1 || got[0].ID != ownerRole {
+			t.Errorf("last owner roles = %+v", got)
+		}
+		// Owner nonaktif boleh dilepas, owner aktif tetap boleh ganti data lain
+		if err := r.roles.SetUserRoles(budi.ID, nil); err != nil {
+			t.Errorf("remove inactive owner err = %v", err)
+		}
+		ani.Name = "Ani"
+		if err := r.users.Update(&ani); err != nil {
+			t.Errorf("rename last owner err = %v", err)
+		}
+
+		budi.Active = true
+		if err := r.users.Update(&budi); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.roles.SetUserRoles(budi.ID, []int{ownerRole, managerRole}); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.users.Delete(ani.ID); err != nil {
+			t.Errorf("delete owner when another is active err = %v", err)
+		}
+	})
+}
+
+func TestShiftRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		ani := models.User{Username: "ani", PasswordHash: "x", Active: true}
+		if err := r.users.Create(&ani); err != nil {
+			t.Fatal(err)
+		}
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 10}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		shift := models.Shift{UserID: ani.ID, OpeningCash: 100000, Note: "pagi"}
+		if err := r.shifts.Open(&shift); err != nil {
+			t.Fatal(err)
+		}
+		if shift.ID == 0 || shift.Status != models.ShiftOpen || shift.Username != "ani" || shift.OpenedAt.IsZero() {
+			t.Errorf("Open = %+v", shift)
+		}
+		if err := r.shifts.Open(&models.Shift{UserID: ani.ID}); !errors.Is(err, repositories.ErrShiftAlreadyOpen) {
+			t.Errorf("second Open err = %v, want ErrShiftAlreadyOpen", err)
+		}
+		if err := r.shifts.Open(&models.Shift{UserID: 99}); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("Open for missing user err = %v, want ErrForeignKey", err)
+		}
+		if got, err := r.shifts.GetOpenByUser(ani.ID); err != nil || got.ID != shift.ID {
+			t.Errorf("GetOpenByUser = %+v, %v", got, err)
+		}
+
+		// Dua transaksi di shift ini: cash 10000 dan card 5000, lalu refund 1 teh dari masing-masing
+		inShift := func(method string) repositories.PriceFunc {
+			return func(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+				trx, err := repositories.DefaultPricing(items, products)
+				trx.Payments = []models.Payment{{Method: method, Amount: trx.TotalAmount, Tendered: trx.TotalAmount}}
+				trx.CashierID, trx.ShiftID = &ani.ID, &shift.ID
+				return trx, err
+			}
+		}
+		cash, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 2}}, inShift(models.PaymentCash))
+		if err != nil {
+			t.Fatal(err)
+		}
+		card, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, inShift(models.PaymentCard))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Transaksi tanpa kasir tidak masuk shift mana pun
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, nil); err != nil {
+			t.Fatal(err)
+		}
+		saved, err := r.transactions.GetByID(cash.ID)
+		if err != nil || saved.CashierID == nil || *saved.CashierID != ani.ID || saved.ShiftID == nil || *saved.ShiftID != shift.ID {
+			t.Fatalf("GetByID = %+v, %v", saved, err)
+		}
+		refund, err := r.transactions.CreateRefund(cash.ID, models.RefundTypePartial, "rusak", []models.RefundLine{{DetailID: saved.Details[0].ID, Quantity: 1}}, &shift)
+		if err != nil || refund.ShiftID == nil || *refund.ShiftID != shift.ID || refund.CashAmount != 5000 {
+			t.Fatalf("CreateRefund = %+v, %v", refund, err)
+		}
+		// Refund transaksi card dikembalikan ke kartu, tidak keluar dari laci
+		refund, err = r.transactions.CreateRefund(card.ID, models.RefundTypeVoid, "batal", nil, &shift)
+		if err != nil || refund.CashAmount != 0 {
+			t.Fatalf("card CreateRefund = %+v, %v", refund, err)
+		}
+		if saved, _ := r.transactions.GetByID(card.ID); len(saved.Refunds) != 1 || saved.Refunds[0].CashAmount != 0 || saved.Refunds[0].Amount != 5000 {
+			t.Errorf("card refunds = %+v", saved.Refunds)
+		}
+
+		page, total, err := r.transactions.GetAll(models.TransactionFilter{ShiftID: shift.ID, Page: 1, Limit: 10})
+		if err != nil || total != 2 || len(page) != 2 || page[0].ShiftID == nil {
+			t.Errorf("GetAll by shift = %+v, %d, %v", page, total, err)
+		}
+		if _, total, _ := r.transactions.GetAll(models.TransactionFilter{CashierID: 99, Page: 1, Limit: 10}); total != 0 {
+			t.Errorf("GetAll by other cashier total = %d, want 0", total)
+		}
+
+		want := models.ShiftSummary{
+			TransactionCount: 2,
+			SalesByMethod:    map[string]int{models.PaymentCash: 10000, models.PaymentCard: 5000},
+			RefundCount:      2,
+			RefundTotal:      10000,
+			CashRefundTotal:  5000,
+		}
+		if summary, err := r.shifts.Summary(shift.ID); err != nil || !reflect.DeepEqual(summary, want) {
+			t.Errorf("Summary = %+v, %v, want %+v", summary, err, want)
+		}
+
+		closed, summary, err := r.shifts.Close(shift.ID, 104000, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(summary, want) {
+			t.Errorf("Close summary = %+v", summary)
+		}
+		// 100000 modal + 10000 cash - 5000 refund tunai; refund card tidak mengurangi laci
+		if closed.Status != models.ShiftClosed || closed.ExpectedCash == nil || *closed.ExpectedCash != 105000 ||
+			closed.CountedCash == nil || *closed.CountedCash != 104000 || closed.ClosedAt == nil || closed.Note != "pagi" {
+			t.Errorf("Close = %+v", closed)
+		}
+		if _, _, err := r.shifts.Close(shift.ID, 0, ""); !errors.Is(err, repositories.ErrShiftClosed) {
+			t.Errorf("second Close err = %v, want ErrShiftClosed", err)
+		}
+
+		// Setelah shift ditutup tidak ada transaksi atau refund yang boleh masuk ke shift itu
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, inShift(models.PaymentCash)); !errors.Is(err, repositories.ErrShiftClosed) {
+			t.Errorf("checkout on closed shift err = %v, want ErrShiftClosed", err)
+		}
+		if _, err := r.transactions.CreateRefund(cash.ID, models.RefundTypeVoid, "batal", nil, &shift); !errors.Is(err, repositories.ErrShiftClosed) {
+			t.Errorf("refund on closed shift err = %v, want ErrShiftClosed", err)
+		}
+		if p, _ := r.products.GetByID(teh.ID); p.Stock != 8 {
+			t.Errorf("stock = %d, want 8", p.Stock)
+		}
+
+		if _, err := r.shifts.GetOpenByUser(ani.ID); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("GetOpenByUser after close err = %v, want ErrNotFound", err)
+		}
+		next := models.Shift{UserID: ani.ID}
+		if err := r.shifts.Open(&next); err != nil {
+			t.Fatal(err)
+		}
+		if shifts, err := r.shifts.GetAll(models.ShiftFilter{UserID: ani.ID}); err != nil || len(shifts) != 2 || shifts[0].ID != next.ID {
+			t.Errorf("GetAll = %+v, %v", shifts, err)
+		}
+		if shifts, err := r.shifts.GetAll(models.ShiftFilter{Status: models.ShiftClosed}); err != nil || len(shifts) != 1 {
+			t.Errorf("GetAll closed = %+v, %v", shifts, err)
+		}
+
+		// User yang pernah membuka shift tidak bisa dihapus
+		if err := r.users.Delete(ani.ID); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("delete cashier err = %v, want ErrForeignKey", err)
+		}
+	})
+}
+
+func TestAuditRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		ani := models.User{Username: "ani", PasswordHash: "x", Active: true}
+		if err := r.users.Create(&ani); err != nil {
+			t.Fatal(err)
+		}
+		c := models.Category{Name: "Minuman"}
+		if err := r.categories.Create(&c, ani.ID); err != nil {
+			t.Fatal(err)
+		}
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 10, CategoryID: c.ID}
+		if err := r.products.Create(&teh, ani.ID); err != nil {
+			t.Fatal(err)
+		}
+		teh.Price = 6000
+		if _, err := r.products.Update(teh.ID, productUpdate(teh), ani.ID); err != nil {
+			t.Fatal(err)
+		}
+		// Perubahan yang gagal tidak meninggalkan audit log
+		if _, err := r.products.Update(teh.ID, models.ProductUpdateRequest{Name: "Teh", CategoryID: 999}, ani.ID); err == nil {
+			t.Fatal("update with missing category should fail")
+		}
+		if err := r.products.BulkUpdateCategory(c.ID, 1, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.categories.Delete(c.ID, ani.ID); err != nil {
+			t.Fatal(err)
+		}
+		trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.transactions.CreateRefund(trx.ID, models.RefundTypeVoid, "batal", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, total, err := r.audit.GetAll(models.AuditFilter{Page: 1, Limit: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		type row struct {
+			entity, action string
+			entityID       int
+		}
+		var got []row
+		for _, e := range entries {
+			got = append(got, row{e.Entity, e.Action, e.EntityID})
+		}
+		want := []row{
+			{models.AuditTransaction, models.AuditUpdate, trx.ID},
+			{models.AuditTransaction, models.AuditCreate, trx.ID},
+			{models.AuditCategory, models.AuditDelete, c.ID},
+			{models.AuditProduct, models.AuditUpdate, teh.ID},
+			{models.AuditProduct, models.AuditUpdate, teh.ID},
+			{models.AuditProduct, models.AuditCreate, teh.ID},
+			{models.AuditCategory, models.AuditCreate, c.ID},
+		}
+		if total != len(want) || !reflect.DeepEqual(got, want) {
+			t.Fatalf("audit log = %v (total %d), want %v", got, total, want)
+		}
+
+		priceChange := entries[4]
+		if priceChange.ActorID == nil || *priceChange.ActorID != ani.ID || priceChange.ActorUsername != "ani" || priceChange.CreatedAt.IsZero() {
+			t.Errorf("price change actor = %+v", priceChange)
+		}
+		var before, after models.Product
+		if err := json.Unmarshal(priceChange.Before, &before); err != nil || before.Price != 5000 {
+			t.Errorf("before = %s, %v", priceChange.Before, err)
+		}
+		if err := json.Unmarshal(priceChange.After, &after); err != nil || after.Price != 6000 {
+			t.Errorf("after = %s, %v", priceChange.After, err)
+		}
+		if moved := entries[3]; moved.ActorID != nil || !strings.Contains(string(moved.After), `"category_id":1`) {
+			t.Errorf("bulk move entry = %+v, after %s", moved, moved.After)
+		}
+		if created := entries[6]; created.Before != nil || created.After == nil {
+			t.Errorf("create entry before/after = %s / %s", created.Before, created.After)
+		}
+		if deleted := entries[2]; deleted.After != nil || !strings.Contains(string(deleted.Before), `"Minuman"`) {
+			t.Errorf("delete entry before/after = %s / %s", deleted.Before, deleted.After)
+		}
+		if void := entries[0]; !strings.Contains(string(void.After), `"status":"voided"`) || !strings.Contains(string(void.Before), `"status":"completed"`) {
+			t.Errorf("void entry before/after = %s / %s", void.Before, void.After)
+		}
+
+		tests := []struct {
+			name   string
+			filter models.AuditFilter
+			want   int
+		}{
+			{"by entity", models.AuditFilter{Entity: models.AuditProduct}, 3},
+			{"by entity ID", models.AuditFilter{Entity: models.AuditCategory, EntityID: c.ID}, 2},
+			{"by actor", models.AuditFilter{ActorID: ani.ID}, 4},
+			{"by action", models.AuditFilter{Action: models.AuditDelete}, 1},
+			{"by date", models.AuditFilter{StartDate: "2000-01-01", EndDate: "2000-01-31"}, 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.filter.Page, tt.filter.Limit = 1, 2
+				page, total, err := r.audit.GetAll(tt.filter)
+				if err != nil || total != tt.want || len(page) != min(tt.want, 2) {
+					t.Errorf("GetAll = %d entries, total %d, %v; want total %d", len(page), total, err, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestStockMovementRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		ani := models.User{Username: "ani", PasswordHash: "x", Active: true}
+		if err := r.users.Create(&ani); err != nil {
+			t.Fatal(err)
+		}
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 10}
+		kosong := models.Product{Name: "Kopi", Price: 8000}
+		for _, p := range []*models.Product{&teh, &kosong} {
+			if err := r.products.Create(p, ani.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		teh.Stock = 15
+		if _, err := r.products.Update(teh.ID, productUpdate(teh), ani.ID); err != nil {
+			t.Fatal(err)
+		}
+		// Update yang gagal tidak meninggalkan movement
+		if _, err := r.products.Update(teh.ID, models.ProductUpdateRequest{Name: "Teh", Stock: 99, CategoryID: 999}, ani.ID); err == nil {
+			t.Fatal("update with missing category should fail")
+		}
+		trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 3}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refund, err := r.transactions.CreateRefund(trx.ID, models.RefundTypeVoid, "batal", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		until := time.Now().Add(time.Hour)
+		cart := models.Cart{Reserved: true, ReservedUntil: &until, Items: []models.CartItem{{ProductID: teh.ID, Quantity: 2}}}
+		if err := r.carts.Create(&cart); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.carts.Close(cart.ID, models.CartCancelled, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		movements, total, err := r.stock.GetByProduct(models.StockMovementFilter{ProductID: teh.ID, Page: 1, Limit: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		type row struct {
+			typ      string
+			quantity int
+			ref      int
+		}
+		var got []row
+		for _, m := range movements {
+			ref := 0
+			if m.ReferenceID != nil {
+				ref = *m.ReferenceID
+			}
+			got = append(got, row{m.Type, m.Quantity, ref})
+		}
+		want := []row{
+			{models.StockReservation, 2, cart.ID},
+			{models.StockReservation, -2, cart.ID},
+			{models.StockRefund, 3, refund.ID},
+			{models.StockSale, -3, trx.ID},
+			{models.StockAdjustment, 5, 0},
+			{models.StockAdjustment, 10, 0},
+		}
+		if total != len(want) || !reflect.DeepEqual(got, want) {
+			t.Fatalf("movements = %v (total %d), want %v", got, total, want)
+		}
+		if initial := movements[5]; initial.ActorID == nil || *initial.ActorID != ani.ID || initial.ActorUsername != "ani" || initial.Note != "initial stock" || initial.CreatedAt.IsZero() {
+			t.Errorf("initial stock movement = %+v", initial)
+		}
+		if sale := movements[3]; sale.ActorID != nil {
+			t.Errorf("sale without cashier actor = %v", *sale.ActorID)
+		}
+
+		tests := []struct {
+			name   string
+			filter models.StockMovementFilter
+			want   int
+		}{
+			{"by type", models.StockMovementFilter{ProductID: teh.ID, Type: models.StockSale}, 1},
+			{"other product", models.StockMovementFilter{ProductID: kosong.ID}, 0},
+			{"by date", models.StockMovementFilter{ProductID: teh.ID, StartDate: "2000-01-01", EndDate: "2000-01-31"}, 0},
+			{"paginated", models.StockMovementFilter{ProductID: teh.ID}, 6},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.filter.Page, tt.filter.Limit = 1, 2
+				page, total, err := r.stock.GetByProduct(tt.filter)
+				if err != nil || total != tt.want || len(page) != min(tt.want, 2) {
+					t.Errorf("GetByProduct = %d movements, total %d, %v; want total %d", len(page), total, err, tt.want)
+				}
+			})
+		}
+
+		checked, discrepancies, err := r.stock.CheckConsistency()
+		if err != nil || checked != 2 || len(discrepancies) != 0 {
+			t.Errorf("CheckConsistency = %d, %+v, %v; want 2 products, no discrepancy", checked, discrepancies, err)
+		}
+	})
+}
+
+func TestStockCountRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 10}
+		kopi := models.Product{Name: "Kopi", Price: 8000, Stock: 5}
+		for _, p := range []*models.Product{&teh, &kopi} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stock := func(id int) int {
+			p, _ := r.products.GetByID(id)
+			return p.Stock
+		}
+
+		count := models.StockCount{Note: "Opname Januari"}
+		if err := r.stockCounts.Create(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count.ID == 0 || count.Status != models.StockCountOpen || count.CreatedAt.IsZero() || len(count.Items) != 0 {
+			t.Errorf("Create = %+v", count)
+		}
+		if err := r.stockCounts.Create(&models.StockCount{}); !errors.Is(err, repositories.ErrStockCountOpen) {
+			t.Errorf("second Create err = %v, want ErrStockCountOpen", err)
+		}
+
+		if err := r.stockCounts.SubmitItems(count.ID, []models.StockCountItem{{ProductID: teh.ID, CountedQuantity: 8}}, 0); err != nil {
+			t.Fatal(err)
+		}
+		// Penjualan setelah teh dihitung tidak boleh dianggap selisih
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 3}}, nil); err != nil {
+			t.Fatal(err)
+		}
+		// Batch dengan produk yang tidak ada ditolak seluruhnya
+		err := r.stockCounts.SubmitItems(count.ID, []models.StockCountItem{{ProductID: kopi.ID, CountedQuantity: 1}, {ProductID: 999, CountedQuantity: 1}}, 0)
+		if !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("SubmitItems with missing product err = %v, want ErrForeignKey", err)
+		}
+		if err := r.stockCounts.SubmitItems(count.ID, []models.StockCountItem{{ProductID: kopi.ID, CountedQuantity: 6}}, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := r.stockCounts.GetByID(count.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		type row struct{ product, system, counted, variance int }
+		var items []row
+		for _, it := range got.Items {
+			items = append(items, row{it.ProductID, it.SystemStock, it.CountedQuantity, it.Variance})
+		}
+		want := []row{{teh.ID, 10, 8, -2}, {kopi.ID, 5, 6, 1}}
+		if !reflect.DeepEqual(items, want) || got.Items[1].ProductName != "Kopi" || got.Items[1].Price != 8000 {
+			t.Fatalf("items = %v (%+v), want %v", items, got.Items, want)
+		}
+
+		if err := r.stockCounts.Finalize(count.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if stock(teh.ID) != 5 || stock(kopi.ID) != 6 {
+			t.Errorf("stock after finalize = %d/%d, want 5/6", stock(teh.ID), stock(kopi.ID))
+		}
+		movements, _, _ := r.stock.GetByProduct(models.StockMovementFilter{ProductID: teh.ID, Page: 1, Limit: 1})
+		if m := movements[0]; m.Type != models.StockAdjustment || m.Quantity != -2 || m.ReferenceID == nil || *m.ReferenceID != count.ID {
+			t.Errorf("finalize movement = %+v", m)
+		}
+		if _, discrepancies, _ := r.stock.CheckConsistency(); len(discrepancies) != 0 {
+			t.Errorf("ledger out of sync after finalize: %+v", discrepancies)
+		}
+		entries, total, _ := r.audit.GetAll(models.AuditFilter{Entity: models.AuditProduct, EntityID: teh.ID, Action: models.AuditUpdate, Page: 1, Limit: 10})
+		var before, after models.Product
+		if total != 1 || json.Unmarshal(entries[0].Before, &before) != nil || json.Unmarshal(entries[0].After, &after) != nil || before.Stock != 7 || after.Stock != 5 || after.Name != "Teh" {
+			t.Errorf("finalize audit = %+v", entries)
+		}
+		if got, _ := r.stockCounts.GetByID(count.ID); got.Status != models.StockCountFinalized || got.ClosedAt == nil {
+			t.Errorf("finalized count = %+v", got)
+		}
+		for name, err := range map[string]error{
+			"submit":   r.stockCounts.SubmitItems(count.ID, []models.StockCountItem{{ProductID: teh.ID}}, 0),
+			"finalize": r.stockCounts.Finalize(count.ID, 0),
+			"cancel":   r.stockCounts.Cancel(count.ID, 0),
+		} {
+			if !errors.Is(err, repositories.ErrStockCountClosed) {
+				t.Errorf("%s after finalize err = %v, want ErrStockCountClosed", name, err)
+			}
+		}
+
+		// Finalize gagal kalau stock akan negatif, dan tidak ada selisih yang diterapkan sebagian
+		second := models.StockCount{}
+		if err := r.stockCounts.Create(&second); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.stockCounts.SubmitItems(second.ID, []models.StockCountItem{{ProductID: teh.ID, CountedQuantity: 0}, {ProductID: kopi.ID, CountedQuantity: 9}}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.products.AdjustStock(teh.ID, -3, 0, "rusak"); err != nil {
+			t.Fatal(err)
+		}
+		var stockErr *repositories.InsufficientStockError
+		if err := r.stockCounts.Finalize(second.ID, 0); !errors.As(err, &stockErr) || stockErr.Available != 2 || stockErr.Requested != 5 {
+			t.Errorf("Finalize below zero err = %v", err)
+		}
+		if stock(teh.ID) != 2 || stock(kopi.ID) != 6 {
+			t.Errorf("failed finalize changed stock to %d/%d", stock(teh.ID), stock(kopi.ID))
+		}
+		if err := r.stockCounts.Cancel(second.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		for status, want := range map[string]int{"": 2, models.StockCountFinalized: 1, models.StockCountCancelled: 1, models.StockCountOpen: 0} {
+			if counts, err := r.stockCounts.GetAll(status); err != nil || len(counts) != want {
+				t.Errorf("GetAll(%q) = %d counts, %v; want %d", status, len(counts), err, want)
+			}
+		}
+		if _, err := r.stockCounts.GetByID(99); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("GetByID missing err = %v", err)
+		}
+	})
+}
+
+func TestStockCountReservedStock(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 10}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+		stock := func() int {
+			p, _ := r.products.GetByID(teh.ID)
+			return p.Stock
+		}
+
+		// Cart reserved mengurangi products.stock, tapi barangnya masih di rak
+		until := time.Now().Add(time.Hour)
+		cart := models.Cart{Reserved: true, ReservedUntil: &until, Items: []models.CartItem{{ProductID: teh.ID, Quantity: 3}}}
+		if err := r.carts.Create(&cart); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.carts.Create(&models.Cart{Items: []models.CartItem{{ProductID: teh.ID, Quantity: 4}}}); err != nil {
+			t.Fatal(err)
+		}
+
+		count := models.StockCount{}
+		if err := r.stockCounts.Create(&count); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.stockCounts.SubmitItems(count.ID, []models.StockCountItem{{ProductID: teh.ID, CountedQuantity: 10}}, 0); err != nil {
+			t.Fatal(err)
+		}
+		got, err := r.stockCounts.GetByID(count.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if it := got.Items[0]; it.SystemStock != 10 || it.Variance != 0 {
+			t.Errorf("item = %+v, want system stock 10 and no variance", it)
+		}
+		if err := r.stockCounts.Finalize(count.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if stock() != 7 {
+			t.Errorf("stock after finalize = %d, want 7", stock())
+		}
+		if err := r.carts.Close(cart.ID, models.CartCancelled, nil); err != nil {
+			t.Fatal(err)
+		}
+		if stock() != 10 {
+			t.Errorf("stock after releasing cart = %d, want 10", stock())
+		}
+	})
+}
+
+func TestSupplierRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		s := models.Supplier{Name: "CV Sumber Teh", Phone: "0812", Email: "teh@example.com"}
+		if err := r.suppliers.Create(&s); err != nil {
+			t.Fatal(err)
+		}
+		if s.ID == 0 || s.CreatedAt.IsZero() {
+			t.Errorf("Create = %+v", s)
+		}
+		if err := r.suppliers.Create(&models.Supplier{Name: "CV Sumber Teh"}); !errors.Is(err, repositories.ErrDuplicate) {
+			t.Errorf("duplicate Create err = %v", err)
+		}
+		s.Address = "Bandung"
+		if err := r.suppliers.Update(&s); err != nil || s.Address != "Bandung" || s.CreatedAt.IsZero() {
+			t.Fatalf("Update = %+v, %v", s, err)
+		}
+		if err := r.suppliers.Update(&models.Supplier{ID: 99, Name: "X"}); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("Update missing err = %v", err)
+		}
+
+		teh := models.Product{Name: "Teh", Price: 5000}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+		po := models.PurchaseOrder{SupplierID: s.ID, Items: []models.PurchaseOrderItem{{ProductID: teh.ID, Quantity: 1, UnitCost: 3000}}}
+		if err := r.purchases.Create(&po); err != nil {
+			t.Fatal(err)
+		}
+		// Supplier dan produk yang masih dipakai PO tidak bisa dihapus
+		if err := r.suppliers.Delete(s.ID); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("Delete supplier with PO err = %v", err)
+		}
+		if err := r.products.Delete(teh.ID, 0); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("Delete product on PO err = %v", err)
+		}
+
+		other := models.Supplier{Name: "UD Kopi"}
+		if err := r.suppliers.Create(&other); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.suppliers.Delete(other.ID); err != nil {
+			t.Fatal(err)
+		}
+		if all, _ := r.suppliers.GetAll(); len(all) != 1 || all[0].Name != "CV Sumber Teh" {
+			t.Errorf("GetAll = %+v", all)
+		}
+	})
+}
+
+func TestPurchaseOrderRepository(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		supplier := models.Supplier{Name: "CV Sumber Teh"}
+		if err := r.suppliers.Create(&supplier); err != nil {
+			t.Fatal(err)
+		}
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 2}
+		kopi := models.Product{Name: "Kopi", Price: 8000}
+		for _, p := range []*models.Product{&teh, &kopi} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		stock := func(id int) int {
+			p, _ := r.products.GetByID(id)
+			return p.Stock
+		}
+
+		po := models.PurchaseOrder{SupplierID: supplier.ID, Note: "restock", CreatedBy: repositories.ActorRef(0), Items: []models.PurchaseOrderItem{
+			{ProductID: kopi.ID, Quantity: 5, UnitCost: 6000},
+			{ProductID: teh.ID, Quantity: 10, UnitCost: 3000},
+		}}
+		if err := r.purchases.Create(&po); err != nil {
+			t.Fatal(err)
+		}
+		if po.Status != models.PurchaseOrderDraft || po.SupplierName != "CV Sumber Teh" || len(po.Items) != 2 || po.Items[0].ProductID != teh.ID || po.Items[0].RemainingQuantity != 10 {
+			t.Fatalf("Create = %+v", po)
+		}
+		bad := models.PurchaseOrder{SupplierID: supplier.ID, Items: []models.PurchaseOrderItem{{ProductID: 999, Quantity: 1}}}
+		if err := r.purchases.Create(&bad); !errors.Is(err, repositories.ErrForeignKey) {
+			t.Errorf("Create with missing product err = %v", err)
+		}
+
+		// Draft belum bisa diterima
+		receipt := []models.PurchaseReceipt{{ProductID: teh.ID, Quantity: 4, UnitCost: 3000}}
+		if err := r.purchases.Receive(po.ID, receipt, 0); !errors.Is(err, repositories.ErrPurchaseOrderState) {
+			t.Errorf("Receive draft err = %v", err)
+		}
+		if err := r.purchases.Approve(po.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.purchases.Approve(po.ID, 0); !errors.Is(err, repositories.ErrPurchaseOrderState) {
+			t.Errorf("second Approve err = %v", err)
+		}
+
+		// Penerimaan sebagian
+		if err := r.purchases.Receive(po.ID, receipt, 0); err != nil {
+			t.Fatal(err)
+		}
+		got, _ := r.purchases.GetByID(po.ID)
+		if got.Status != models.PurchaseOrderPartiallyReceived || got.ApprovedAt == nil || got.ClosedAt != nil || got.Items[0].ReceivedQuantity != 4 || len(got.Receipts) != 1 {
+			t.Errorf("after partial receipt = %+v", got)
+		}
+		if stock(teh.ID) != 6 {
+			t.Errorf("teh stock = %d, want 6", stock(teh.ID))
+		}
+
+		// Melebihi sisa atau produk di luar PO ditolak seluruhnya
+		for _, rc := range [][]models.PurchaseReceipt{
+			{{ProductID: kopi.ID, Quantity: 5, UnitCost: 6000}, {ProductID: teh.ID, Quantity: 7, UnitCost: 3000}},
+			{{ProductID: kopi.ID, Quantity: 5, UnitCost: 6000}, {ProductID: 999, Quantity: 1}},
+		} {
+			if err := r.purchases.Receive(po.ID, rc, 0); !errors.Is(err, repositories.ErrInvalidReceipt) {
+				t.Errorf("Receive %+v err = %v", rc, err)
+			}
+		}
+		if stock(kopi.ID) != 0 {
+			t.Errorf("kopi stock after rejected receipts = %d", stock(kopi.ID))
+		}
+
+		// Sisa diterima dengan harga beli berbeda
+		if err := r.purchases.Receive(po.ID, []models.PurchaseReceipt{{ProductID: teh.ID, Quantity: 6, UnitCost: 3200}, {ProductID: kopi.ID, Quantity: 5, UnitCost: 6000}}, 0); err != nil {
+			t.Fatal(err)
+		}
+		got, _ = r.purchases.GetByID(po.ID)
+		if got.Status != models.PurchaseOrderReceived || got.ClosedAt == nil || len(got.Receipts) != 3 || got.Receipts[1].ProductID != teh.ID || got.Receipts[1].UnitCost != 3200 {
+			t.Errorf("after full receipt = %+v", got)
+		}
+		if stock(teh.ID) != 12 || stock(kopi.ID) != 5 {
+			t.Errorf("stock teh = %d kopi = %d, want 12 and 5", stock(teh.ID), stock(kopi.ID))
+		}
+		if err := r.purchases.Cancel(po.ID, 0); !errors.Is(err, repositories.ErrPurchaseOrderState) {
+			t.Errorf("Cancel received err = %v", err)
+		}
+
+		// Penerimaan tercatat di ledger sebagai purchase dengan referensi PO
+		movements, _, _ := r.stock.GetByProduct(models.StockMovementFilter{ProductID: teh.ID, Type: models.StockPurchase, Page: 1, Limit: 10})
+		if len(movements) != 2 || movements[0].Quantity != 6 || movements[0].ReferenceID == nil || *movements[0].ReferenceID != po.ID {
+			t.Errorf("purchase movements = %+v", movements)
+		}
+		if _, discrepancies, _ := r.stock.CheckConsistency(); len(discrepancies) != 0 {
+			t.Errorf("discrepancies = %+v", discrepancies)
+		}
+		// Perubahan stock dan harga pokok juga masuk audit log
+		entries, total, _ := r.audit.GetAll(models.AuditFilter{Entity: models.AuditProduct, EntityID: teh.ID, Action: models.AuditUpdate, Page: 1, Limit: 10})
+		var before, after models.Product
+		if total != 2 || json.Unmarshal(entries[0].Before, &before) != nil || json.Unmarshal(entries[0].After, &after) != nil ||
+			before.Stock != 6 || before.CostPrice != 2000 || after.Stock != 12 || after.CostPrice != 2600 {
+			t.Errorf("receive audit = %+v", entries)
+		}
+
+		other := models.PurchaseOrder{SupplierID: supplier.ID, Items: []models.PurchaseOrderItem{{ProductID: teh.ID, Quantity: 1, UnitCost: 3000}}}
+		if err := r.purchases.Create(&other); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.purchases.Cancel(other.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+		list, _ := r.purchases.GetAll(models.PurchaseOrderFilter{Status: models.PurchaseOrderCancelled})
+		if len(list) != 1 || list[0].ID != other.ID {
+			t.Errorf("GetAll cancelled = %+v", list)
+		}
+		if list, _ := r.purchases.GetAll(models.PurchaseOrderFilter{SupplierID: supplier.ID}); len(list) != 2 || list[0].ID != other.ID {
+			t.Errorf("GetAll by supplier = %+v", list)
+		}
+	})
+}
+
+func TestAdjustStock(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 4}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+		p, err := r.products.AdjustStock(teh.ID, -3, 0, "pecah")
+		if err != nil || p.Stock != 1 || p.CategoryName == "" {
+			t.Fatalf("AdjustStock = %+v, %v", p, err)
+		}
+		var stockErr *repositories.InsufficientStockError
+		if _, err := r.products.AdjustStock(teh.ID, -2, 0, "pecah"); !errors.As(err, &stockErr) || stockErr.Available != 1 {
+			t.Errorf("AdjustStock below zero err = %v", err)
+		}
+		if _, err := r.products.AdjustStock(99, 1, 0, "x"); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("AdjustStock missing err = %v", err)
+		}
+		movements, total, _ := r.stock.GetByProduct(models.StockMovementFilter{ProductID: teh.ID, Page: 1, Limit: 10})
+		if total != 2 || movements[0].Quantity != -3 || movements[0].Note != "pecah" || movements[0].Type != models.StockAdjustment {
+			t.Errorf("movements = %+v", movements)
+		}
+		// Hanya penyesuaian yang berhasil yang tercatat di audit log
+		entries, total, _ := r.audit.GetAll(models.AuditFilter{Entity: models.AuditProduct, EntityID: teh.ID, Action: models.AuditUpdate, Page: 1, Limit: 10})
+		if total != 1 {
+			t.Fatalf("audit entries = %+v", entries)
+		}
+		var before, after models.Product
+		if json.Unmarshal(entries[0].Before, &before) != nil || json.Unmarshal(entries[0].After, &after) != nil || before.Stock != 4 || after.Stock != 1 {
+			t.Errorf("audit before/after = %s / %s", entries[0].Before, entries[0].After)
+		}
+	})
+}
+
+func TestGetLowStock(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		products := []models.Product{
+			{Name: "Teh", Price: 5000, Stock: 3, ReorderPoint: 5, ReorderQuantity: 20},
+			{Name: "Kopi", Price: 8000, Stock: 5, ReorderPoint: 5, ReorderQuantity: 10},
+			{Name: "Gula", Price: 2000, Stock: 6, ReorderPoint: 5},
+			{Name: "Air", Price: 3000, Stock: 0},
+		}
+		for i := range products {
+			if err := r.products.Create(&products[i], 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := r.products.GetByID(products[0].ID)
+		if err != nil || got.ReorderPoint != 5 || got.ReorderQuantity != 20 {
+			t.Fatalf("GetByID = %+v, %v", got, err)
+		}
+
+		low, err := r.products.GetLowStock()
+		if err != nil || len(low) != 2 || low[0].Name != "Teh" || low[1].Name != "Kopi" || low[0].CategoryName == "" {
+			t.Fatalf("GetLowStock = %+v, %v", low, err)
+		}
+
+		// Checkout membaca reorder point dari snapshot yang di-lock
+		var seen models.Product
+		capture := func(items []models.CheckoutItem, snapshot map[int]models.Product) (*models.Transaction, error) {
+			seen = snapshot[products[2].ID]
+			return repositories.DefaultPricing(items, snapshot)
+		}
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: products[2].ID, Quantity: 1}}, capture); err != nil {
+			t.Fatal(err)
+		}
+		if seen.ReorderPoint != 5 || seen.Stock != 6 {
+			t.Errorf("snapshot = %+v", seen)
+		}
+		if low, _ := r.products.GetLowStock(); len(low) != 3 {
+			t.Errorf("GetLowStock after checkout = %+v", low)
+		}
+
+		// Update yang hanya mengirim nama dan harga tidak menghapus pengaturan reorder
+		renamed := models.ProductUpdateRequest{Name: "Teh Celup", Price: 5500, Stock: 3, CategoryID: products[0].CategoryID}
+		if p, err := r.products.Update(products[0].ID, renamed, 0); err != nil || p.ReorderPoint != 5 || p.ReorderQuantity != 20 {
+			t.Errorf("update without reorder fields = %+v, %v", p, err)
+		}
+		if low, _ := r.products.GetLowStock(); len(low) != 3 {
+			t.Errorf("GetLowStock after rename = %+v", low)
+		}
+	})
+}
+
+// Stock yang diubah di luar aplikasi (langsung di database) terdeteksi oleh CheckConsistency
+func TestStockConsistencyDetectsDrift(t *testing.T) {
+	db := openSQLite(t)
+	products := repositories.NewProductRepository(db)
+	teh := models.Product{Name: "Teh", Price: 5000, Stock: 10}
+	if err := products.Create(&teh, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE products SET stock = 7 WHERE id = ?", teh.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	checked, discrepancies, err := repositories.NewStockMovementRepository(db).CheckConsistency()
+	want := []models.StockDiscrepancy{{ProductID: teh.ID, ProductName: "Teh", Stock: 7, LedgerStock: 10, Difference: -3}}
+	if err != nil || checked != 1 || !reflect.DeepEqual(discrepancies, want) {
+		t.Errorf("CheckConsistency = %d, %+v, %v; want %+v", checked, discrepancies, err, want)
+	}
+}
+
+// Baris transaksi dari sebelum harga pokok dicatat (unit_cost NULL) tidak diberi
+// HPP karangan, tapi dipisah sebagai unknown cost sales
+func TestProfitUnknownCost(t *testing.T) {
+	db := openSQLite(t)
+	products := repositories.NewProductRepository(db)
+	transactions := repositories.NewTransactionRepository(db)
+	teh := models.Product{Name: "Teh", Price: 5000, CostPrice: 3000, Stock: 10}
+	if err := products.Create(&teh, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, qty := range []int{1, 2} {
+		if _, err := transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: qty}}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec("UPDATE transaction_details SET unit_cost = NULL WHERE quantity = 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := transactions.GetProfitToday("")
+	want := models.ProfitLine{Quantity: 3, NetSales: 15000, COGS: 3000, UnknownCostSales: 10000}
+	if err != nil || len(lines) != 1 || lines[0] != want {
+		t.Errorf("GetProfitToday = %+v, %v; want %+v", lines, err, want)
+	}
+}
+
+func TestTransactionTax(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 5}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		// PPN 11% eksklusif: 2 x 5000 + 1100 pajak
+		withTax := func(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+			trx, err := repositories.DefaultPricing(items, products)
+			trx.Details[0].TaxAmount, trx.Details[0].Total = 1100, 11100
+			trx.TaxAmount, trx.TotalAmount = 1100, 11100
+			return trx, err
+		}
+		trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 2}}, withTax)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved, err := r.transactions.GetByID(trx.ID)
+		if err != nil || saved.Subtotal != 10000 || saved.TaxAmount != 1100 || saved.Details[0].Total != 11100 {
+			t.Fatalf("GetByID = %+v, %v", saved, err)
+		}
+
+		refund, err := r.transactions.CreateRefund(trx.ID, models.RefundTypePartial, "rusak", []models.RefundLine{{DetailID: saved.Details[0].ID, Quantity: 1}}, nil)
+		if err != nil || refund.Amount != 5550 || refund.Items[0].TaxAmount != 550 {
+			t.Fatalf("CreateRefund = %+v, %v", refund, err)
+		}
+		if saved, _ = r.transactions.GetByID(trx.ID); saved.RefundedTax != 550 || saved.Refunds[0].Items[0].TaxAmount != 550 {
+			t.Errorf("after refund = %+v", saved)
+		}
+
+		sales, err := r.transactions.GetSalesToday()
+		if err != nil || sales.TotalTax != 550 || sales.Subtotal != 10000 || sales.TotalRevenue != 5550 {
+			t.Errorf("GetSalesToday = %+v, %v", sales, err)
+		}
+	})
+}
+
+func TestProfitReport(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		c := models.Category{Name: "Minuman"}
+		if err := r.categories.Create(&c, 0); err != nil {
+			t.Fatal(err)
+		}
+		teh := models.Product{Name: "Teh", Price: 5000, CostPrice: 3000, Stock: 10, CategoryID: c.ID}
+		kopi := models.Product{Name: "Kopi", Price: 8000, CostPrice: 5000, Stock: 5}
+		for _, p := range []*models.Product{&teh, &kopi} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		supplier := models.Supplier{Name: "CV Sumber Teh"}
+		if err := r.suppliers.Create(&supplier); err != nil {
+			t.Fatal(err)
+		}
+
+		// 10 @ 3000 + 5 @ 3600 = rata-rata 3200
+		po := models.PurchaseOrder{SupplierID: supplier.ID, Items: []models.PurchaseOrderItem{{ProductID: teh.ID, Quantity: 5, UnitCost: 3600}}}
+		if err := r.purchases.Create(&po); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.purchases.Approve(po.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.purchases.Receive(po.ID, []models.PurchaseReceipt{{ProductID: teh.ID, Quantity: 5, UnitCost: 3600}}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := r.products.GetByID(teh.ID); got.CostPrice != 3200 || got.Stock != 15 {
+			t.Fatalf("after receipt = %+v", got)
+		}
+
+		// Teh 2 x 5000 + PPN 1100, satu di-refund; kopi di-void
+		withTax := func(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+			trx, err := repositories.DefaultPricing(items, products)
+			trx.Details[0].TaxAmount, trx.Details[0].Total = 1100, 11100
+			trx.TaxAmount, trx.TotalAmount = 1100, 11100
+			return trx, err
+		}
+		trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 2}}, withTax)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved, _ := r.transactions.GetByID(trx.ID)
+		if saved.Details[0].UnitCost != 3200 {
+			t.Errorf("unit cost = %d, want 3200", saved.Details[0].UnitCost)
+		}
+		if _, err := r.transactions.CreateRefund(trx.ID, models.RefundTypePartial, "rusak", []models.RefundLine{{DetailID: saved.Details[0].ID, Quantity: 1}}, nil); err != nil {
+			t.Fatal(err)
+		}
+		voided, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: kopi.ID, Quantity: 1}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.transactions.CreateRefund(voided.ID, models.RefundTypeVoid, "batal", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		// Harga pokok yang berubah setelah penjualan tidak mengubah COGS
+		teh.CostPrice, teh.Stock = 9000, 14
+		if _, err := r.products.Update(teh.ID, productUpdate(teh), 0); err != nil {
+			t.Fatal(err)
+		}
+		// Client lama yang tidak mengirim cost_price tidak mereset harga pokok
+		legacy := productUpdate(teh)
+		legacy.CostPrice = nil
+		if p, err := r.products.Update(teh.ID, legacy, 0); err != nil || p.CostPrice != 9000 {
+			t.Errorf("update without cost_price = %+v, %v", p, err)
+		}
+
+		today := time.Now().Format("2006-01-02")
+		want := models.ProfitLine{Quantity: 1, NetSales: 5000, COGS: 3200}
+		tests := []struct {
+			groupBy string
+			want    models.ProfitLine
+		}{
+			{"", want},
+			{models.ProfitGroupProduct, models.ProfitLine{ID: teh.ID, Name: "Teh", Quantity: 1, NetSales: 5000, COGS: 3200}},
+			{models.ProfitGroupCategory, models.ProfitLine{ID: c.ID, Name: "Minuman", Quantity: 1, NetSales: 5000, COGS: 3200}},
+			{models.ProfitGroupDay, models.ProfitLine{Period: today, Quantity: 1, NetSales: 5000, COGS: 3200}},
+			{models.ProfitGroupMonth, models.ProfitLine{Period: today[:7], Quantity: 1, NetSales: 5000, COGS: 3200}},
+		}
+		for _, tt := range tests {
+			lines, err := r.transactions.GetProfitInRange(today, today, tt.groupBy)
+			if err != nil || len(lines) != 1 || lines[0] != tt.want {
+				t.Errorf("GetProfitInRange(%q) = %+v, %v, want %+v", tt.groupBy, lines, err, tt.want)
+			}
+		}
+		if lines, err := r.transactions.GetProfitToday(""); err != nil || len(lines) != 1 || lines[0] != want {
+			t.Errorf("GetProfitToday = %+v, %v", lines, err)
+		}
+		if lines, err := r.transactions.GetProfitInRange("2000-01-01", "2000-01-31", ""); err != nil || len(lines) != 1 || lines[0] != (models.ProfitLine{}) {
+			t.Errorf("empty total = %+v, %v", lines, err)
+		}
+		if lines, err := r.transactions.GetProfitInRange("2000-01-01", "2000-01-31", models.ProfitGroupProduct); err != nil || len(lines) != 0 {
+			t.Errorf("empty by product = %+v, %v", lines, err)
+		}
+	})
+}
+
+func TestSentinelErrors(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		p := models.Product{Name: "Teh", Price: 5000, Stock: 5}
+		if err := r.products.Create(&p, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: p.ID, Quantity: 1}}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name string
+			run  func() error
+			want error
+		}{
+			{"product GetByID missing", func() error { _, err := r.products.GetByID(999); return err }, repositories.ErrNotFound},
+			{"product Update missing", func() error {
+				_, err := r.products.Update(999, models.ProductUpdateRequest{Name: "x", CategoryID: 1}, 0)
+				return err
+			}, repositories.ErrNotFound},
+			{"product Update unchanged values", func() error {
+				_, err := r.products.Update(p.ID, models.ProductUpdateRequest{Name: "Teh", Price: 5000, Stock: 4, CategoryID: 1}, 0)
+				return err
+			}, nil},
+			{"product Delete missing", func() error { return r.products.Delete(999, 0) }, repositories.ErrNotFound},
+			{"product with unknown category", func() error {
+				return r.products.Create(&models.Product{Name: "x", CategoryID: 999}, 0)
+			}, repositories.ErrForeignKey},
+			{"product referenced by transaction", func() error { return r.products.Delete(p.ID, 0) }, repositories.ErrForeignKey},
+			{"category GetByID missing", func() error { _, err := r.categories.GetByID(999); return err }, repositories.ErrNotFound},
+			{"category duplicate name", func() error {
+				return r.categories.Create(&models.Category{Name: "No Category"}, 0)
+			}, repositories.ErrDuplicate},
+			{"checkout unknown product", func() error {
+				_, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: 999, Quantity: 1}}, nil)
+				return err
+			}, repositories.ErrNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := tt.run()
+				if tt.want == nil && err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if !errors.Is(err, tt.want) {
+					t.Errorf("err = %v, want %v", err, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestTransactionHistory(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 100}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, qty := range []int{1, 2, 3} {
+			trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: qty}}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, trx.ID)
+		}
+
+		intPtr := func(v int) *int { return &v }
+		tests := []struct {
+			name      string
+			filter    models.TransactionFilter
+			wantIDs   []int
+			wantTotal int
+		}{
+			{"first page newest first", models.TransactionFilter{Page: 1, Limit: 2}, []int{ids[2], ids[1]}, 3},
+			{"second page", models.TransactionFilter{Page: 2, Limit: 2}, []int{ids[0]}, 3},
+			{"min total", models.TransactionFilter{Page: 1, Limit: 10, MinTotal: intPtr(10000)}, []int{ids[2], ids[1]}, 2},
+			{"max total", models.TransactionFilter{Page: 1, Limit: 10, MaxTotal: intPtr(5000)}, []int{ids[0]}, 1},
+			{"date range outside", models.TransactionFilter{Page: 1, Limit: 10, StartDate: "2000-01-01", EndDate: "2000-01-02"}, nil, 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, total, err := r.transactions.GetAll(tt.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if total != tt.wantTotal || len(got) != len(tt.wantIDs) {
+					t.Fatalf("got %d rows (total %d), want %v (total %d)", len(got), total, tt.wantIDs, tt.wantTotal)
+				}
+				for i, id := range tt.wantIDs {
+					if got[i].ID != id {
+						t.Errorf("row %d id = %d, want %d", i, got[i].ID, id)
+					}
+				}
+			})
+		}
+
+		trx, err := r.transactions.GetByID(ids[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if trx.TotalAmount != 10000 || len(trx.Details) != 1 || trx.Details[0].ProductName != "Teh" || trx.Details[0].ID == 0 || trx.CreatedAt.IsZero() {
+			t.Errorf("GetByID = %+v", trx)
+		}
+		if _, err := r.transactions.GetByID(999); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("GetByID(999) err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestRefundAmountHasNoRoundingLeftover(t *testing.T) {
+	// subtotal 10000 untuk 3 unit: 3333 + 3333 + 3334
+	total := 0
+	for refunded := 0; refunded < 3; refunded++ {
+		total += repositories.RefundAmount(10000, 3, refunded, 1)
+	}
+	if total != 10000 {
+		t.Errorf("sum of unit refunds = %d, want 10000", total)
+	}
+}
+
+func TestRefundCash(t *testing.T) {
+	tests := []struct {
+		name                                string
+		cashPaid, totalPaid, before, amount int
+		want                                int
+	}{
+		{"cash only", 10000, 10000, 0, 4000, 4000},
+		{"card only", 0, 10000, 0, 4000, 0},
+		{"split payment is proportional", 6000, 10000, 0, 5000, 3000},
+		{"last refund takes remaining cash", 6000, 10000, 5000, 5000, 3000},
+		{"no payment data is cash", 0, 0, 0, 4000, 4000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repositories.RefundCash(tt.cashPaid, tt.totalPaid, tt.before, tt.amount); got != tt.want {
+				t.Errorf("RefundCash = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateRefund(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 10}
+		kopi := models.Product{Name: "Kopi", Price: 8000, Stock: 10}
+		for _, p := range []*models.Product{&teh, &kopi} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+		sale, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 4}, {ProductID: kopi.ID, Quantity: 1}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		receipt, _ := r.transactions.GetByID(sale.ID)
+		tehLine, kopiLine := receipt.Details[0].ID, receipt.Details[1].ID
+
+		stock := func(id int) int {
+			p, err := r.products.GetByID(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return p.Stock
+		}
+
+		// Refund sebagian: 3 teh
+		refund, err := r.transactions.CreateRefund(sale.ID, models.RefundTypePartial, "salah input", []models.RefundLine{{DetailID: tehLine, Quantity: 3}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if refund.Amount != 15000 || len(refund.Items) != 1 || refund.CreatedAt.IsZero() {
+			t.Errorf("refund = %+v", refund)
+		}
+		if got := stock(teh.ID); got != 9 {
+			t.Errorf("teh stock after refund = %d, want 9", got)
+		}
+		sales, _ := r.transactions.GetSalesToday()
+		if sales.TotalRevenue != 13000 || sales.TotalTransaksi != 1 || sales.TotalRefund != 15000 {
+			t.Errorf("GetSalesToday after partial refund = %+v, want 13000/1", sales)
+		}
+		// teh tersisa 1 dan kopi 1; urutan tie tidak dijamin di SQL, jadi cukup cek qty
+		if name, qty, _ := r.transactions.GetTopProductToday(); qty != 1 {
+			t.Errorf("GetTopProductToday = %s/%d, want qty 1", name, qty)
+		}
+
+		invalid := []struct {
+			name  string
+			lines []models.RefundLine
+		}{
+			{"more than remaining", []models.RefundLine{{DetailID: tehLine, Quantity: 2}}},
+			{"unknown detail", []models.RefundLine{{DetailID: 999, Quantity: 1}}},
+			{"non positive quantity", []models.RefundLine{{DetailID: kopiLine, Quantity: 0}}},
+		}
+		for _, tt := range invalid {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := r.transactions.CreateRefund(sale.ID, models.RefundTypePartial, "x", tt.lines, nil)
+				if !errors.Is(err, repositories.ErrInvalidRefund) {
+					t.Errorf("err = %v, want ErrInvalidRefund", err)
+				}
+			})
+		}
+
+		// Void sisa transaksi
+		void, err := r.transactions.CreateRefund(sale.ID, models.RefundTypeVoid, "pelanggan batal", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if void.Amount != 13000 || len(void.Items) != 2 {
+			t.Errorf("void = %+v", void)
+		}
+		if stock(teh.ID) != 10 || stock(kopi.ID) != 10 {
+			t.Errorf("stock after void = %d/%d, want 10/10", stock(teh.ID), stock(kopi.ID))
+		}
+		sales, _ = r.transactions.GetSalesToday()
+		if sales.TotalRevenue != 0 || sales.TotalTransaksi != 0 {
+			t.Errorf("GetSalesToday after void = %+v, want 0/0", sales)
+		}
+		if name, _, _ := r.transactions.GetTopProductToday(); name != "" {
+			t.Errorf("GetTopProductToday after void = %q, want none", name)
+		}
+
+		got, err := r.transactions.GetByID(sale.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != models.TransactionVoided || got.RefundedAmount != 28000 || len(got.Refunds) != 2 || len(got.Refunds[1].Items) != 2 {
+			t.Errorf("transaction after void = %+v", got)
+		}
+
+		if _, err := r.transactions.CreateRefund(sale.ID, models.RefundTypeVoid, "lagi", nil, nil); !errors.Is(err, repositories.ErrTransactionVoided) {
+			t.Errorf("second void err = %v, want ErrTransactionVoided", err)
+		}
+		if _, err := r.transactions.CreateRefund(999, models.RefundTypeVoid, "x", nil, nil); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("void missing err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// TestConcurrentCheckoutNeverOversells: banyak checkout paralel untuk produk
+// yang sama, stock tidak boleh negatif dan jumlah yang sukses harus tepat sama
+// dengan stock awal
+func TestConcurrentCheckoutNeverOversells(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		const initialStock, buyers = 10, 40
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: initialStock}
+		kopi := models.Product{Name: "Kopi", Price: 8000, Stock: initialStock * buyers}
+		for _, p := range []*models.Product{&teh, &kopi} {
+			if err := r.products.Create(p, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		succeeded, shortages := 0, 0
+		for i := 0; i < buyers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// Urutan item dibalik di setengah goroutine untuk memancing deadlock kalau lock ordering salah
+				items := []models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}, {ProductID: kopi.ID, Quantity: 1}}
+				if i%2 == 1 {
+					items[0], items[1] = items[1], items[0]
+				}
+				_, err := r.transactions.CreateTransaction(items, nil)
+
+				mu.Lock()
+				defer mu.Unlock()
+				var stockErr *repositories.InsufficientStockError
+				switch {
+				case err == nil:
+					succeeded++
+				case errors.As(err, &stockErr):
+					shortages++
+				default:
+					t.Errorf("unexpected checkout error: %v", err)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		got, err := r.products.GetByID(teh.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Stock < 0 {
+			t.Fatalf("stock went negative: %d", got.Stock)
+		}
+		if succeeded != initialStock || got.Stock != 0 || shortages != buyers-initialStock {
+			t.Errorf("succeeded=%d shortages=%d stock=%d, want %d/%d/0", succeeded, shortages, got.Stock, initialStock, buyers-initialStock)
+		}
+
+		kopiAfter, _ := r.products.GetByID(kopi.ID)
+		if kopiAfter.Stock != initialStock*buyers-succeeded {
+			t.Errorf("kopi stock = %d, want %d (failed checkouts must roll back)", kopiAfter.Stock, initialStock*buyers-succeeded)
+		}
+	})
+}
+
+func TestConcurrentRefundAndCheckout(t *testing.T) {
+	backends(t, func(t *testing.T, r repoSet) {
+		const sales = 10
+		teh := models.Product{Name: "Teh", Price: 5000, Stock: 2 * sales}
+		if err := r.products.Create(&teh, 0); err != nil {
+			t.Fatal(err)
+		}
+		var sold []*models.Transaction
+		for i := 0; i < sales; i++ {
+			trx, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sold = append(sold, trx)
+		}
+
+		// Void dan checkout produk yang sama berjalan bersamaan
+		var wg sync.WaitGroup
+		for _, trx := range sold {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if _, err := r.transactions.CreateRefund(trx.ID, models.RefundTypeVoid, "batal", nil, nil); err != nil {
+					t.Errorf("void %d: %v", trx.ID, err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				if _, err := r.transactions.CreateTransaction([]models.CheckoutItem{{ProductID: teh.ID, Quantity: 1}}, nil); err != nil {
+					t.Errorf("checkout: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got, _ := r.products.GetByID(teh.ID); got.Stock != sales {
+			t.Errorf("stock = %d, want %d", got.Stock, sales)
+		}
+		if _, discrepancies, _ := r.stock.CheckConsistency(); len(discrepancies) != 0 {
+			t.Errorf("discrepancies = %+v", discrepancies)
+		}
+	})
+}
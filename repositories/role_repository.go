@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"database/sql"
+	"slices"
+	"sort"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlRoleRepository adalah implementasi RoleRepository berbasis database/sql (MySQL atau SQLite)
+type sqlRoleRepository struct {
+	db *database.DB
+}
+
+func NewRoleRepository(db *database.DB) RoleRepository {
+	return &sqlRoleRepository{db: db}
+}
+
+// queryRoles membaca role hasil query (id, name) lalu mengisi permission-nya
+func (r *sqlRoleRepository) queryRoles(query string, args ...interface{}) ([]models.Role, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var roles []models.Role
+	for rows.Next() {
+		role := models.Role{Permissions: []string{}}
+		if err := rows.Scan(&role.ID, &role.Name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range roles {
+		perms, err := r.db.Query("SELECT permission FROM role_permissions WHERE role_id = ? ORDER BY permission", roles[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		for perms.Next() {
+			var p string
+			if err := perms.Scan(&p); err != nil {
+				perms.Close()
+				return nil, err
+			}
+			roles[i].Permissions = append(roles[i].Permissions, p)
+		}
+		perms.Close()
+		if err := perms.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return roles, nil
+}
+
+func (r *sqlRoleRepository) GetAll() ([]models.Role, error) {
+	return r.queryRoles("SELECT id, name FROM roles ORDER BY id")
+}
+
+func (r *sqlRoleRepository) GetByID(id int) (*models.Role, error) {
+	roles, err := r.queryRoles("SELECT id, name FROM roles WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, ErrNotFound
+	}
+	return &roles[0], nil
+}
+
+func (r *sqlRoleRepository) SetPermissions(roleID int, permissions []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT id FROM roles WHERE id = ?"+r.db.Dialect.ForUpdate(), roleID).Scan(&exists); err != nil {
+		return translateError(err)
+	}
+	if _, err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", roleID); err != nil {
+		return err
+	}
+	for _, p := range permissions {
+		if _, err := tx.Exec("INSERT INTO role_permissions (role_id, permission) VALUES (?, ?)", roleID, p); err != nil {
+			return translateError(err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *sqlRoleRepository) GetUserRoles(userID int) ([]models.Role, error) {
+	return r.queryRoles("SELECT r.id, r.name FROM roles r JOIN user_roles ur ON ur.role_id = r.id WHERE ur.user_id = ? ORDER BY r.id", userID)
+}
+
+// checkNotLastOwner dipanggil di dalam transaksi sebelum user kehilangan status
+// owner aktif. Baris owner aktif di-lock (urut ID) sampai commit, jadi dua request
+// yang mencabut dua owner berbeda tidak bisa sama-sama lolos.
+func checkNotLastOwner(tx *sql.Tx, dialect database.Dialect, userID int) error {
+	rows, err := tx.Query(`SELECT u.id FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id JOIN roles r ON r.id = ur.role_id
+		WHERE r.name = ? AND u.active = ? ORDER BY u.id`+dialect.ForUpdate(), models.RoleOwner, true)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var owners []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		owners = append(owners, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(owners) == 1 && owners[0] == userID {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func (r *sqlRoleRepository) SetUserRoles(userID int, roleIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var ownerID int
+	if err := tx.QueryRow("SELECT id FROM roles WHERE name = ?", models.RoleOwner).Scan(&ownerID); err != nil {
+		return err
+	}
+	if !slices.Contains(roleIDs, ownerID) {
+		if err := checkNotLastOwner(tx, r.db.Dialect, userID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM user_roles WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+	for _, roleID := range roleIDs {
+		if _, err := tx.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)", userID, roleID); err != nil {
+			return translateError(err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *sqlRoleRepository) CountUsersWithRole(roleID int) (int, error) {
+	var n int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM user_roles WHERE role_id = ?", roleID).Scan(&n)
+	return n, err
+}
+
+func (r *sqlRoleRepository) UserPermissions(userID int) ([]string, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT rp.permission FROM role_permissions rp
+		JOIN user_roles ur ON ur.role_id = rp.role_id WHERE ur.user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perms := []string{}
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		perms = append(perms, p)
+	}
+	sort.Strings(perms)
+	return perms, rows.Err()
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"time"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlSessionRepository adalah implementasi SessionRepository berbasis database/sql (MySQL atau SQLite)
+type sqlSessionRepository struct {
+	db *database.DB
+}
+
+func NewSessionRepository(db *database.DB) SessionRepository {
+	return &sqlSessionRepository{db: db}
+}
+
+func (r *sqlSessionRepository) Create(s *models.Session) error {
+	_, err := r.db.Exec("INSERT INTO sessions (token_hash, user_id, expires_at) VALUES (?, ?, ?)",
+		s.TokenHash, s.UserID, s.ExpiresAt.Unix())
+	return translateError(err)
+}
+
+func (r *sqlSessionRepository) GetByTokenHash(tokenHash string) (*models.Session, error) {
+	s := models.Session{TokenHash: tokenHash}
+	var expiresAt int64
+	err := r.db.QueryRow("SELECT user_id, expires_at FROM sessions WHERE token_hash = ?", tokenHash).Scan(&s.UserID, &expiresAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	s.ExpiresAt = time.Unix(expiresAt, 0)
+	return &s, nil
+}
+
+func (r *sqlSessionRepository) Delete(tokenHash string) error {
+	result, err := r.db.Exec("DELETE FROM sessions WHERE token_hash = ?", tokenHash)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *sqlSessionRepository) DeleteByUser(userID int) error {
+	_, err := r.db.Exec("DELETE FROM sessions WHERE user_id = ?", userID)
+	return err
+}
+
+func (r *sqlSessionRepository) DeleteExpired(now time.Time) (int, error) {
+	result, err := r.db.Exec("DELETE FROM sessions WHERE expires_at < ?", now.Unix())
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
@@ -0,0 +1,11 @@
+package repositories
+
+import "kasir-api-golang-v1/models"
+
+// ExpectedCash adalah uang yang seharusnya ada di laci: modal awal ditambah
+// pembayaran cash (sudah dikurangi kembalian) dikurangi refund tunai. Refund
+// transaksi card/QRIS/e-wallet tidak keluar dari laci.
+// Dipakai implementasi SQL dan in-memory saat Close, dan service untuk shift open.
+func ExpectedCash(openingCash int, summary models.ShiftSummary) int {
+	return openingCash + summary.SalesByMethod[models.PaymentCash] - summary.CashRefundTotal
+}
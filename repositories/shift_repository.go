@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlShiftRepository adalah implementasi ShiftRepository berbasis database/sql (MySQL atau SQLite)
+type sqlShiftRepository struct {
+	db *database.DB
+}
+
+func NewShiftRepository(db *database.DB) ShiftRepository {
+	return &sqlShiftRepository{db: db}
+}
+
+const shiftSelect = `SELECT s.id, s.user_id, u.username, s.status, s.opening_cash, s.expected_cash, s.counted_cash, s.note, s.opened_at, s.closed_at
+	FROM shifts s JOIN users u ON u.id = s.user_id`
+
+// nullInt mengubah kolom INT NULL menjadi *int
+func nullInt(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
+func scanShift(row rowScanner) (*models.Shift, error) {
+	var s models.Shift
+	var expected, counted sql.NullInt64
+	var closedAt sql.NullTime
+	if err := row.Scan(&s.ID, &s.UserID, &s.Username, &s.Status, &s.OpeningCash, &expected, &counted, &s.Note, &s.OpenedAt, &closedAt); err != nil {
+		return nil, err
+	}
+	s.ExpectedCash, s.CountedCash = nullInt(expected), nullInt(counted)
+	if closedAt.Valid {
+		s.ClosedAt = &closedAt.Time
+	}
+	return &s, nil
+}
+
+func (r *sqlShiftRepository) GetAll(filter models.ShiftFilter) ([]models.Shift, error) {
+	var conds []string
+	var args []interface{}
+	if filter.UserID != 0 {
+		conds = append(conds, "s.user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Status != "" {
+		conds = append(conds, "s.status = ?")
+		args = append(args, filter.Status)
+	}
+	query := shiftSelect
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := r.db.Query(query+" ORDER BY s.id DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []models.Shift{}
+	for rows.Next() {
+		s, err := scanShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, *s)
+	}
+	return shifts, rows.Err()
+}
+
+func (r *sqlShiftRepository) GetByID(id int) (*models.Shift, error) {
+	s, err := scanShift(r.db.QueryRow(shiftSelect+" WHERE s.id = ?", id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s, nil
+}
+
+func (r *sqlShiftRepository) GetOpenByUser(userID int) (*models.Shift, error) {
+	s, err := scanShift(r.db.QueryRow(shiftSelect+" WHERE s.user_id = ? AND s.status = ?", userID, models.ShiftOpen))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s, nil
+}
+
+func (r *sqlShiftRepository) Open(shift *models.Shift) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock baris user supaya dua request open bersamaan tidak membuat dua shift open
+	var userID int
+	err = tx.QueryRow("SELECT id FROM users WHERE id = ?"+r.db.Dialect.ForUpdate(), shift.UserID).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrForeignKey
+	}
+	if err != nil {
+		return err
+	}
+	var open int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM shifts WHERE user_id = ? AND status = ?", shift.UserID, models.ShiftOpen).Scan(&open); err != nil {
+		return err
+	}
+	if open > 0 {
+		return ErrShiftAlreadyOpen
+	}
+
+	result, err := tx.Exec("INSERT INTO shifts (user_id, status, opening_cash, note) VALUES (?, ?, ?, ?)",
+		shift.UserID, models.ShiftOpen, shift.OpeningCash, shift.Note)
+	if err != nil {
+		return translateError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	saved, err := r.GetByID(int(id))
+	if err != nil {
+		return err
+	}
+	*shift = *saved
+	return nil
+}
+
+// shiftSummary dipakai Summary dan Close (di dalam DB transaction)
+func shiftSummary(q querier, shiftID int) (models.ShiftSummary, error) {
+	summary := models.ShiftSummary{SalesByMethod: map[string]int{}}
+	if err := q.QueryRow("SELECT COUNT(*) FROM transactions WHERE shift_id = ?", shiftID).Scan(&summary.TransactionCount); err != nil {
+		return summary, err
+	}
+	err := q.QueryRow("SELECT COUNT(*), COALESCE(SUM(amount), 0), COALESCE(SUM(cash_amount), 0) FROM refunds WHERE shift_id = ?", shiftID).
+		Scan(&summary.RefundCount, &summary.RefundTotal, &summary.CashRefundTotal)
+	if err != nil {
+		return summary, err
+	}
+
+	// Transaksi yang kemudian di-void tetap dihitung: uangnya sudah masuk, void-nya tercatat sebagai refund
+	rows, err := q.Query(`
+		SELECT py.method, SUM(py.amount)
+		FROM payments py
+		JOIN transactions t ON py.transaction_id = t.id
+		WHERE t.shift_id = ?
+		GROUP BY py.method`, shiftID)
+	if err != nil {
+		return summary, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var method string
+		var amount int
+		if err := rows.Scan(&method, &amount); err != nil {
+			return summary, err
+		}
+		summary.SalesByMethod[method] = amount
+	}
+	return summary, rows.Err()
+}
+
+func (r *sqlShiftRepository) Summary(shiftID int) (models.ShiftSummary, error) {
+	return shiftSummary(r.db, shiftID)
+}
+
+func (r *sqlShiftRepository) Close(id, countedCash int, note string) (*models.Shift, models.ShiftSummary, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, models.ShiftSummary{}, err
+	}
+	defer tx.Rollback()
+
+	// Lock shift dulu: checkout yang sedang berjalan di shift ini ditunggu selesai
+	var status string
+	var openingCash int
+	err = tx.QueryRow("SELECT status, opening_cash FROM shifts WHERE id = ?"+r.db.Dialect.ForUpdate(), id).Scan(&status, &openingCash)
+	if err != nil {
+		return nil, models.ShiftSummary{}, translateError(err)
+	}
+	if status != models.ShiftOpen {
+		return nil, models.ShiftSummary{}, ErrShiftClosed
+	}
+
+	summary, err := shiftSummary(tx, id)
+	if err != nil {
+		return nil, models.ShiftSummary{}, err
+	}
+	_, err = tx.Exec("UPDATE shifts SET status = ?, expected_cash = ?, counted_cash = ?, note = CASE WHEN ? = '' THEN note ELSE ? END, closed_at = ? WHERE id = ?",
+		models.ShiftClosed, ExpectedCash(openingCash, summary), countedCash, note, note, time.Now().UTC(), id)
+	if err != nil {
+		return nil, models.ShiftSummary{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, models.ShiftSummary{}, err
+	}
+
+	shift, err := r.GetByID(id)
+	if err != nil {
+		return nil, models.ShiftSummary{}, err
+	}
+	return shift, summary, nil
+}
@@ -0,0 +1,282 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlStockCountRepository adalah implementasi StockCountRepository berbasis database/sql (MySQL atau SQLite)
+type sqlStockCountRepository struct {
+	db *database.DB
+}
+
+func NewStockCountRepository(db *database.DB) StockCountRepository {
+	return &sqlStockCountRepository{db: db}
+}
+
+const stockCountColumns = "id, status, note, created_by, created_at, closed_by, closed_at"
+
+// stockCountNote adalah keterangan movement adjustment hasil finalize
+const stockCountNote = "stock opname"
+
+func scanStockCount(row rowScanner) (*models.StockCount, error) {
+	var c models.StockCount
+	var createdBy, closedBy sql.NullInt64
+	var closedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.Status, &c.Note, &createdBy, &c.CreatedAt, &closedBy, &closedAt); err != nil {
+		return nil, err
+	}
+	c.CreatedBy, c.ClosedBy = nullInt(createdBy), nullInt(closedBy)
+	if closedAt.Valid {
+		c.ClosedAt = &closedAt.Time
+	}
+	c.Items = []models.StockCountItem{}
+	return &c, nil
+}
+
+// loadStockCountItems mengisi Items (beserta nama dan harga produk) untuk sesi-sesi yang sudah di-load
+func loadStockCountItems(q querier, counts []models.StockCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(counts))
+	placeholders := make([]string, len(counts))
+	args := make([]interface{}, len(counts))
+	for i, c := range counts {
+		index[c.ID] = i
+		placeholders[i] = "?"
+		args[i] = c.ID
+	}
+
+	rows, err := q.Query(`SELECT i.stock_count_id, i.product_id, p.name, p.price, i.system_stock, i.counted_quantity, i.counted_by, i.counted_at
+		FROM stock_count_items i JOIN products p ON p.id = i.product_id
+		WHERE i.stock_count_id IN (`+strings.Join(placeholders, ", ")+") ORDER BY i.product_id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var countID int
+		var countedBy sql.NullInt64
+		var it models.StockCountItem
+		if err := rows.Scan(&countID, &it.ProductID, &it.ProductName, &it.Price, &it.SystemStock, &it.CountedQuantity, &countedBy, &it.CountedAt); err != nil {
+			return err
+		}
+		it.CountedBy = nullInt(countedBy)
+		it.Variance = it.CountedQuantity - it.SystemStock
+		c := &counts[index[countID]]
+		c.Items = append(c.Items, it)
+	}
+	return rows.Err()
+}
+
+func (r *sqlStockCountRepository) GetAll(status string) ([]models.StockCount, error) {
+	query := "SELECT " + stockCountColumns + " FROM stock_counts"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	rows, err := r.db.Query(query+" ORDER BY id DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]models.StockCount, 0)
+	for rows.Next() {
+		c, err := scanStockCount(rows)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, loadStockCountItems(r.db, counts)
+}
+
+func (r *sqlStockCountRepository) GetByID(id int) (*models.StockCount, error) {
+	c, err := scanStockCount(r.db.QueryRow("SELECT "+stockCountColumns+" FROM stock_counts WHERE id = ?", id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	counts := []models.StockCount{*c}
+	if err := loadStockCountItems(r.db, counts); err != nil {
+		return nil, err
+	}
+	return &counts[0], nil
+}
+
+func (r *sqlStockCountRepository) Create(count *models.StockCount) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Hanya boleh ada satu sesi open: dua sesi yang menghitung produk yang sama
+	// akan menerapkan selisihnya dua kali. Di MySQL FOR UPDATE pada index status
+	// juga mengunci gap-nya, jadi dua Create bersamaan tetap berurutan.
+	var openID int
+	err = tx.QueryRow("SELECT id FROM stock_counts WHERE status = ? LIMIT 1"+r.db.Dialect.ForUpdate(), models.StockCountOpen).Scan(&openID)
+	if err == nil {
+		return ErrStockCountOpen
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	result, err := tx.Exec("INSERT INTO stock_counts (status, note, created_by) VALUES (?, ?, ?)", models.StockCountOpen, count.Note, count.CreatedBy)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	saved, err := r.GetByID(int(id))
+	if err != nil {
+		return err
+	}
+	*count = *saved
+	return nil
+}
+
+// lockOpenStockCount mengunci sesi dan memastikan statusnya masih open
+func (r *sqlStockCountRepository) lockOpenStockCount(tx *sql.Tx, id int) error {
+	var status string
+	if err := tx.QueryRow("SELECT status FROM stock_counts WHERE id = ?"+r.db.Dialect.ForUpdate(), id).Scan(&status); err != nil {
+		return translateError(err)
+	}
+	if status != models.StockCountOpen {
+		return ErrStockCountClosed
+	}
+	return nil
+}
+
+func (r *sqlStockCountRepository) SubmitItems(id int, items []models.StockCountItem, actorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockOpenStockCount(tx, id); err != nil {
+		return err
+	}
+	for _, it := range items {
+		// Barang yang di-reserve cart masih ada di rak, jadi ikut dihitung sebagai
+		// system stock. Lock produk supaya reservasi tidak berubah di tengah snapshot.
+		var stock int
+		err := tx.QueryRow(`SELECT p.stock + IFNULL((SELECT SUM(ci.quantity) FROM cart_items ci JOIN carts c ON c.id = ci.cart_id
+			WHERE ci.product_id = p.id AND c.status = ? AND c.reserved_until IS NOT NULL), 0)
+			FROM products p WHERE p.id = ?`+r.db.Dialect.ForUpdate(), models.CartOpen, it.ProductID).Scan(&stock)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrForeignKey
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM stock_count_items WHERE stock_count_id = ? AND product_id = ?", id, it.ProductID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO stock_count_items (stock_count_id, product_id, system_stock, counted_quantity, counted_by) VALUES (?, ?, ?, ?, ?)",
+			id, it.ProductID, stock, it.CountedQuantity, ActorRef(actorID)); err != nil {
+			return translateError(err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *sqlStockCountRepository) Finalize(id, actorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockOpenStockCount(tx, id); err != nil {
+		return err
+	}
+
+	// Lock produk yang dihitung, urut product ID seperti checkout supaya tidak deadlock
+	rows, err := tx.Query(`SELECT `+productColumns+`, i.counted_quantity - i.system_stock
+		FROM stock_count_items i JOIN products p ON p.id = i.product_id
+		WHERE i.stock_count_id = ? ORDER BY p.id`+r.db.Dialect.ForUpdate(), id)
+	if err != nil {
+		return err
+	}
+	type adjustment struct {
+		product  models.Product
+		variance int
+	}
+	var adjustments []adjustment
+	for rows.Next() {
+		var a adjustment
+		if err := rows.Scan(append(productDest(&a.product), &a.variance)...); err != nil {
+			rows.Close()
+			return err
+		}
+		if a.variance != 0 {
+			adjustments = append(adjustments, a)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	countID := id
+	for _, a := range adjustments {
+		// Variance ditambahkan ke stock saat ini, jadi penjualan setelah produk dihitung tetap terhitung
+		if a.product.Stock+a.variance < 0 {
+			return &InsufficientStockError{ProductID: a.product.ID, ProductName: a.product.Name, Available: a.product.Stock, Requested: -a.variance}
+		}
+		if _, err := tx.Exec("UPDATE products SET stock = stock + ? WHERE id = ?", a.variance, a.product.ID); err != nil {
+			return err
+		}
+		if err := writeStockMovement(tx, NewStockMovement(a.product.ID, models.StockAdjustment, a.variance, &countID, actorID, stockCountNote)); err != nil {
+			return err
+		}
+		after := a.product
+		after.Stock += a.variance
+		if err := writeAudit(tx, actorID, models.AuditProduct, a.product.ID, models.AuditUpdate, auditProduct(a.product), auditProduct(after)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE stock_counts SET status = ?, closed_by = ?, closed_at = ? WHERE id = ?",
+		models.StockCountFinalized, ActorRef(actorID), time.Now().UTC(), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqlStockCountRepository) Cancel(id, actorID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockOpenStockCount(tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE stock_counts SET status = ?, closed_by = ?, closed_at = ? WHERE id = ?",
+		models.StockCountCancelled, ActorRef(actorID), time.Now().UTC(), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import "kasir-api-golang-v1/models"
+
+// NewStockMovement menyusun satu baris ledger stock. actorID 0 berarti
+// perubahan dari sistem. Dipakai oleh implementasi SQL dan in-memory supaya
+// isi ledger-nya sama.
+func NewStockMovement(productID int, movementType string, quantity int, referenceID *int, actorID int, note string) models.StockMovement {
+	return models.StockMovement{
+		ProductID:   productID,
+		Type:        movementType,
+		Quantity:    quantity,
+		ReferenceID: referenceID,
+		ActorID:     ActorRef(actorID),
+		Note:        note,
+	}
+}
+
+// writeStockMovement menulis ledger di dalam DB transaction yang mengubah
+// products.stock, jadi stock dan ledger-nya selalu tersimpan atau batal bersama
+func writeStockMovement(tx execer, m models.StockMovement) error {
+	_, err := tx.Exec("INSERT INTO stock_movements (product_id, type, quantity, reference_id, actor_id, note) VALUES (?, ?, ?, ?, ?, ?)",
+		m.ProductID, m.Type, m.Quantity, m.ReferenceID, m.ActorID, m.Note)
+	return err
+}
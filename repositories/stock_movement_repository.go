@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlStockMovementRepository membaca stock_movements; penulisannya dilakukan
+// repository lain di dalam DB transaction masing-masing (lihat writeStockMovement)
+type sqlStockMovementRepository struct {
+	db *database.DB
+}
+
+func NewStockMovementRepository(db *database.DB) StockMovementRepository {
+	return &sqlStockMovementRepository{db: db}
+}
+
+func (r *sqlStockMovementRepository) GetByProduct(filter models.StockMovementFilter) ([]models.StockMovement, int, error) {
+	conds := []string{"m.product_id = ?"}
+	args := []interface{}{filter.ProductID}
+	if filter.Type != "" {
+		conds = append(conds, "m.type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.StartDate != "" {
+		conds = append(conds, r.db.Dialect.DateOf("m.created_at")+" >= ?")
+		args = append(args, filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		conds = append(conds, r.db.Dialect.DateOf("m.created_at")+" <= ?")
+		args = append(args, filter.EndDate)
+	}
+	where := " WHERE " + strings.Join(conds, " AND ")
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM stock_movements m"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT m.id, m.product_id, m.type, m.quantity, m.reference_id, m.actor_id, IFNULL(u.username, ''), m.note, m.created_at
+		FROM stock_movements m LEFT JOIN users u ON u.id = m.actor_id` + where + " ORDER BY m.id DESC LIMIT ? OFFSET ?"
+	rows, err := r.db.Query(query, append(args, filter.Limit, (filter.Page-1)*filter.Limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := make([]models.StockMovement, 0)
+	for rows.Next() {
+		var m models.StockMovement
+		var referenceID, actorID sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.Type, &m.Quantity, &referenceID, &actorID, &m.ActorUsername, &m.Note, &m.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		m.ReferenceID = nullInt(referenceID)
+		m.ActorID = nullInt(actorID)
+		movements = append(movements, m)
+	}
+	return movements, total, rows.Err()
+}
+
+func (r *sqlStockMovementRepository) CheckConsistency() (int, []models.StockDiscrepancy, error) {
+	var checked int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM products").Scan(&checked); err != nil {
+		return 0, nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT p.id, p.name, p.stock, IFNULL(SUM(m.quantity), 0) AS ledger
+		FROM products p LEFT JOIN stock_movements m ON m.product_id = p.id
+		GROUP BY p.id, p.name, p.stock
+		HAVING p.stock <> IFNULL(SUM(m.quantity), 0)
+		ORDER BY p.id`)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	discrepancies := make([]models.StockDiscrepancy, 0)
+	for rows.Next() {
+		var d models.StockDiscrepancy
+		if err := rows.Scan(&d.ProductID, &d.ProductName, &d.Stock, &d.LedgerStock); err != nil {
+			return 0, nil, err
+		}
+		d.Difference = d.Stock - d.LedgerStock
+		discrepancies = append(discrepancies, d)
+	}
+	return checked, discrepancies, rows.Err()
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlSupplierRepository adalah implementasi SupplierRepository berbasis database/sql (MySQL atau SQLite)
+type sqlSupplierRepository struct {
+	db *database.DB
+}
+
+func NewSupplierRepository(db *database.DB) SupplierRepository {
+	return &sqlSupplierRepository{db: db}
+}
+
+const supplierColumns = "id, name, phone, email, address, created_at"
+
+func scanSupplier(row rowScanner) (*models.Supplier, error) {
+	var s models.Supplier
+	if err := row.Scan(&s.ID, &s.Name, &s.Phone, &s.Email, &s.Address, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sqlSupplierRepository) GetAll() ([]models.Supplier, error) {
+	rows, err := r.db.Query("SELECT " + supplierColumns + " FROM suppliers ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]models.Supplier, 0)
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, *s)
+	}
+	return suppliers, rows.Err()
+}
+
+func (r *sqlSupplierRepository) GetByID(id int) (*models.Supplier, error) {
+	s, err := scanSupplier(r.db.QueryRow("SELECT "+supplierColumns+" FROM suppliers WHERE id = ?", id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return s, nil
+}
+
+func (r *sqlSupplierRepository) Create(s *models.Supplier) error {
+	result, err := r.db.Exec("INSERT INTO suppliers (name, phone, email, address) VALUES (?, ?, ?, ?)", s.Name, s.Phone, s.Email, s.Address)
+	if err != nil {
+		return translateError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	saved, err := r.GetByID(int(id))
+	if err != nil {
+		return err
+	}
+	*s = *saved
+	return nil
+}
+
+func (r *sqlSupplierRepository) Update(s *models.Supplier) error {
+	// Cek dulu supaya update tanpa perubahan (0 rows affected di MySQL) tidak dianggap not found
+	if _, err := r.GetByID(s.ID); err != nil {
+		return err
+	}
+	if _, err := r.db.Exec("UPDATE suppliers SET name = ?, phone = ?, email = ?, address = ? WHERE id = ?",
+		s.Name, s.Phone, s.Email, s.Address, s.ID); err != nil {
+		return translateError(err)
+	}
+	saved, err := r.GetByID(s.ID)
+	if err != nil {
+		return err
+	}
+	*s = *saved
+	return nil
+}
+
+func (r *sqlSupplierRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM suppliers WHERE id = ?", id)
+	if err != nil {
+		return translateError(err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
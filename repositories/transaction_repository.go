@@ -3,61 +3,184 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"kasir-api-golang-v1/database"
 	"kasir-api-golang-v1/models"
+	"sort"
+	"strings"
+	"time"
 )
 
-type TransactionRepository struct {
-	db *sql.DB
+// sqlTransactionRepository adalah implementasi TransactionRepository berbasis database/sql (MySQL atau SQLite)
+type sqlTransactionRepository struct {
+	db *database.DB
 }
 
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+func NewTransactionRepository(db *database.DB) TransactionRepository {
+	return &sqlTransactionRepository{db: db}
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
-	tx, err := repo.db.Begin()
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback()
+// maxCheckoutAttempts adalah batas percobaan ulang checkout saat terkena deadlock / lock timeout
+const maxCheckoutAttempts = 3
 
-	totalAmount := 0
-	details := make([]models.TransactionDetail, 0)
+// CreateTransaction menjalankan checkout dan otomatis mengulang kalau database
+// melaporkan deadlock (MySQL) atau database busy (SQLite). price dipanggil di
+// dalam DB transaction dengan snapshot produk yang sudah di-lock; nil berarti DefaultPricing.
+func (repo *sqlTransactionRepository) CreateTransaction(items []models.CheckoutItem, price PriceFunc) (*models.Transaction, error) {
+	return repo.checkout(0, items, price)
+}
+
+// CreateCartTransaction seperti CreateTransaction, tetapi sekaligus menutup cart
+// di dalam DB transaction yang sama
+func (repo *sqlTransactionRepository) CreateCartTransaction(cartID int, items []models.CheckoutItem, price PriceFunc) (*models.Transaction, error) {
+	return repo.checkout(cartID, items, price)
+}
 
+func (repo *sqlTransactionRepository) checkout(cartID int, items []models.CheckoutItem, price PriceFunc) (*models.Transaction, error) {
+	if price == nil {
+		price = DefaultPricing
+	}
+	for attempt := 1; ; attempt++ {
+		trx, err := repo.createTransaction(cartID, items, price)
+		if err == nil || !isRetryable(err) || attempt == maxCheckoutAttempts {
+			return trx, err
+		}
+		time.Sleep(time.Duration(attempt) * 20 * time.Millisecond)
+	}
+}
+
+// sortedProductIDs mengembalikan product ID unik terurut, dipakai sebagai urutan lock
+func sortedProductIDs(items []models.CheckoutItem) []int {
+	seen := map[int]bool{}
+	var ids []int
 	for _, item := range items {
-		var productPrice, stock int
-		var productName string
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			ids = append(ids, item.ProductID)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// rowQuerier dipenuhi oleh *sql.DB dan *sql.Tx
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
 
-		err := tx.QueryRow("SELECT name, price, stock FROM products WHERE id = ?", item.ProductID).Scan(&productName, &productPrice, &stock)
+// loadProducts membaca produk untuk checkout, urut berdasarkan product ID.
+// suffix diisi Dialect.ForUpdate() untuk mengunci baris di dalam DB transaction.
+func loadProducts(q rowQuerier, items []models.CheckoutItem, suffix string) (map[int]models.Product, error) {
+	products := map[int]models.Product{}
+	for _, id := range sortedProductIDs(items) {
+		var p models.Product
+		err := q.QueryRow("SELECT "+productColumns+" FROM products p WHERE p.id = ?"+suffix, id).Scan(productDest(&p)...)
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+			return nil, fmt.Errorf("product id %d: %w", id, ErrNotFound)
 		}
 		if err != nil {
 			return nil, err
 		}
+		products[id] = p
+	}
+	return products, nil
+}
+
+func (repo *sqlTransactionRepository) QuoteTransaction(items []models.CheckoutItem, price PriceFunc) (*models.Transaction, error) {
+	if price == nil {
+		price = DefaultPricing
+	}
+	products, err := loadProducts(repo.db, items, "")
+	if err != nil {
+		return nil, err
+	}
+	return price(items, products)
+}
 
-		// Cek apakah stock cukup
-		if stock < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product %s (available: %d, requested: %d)", productName, stock, item.Quantity)
+// createTransaction menjalankan satu percobaan checkout. cartID 0 berarti checkout
+// biasa; selain itu cart dikunci dulu (urutan lock yang sama dengan update cart) dan
+// stock yang di-reserve cart dipakai untuk penjualan ini.
+func (repo *sqlTransactionRepository) createTransaction(cartID int, items []models.CheckoutItem, price PriceFunc) (*models.Transaction, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var reserved map[int]int
+	if cartID != 0 {
+		cart, err := getCart(tx, cartID, repo.db.Dialect.ForUpdate())
+		if err != nil {
+			return nil, err
 		}
+		if reserved, err = CartCheckout(cart, items); err != nil {
+			return nil, err
+		}
+	}
+
+	// Lock semua baris produk dulu, urut berdasarkan product ID, supaya dua checkout
+	// dengan produk yang overlap selalu mengambil lock dengan urutan yang sama (tidak deadlock)
+	products, err := loadProducts(tx, items, repo.db.Dialect.ForUpdate())
+	if err != nil {
+		return nil, err
+	}
+	WithReservedStock(products, reserved)
+
+	// Cek apakah stock cukup
+	if err := CheckStock(items, products); err != nil {
+		return nil, err
+	}
 
-		subtotal := productPrice * item.Quantity
-		totalAmount += subtotal
+	trx, err := price(items, products)
+	if err != nil {
+		return nil, err
+	}
+	CaptureUnitCost(trx, products)
 
-		_, err = tx.Exec("UPDATE products SET stock = stock - ? WHERE id = ?", item.Quantity, item.ProductID)
+	// Shift dikunci supaya tidak ditutup di tengah checkout; kas yang dihitung saat
+	// close selalu mencakup semua transaksi yang masuk ke shift ini
+	if trx.ShiftID != nil {
+		if err := lockOpenShift(tx, *trx.ShiftID, repo.db.Dialect.ForUpdate()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, item := range items {
+		// Qty yang sudah di-reserve cart tidak dikurangi lagi
+		qty := item.Quantity - reserved[item.ProductID]
+		if qty <= 0 {
+			continue
+		}
+		// Conditional update sebagai pengaman terakhir: stock tidak pernah bisa negatif
+		result, err := tx.Exec("UPDATE products SET stock = stock - ? WHERE id = ? AND stock >= ?", qty, item.ProductID, qty)
 		if err != nil {
 			return nil, err
 		}
+		if affected, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if affected == 0 {
+			p := products[item.ProductID]
+			return nil, &InsufficientStockError{ProductID: p.ID, ProductName: p.Name, Available: p.Stock, Requested: item.Quantity}
+		}
+	}
 
-		details = append(details, models.TransactionDetail{
-			ProductID:   item.ProductID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
-		})
+	// Kuota promo dipakai di dalam DB transaction yang sama, jadi tidak bisa terpakai melebihi usage_limit
+	var promoCode interface{}
+	if trx.PromoCode != "" {
+		promoCode = trx.PromoCode
+		result, err := tx.Exec("UPDATE promo_codes SET used_count = used_count + 1 WHERE code = ? AND active AND (usage_limit = 0 OR used_count < usage_limit)", trx.PromoCode)
+		if err != nil {
+			return nil, err
+		}
+		if affected, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if affected == 0 {
+			return nil, ErrPromoUnavailable
+		}
 	}
 
-	result, err := tx.Exec("INSERT INTO transactions (total_amount) VALUES (?)", totalAmount)
+	result, err := tx.Exec(`INSERT INTO transactions (total_amount, gross_amount, discount_amount, promo_code, subtotal, service_charge, tax_amount, tax_inclusive, paid_amount, change_amount, cashier_id, shift_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		trx.TotalAmount, trx.GrossAmount, trx.DiscountAmount, promoCode, trx.Subtotal, trx.ServiceCharge, trx.TaxAmount, trx.TaxInclusive, trx.PaidAmount, trx.ChangeAmount, trx.CashierID, trx.ShiftID)
 	if err != nil {
 		return nil, err
 	}
@@ -66,57 +189,105 @@ func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem
 	if err != nil {
 		return nil, err
 	}
-	transactionID := int(transactionID64)
+	trx.ID = int(transactionID64)
+	trx.Status = models.TransactionCompleted
+	if err := tx.QueryRow("SELECT created_at FROM transactions WHERE id = ?", trx.ID).Scan(&trx.CreatedAt); err != nil {
+		return nil, err
+	}
 
 	// PERBAIKAN: Gunakan batch insert atau prepared statement untuk efisiensi
-	stmt, err := tx.Prepare("INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES (?, ?, ?, ?)")
+	stmt, err := tx.Prepare("INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal, discount_amount, service_charge, tax_amount, total, unit_cost) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	for i := range details {
-		details[i].TransactionID = transactionID
-		_, err = stmt.Exec(transactionID, details[i].ProductID, details[i].Quantity, details[i].Subtotal)
+	for i := range trx.Details {
+		d := &trx.Details[i]
+		d.TransactionID = trx.ID
+		_, err = stmt.Exec(trx.ID, d.ProductID, d.Quantity, d.Subtotal, d.DiscountAmount, d.ServiceCharge, d.TaxAmount, d.Total, d.UnitCost)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	for i := range trx.Payments {
+		pay := &trx.Payments[i]
+		pay.TransactionID = trx.ID
+		result, err := tx.Exec("INSERT INTO payments (transaction_id, method, amount, tendered) VALUES (?, ?, ?, ?)",
+			trx.ID, pay.Method, pay.Amount, pay.Tendered)
+		if err != nil {
+			return nil, err
+		}
+		paymentID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		pay.ID = int(paymentID)
+	}
+
+	for i := range trx.Promotions {
+		ap := &trx.Promotions[i]
+		ap.TransactionID = trx.ID
+		result, err := tx.Exec("INSERT INTO transaction_promotions (transaction_id, promotion_id, name, type, discount_amount) VALUES (?, ?, ?, ?, ?)",
+			trx.ID, ap.PromotionID, ap.Name, ap.Type, ap.DiscountAmount)
+		if err != nil {
+			return nil, err
+		}
+		appliedID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		ap.ID = int(appliedID)
+	}
+
+	// Pengurangan stock di atas dicatat ke ledger setelah transaction ID diketahui.
+	// Reservasi cart dicatat dilepas dulu supaya ledger tetap sama dengan stock.
+	trxID := trx.ID
+	if cartID != 0 {
+		ids, _ := ReservationDelta(nil, reserved)
+		for _, id := range ids {
+			if err := writeStockMovement(tx, NewStockMovement(id, models.StockReservation, reserved[id], &cartID, AuditActor(trx.CashierID), "cart checkout")); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tx.Exec("UPDATE carts SET status = ?, reserved_until = NULL, transaction_id = ? WHERE id = ?", models.CartCheckedOut, trxID, cartID); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range items {
+		if err := writeStockMovement(tx, NewStockMovement(item.ProductID, models.StockSale, -item.Quantity, &trxID, AuditActor(trx.CashierID), "")); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeAudit(tx, AuditActor(trx.CashierID), models.AuditTransaction, trx.ID, models.AuditCreate, nil, trx); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
-
-	return &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		Details:     details,
-	}, nil
+	return trx, nil
 }
 
 // GetSalesToday untuk report hari ini
-func (repo *TransactionRepository) GetSalesToday() (totalRevenue int, totalTransaksi int, err error) {
-	query := `
-		SELECT IFNULL(SUM(total_amount), 0), COUNT(*)
-		FROM transactions
-		WHERE DATE(created_at) = CURDATE()`
-	
-	err = repo.db.QueryRow(query).Scan(&totalRevenue, &totalTransaksi)
-	return
+func (repo *sqlTransactionRepository) GetSalesToday() (models.SalesSummary, error) {
+	return repo.salesWhere(repo.db.Dialect.DateOf("created_at") + " = " + repo.db.Dialect.Today())
 }
 
 // GetTopProductToday untuk produk terlaris hari ini
-func (repo *TransactionRepository) GetTopProductToday() (productName string, qtySold int, err error) {
-	query := `
-		SELECT p.name, SUM(td.quantity) as total_qty
+func (repo *sqlTransactionRepository) GetTopProductToday() (productName string, qtySold int, err error) {
+	query := fmt.Sprintf(`
+		SELECT p.name, SUM(td.quantity - td.refunded_quantity) as total_qty
 		FROM transaction_details td
 		JOIN products p ON td.product_id = p.id
 		JOIN transactions t ON td.transaction_id = t.id
-		WHERE DATE(t.created_at) = CURDATE()
+		WHERE t.status <> 'voided' AND %s = %s
 		GROUP BY td.product_id, p.name
+		HAVING SUM(td.quantity - td.refunded_quantity) > 0
 		ORDER BY total_qty DESC
-		LIMIT 1`
-	
+		LIMIT 1`, repo.db.Dialect.DateOf("t.created_at"), repo.db.Dialect.Today())
+
 	err = repo.db.QueryRow(query).Scan(&productName, &qtySold)
 	if err == sql.ErrNoRows {
 		return "", 0, nil
@@ -125,31 +296,499 @@ func (repo *TransactionRepository) GetTopProductToday() (productName string, qty
 }
 
 // GetSalesInRange untuk report dengan date range
-func (repo *TransactionRepository) GetSalesInRange(startDate, endDate string) (totalRevenue int, totalTransaksi int, err error) {
-	query := `
-		SELECT IFNULL(SUM(total_amount), 0), COUNT(*)
+func (repo *sqlTransactionRepository) GetSalesInRange(startDate, endDate string) (models.SalesSummary, error) {
+	return repo.salesWhere(repo.db.Dialect.DateOf("created_at")+" BETWEEN ? AND ?", startDate, endDate)
+}
+
+// salesWhere menjumlahkan transaksi yang tidak di-void. Revenue bersih sudah dikurangi refund.
+func (repo *sqlTransactionRepository) salesWhere(cond string, args ...interface{}) (models.SalesSummary, error) {
+	var sum models.SalesSummary
+	err := repo.db.QueryRow(`
+		SELECT IFNULL(SUM(total_amount - refunded_amount), 0), COUNT(*),
+			IFNULL(SUM(gross_amount), 0), IFNULL(SUM(discount_amount), 0), IFNULL(SUM(refunded_amount), 0),
+			IFNULL(SUM(subtotal), 0), IFNULL(SUM(service_charge), 0), IFNULL(SUM(tax_amount - refunded_tax), 0)
 		FROM transactions
-		WHERE DATE(created_at) BETWEEN ? AND ?`
-	
-	err = repo.db.QueryRow(query, startDate, endDate).Scan(&totalRevenue, &totalTransaksi)
-	return
+		WHERE status <> 'voided' AND `+cond, args...).
+		Scan(&sum.TotalRevenue, &sum.TotalTransaksi, &sum.GrossRevenue, &sum.TotalDiscount, &sum.TotalRefund,
+			&sum.Subtotal, &sum.TotalServiceCharge, &sum.TotalTax)
+	return sum, err
+}
+
+// GetProfitToday untuk laporan laba kotor hari ini
+func (repo *sqlTransactionRepository) GetProfitToday(groupBy string) ([]models.ProfitLine, error) {
+	return repo.profitWhere(groupBy, repo.db.Dialect.DateOf("t.created_at")+" = "+repo.db.Dialect.Today())
+}
+
+// GetProfitInRange untuk laporan laba kotor dalam date range
+func (repo *sqlTransactionRepository) GetProfitInRange(startDate, endDate, groupBy string) ([]models.ProfitLine, error) {
+	return repo.profitWhere(groupBy, repo.db.Dialect.DateOf("t.created_at")+" BETWEEN ? AND ?", startDate, endDate)
+}
+
+// profitWhere menjumlahkan net sales (total baris tanpa pajak dikurangi refund tanpa
+// pajak) dan COGS (unit_cost * qty yang tidak di-refund) dari transaksi yang tidak di-void.
+// Baris dengan unit_cost NULL (sebelum harga pokok dicatat) masuk unknown cost sales.
+// Kategori mengikuti kategori produk saat ini.
+func (repo *sqlTransactionRepository) profitWhere(groupBy, cond string, args ...interface{}) ([]models.ProfitLine, error) {
+	keys, group := "0, '', ''", ""
+	switch groupBy {
+	case models.ProfitGroupProduct:
+		keys, group = "td.product_id, p.name, ''", " GROUP BY td.product_id, p.name ORDER BY td.product_id"
+	case models.ProfitGroupCategory:
+		keys, group = "IFNULL(c.id, 0), IFNULL(c.name, 'No Category'), ''", " GROUP BY c.id, c.name ORDER BY 1"
+	case models.ProfitGroupDay, models.ProfitGroupMonth:
+		format := "%Y-%m-%d"
+		if groupBy == models.ProfitGroupMonth {
+			format = "%Y-%m"
+		}
+		period := repo.db.Dialect.FormatDate("t.created_at", format)
+		keys, group = "0, '', "+period, " GROUP BY "+period+" ORDER BY 3"
+	}
+
+	rows, err := repo.db.Query(`
+		SELECT `+keys+`, IFNULL(SUM(td.quantity - td.refunded_quantity), 0),
+			IFNULL(SUM(td.total - td.tax_amount - IFNULL(r.amount, 0)), 0),
+			IFNULL(SUM(td.unit_cost * (td.quantity - td.refunded_quantity)), 0),
+			IFNULL(SUM(CASE WHEN td.unit_cost IS NULL THEN td.total - td.tax_amount - IFNULL(r.amount, 0) ELSE 0 END), 0)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		JOIN products p ON td.product_id = p.id
+		LEFT JOIN categories c ON p.category_id = c.id
+		LEFT JOIN (
+			SELECT transaction_detail_id, SUM(amount - tax_amount) AS amount
+			FROM refund_items
+			GROUP BY transaction_detail_id
+		) r ON r.transaction_detail_id = td.id
+		WHERE t.status <> 'voided' AND `+cond+group, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]models.ProfitLine, 0)
+	for rows.Next() {
+		var l models.ProfitLine
+		if err := rows.Scan(&l.ID, &l.Name, &l.Period, &l.Quantity, &l.NetSales, &l.COGS, &l.UnknownCostSales); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
 }
 
 // GetTopProductInRange untuk produk terlaris dalam date range
-func (repo *TransactionRepository) GetTopProductInRange(startDate, endDate string) (productName string, qtySold int, err error) {
-	query := `
-		SELECT p.name, SUM(td.quantity) as total_qty
+func (repo *sqlTransactionRepository) GetTopProductInRange(startDate, endDate string) (productName string, qtySold int, err error) {
+	query := fmt.Sprintf(`
+		SELECT p.name, SUM(td.quantity - td.refunded_quantity) as total_qty
 		FROM transaction_details td
 		JOIN products p ON td.product_id = p.id
 		JOIN transactions t ON td.transaction_id = t.id
-		WHERE DATE(t.created_at) BETWEEN ? AND ?
+		WHERE t.status <> 'voided' AND %s BETWEEN ? AND ?
 		GROUP BY td.product_id, p.name
+		HAVING SUM(td.quantity - td.refunded_quantity) > 0
 		ORDER BY total_qty DESC
-		LIMIT 1`
-	
+		LIMIT 1`, repo.db.Dialect.DateOf("t.created_at"))
+
 	err = repo.db.QueryRow(query, startDate, endDate).Scan(&productName, &qtySold)
 	if err == sql.ErrNoRows {
 		return "", 0, nil
 	}
 	return
 }
+
+// GetPaymentsToday untuk revenue per metode pembayaran hari ini
+func (repo *sqlTransactionRepository) GetPaymentsToday() (map[string]int, error) {
+	return repo.paymentsWhere(repo.db.Dialect.DateOf("t.created_at") + " = " + repo.db.Dialect.Today())
+}
+
+// GetPaymentsInRange untuk revenue per metode pembayaran dalam date range
+func (repo *sqlTransactionRepository) GetPaymentsInRange(startDate, endDate string) (map[string]int, error) {
+	return repo.paymentsWhere(repo.db.Dialect.DateOf("t.created_at")+" BETWEEN ? AND ?", startDate, endDate)
+}
+
+// paymentsWhere menjumlahkan payments.amount (setelah kembalian) dari transaksi yang tidak di-void.
+// Refund sebagian tidak dibebankan ke metode tertentu.
+func (repo *sqlTransactionRepository) paymentsWhere(cond string, args ...interface{}) (map[string]int, error) {
+	rows, err := repo.db.Query(`
+		SELECT py.method, SUM(py.amount)
+		FROM payments py
+		JOIN transactions t ON py.transaction_id = t.id
+		WHERE t.status <> 'voided' AND `+cond+`
+		GROUP BY py.method`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byMethod := map[string]int{}
+	for rows.Next() {
+		var method string
+		var amount int
+		if err := rows.Scan(&method, &amount); err != nil {
+			return nil, err
+		}
+		byMethod[method] = amount
+	}
+	return byMethod, rows.Err()
+}
+
+const transactionColumns = `id, total_amount, status, refunded_amount, refunded_tax, gross_amount, discount_amount, IFNULL(promo_code, ''),
+	subtotal, service_charge, tax_amount, tax_inclusive, paid_amount, change_amount, cashier_id, shift_id, created_at`
+
+func scanTransaction(row rowScanner) (models.Transaction, error) {
+	var t models.Transaction
+	var cashierID, shiftID sql.NullInt64
+	err := row.Scan(&t.ID, &t.TotalAmount, &t.Status, &t.RefundedAmount, &t.RefundedTax, &t.GrossAmount, &t.DiscountAmount, &t.PromoCode,
+		&t.Subtotal, &t.ServiceCharge, &t.TaxAmount, &t.TaxInclusive, &t.PaidAmount, &t.ChangeAmount, &cashierID, &shiftID, &t.CreatedAt)
+	t.CashierID, t.ShiftID = nullInt(cashierID), nullInt(shiftID)
+	return t, err
+}
+
+// lockOpenShift mengunci baris shift dan memastikan statusnya masih open
+func lockOpenShift(q rowQuerier, shiftID int, forUpdate string) error {
+	var status string
+	if err := q.QueryRow("SELECT status FROM shifts WHERE id = ?"+forUpdate, shiftID).Scan(&status); err != nil {
+		return translateError(err)
+	}
+	if status != models.ShiftOpen {
+		return ErrShiftClosed
+	}
+	return nil
+}
+
+// GetAll untuk riwayat transaksi dengan filter tanggal & total, dipaginasi
+func (repo *sqlTransactionRepository) GetAll(filter models.TransactionFilter) ([]models.Transaction, int, error) {
+	var conds []string
+	var args []interface{}
+	if filter.StartDate != "" {
+		conds = append(conds, repo.db.Dialect.DateOf("created_at")+" >= ?")
+		args = append(args, filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		conds = append(conds, repo.db.Dialect.DateOf("created_at")+" <= ?")
+		args = append(args, filter.EndDate)
+	}
+	if filter.MinTotal != nil {
+		conds = append(conds, "total_amount >= ?")
+		args = append(args, *filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		conds = append(conds, "total_amount <= ?")
+		args = append(args, *filter.MaxTotal)
+	}
+	if filter.CashierID != 0 {
+		conds = append(conds, "cashier_id = ?")
+		args = append(args, filter.CashierID)
+	}
+	if filter.ShiftID != 0 {
+		conds = append(conds, "shift_id = ?")
+		args = append(args, filter.ShiftID)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM transactions"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + transactionColumns + " FROM transactions" + where +
+		" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?"
+	rows, err := repo.db.Query(query, append(args, filter.Limit, (filter.Page-1)*filter.Limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, total, rows.Err()
+}
+
+// GetByID untuk detail transaksi (cetak ulang struk)
+func (repo *sqlTransactionRepository) GetByID(id int) (*models.Transaction, error) {
+	t, err := scanTransaction(repo.db.QueryRow("SELECT "+transactionColumns+" FROM transactions WHERE id = ?", id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	query := `
+		SELECT td.id, td.transaction_id, td.product_id, p.name, td.quantity, td.subtotal, td.discount_amount,
+			td.service_charge, td.tax_amount, td.total, IFNULL(td.unit_cost, 0), td.refunded_quantity
+		FROM transaction_details td
+		JOIN products p ON td.product_id = p.id
+		WHERE td.transaction_id = ?
+		ORDER BY td.id`
+	rows, err := repo.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.TransactionDetail
+		if err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.Quantity, &d.Subtotal, &d.DiscountAmount,
+			&d.ServiceCharge, &d.TaxAmount, &d.Total, &d.UnitCost, &d.RefundedQuantity); err != nil {
+			return nil, err
+		}
+		t.Details = append(t.Details, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	t.Payments, err = repo.getPayments(id)
+	if err != nil {
+		return nil, err
+	}
+	t.Promotions, err = repo.getPromotions(id)
+	if err != nil {
+		return nil, err
+	}
+	t.Refunds, err = repo.getRefunds(id)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (repo *sqlTransactionRepository) getPayments(transactionID int) ([]models.Payment, error) {
+	rows, err := repo.db.Query("SELECT id, transaction_id, method, amount, tendered FROM payments WHERE transaction_id = ? ORDER BY id", transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []models.Payment
+	for rows.Next() {
+		var pay models.Payment
+		if err := rows.Scan(&pay.ID, &pay.TransactionID, &pay.Method, &pay.Amount, &pay.Tendered); err != nil {
+			return nil, err
+		}
+		payments = append(payments, pay)
+	}
+	return payments, rows.Err()
+}
+
+func (repo *sqlTransactionRepository) getPromotions(transactionID int) ([]models.AppliedPromotion, error) {
+	rows, err := repo.db.Query("SELECT id, transaction_id, promotion_id, name, type, discount_amount FROM transaction_promotions WHERE transaction_id = ? ORDER BY id", transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []models.AppliedPromotion
+	for rows.Next() {
+		var ap models.AppliedPromotion
+		if err := rows.Scan(&ap.ID, &ap.TransactionID, &ap.PromotionID, &ap.Name, &ap.Type, &ap.DiscountAmount); err != nil {
+			return nil, err
+		}
+		applied = append(applied, ap)
+	}
+	return applied, rows.Err()
+}
+
+func (repo *sqlTransactionRepository) getRefunds(transactionID int) ([]models.Refund, error) {
+	rows, err := repo.db.Query("SELECT id, transaction_id, type, reason, amount, cash_amount, cashier_id, shift_id, created_at FROM refunds WHERE transaction_id = ? ORDER BY id", transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []models.Refund
+	index := map[int]int{}
+	for rows.Next() {
+		var rf models.Refund
+		var cashierID, shiftID sql.NullInt64
+		if err := rows.Scan(&rf.ID, &rf.TransactionID, &rf.Type, &rf.Reason, &rf.Amount, &rf.CashAmount, &cashierID, &shiftID, &rf.CreatedAt); err != nil {
+			return nil, err
+		}
+		rf.CashierID, rf.ShiftID = nullInt(cashierID), nullInt(shiftID)
+		index[rf.ID] = len(refunds)
+		refunds = append(refunds, rf)
+	}
+	if err := rows.Err(); err != nil || len(refunds) == 0 {
+		return refunds, err
+	}
+
+	itemRows, err := repo.db.Query(`
+		SELECT ri.id, ri.refund_id, ri.transaction_detail_id, ri.product_id, ri.quantity, ri.amount, ri.tax_amount
+		FROM refund_items ri
+		JOIN refunds r ON ri.refund_id = r.id
+		WHERE r.transaction_id = ?
+		ORDER BY ri.id`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var it models.RefundItem
+		if err := itemRows.Scan(&it.ID, &it.RefundID, &it.TransactionDetailID, &it.ProductID, &it.Quantity, &it.Amount, &it.TaxAmount); err != nil {
+			return nil, err
+		}
+		rf := &refunds[index[it.RefundID]]
+		rf.Items = append(rf.Items, it)
+	}
+	return refunds, itemRows.Err()
+}
+
+// CreateRefund mengembalikan stock dan mencatat refund dalam satu DB transaction.
+// Untuk RefundTypeVoid, lines diabaikan dan semua sisa qty di-refund. Seperti checkout,
+// refund diulang kalau terkena deadlock / database busy.
+func (repo *sqlTransactionRepository) CreateRefund(transactionID int, refundType, reason string, lines []models.RefundLine, shift *models.Shift) (*models.Refund, error) {
+	for attempt := 1; ; attempt++ {
+		refund, err := repo.createRefund(transactionID, refundType, reason, lines, shift)
+		if err == nil || !isRetryable(err) || attempt == maxCheckoutAttempts {
+			return refund, err
+		}
+		time.Sleep(time.Duration(attempt) * 20 * time.Millisecond)
+	}
+}
+
+func (repo *sqlTransactionRepository) createRefund(transactionID int, refundType, reason string, lines []models.RefundLine, shift *models.Shift) (*models.Refund, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var before TransactionAudit
+	var promoCode sql.NullString
+	err = tx.QueryRow("SELECT status, refunded_amount, refunded_tax, promo_code FROM transactions WHERE id = ?"+repo.db.Dialect.ForUpdate(), transactionID).
+		Scan(&before.Status, &before.RefundedAmount, &before.RefundedTax, &promoCode)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if before.Status == models.TransactionVoided {
+		return nil, ErrTransactionVoided
+	}
+
+	rows, err := tx.Query("SELECT id, product_id, quantity, subtotal, tax_amount, total, refunded_quantity FROM transaction_details WHERE transaction_id = ? ORDER BY id"+repo.db.Dialect.ForUpdate(), transactionID)
+	if err != nil {
+		return nil, err
+	}
+	var details []models.TransactionDetail
+	for rows.Next() {
+		d := models.TransactionDetail{TransactionID: transactionID}
+		if err := rows.Scan(&d.ID, &d.ProductID, &d.Quantity, &d.Subtotal, &d.TaxAmount, &d.Total, &d.RefundedQuantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		details = append(details, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	items, newStatus, err := PlanRefund(details, refundType, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	// Produk di-lock urut product ID lalu shift, urutan yang sama dengan checkout,
+	// supaya refund dan checkout yang berjalan bersamaan tidak saling deadlock
+	restock := make([]models.CheckoutItem, 0, len(items))
+	for _, it := range items {
+		restock = append(restock, models.CheckoutItem{ProductID: it.ProductID, Quantity: it.Quantity})
+	}
+	if _, err := loadProducts(tx, restock, repo.db.Dialect.ForUpdate()); err != nil {
+		return nil, err
+	}
+
+	refund := models.Refund{TransactionID: transactionID, Type: refundType, Reason: reason, Items: items}
+	if shift != nil {
+		if err := lockOpenShift(tx, shift.ID, repo.db.Dialect.ForUpdate()); err != nil {
+			return nil, err
+		}
+		refund.CashierID, refund.ShiftID = &shift.UserID, &shift.ID
+	}
+	refundedTax := 0
+	for _, it := range items {
+		refund.Amount += it.Amount
+		refundedTax += it.TaxAmount
+	}
+	var paid, cashPaid int
+	if err := tx.QueryRow("SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(CASE WHEN method = ? THEN amount ELSE 0 END), 0) FROM payments WHERE transaction_id = ?",
+		models.PaymentCash, transactionID).Scan(&paid, &cashPaid); err != nil {
+		return nil, err
+	}
+	refund.CashAmount = RefundCash(cashPaid, paid, before.RefundedAmount, refund.Amount)
+
+	result, err := tx.Exec("INSERT INTO refunds (transaction_id, type, reason, amount, cash_amount, cashier_id, shift_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		transactionID, refundType, reason, refund.Amount, refund.CashAmount, refund.CashierID, refund.ShiftID)
+	if err != nil {
+		return nil, err
+	}
+	refundID64, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	refund.ID = int(refundID64)
+
+	for i := range refund.Items {
+		it := &refund.Items[i]
+		it.RefundID = refund.ID
+		result, err := tx.Exec("INSERT INTO refund_items (refund_id, transaction_detail_id, product_id, quantity, amount, tax_amount) VALUES (?, ?, ?, ?, ?, ?)",
+			refund.ID, it.TransactionDetailID, it.ProductID, it.Quantity, it.Amount, it.TaxAmount)
+		if err != nil {
+			return nil, err
+		}
+		itemID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		it.ID = int(itemID)
+
+		if _, err := tx.Exec("UPDATE transaction_details SET refunded_quantity = refunded_quantity + ? WHERE id = ?", it.Quantity, it.TransactionDetailID); err != nil {
+			return nil, err
+		}
+		// Kembalikan stock produk
+		if _, err := tx.Exec("UPDATE products SET stock = stock + ? WHERE id = ?", it.Quantity, it.ProductID); err != nil {
+			return nil, err
+		}
+		if err := writeStockMovement(tx, NewStockMovement(it.ProductID, models.StockRefund, it.Quantity, &refund.ID, AuditActor(refund.CashierID), refundType)); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE transactions SET status = ?, refunded_amount = refunded_amount + ?, refunded_tax = refunded_tax + ? WHERE id = ?",
+		newStatus, refund.Amount, refundedTax, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transaksi yang di-void tidak lagi memakai kuota kode promonya
+	if newStatus == models.TransactionVoided && promoCode.Valid {
+		if _, err := tx.Exec("UPDATE promo_codes SET used_count = used_count - 1 WHERE code = ? AND used_count > 0", promoCode.String); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.QueryRow("SELECT created_at FROM refunds WHERE id = ?", refund.ID).Scan(&refund.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	after := TransactionAudit{
+		Status:         newStatus,
+		RefundedAmount: before.RefundedAmount + refund.Amount,
+		RefundedTax:    before.RefundedTax + refundedTax,
+		Refund:         &refund,
+	}
+	if err := writeAudit(tx, AuditActor(refund.CashierID), models.AuditTransaction, transactionID, models.AuditUpdate, before, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"kasir-api-golang-v1/database"
+	"kasir-api-golang-v1/models"
+)
+
+// sqlUserRepository adalah implementasi UserRepository berbasis database/sql (MySQL atau SQLite)
+type sqlUserRepository struct {
+	db *database.DB
+}
+
+func NewUserRepository(db *database.DB) UserRepository {
+	return &sqlUserRepository{db: db}
+}
+
+const userColumns = "id, username, name, password_hash, active, created_at"
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var u models.User
+	if err := row.Scan(&u.ID, &u.Username, &u.Name, &u.PasswordHash, &u.Active, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *sqlUserRepository) GetAll() ([]models.User, error) {
+	rows, err := r.db.Query("SELECT " + userColumns + " FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
+func (r *sqlUserRepository) GetByID(id int) (*models.User, error) {
+	u, err := scanUser(r.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = ?", id))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return u, nil
+}
+
+func (r *sqlUserRepository) GetByUsername(username string) (*models.User, error) {
+	u, err := scanUser(r.db.QueryRow("SELECT "+userColumns+" FROM users WHERE username = ?", username))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return u, nil
+}
+
+func (r *sqlUserRepository) Count() (int, error) {
+	var n int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&n)
+	return n, err
+}
+
+func (r *sqlUserRepository) Create(u *models.User) error {
+	result, err := r.db.Exec("INSERT INTO users (username, name, password_hash, active) VALUES (?, ?, ?, ?)",
+		u.Username, u.Name, u.PasswordHash, u.Active)
+	if err != nil {
+		return translateError(err)
+	}
+	id, _ := result.LastInsertId()
+	u.ID = int(id)
+	return r.db.QueryRow("SELECT created_at FROM users WHERE id = ?", u.ID).Scan(&u.CreatedAt)
+}
+
+func (r *sqlUserRepository) Update(u *models.User) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if !u.Active {
+		if err := checkNotLastOwner(tx, r.db.Dialect, u.ID); err != nil {
+			return err
+		}
+	}
+	query, args := "UPDATE users SET username = ?, name = ?, active = ?", []interface{}{u.Username, u.Name, u.Active}
+	if u.PasswordHash != "" {
+		query += ", password_hash = ?"
+		args = append(args, u.PasswordHash)
+	}
+	result, err := tx.Exec(query+" WHERE id = ?", append(args, u.ID)...)
+	if err != nil {
+		return translateError(err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+	if err := tx.QueryRow("SELECT password_hash, created_at FROM users WHERE id = ?", u.ID).Scan(&u.PasswordHash, &u.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete menghapus user; session ikut terhapus lewat ON DELETE CASCADE
+func (r *sqlUserRepository) Delete(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkNotLastOwner(tx, r.db.Dialect, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM users WHERE id = ?", id)
+	if err != nil {
+		return translateError(err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
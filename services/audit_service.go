@@ -0,0 +1,38 @@
+package services
+
+import (
+	"slices"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type auditService struct {
+	repo repositories.AuditRepository
+}
+
+func NewAuditService(repo repositories.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// List untuk GET /api/audit, filter divalidasi dulu sebelum ke repository
+func (s *auditService) List(filter models.AuditFilter) (*models.AuditPage, error) {
+	fields := validateDateRange(filter.StartDate, filter.EndDate)
+	if filter.Entity != "" && !slices.Contains([]string{models.AuditProduct, models.AuditCategory, models.AuditTransaction}, filter.Entity) {
+		fields = append(fields, FieldError{Field: "entity", Message: "must be product, category or transaction"})
+	}
+	if filter.Action != "" && !slices.Contains([]string{models.AuditCreate, models.AuditUpdate, models.AuditDelete}, filter.Action) {
+		fields = append(fields, FieldError{Field: "action", Message: "must be create, update or delete"})
+	}
+	fields = append(fields, validatePage(filter.Page, filter.Limit)...)
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid audit filter", fields...)
+	}
+	defaultPage(&filter.Page, &filter.Limit)
+
+	entries, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, fromRepo(err, "audit entry")
+	}
+	return &models.AuditPage{Data: entries, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
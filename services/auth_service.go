@@ -0,0 +1,135 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+// DefaultAuthTokenTTL dipakai kalau AUTH_TOKEN_TTL tidak diisi, kira-kira satu shift kasir
+const DefaultAuthTokenTTL = 12 * time.Hour
+
+type authService struct {
+	users    repositories.UserRepository
+	sessions repositories.SessionRepository
+	ttl      time.Duration
+}
+
+// NewAuthService: ttl adalah masa berlaku token sejak login / refresh
+func NewAuthService(users repositories.UserRepository, sessions repositories.SessionRepository, ttl time.Duration) AuthService {
+	if ttl <= 0 {
+		ttl = DefaultAuthTokenTTL
+	}
+	return &authService{users: users, sessions: sessions, ttl: ttl}
+}
+
+// hashToken: token disimpan sebagai sha256 supaya isi tabel sessions tidak bisa dipakai login
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issue membuat token acak baru untuk user dan menyimpan session-nya
+func (s *authService) issue(user *models.User) (*models.AuthToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, NewInternalError(err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	session := models.Session{TokenHash: hashToken(token), UserID: user.ID, ExpiresAt: time.Now().Add(s.ttl)}
+	if err := s.sessions.Create(&session); err != nil {
+		return nil, NewInternalError(err)
+	}
+	return &models.AuthToken{Token: token, ExpiresAt: session.ExpiresAt, User: *user}, nil
+}
+
+func (s *authService) Login(req models.LoginRequest) (*models.AuthToken, error) {
+	username := strings.ToLower(strings.TrimSpace(req.Username))
+	if username == "" || req.Password == "" {
+		return nil, NewValidationError("username and password are required")
+	}
+
+	// Pesan yang sama untuk user tidak ada, nonaktif dan password salah
+	invalid := NewUnauthorizedError("invalid username or password")
+	user, err := s.users.GetByUsername(username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	if !user.Active || !checkPassword(user.PasswordHash, req.Password) {
+		return nil, invalid
+	}
+	return s.issue(user)
+}
+
+func (s *authService) Authenticate(token string) (*models.User, error) {
+	if token == "" {
+		return nil, NewUnauthorizedError("authentication required")
+	}
+	session, err := s.sessions.GetByTokenHash(hashToken(token))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, NewUnauthorizedError("invalid token")
+	}
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	if !time.Now().Before(session.ExpiresAt) {
+		return nil, NewUnauthorizedError("token expired")
+	}
+
+	user, err := s.users.GetByID(session.UserID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, NewUnauthorizedError("invalid token")
+	}
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	if !user.Active {
+		return nil, NewUnauthorizedError("user is inactive")
+	}
+	return user, nil
+}
+
+func (s *authService) Refresh(token string) (*models.AuthToken, error) {
+	user, err := s.Authenticate(token)
+	if err != nil {
+		return nil, err
+	}
+	issued, err := s.issue(user)
+	if err != nil {
+		return nil, err
+	}
+	// Token lama dicabut; kalau sudah terhapus (refresh bersamaan) tidak masalah
+	if err := s.sessions.Delete(hashToken(token)); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, NewInternalError(err)
+	}
+	return issued, nil
+}
+
+func (s *authService) Logout(token string) error {
+	err := s.sessions.Delete(hashToken(token))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return NewUnauthorizedError("invalid token")
+	}
+	if err != nil {
+		return NewInternalError(err)
+	}
+	return nil
+}
+
+func (s *authService) PurgeExpired() (int, error) {
+	n, err := s.sessions.DeleteExpired(time.Now())
+	if err != nil {
+		return 0, NewInternalError(err)
+	}
+	return n, nil
+}
@@ -0,0 +1,162 @@
+package services_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+// testHasher: iterasi kecil supaya test yang membuat user tetap cepat
+var testHasher = services.PasswordHasher{Iterations: 1000}
+
+func newAuthFixture(t *testing.T) (services.UserService, services.AuthService, *models.User) {
+	t.Helper()
+	store := memory.NewStore()
+	users, sessions := memory.NewUserRepository(store), memory.NewSessionRepository(store)
+	userService := services.NewUserService(users, sessions, memory.NewRoleRepository(store), testHasher)
+	user := models.User{Username: " Kasir1 ", Name: "Kasir Satu", Password: "rahasia123", Active: true}
+	if err := userService.Create(&user); err != nil {
+		t.Fatal(err)
+	}
+	return userService, services.NewAuthService(users, sessions, time.Hour), &user
+}
+
+func TestUserServiceValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       models.User
+		wantFields []string
+	}{
+		{"missing username and password", models.User{}, []string{"username", "password"}},
+		{"username with space", models.User{Username: "kasir satu", Password: "rahasia123"}, []string{"username"}},
+		{"short password", models.User{Username: "kasir", Password: "123"}, []string{"password"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			svc := services.NewUserService(memory.NewUserRepository(store), memory.NewSessionRepository(store), memory.NewRoleRepository(store), testHasher)
+			e := services.AsError(svc.Create(&tt.user))
+			fields, _ := e.Details.([]services.FieldError)
+			var names []string
+			for _, f := range fields {
+				names = append(names, f.Field)
+			}
+			if e.Code != services.CodeValidation || !reflect.DeepEqual(names, tt.wantFields) {
+				t.Errorf("err = %v fields %v, want %v", e, names, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestAuthServiceLogin(t *testing.T) {
+	users, auth, user := newAuthFixture(t)
+	if user.Username != "kasir1" || user.Password != "" || user.PasswordHash == "" {
+		t.Fatalf("created user = %+v, want normalized username and hashed password", user)
+	}
+
+	for _, req := range []models.LoginRequest{
+		{Username: "kasir1", Password: "salah12345"},
+		{Username: "tidakada", Password: "rahasia123"},
+	} {
+		if _, err := auth.Login(req); services.AsError(err).Code != services.CodeUnauthorized {
+			t.Errorf("Login(%+v) err = %v, want UNAUTHORIZED", req, err)
+		}
+	}
+
+	token, err := auth.Login(models.LoginRequest{Username: "KASIR1", Password: "rahasia123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := auth.Authenticate(token.Token); err != nil || got.ID != user.ID {
+		t.Errorf("Authenticate = %+v, %v", got, err)
+	}
+
+	// Refresh mencabut token lama
+	refreshed, err := auth.Refresh(token.Token)
+	if err != nil || refreshed.Token == token.Token {
+		t.Fatalf("Refresh = %+v, %v", refreshed, err)
+	}
+	if _, err := auth.Authenticate(token.Token); services.AsError(err).Code != services.CodeUnauthorized {
+		t.Errorf("old token err = %v, want UNAUTHORIZED", err)
+	}
+
+	if err := auth.Logout(refreshed.Token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Authenticate(refreshed.Token); services.AsError(err).Code != services.CodeUnauthorized {
+		t.Errorf("token after logout err = %v, want UNAUTHORIZED", err)
+	}
+
+	// Menonaktifkan user mencabut token dan menolak login berikutnya
+	token, _ = auth.Login(models.LoginRequest{Username: "kasir1", Password: "rahasia123"})
+	user.Active = false
+	if err := users.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Authenticate(token.Token); services.AsError(err).Code != services.CodeUnauthorized {
+		t.Errorf("token of deactivated user err = %v, want UNAUTHORIZED", err)
+	}
+	if _, err := auth.Login(models.LoginRequest{Username: "kasir1", Password: "rahasia123"}); services.AsError(err).Code != services.CodeUnauthorized {
+		t.Errorf("login of deactivated user err = %v, want UNAUTHORIZED", err)
+	}
+}
+
+func TestAuthServicePasswordChangeRevokesTokens(t *testing.T) {
+	users, auth, user := newAuthFixture(t)
+	token, err := auth.Login(models.LoginRequest{Username: "kasir1", Password: "rahasia123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Update tanpa password tidak mengubah password dan tidak mencabut token
+	user.Name = "Kasir Pagi"
+	if err := users.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Authenticate(token.Token); err != nil {
+		t.Errorf("token after profile update err = %v", err)
+	}
+
+	user.Password = "passwordbaru"
+	if err := users.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Authenticate(token.Token); services.AsError(err).Code != services.CodeUnauthorized {
+		t.Errorf("token after password change err = %v, want UNAUTHORIZED", err)
+	}
+	if _, err := auth.Login(models.LoginRequest{Username: "kasir1", Password: "passwordbaru"}); err != nil {
+		t.Errorf("login with new password err = %v", err)
+	}
+}
+
+func TestUserServiceEnsureAdmin(t *testing.T) {
+	store := memory.NewStore()
+	svc := services.NewUserService(memory.NewUserRepository(store), memory.NewSessionRepository(store), memory.NewRoleRepository(store), testHasher)
+	if created, err := svc.EnsureAdmin("admin", "rahasia123"); err != nil || !created {
+		t.Fatalf("first EnsureAdmin = %v, %v, want created", created, err)
+	}
+	if created, err := svc.EnsureAdmin("admin2", "rahasia123"); err != nil || created {
+		t.Errorf("second EnsureAdmin = %v, %v, want no-op", created, err)
+	}
+}
+
+func TestPasswordHasherIterations(t *testing.T) {
+	for _, tt := range []struct {
+		hasher services.PasswordHasher
+		want   string
+	}{
+		{services.PasswordHasher{}, "pbkdf2-sha256$600000$"},
+		{testHasher, "pbkdf2-sha256$1000$"},
+	} {
+		hash, err := tt.hasher.Hash("rahasia123")
+		if err != nil || !strings.HasPrefix(hash, tt.want) {
+			t.Errorf("Hash with %+v = %q, %v; want prefix %q", tt.hasher, hash, err, tt.want)
+		}
+	}
+}
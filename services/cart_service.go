@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+// DefaultCartReservationTTL dipakai kalau CART_RESERVATION_TTL tidak diisi
+const DefaultCartReservationTTL = 15 * time.Minute
+
+type cartService struct {
+	repo     repositories.CartRepository
+	checkout TransactionService
+	ttl      time.Duration
+}
+
+// NewCartService: ttl adalah lama reservasi stock, diperpanjang setiap kali cart diubah
+func NewCartService(repo repositories.CartRepository, checkout TransactionService, ttl time.Duration) CartService {
+	if ttl <= 0 {
+		ttl = DefaultCartReservationTTL
+	}
+	return &cartService{repo: repo, checkout: checkout, ttl: ttl}
+}
+
+// cartError menerjemahkan error repository cart
+func cartError(err error) error {
+	var stockErr *repositories.InsufficientStockError
+	switch {
+	case errors.As(err, &stockErr):
+		return NewInsufficientStockError(StockShortage{
+			ProductID:   stockErr.ProductID,
+			ProductName: stockErr.ProductName,
+			Available:   stockErr.Available,
+			Requested:   stockErr.Requested,
+		})
+	case errors.Is(err, repositories.ErrCartClosed):
+		return NewConflictError("cart is already checked out or cancelled")
+	case errors.Is(err, repositories.ErrForeignKey):
+		return NewValidationError("invalid cart", FieldError{Field: "items", Message: "product does not exist"})
+	}
+	return fromRepo(err, "cart")
+}
+
+// validateCartItem memvalidasi satu baris; prefix misalnya "items[0]." atau ""
+func validateCartItem(item models.CartItem, prefix string) []FieldError {
+	var fields []FieldError
+	if item.ProductID <= 0 {
+		fields = append(fields, FieldError{Field: prefix + "product_id", Message: "must be a positive product ID"})
+	}
+	if item.Quantity <= 0 {
+		fields = append(fields, FieldError{Field: prefix + "quantity", Message: "must be greater than zero"})
+	}
+	return append(fields, validateDiscount(item.Discount, prefix+"discount")...)
+}
+
+// validateCart memvalidasi note dan items; satu produk hanya boleh satu baris
+func validateCart(cart *models.Cart) error {
+	cart.Note = strings.TrimSpace(cart.Note)
+
+	var fields []FieldError
+	if len(cart.Note) > 255 {
+		fields = append(fields, FieldError{Field: "note", Message: "must be at most 255 characters"})
+	}
+	if len(cart.Items) > MaxCheckoutLines {
+		fields = append(fields, FieldError{Field: "items", Message: fmt.Sprintf("at most %d lines are allowed", MaxCheckoutLines)})
+	}
+	seen := map[int]bool{}
+	for i, item := range cart.Items {
+		prefix := fmt.Sprintf("items[%d].", i)
+		fields = append(fields, validateCartItem(item, prefix)...)
+		if seen[item.ProductID] {
+			fields = append(fields, FieldError{Field: prefix + "product_id", Message: "product listed more than once"})
+		}
+		seen[item.ProductID] = true
+	}
+	if len(fields) > 0 {
+		return NewValidationError("invalid cart", fields...)
+	}
+	return nil
+}
+
+// refreshReservation memperpanjang reservasi cart yang di-reserve
+func (s *cartService) refreshReservation(cart *models.Cart) {
+	if !cart.Reserved {
+		cart.ReservedUntil = nil
+		return
+	}
+	until := time.Now().Add(s.ttl)
+	cart.ReservedUntil = &until
+}
+
+func (s *cartService) GetAll() ([]models.Cart, error) {
+	carts, err := s.repo.GetAll(models.CartOpen)
+	if err != nil {
+		return nil, fromRepo(err, "cart")
+	}
+	return carts, nil
+}
+
+func (s *cartService) GetByID(id int) (*models.Cart, error) {
+	cart, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "cart")
+	}
+	return cart, nil
+}
+
+func (s *cartService) Create(cart *models.Cart) error {
+	if err := validateCart(cart); err != nil {
+		return err
+	}
+	s.refreshReservation(cart)
+	if err := s.repo.Create(cart); err != nil {
+		return cartError(err)
+	}
+	return nil
+}
+
+func (s *cartService) Update(cart *models.Cart) error {
+	stored, err := s.repo.GetByID(cart.ID)
+	if err != nil {
+		return fromRepo(err, "cart")
+	}
+	cart.Items = stored.Items
+	if err := validateCart(cart); err != nil {
+		return err
+	}
+	s.refreshReservation(cart)
+	if err := s.repo.Update(cart); err != nil {
+		return cartError(err)
+	}
+	return nil
+}
+
+// save menyimpan perubahan items; reservasi ikut diperpanjang
+func (s *cartService) save(cart *models.Cart) (*models.Cart, error) {
+	s.refreshReservation(cart)
+	if err := s.repo.Update(cart); err != nil {
+		return nil, cartError(err)
+	}
+	return cart, nil
+}
+
+func (s *cartService) AddItem(cartID int, item models.CartItem) (*models.Cart, error) {
+	if fields := validateCartItem(item, ""); len(fields) > 0 {
+		return nil, NewValidationError("invalid cart item", fields...)
+	}
+	cart, err := s.repo.GetByID(cartID)
+	if err != nil {
+		return nil, fromRepo(err, "cart")
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == item.ProductID {
+			cart.Items[i].Quantity += item.Quantity
+			if item.Discount != nil {
+				cart.Items[i].Discount = item.Discount
+			}
+			return s.save(cart)
+		}
+	}
+	if len(cart.Items) >= MaxCheckoutLines {
+		return nil, NewValidationError("too many items", FieldError{Field: "items", Message: fmt.Sprintf("at most %d lines are allowed", MaxCheckoutLines)})
+	}
+	cart.Items = append(cart.Items, item)
+	return s.save(cart)
+}
+
+func (s *cartService) UpdateItem(cartID int, item models.CartItem) (*models.Cart, error) {
+	if fields := validateCartItem(item, ""); len(fields) > 0 {
+		return nil, NewValidationError("invalid cart item", fields...)
+	}
+	cart, err := s.repo.GetByID(cartID)
+	if err != nil {
+		return nil, fromRepo(err, "cart")
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == item.ProductID {
+			cart.Items[i].Quantity = item.Quantity
+			cart.Items[i].Discount = item.Discount
+			return s.save(cart)
+		}
+	}
+	return nil, NewNotFoundError("product is not in the cart")
+}
+
+func (s *cartService) RemoveItem(cartID, productID int) (*models.Cart, error) {
+	cart, err := s.repo.GetByID(cartID)
+	if err != nil {
+		return nil, fromRepo(err, "cart")
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID {
+			cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+			return s.save(cart)
+		}
+	}
+	return nil, NewNotFoundError("product is not in the cart")
+}
+
+// Checkout memanggil TransactionService.Checkout dengan items cart. Repository
+// mengunci cart, memakai stock yang di-reserve cart untuk penjualan dan menutup
+// cart di DB transaction yang sama, jadi reservasi tidak pernah sempat bebas dan
+// cart yang sama tidak bisa terjual dua kali.
+func (s *cartService) Checkout(cartID int, req models.CheckoutRequest) (*models.Transaction, error) {
+	if len(req.Items) > 0 {
+		return nil, NewValidationError("items come from the cart", FieldError{Field: "items", Message: "must be empty for cart checkout"})
+	}
+	cart, err := s.repo.GetByID(cartID)
+	if err != nil {
+		return nil, fromRepo(err, "cart")
+	}
+	if cart.Status != models.CartOpen {
+		return nil, cartError(repositories.ErrCartClosed)
+	}
+	if len(cart.Items) == 0 {
+		return nil, NewValidationError("cart is empty", FieldError{Field: "items", Message: "at least one item is required"})
+	}
+
+	for _, it := range cart.Items {
+		req.Items = append(req.Items, models.CheckoutItem{ProductID: it.ProductID, Quantity: it.Quantity, Discount: it.Discount})
+	}
+	req.CartID = cartID
+	return s.checkout.Checkout(req)
+}
+
+func (s *cartService) Cancel(id int) error {
+	if err := s.repo.Close(id, models.CartCancelled, nil); err != nil {
+		return cartError(err)
+	}
+	return nil
+}
+
+func (s *cartService) ExpireReservations() (int, error) {
+	n, err := s.repo.ExpireReservations(time.Now())
+	if err != nil {
+		return n, NewInternalError(err)
+	}
+	return n, nil
+}
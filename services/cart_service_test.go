@@ -0,0 +1,158 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func newCartService(f *transactionFixture, ttl time.Duration) services.CartService {
+	return services.NewCartService(memory.NewCartRepository(f.store), f.trx, ttl)
+}
+
+func TestCartServiceReservedCheckout(t *testing.T) {
+	f := newTransactionFixture(t)
+	carts := newCartService(f, time.Minute)
+
+	cart := models.Cart{Note: " Meja 1 ", Reserved: true, Items: []models.CartItem{{ProductID: f.teh.ID, Quantity: 2}}}
+	if err := carts.Create(&cart); err != nil {
+		t.Fatal(err)
+	}
+	if cart.Note != "Meja 1" || cart.ReservedUntil == nil || f.stock(t, f.teh.ID) != 8 {
+		t.Fatalf("cart = %+v, teh stock %d", cart, f.stock(t, f.teh.ID))
+	}
+
+	got, err := carts.AddItem(cart.ID, models.CartItem{ProductID: f.teh.ID, Quantity: 1})
+	if err != nil || got.Items[0].Quantity != 3 || f.stock(t, f.teh.ID) != 7 {
+		t.Fatalf("AddItem = %+v, %v", got, err)
+	}
+	if got, err = carts.AddItem(cart.ID, models.CartItem{ProductID: f.kopi.ID, Quantity: 1}); err != nil || len(got.Items) != 2 {
+		t.Fatalf("AddItem new line = %+v, %v", got, err)
+	}
+	if _, err := carts.UpdateItem(cart.ID, models.CartItem{ProductID: f.kopi.ID, Quantity: 5}); services.AsError(err).Code != services.CodeInsufficientStock {
+		t.Errorf("UpdateItem over stock err = %v", err)
+	}
+	if got, err = carts.RemoveItem(cart.ID, f.kopi.ID); err != nil || len(got.Items) != 1 || f.stock(t, f.kopi.ID) != 2 {
+		t.Fatalf("RemoveItem = %+v, %v", got, err)
+	}
+
+	// Stock yang di-reserve tidak bisa dibeli checkout lain
+	if _, err := f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 8}}}); services.AsError(err).Code != services.CodeInsufficientStock {
+		t.Errorf("checkout over reserved stock err = %v", err)
+	}
+
+	trx, err := carts.Checkout(cart.ID, models.CheckoutRequest{Payments: []models.CheckoutPayment{{Method: "cash", Amount: 20000}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trx.TotalAmount != 15000 || trx.ChangeAmount != 5000 || f.stock(t, f.teh.ID) != 7 {
+		t.Errorf("transaction = %+v, teh stock %d (want 7)", trx, f.stock(t, f.teh.ID))
+	}
+
+	closed, _ := carts.GetByID(cart.ID)
+	if closed.Status != models.CartCheckedOut || closed.TransactionID == nil || *closed.TransactionID != trx.ID || closed.Reserved {
+		t.Errorf("cart after checkout = %+v", closed)
+	}
+	if _, err := carts.Checkout(cart.ID, models.CheckoutRequest{}); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("second checkout err = %v, want CONFLICT", err)
+	}
+	if open, _ := carts.GetAll(); len(open) != 0 {
+		t.Errorf("open carts = %+v", open)
+	}
+}
+
+func TestCartServiceFailedCheckoutKeepsReservation(t *testing.T) {
+	f := newTransactionFixture(t)
+	carts := newCartService(f, time.Minute)
+
+	cart := models.Cart{Reserved: true, Items: []models.CartItem{{ProductID: f.kopi.ID, Quantity: 2}}}
+	if err := carts.Create(&cart); err != nil {
+		t.Fatal(err)
+	}
+	_, err := carts.Checkout(cart.ID, models.CheckoutRequest{Payments: []models.CheckoutPayment{{Method: "cash", Amount: 1000}}})
+	if services.AsError(err).Code != services.CodeInsufficientPayment {
+		t.Fatalf("err = %v, want INSUFFICIENT_PAYMENT", err)
+	}
+	got, _ := carts.GetByID(cart.ID)
+	if got.Status != models.CartOpen || !got.Reserved || f.stock(t, f.kopi.ID) != 0 {
+		t.Errorf("cart = %+v, kopi stock %d; reservation must be restored", got, f.stock(t, f.kopi.ID))
+	}
+
+	if err := carts.Cancel(cart.ID); err != nil {
+		t.Fatal(err)
+	}
+	if f.stock(t, f.kopi.ID) != 2 {
+		t.Errorf("kopi stock after cancel = %d, want 2", f.stock(t, f.kopi.ID))
+	}
+	if err := carts.Cancel(cart.ID); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("cancel twice err = %v, want CONFLICT", err)
+	}
+}
+
+func TestCartServiceValidation(t *testing.T) {
+	f := newTransactionFixture(t)
+	carts := newCartService(f, time.Minute)
+
+	tests := []struct {
+		name     string
+		cart     models.Cart
+		wantCode services.ErrorCode
+	}{
+		{"empty cart is allowed", models.Cart{Note: "nanti"}, ""},
+		{"zero quantity", models.Cart{Items: []models.CartItem{{ProductID: f.teh.ID}}}, services.CodeValidation},
+		{"duplicate product", models.Cart{Items: []models.CartItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.teh.ID, Quantity: 2}}}, services.CodeValidation},
+		{"unknown product", models.Cart{Items: []models.CartItem{{ProductID: 99, Quantity: 1}}}, services.CodeValidation},
+		{"reserve more than stock", models.Cart{Reserved: true, Items: []models.CartItem{{ProductID: f.kopi.ID, Quantity: 3}}}, services.CodeInsufficientStock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := carts.Create(&tt.cart)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if got := services.AsError(err).Code; got != tt.wantCode {
+				t.Errorf("code = %s, want %s (err %v)", got, tt.wantCode, err)
+			}
+		})
+	}
+
+	if _, err := carts.Checkout(1, models.CheckoutRequest{}); services.AsError(err).Code != services.CodeValidation {
+		t.Errorf("checkout empty cart err = %v, want VALIDATION_ERROR", err)
+	}
+}
+
+func TestCartSweeperReleasesExpiredReservations(t *testing.T) {
+	f := newTransactionFixture(t)
+	carts := newCartService(f, time.Millisecond)
+
+	cart := models.Cart{Reserved: true, Items: []models.CartItem{{ProductID: f.teh.ID, Quantity: 4}}}
+	if err := carts.Create(&cart); err != nil {
+		t.Fatal(err)
+	}
+	if f.stock(t, f.teh.ID) != 6 {
+		t.Fatalf("teh stock = %d, want 6", f.stock(t, f.teh.ID))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go services.RunSweeper(ctx, "cart reservation", 5*time.Millisecond, carts.ExpireReservations)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for f.stock(t, f.teh.ID) != 10 {
+		if time.Now().After(deadline) {
+			t.Fatalf("reservation not released, teh stock %d", f.stock(t, f.teh.ID))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	got, _ := carts.GetByID(cart.ID)
+	if got.Reserved || got.Status != models.CartOpen || len(got.Items) != 1 {
+		t.Errorf("cart after expiry = %+v, want open and unreserved", got)
+	}
+}
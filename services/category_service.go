@@ -2,54 +2,91 @@ package services
 
 import (
 	"errors"
+	"strings"
+
 	"kasir-api-golang-v1/models"
 	"kasir-api-golang-v1/repositories"
 )
 
-type CategoryService struct {
-	catRepo  *repositories.CategoryRepository
-	prodRepo *repositories.ProductRepository
+type categoryService struct {
+	catRepo  repositories.CategoryRepository
+	prodRepo repositories.ProductRepository
 }
 
-func NewCategoryService(catRepo *repositories.CategoryRepository, prodRepo *repositories.ProductRepository) *CategoryService {
-	return &CategoryService{catRepo: catRepo, prodRepo: prodRepo}
+func NewCategoryService(catRepo repositories.CategoryRepository, prodRepo repositories.ProductRepository) CategoryService {
+	return &categoryService{catRepo: catRepo, prodRepo: prodRepo}
 }
 
-func (s *CategoryService) GetAll() ([]models.Category, error) {
-	return s.catRepo.GetAll()
+func validateCategory(category *models.Category) error {
+	if strings.TrimSpace(category.Name) == "" {
+		return NewValidationError("invalid category", FieldError{Field: "name", Message: "name is required"})
+	}
+	return nil
 }
 
-func (s *CategoryService) GetByID(id int) (*models.Category, error) {
-	return s.catRepo.GetByID(id)
+func (s *categoryService) GetAll() ([]models.Category, error) {
+	categories, err := s.catRepo.GetAll()
+	if err != nil {
+		return nil, fromRepo(err, "category")
+	}
+	return categories, nil
 }
 
-func (s *CategoryService) Create(category *models.Category) error {
-	return s.catRepo.Create(category)
+func (s *categoryService) GetByID(id int) (*models.Category, error) {
+	category, err := s.catRepo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "category")
+	}
+	return category, nil
 }
 
-func (s *CategoryService) Update(category *models.Category) error {
-	return s.catRepo.Update(category)
+func (s *categoryService) Create(category *models.Category, actorID int) error {
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	if err := s.catRepo.Create(category, actorID); err != nil {
+		return fromRepo(err, "category")
+	}
+	return nil
 }
 
-// LOGIC SPESIAL: Safe Delete
-func (s *CategoryService) Delete(id int) error {
+func (s *categoryService) Update(category *models.Category, actorID int) error {
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	if err := s.catRepo.Update(category, actorID); err != nil {
+		return fromRepo(err, "category")
+	}
+	return nil
+}
+
+// LOGIC SPESIAL: Safe Delete. Pemindahan produk dan penghapusan kategori
+// sama-sama tercatat di audit log atas nama actorID.
+func (s *categoryService) Delete(id, actorID int) error {
 	// 1. Ambil ID dari "No Category"
 	defaultCat, err := s.catRepo.GetByName("No Category")
 	if err != nil {
-		return errors.New("system error: 'No Category' default data missing")
+		return NewInternalError(errors.New("system error: 'No Category' default data missing"))
 	}
 
 	// 2. Cegah penghapusan kategori default itu sendiri
 	if id == defaultCat.ID {
-		return errors.New("cannot delete default category")
+		return NewConflictError("cannot delete default category")
 	}
 
-	// 3. Pindahkan semua produk di kategori ini ke "No Category"
-	err = s.prodRepo.BulkUpdateCategory(id, defaultCat.ID)
-	if err != nil {
+	// 3. Pastikan kategori ada sebelum memindahkan produk
+	if _, err := s.GetByID(id); err != nil {
 		return err
 	}
 
-	// 4. Hapus kategori
-	return s.catRepo.Delete(id)
-}
\ No newline at end of file
+	// 4. Pindahkan semua produk di kategori ini ke "No Category"
+	if err := s.prodRepo.BulkUpdateCategory(id, defaultCat.ID, actorID); err != nil {
+		return fromRepo(err, "category")
+	}
+
+	// 5. Hapus kategori
+	if err := s.catRepo.Delete(id, actorID); err != nil {
+		return fromRepo(err, "category")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"testing"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func newCategoryService() (services.CategoryService, services.ProductService) {
+	store := memory.NewStore()
+	productRepo := memory.NewProductRepository(store)
+	return services.NewCategoryService(memory.NewCategoryRepository(store), productRepo),
+		services.NewProductService(productRepo, memory.NewStockMovementRepository(store))
+}
+
+func TestCategoryServiceSafeDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  func(created int) int
+		wantErr bool
+	}{
+		{"moves products to default category", func(created int) int { return created }, false},
+		{"refuses to delete default category", func(int) int { return 1 }, true},
+		{"missing category", func(int) int { return 999 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catSvc, prodSvc := newCategoryService()
+			cat := models.Category{Name: "Minuman"}
+			if err := catSvc.Create(&cat, 0); err != nil {
+				t.Fatal(err)
+			}
+			p := models.Product{Name: "Teh", Price: 5000, CategoryID: cat.ID}
+			if err := prodSvc.Create(&p, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			err := catSvc.Delete(tt.target(cat.ID), 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Delete err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if _, err := catSvc.GetByID(cat.ID); err == nil {
+				t.Error("category still exists after delete")
+			}
+			got, err := prodSvc.GetByID(p.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.CategoryID != 1 || got.CategoryName != "No Category" {
+				t.Errorf("product category = %d/%q, want 1/No Category", got.CategoryID, got.CategoryName)
+			}
+		})
+	}
+}
+
+func TestCategoryServiceCRUD(t *testing.T) {
+	catSvc, _ := newCategoryService()
+
+	cat := models.Category{Name: "Makanan"}
+	if err := catSvc.Create(&cat, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr bool
+	}{
+		{"get existing", func() error { _, err := catSvc.GetByID(cat.ID); return err }, false},
+		{"get missing", func() error { _, err := catSvc.GetByID(999); return err }, true},
+		{"update existing", func() error { return catSvc.Update(&models.Category{ID: cat.ID, Name: "Snack"}, 0) }, false},
+		{"update missing", func() error { return catSvc.Update(&models.Category{ID: 999, Name: "X"}, 0) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	all, err := catSvc.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[1].Name != "Snack" {
+		t.Errorf("GetAll = %+v, want default category and Snack", all)
+	}
+}
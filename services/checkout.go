@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+
+	"kasir-api-golang-v1/models"
+)
+
+// MaxCheckoutLines adalah batas jumlah baris item dalam satu checkout
+const MaxCheckoutLines = 100
+
+// NormalizeCheckoutItems memvalidasi setiap baris (product_id dan quantity harus
+// positif, diskon valid) lalu menggabungkan baris dengan product_id dan diskon
+// yang sama (diskon fixed dijumlahkan). Urutan hasil mengikuti kemunculan pertama tiap baris. Semua baris
+// yang salah dilaporkan sekaligus di Details supaya kasir bisa memperbaiki dalam
+// satu kali kirim.
+func NormalizeCheckoutItems(items []models.CheckoutItem) ([]models.CheckoutItem, error) {
+	if len(items) == 0 {
+		return nil, NewValidationError("items cannot be empty", FieldError{Field: "items", Message: "at least one item is required"})
+	}
+	if len(items) > MaxCheckoutLines {
+		return nil, NewValidationError("too many items", FieldError{
+			Field:   "items",
+			Message: fmt.Sprintf("at most %d lines are allowed, got %d", MaxCheckoutLines, len(items)),
+		})
+	}
+
+	var fields []FieldError
+	for i, item := range items {
+		if item.ProductID <= 0 {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("items[%d].product_id", i), Message: "must be a positive product ID"})
+		}
+		if item.Quantity <= 0 {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("items[%d].quantity", i), Message: "must be greater than zero"})
+		}
+		fields = append(fields, validateDiscount(item.Discount, fmt.Sprintf("items[%d].discount", i))...)
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid checkout items", fields...)
+	}
+
+	// Baris dengan produk sama tapi diskon berbeda tetap dipisah. Diskon fixed
+	// berlaku per baris, jadi saat digabung nilainya dijumlahkan supaya total
+	// potongan sama dengan yang diinput kasir.
+	type lineKey struct {
+		productID  int
+		discounted bool
+		discount   models.Discount
+	}
+	merged := make([]models.CheckoutItem, 0, len(items))
+	index := map[lineKey]int{}
+	for _, item := range items {
+		key := lineKey{productID: item.ProductID}
+		if item.Discount != nil {
+			key.discounted = true
+			key.discount = *item.Discount
+			if item.Discount.Type == models.DiscountFixed {
+				key.discount.Value = 0
+			}
+		}
+		if i, ok := index[key]; ok {
+			merged[i].Quantity += item.Quantity
+			if key.discount.Type == models.DiscountFixed {
+				sum := *merged[i].Discount
+				sum.Value += item.Discount.Value
+				merged[i].Discount = &sum
+			}
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged, nil
+}
@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"reflect"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+)
+
+func TestNormalizeCheckoutItems(t *testing.T) {
+	tooMany := make([]models.CheckoutItem, services.MaxCheckoutLines+1)
+	for i := range tooMany {
+		tooMany[i] = models.CheckoutItem{ProductID: i + 1, Quantity: 1}
+	}
+
+	tests := []struct {
+		name       string
+		items      []models.CheckoutItem
+		want       []models.CheckoutItem
+		wantFields []string
+	}{
+		{
+			name:  "merges duplicates keeping first order",
+			items: []models.CheckoutItem{{ProductID: 2, Quantity: 1}, {ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 3}},
+			want:  []models.CheckoutItem{{ProductID: 2, Quantity: 4}, {ProductID: 1, Quantity: 2}},
+		},
+		{
+			name: "fixed discounts summed when merged",
+			items: []models.CheckoutItem{
+				{ProductID: 5, Quantity: 1, Discount: &models.Discount{Type: models.DiscountFixed, Value: 1000}},
+				{ProductID: 5, Quantity: 1, Discount: &models.Discount{Type: models.DiscountFixed, Value: 1000}},
+				{ProductID: 5, Quantity: 1},
+			},
+			want: []models.CheckoutItem{
+				{ProductID: 5, Quantity: 2, Discount: &models.Discount{Type: models.DiscountFixed, Value: 2000}},
+				{ProductID: 5, Quantity: 1},
+			},
+		},
+		{
+			name:       "empty",
+			items:      nil,
+			wantFields: []string{"items"},
+		},
+		{
+			name:       "zero and negative quantities reported per line",
+			items:      []models.CheckoutItem{{ProductID: 1, Quantity: 0}, {ProductID: 2, Quantity: 1}, {ProductID: 3, Quantity: -2}},
+			wantFields: []string{"items[0].quantity", "items[2].quantity"},
+		},
+		{
+			name:       "invalid product id",
+			items:      []models.CheckoutItem{{ProductID: 0, Quantity: 1}},
+			wantFields: []string{"items[0].product_id"},
+		},
+		{
+			name:       "too many lines",
+			items:      tooMany,
+			wantFields: []string{"items"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := services.NormalizeCheckoutItems(tt.items)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("got %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			e := services.AsError(err)
+			if e.Code != services.CodeValidation {
+				t.Fatalf("code = %s, want VALIDATION_ERROR", e.Code)
+			}
+			fields, _ := e.Details.([]services.FieldError)
+			var names []string
+			for _, f := range fields {
+				names = append(names, f.Field)
+			}
+			if !reflect.DeepEqual(names, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", names, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestCheckoutMergesDuplicateLines(t *testing.T) {
+	f := newTransactionFixture(t)
+	// Dua baris kopi (1 + 1) tetap muat di stock 2 dan tercatat sebagai satu detail
+	trx, err := f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.kopi.ID, Quantity: 1}, {ProductID: f.kopi.ID, Quantity: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trx.Details) != 1 || trx.Details[0].Quantity != 2 || trx.TotalAmount != 16000 {
+		t.Errorf("transaction = %+v", trx)
+	}
+
+	// Diskon fixed per baris tetap terhitung dua kali setelah baris digabung
+	fixed := func() *models.Discount { return &models.Discount{Type: models.DiscountFixed, Value: 1000} }
+	trx, err = f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1, Discount: fixed()}, {ProductID: f.teh.ID, Quantity: 1, Discount: fixed()}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trx.Details) != 1 || trx.TotalAmount != 8000 || trx.DiscountAmount != 2000 {
+		t.Errorf("fixed discount transaction = %+v", trx)
+	}
+
+	// Setelah digabung, total teh (11) melebihi sisa stock 8
+	_, err = f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.teh.ID, Quantity: 10}}})
+	if services.AsError(err).Code != services.CodeInsufficientStock {
+		t.Errorf("err = %v, want INSUFFICIENT_STOCK", err)
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"kasir-api-golang-v1/models"
+)
+
+// validateDiscount memvalidasi diskon manual; field dipakai sebagai nama field di FieldError
+func validateDiscount(d *models.Discount, field string) []FieldError {
+	if d == nil {
+		return nil
+	}
+	switch {
+	case d.Type != models.DiscountPercent && d.Type != models.DiscountFixed:
+		return []FieldError{{Field: field + ".type", Message: "must be percent or fixed"}}
+	case d.Value < 0:
+		return []FieldError{{Field: field + ".value", Message: "must not be negative"}}
+	case d.Type == models.DiscountPercent && d.Value > 100:
+		return []FieldError{{Field: field + ".value", Message: "percent must be between 0 and 100"}}
+	}
+	return nil
+}
+
+// discountAmount menghitung potongan dari amount, tidak pernah melebihi amount.
+// Diskon persen dibulatkan ke bawah (rupiah).
+func discountAmount(d *models.Discount, amount int) int {
+	if d == nil || amount <= 0 {
+		return 0
+	}
+	cut := d.Value
+	if d.Type == models.DiscountPercent {
+		cut = amount * d.Value / 100
+	}
+	if cut > amount {
+		cut = amount
+	}
+	return cut
+}
+
+// allocateDiscount membebankan diskon transaksi ke setiap baris secara proporsional
+// terhadap subtotal, supaya jumlah subtotal selalu sama dengan total transaksi dan
+// refund per baris tetap benar.
+func allocateDiscount(details []models.TransactionDetail, discount int) {
+	weights := make([]int, len(details))
+	for i, d := range details {
+		weights[i] = d.Subtotal
+	}
+	for i, share := range allocate(discount, weights) {
+		details[i].Subtotal -= share
+		details[i].DiscountAmount += share
+	}
+}
+
+// allocate membagi amount sesuai bobot. Pembulatan memakai proporsi kumulatif
+// seperti repositories.RefundAmount sehingga jumlah bagian selalu tepat amount
+// (kecuali semua bobot nol, maka tidak ada yang dibagi).
+func allocate(amount int, weights []int) []int {
+	shares := make([]int, len(weights))
+	base := 0
+	for _, w := range weights {
+		base += w
+	}
+	if amount <= 0 || base == 0 {
+		return shares
+	}
+
+	cumulative, allocated := 0, 0
+	for i, w := range weights {
+		cumulative += w
+		shares[i] = amount*cumulative/base - allocated
+		allocated += shares[i]
+	}
+	return shares
+}
+
+// checkPromo memastikan promo bisa dipakai saat ini untuk belanja sebesar spend.
+// Kuota (usage_limit) dicek ulang secara atomik oleh repository saat checkout.
+func checkPromo(promo *models.PromoCode, spend int, now time.Time) error {
+	reject := func(msg string) error {
+		return NewValidationError("promo code cannot be used", FieldError{Field: "promo_code", Message: msg})
+	}
+	switch {
+	case !promo.Active:
+		return reject("promo code is inactive")
+	case promo.ValidFrom != nil && now.Before(*promo.ValidFrom):
+		return reject("promo code is not valid yet")
+	case promo.ValidUntil != nil && now.After(*promo.ValidUntil):
+		return reject("promo code has expired")
+	case promo.UsageLimit > 0 && promo.UsedCount >= promo.UsageLimit:
+		return reject("promo code usage limit reached")
+	case spend < promo.MinSpend:
+		return reject(fmt.Sprintf("minimum spend is %d", promo.MinSpend))
+	}
+	return nil
+}
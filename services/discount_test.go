@@ -0,0 +1,148 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+)
+
+func TestCheckoutDiscounts(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	// teh 5000 (stock 10), kopi 8000 (stock 2)
+	tests := []struct {
+		name         string
+		req          func(f *transactionFixture) models.CheckoutRequest
+		wantCode     services.ErrorCode
+		wantTotal    int
+		wantDiscount int
+		wantLines    []int // subtotal per baris setelah diskon
+	}{
+		{
+			name: "line percent and fixed",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{
+					{ProductID: f.teh.ID, Quantity: 2, Discount: &models.Discount{Type: "percent", Value: 10}},
+					{ProductID: f.kopi.ID, Quantity: 1, Discount: &models.Discount{Type: "fixed", Value: 3000}},
+				}}
+			},
+			wantTotal: 14000, wantDiscount: 4000, wantLines: []int{9000, 5000},
+		},
+		{
+			name: "fixed line discount capped at line total",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{
+					{ProductID: f.teh.ID, Quantity: 1, Discount: &models.Discount{Type: "fixed", Value: 9000}},
+				}}
+			},
+			wantTotal: 0, wantDiscount: 5000, wantLines: []int{0},
+		},
+		{
+			name: "cart discount allocated across lines without leftover",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{
+					Items:    []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.kopi.ID, Quantity: 1}},
+					Discount: &models.Discount{Type: "fixed", Value: 1000},
+				}
+			},
+			wantTotal: 12000, wantDiscount: 1000, wantLines: []int{4616, 7384},
+		},
+		{
+			name: "promo applied after cart discount",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{
+					Items:     []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 4}},
+					Discount:  &models.Discount{Type: "fixed", Value: 2000},
+					PromoCode: " hemat10 ",
+				}
+			},
+			wantTotal: 16200, wantDiscount: 3800, wantLines: []int{16200},
+		},
+		{
+			name: "promo below minimum spend",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}}, PromoCode: "HEMAT10"}
+			},
+			wantCode: services.CodeValidation,
+		},
+		{
+			name: "expired promo",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}}, PromoCode: "LEWAT"}
+			},
+			wantCode: services.CodeValidation,
+		},
+		{
+			name: "unknown promo",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}}, PromoCode: "NGASAL"}
+			},
+			wantCode: services.CodeValidation,
+		},
+		{
+			name: "percent over 100",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{
+					Items:    []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}},
+					Discount: &models.Discount{Type: "percent", Value: 150},
+				}
+			},
+			wantCode: services.CodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransactionFixture(t)
+			for _, p := range []models.PromoCode{
+				{Code: "HEMAT10", Type: "percent", Value: 10, MinSpend: 10000, ValidFrom: &past, ValidUntil: &future, Active: true},
+				{Code: "LEWAT", Type: "fixed", Value: 1000, ValidUntil: &past, Active: true},
+			} {
+				if err := f.promos.Create(&p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			trx, err := f.trx.Checkout(tt.req(f))
+			if tt.wantCode != "" {
+				if e := services.AsError(err); e.Code != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trx.TotalAmount != tt.wantTotal || trx.DiscountAmount != tt.wantDiscount || trx.GrossAmount != tt.wantTotal+tt.wantDiscount {
+				t.Errorf("total/discount/gross = %d/%d/%d, want %d/%d", trx.TotalAmount, trx.DiscountAmount, trx.GrossAmount, tt.wantTotal, tt.wantDiscount)
+			}
+			for i, want := range tt.wantLines {
+				if trx.Details[i].Subtotal != want {
+					t.Errorf("line %d subtotal = %d, want %d", i, trx.Details[i].Subtotal, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckoutPromoUsageLimit(t *testing.T) {
+	f := newTransactionFixture(t)
+	promo := models.PromoCode{Code: "SEKALI", Type: "fixed", Value: 1000, UsageLimit: 1, Active: true}
+	if err := f.promos.Create(&promo); err != nil {
+		t.Fatal(err)
+	}
+
+	req := models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}}, PromoCode: "SEKALI"}
+	if _, err := f.trx.Checkout(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.trx.Checkout(req); services.AsError(err).Code != services.CodeValidation {
+		t.Errorf("second use err = %v, want VALIDATION_ERROR", err)
+	}
+	if got, _ := f.promos.GetByID(promo.ID); got.UsedCount != 1 {
+		t.Errorf("used_count = %d, want 1", got.UsedCount)
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"kasir-api-golang-v1/repositories"
+)
+
+// ErrorCode adalah kode error stabil yang dikirim ke client (frontend POS)
+type ErrorCode string
+
+const (
+	CodeNotFound            ErrorCode = "NOT_FOUND"
+	CodeValidation          ErrorCode = "VALIDATION_ERROR"
+	CodeConflict            ErrorCode = "CONFLICT"
+	CodeInsufficientStock   ErrorCode = "INSUFFICIENT_STOCK"
+	CodeInsufficientPayment ErrorCode = "INSUFFICIENT_PAYMENT"
+	CodeIdempotencyMismatch ErrorCode = "IDEMPOTENCY_KEY_REUSED" // key sama dipakai untuk request berbeda
+	CodeUnauthorized        ErrorCode = "UNAUTHORIZED"           // token tidak ada, salah atau kedaluwarsa
+	CodeForbidden           ErrorCode = "FORBIDDEN"              // sudah login tapi role-nya tidak punya permission
+	CodeInternal            ErrorCode = "INTERNAL_ERROR"
+)
+
+// Error adalah error bertipe dari layer service. Handler memetakan Code ke
+// HTTP status, Message dan Details dikirim apa adanya ke client.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details interface{}
+	Err     error // penyebab asli, tidak dikirim ke client
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// FieldError adalah detail validasi per field / per baris
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// StockShortage adalah detail untuk CodeInsufficientStock
+type StockShortage struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Available   int    `json:"available"`
+	Requested   int    `json:"requested"`
+}
+
+// PaymentShortage adalah detail untuk CodeInsufficientPayment
+type PaymentShortage struct {
+	Total     int `json:"total"`
+	Paid      int `json:"paid"`
+	Shortfall int `json:"shortfall"`
+}
+
+func NewNotFoundError(message string) *Error {
+	return &Error{Code: CodeNotFound, Message: message}
+}
+
+func NewValidationError(message string, details ...FieldError) *Error {
+	e := &Error{Code: CodeValidation, Message: message}
+	if len(details) > 0 {
+		e.Details = details
+	}
+	return e
+}
+
+func NewConflictError(message string) *Error {
+	return &Error{Code: CodeConflict, Message: message}
+}
+
+func NewUnauthorizedError(message string) *Error {
+	return &Error{Code: CodeUnauthorized, Message: message}
+}
+
+func NewForbiddenError(message string) *Error {
+	return &Error{Code: CodeForbidden, Message: message}
+}
+
+func NewInsufficientStockError(s StockShortage) *Error {
+	return &Error{
+		Code:    CodeInsufficientStock,
+		Message: fmt.Sprintf("insufficient stock for product %s", s.ProductName),
+		Details: s,
+	}
+}
+
+// stockShortage menerjemahkan InsufficientStockError dari repository, nil kalau err bukan error stock
+func stockShortage(err error) *Error {
+	var stockErr *repositories.InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		return nil
+	}
+	return NewInsufficientStockError(StockShortage{
+		ProductID:   stockErr.ProductID,
+		ProductName: stockErr.ProductName,
+		Available:   stockErr.Available,
+		Requested:   stockErr.Requested,
+	})
+}
+
+func NewInsufficientPaymentError(s PaymentShortage) *Error {
+	return &Error{
+		Code:    CodeInsufficientPayment,
+		Message: fmt.Sprintf("payment is %d short of total %d", s.Shortfall, s.Total),
+		Details: s,
+	}
+}
+
+func NewInternalError(err error) *Error {
+	return &Error{Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// AsError mengubah error apapun menjadi *Error; error yang belum bertipe dianggap internal
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return NewInternalError(err)
+}
+
+// fromRepo menerjemahkan sentinel error repository menjadi *Error.
+// entity dipakai untuk pesan, misalnya "product" -> "product not found".
+func fromRepo(err error, entity string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repositories.ErrNotFound):
+		return &Error{Code: CodeNotFound, Message: entity + " not found", Err: err}
+	case errors.Is(err, repositories.ErrDuplicate):
+		return &Error{Code: CodeConflict, Message: entity + " already exists", Err: err}
+	case errors.Is(err, repositories.ErrForeignKey):
+		return &Error{Code: CodeConflict, Message: entity + " is still referenced by other data", Err: err}
+	case errors.Is(err, repositories.ErrLastOwner):
+		// Tanpa owner aktif tidak ada yang bisa mengelola user dan role lagi
+		return &Error{Code: CodeConflict, Message: err.Error(), Err: err}
+	default:
+		return NewInternalError(err)
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+// DefaultIdempotencyKeyTTL dipakai kalau IDEMPOTENCY_KEY_TTL tidak diisi
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+type idempotencyService struct {
+	repo repositories.IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyService: ttl adalah lama key disimpan sejak request pertama
+func NewIdempotencyService(repo repositories.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+	return &idempotencyService{repo: repo, ttl: ttl}
+}
+
+func (s *idempotencyService) Begin(key, requestHash string) (*models.IdempotencyRecord, error) {
+	// Dua kali percobaan: key yang kedaluwarsa dihapus dulu lalu dicadangkan ulang
+	for attempt := 0; attempt < 2; attempt++ {
+		now := time.Now()
+		err := s.repo.Create(&models.IdempotencyRecord{Key: key, RequestHash: requestHash, ExpiresAt: now.Add(s.ttl)})
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, repositories.ErrDuplicate) {
+			return nil, NewInternalError(err)
+		}
+
+		existing, err := s.repo.GetByKey(key)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue // baru saja dihapus (abort / expired), coba cadangkan lagi
+		}
+		if err != nil {
+			return nil, NewInternalError(err)
+		}
+		if existing.ExpiresAt.Before(now) {
+			if err := s.repo.Delete(key); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+				return nil, NewInternalError(err)
+			}
+			continue
+		}
+
+		switch {
+		case existing.RequestHash != requestHash:
+			return nil, &Error{Code: CodeIdempotencyMismatch, Message: "Idempotency-Key was already used for a different request"}
+		case existing.StatusCode == 0:
+			return nil, NewConflictError("a request with this Idempotency-Key is still being processed")
+		}
+		return existing, nil
+	}
+	return nil, NewConflictError("a request with this Idempotency-Key is still being processed")
+}
+
+func (s *idempotencyService) Complete(key string, statusCode int, response []byte) error {
+	if err := s.repo.Complete(key, statusCode, response); err != nil {
+		return fromRepo(err, "idempotency key")
+	}
+	return nil
+}
+
+func (s *idempotencyService) Abort(key string) error {
+	if err := s.repo.Delete(key); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return NewInternalError(err)
+	}
+	return nil
+}
+
+func (s *idempotencyService) PurgeExpired() (int, error) {
+	n, err := s.repo.DeleteExpired(time.Now())
+	if err != nil {
+		return n, NewInternalError(err)
+	}
+	return n, nil
+}
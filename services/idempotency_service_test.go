@@ -0,0 +1,66 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func TestIdempotencyServiceBegin(t *testing.T) {
+	store := memory.NewStore()
+	svc := services.NewIdempotencyService(memory.NewIdempotencyRepository(store), time.Hour)
+
+	rec, err := svc.Begin("k1", "hash-a")
+	if err != nil || rec != nil {
+		t.Fatalf("first Begin = %+v, %v, want reserved", rec, err)
+	}
+	if _, err := svc.Begin("k1", "hash-a"); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("Begin while processing err = %v, want CONFLICT", err)
+	}
+	if err := svc.Complete("k1", 200, []byte(`{"id":7}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err = svc.Begin("k1", "hash-a")
+	if err != nil || rec == nil || rec.StatusCode != 200 || string(rec.Response) != `{"id":7}` {
+		t.Errorf("replay Begin = %+v, %v", rec, err)
+	}
+	if _, err := svc.Begin("k1", "hash-b"); services.AsError(err).Code != services.CodeIdempotencyMismatch {
+		t.Errorf("Begin with other body err = %v, want IDEMPOTENCY_KEY_REUSED", err)
+	}
+
+	// Abort melepas key supaya retry diproses ulang
+	if _, err := svc.Begin("k2", "hash-a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Abort("k2"); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := svc.Begin("k2", "hash-b"); err != nil || rec != nil {
+		t.Errorf("Begin after Abort = %+v, %v, want reserved", rec, err)
+	}
+}
+
+func TestIdempotencyServiceExpiry(t *testing.T) {
+	store := memory.NewStore()
+	repo := memory.NewIdempotencyRepository(store)
+	svc := services.NewIdempotencyService(repo, time.Hour)
+
+	// Key kedaluwarsa boleh dipakai lagi untuk request lain walaupun sweeper belum jalan
+	if err := repo.Create(&models.IdempotencyRecord{Key: "old", RequestHash: "hash-a", ExpiresAt: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := svc.Begin("old", "hash-b"); err != nil || rec != nil {
+		t.Errorf("Begin on expired key = %+v, %v, want reserved", rec, err)
+	}
+
+	if err := repo.Create(&models.IdempotencyRecord{Key: "older", RequestHash: "x", ExpiresAt: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := svc.PurgeExpired(); err != nil || n != 1 {
+		t.Errorf("PurgeExpired = %d, %v, want 1", n, err)
+	}
+}
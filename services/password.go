@@ -0,0 +1,76 @@
+package services
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Password di-hash dengan PBKDF2-HMAC-SHA256. Jumlah iterasi ikut disimpan di
+// hash ("pbkdf2-sha256$iterasi$salt$key") supaya bisa dinaikkan tanpa memutus
+// password lama.
+const (
+	passwordScheme     = "pbkdf2-sha256"
+	passwordSaltLength = 16
+	passwordKeyLength  = 32
+	minPasswordLength  = 8
+)
+
+// DefaultPasswordIterations adalah jumlah iterasi PBKDF2 untuk production
+const DefaultPasswordIterations = 600000
+
+// PasswordHasher meng-hash password baru. Iterations 0 berarti
+// DefaultPasswordIterations; test memakai angka kecil supaya cepat.
+type PasswordHasher struct {
+	Iterations int
+}
+
+func (h PasswordHasher) iterations() int {
+	if h.Iterations <= 0 {
+		return DefaultPasswordIterations
+	}
+	return h.Iterations
+}
+
+// Hash mengembalikan "pbkdf2-sha256$iterasi$salt$key" dengan salt acak
+func (h PasswordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	iterations := h.iterations()
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, passwordKeyLength)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", passwordScheme, iterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// checkPassword membandingkan password dengan hash; hash yang rusak dianggap tidak cocok
+func checkPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	return err == nil && subtle.ConstantTimeCompare(got, want) == 1
+}
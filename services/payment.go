@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+
+	"kasir-api-golang-v1/models"
+)
+
+// paymentMethods adalah metode pembayaran yang diterima di checkout
+var paymentMethods = map[string]bool{
+	models.PaymentCash:    true,
+	models.PaymentCard:    true,
+	models.PaymentQRIS:    true,
+	models.PaymentEWallet: true,
+}
+
+// ValidatePayments memvalidasi setiap baris pembayaran (metode dikenal, amount positif).
+// Semua baris yang salah dilaporkan sekaligus seperti NormalizeCheckoutItems.
+func ValidatePayments(payments []models.CheckoutPayment) error {
+	var fields []FieldError
+	for i, p := range payments {
+		if !paymentMethods[p.Method] {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("payments[%d].method", i), Message: "must be one of cash, card, qris, ewallet"})
+		}
+		if p.Amount <= 0 {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("payments[%d].amount", i), Message: "must be greater than zero"})
+		}
+	}
+	if len(fields) > 0 {
+		return NewValidationError("invalid payments", fields...)
+	}
+	return nil
+}
+
+// SettlePayments menghitung pembayaran yang masuk ke tagihan, total dibayar dan
+// kembalian. Kembalian hanya bisa dari cash, jadi pembayaran non-cash tidak boleh
+// melebihi total. Tanpa payments (client lama) transaksi dianggap dibayar pas dengan cash.
+func SettlePayments(total int, inputs []models.CheckoutPayment) (payments []models.Payment, paid int, change int, err error) {
+	if len(inputs) == 0 {
+		if total == 0 {
+			return nil, 0, 0, nil
+		}
+		return []models.Payment{{Method: models.PaymentCash, Amount: total, Tendered: total}}, total, 0, nil
+	}
+
+	nonCash := 0
+	for _, in := range inputs {
+		paid += in.Amount
+		if in.Method != models.PaymentCash {
+			nonCash += in.Amount
+		}
+		payments = append(payments, models.Payment{Method: in.Method, Amount: in.Amount, Tendered: in.Amount})
+	}
+
+	if paid < total {
+		return nil, 0, 0, NewInsufficientPaymentError(PaymentShortage{Total: total, Paid: paid, Shortfall: total - paid})
+	}
+	if nonCash > total {
+		return nil, 0, 0, NewValidationError("non-cash payments exceed total", FieldError{
+			Field:   "payments",
+			Message: fmt.Sprintf("card, qris and ewallet payments must not exceed total %d", total),
+		})
+	}
+
+	// Kembalian dipotong dari pembayaran cash, mulai dari yang terakhir
+	change = paid - total
+	remaining := change
+	for i := len(payments) - 1; i >= 0 && remaining > 0; i-- {
+		if payments[i].Method != models.PaymentCash {
+			continue
+		}
+		cut := remaining
+		if cut > payments[i].Amount {
+			cut = payments[i].Amount
+		}
+		payments[i].Amount -= cut
+		remaining -= cut
+	}
+	return payments, paid, change, nil
+}
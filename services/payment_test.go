@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"reflect"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/services"
+)
+
+func TestSettlePayments(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		inputs     []models.CheckoutPayment
+		want       []models.Payment
+		wantPaid   int
+		wantChange int
+		wantCode   services.ErrorCode
+	}{
+		{
+			name:     "no payments means exact cash",
+			total:    15000,
+			want:     []models.Payment{{Method: "cash", Amount: 15000, Tendered: 15000}},
+			wantPaid: 15000,
+		},
+		{
+			name:       "cash with change",
+			total:      15000,
+			inputs:     []models.CheckoutPayment{{Method: "cash", Amount: 20000}},
+			want:       []models.Payment{{Method: "cash", Amount: 15000, Tendered: 20000}},
+			wantPaid:   20000,
+			wantChange: 5000,
+		},
+		{
+			name:       "split card and cash, change from cash",
+			total:      15000,
+			inputs:     []models.CheckoutPayment{{Method: "card", Amount: 10000}, {Method: "cash", Amount: 10000}},
+			want:       []models.Payment{{Method: "card", Amount: 10000, Tendered: 10000}, {Method: "cash", Amount: 5000, Tendered: 10000}},
+			wantPaid:   20000,
+			wantChange: 5000,
+		},
+		{
+			name:     "exact qris",
+			total:    15000,
+			inputs:   []models.CheckoutPayment{{Method: "qris", Amount: 15000}},
+			want:     []models.Payment{{Method: "qris", Amount: 15000, Tendered: 15000}},
+			wantPaid: 15000,
+		},
+		{
+			name:     "underpayment",
+			total:    15000,
+			inputs:   []models.CheckoutPayment{{Method: "cash", Amount: 10000}},
+			wantCode: services.CodeInsufficientPayment,
+		},
+		{
+			name:     "card overpayment",
+			total:    15000,
+			inputs:   []models.CheckoutPayment{{Method: "card", Amount: 20000}},
+			wantCode: services.CodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, paid, change, err := services.SettlePayments(tt.total, tt.inputs)
+			if tt.wantCode != "" {
+				if e := services.AsError(err); e.Code != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || paid != tt.wantPaid || change != tt.wantChange {
+				t.Errorf("got %+v paid %d change %d, want %+v paid %d change %d", got, paid, change, tt.want, tt.wantPaid, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestValidatePayments(t *testing.T) {
+	err := services.ValidatePayments([]models.CheckoutPayment{{Method: "cash", Amount: 1000}, {Method: "bitcoin", Amount: 0}})
+	e := services.AsError(err)
+	fields, _ := e.Details.([]services.FieldError)
+	if e.Code != services.CodeValidation || len(fields) != 2 || fields[0].Field != "payments[1].method" {
+		t.Errorf("err = %+v", e)
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+// checkoutPricing menghitung isi transaksi dari cart. Method price dipakai
+// sebagai repositories.PriceFunc sehingga harga dihitung dari snapshot produk
+// yang sudah di-lock di dalam DB transaction.
+type checkoutPricing struct {
+	promotions []models.Promotion
+	discount   *models.Discount
+	promo      *models.PromoCode
+	payments   []models.CheckoutPayment
+	tax        TaxConfig
+	now        time.Time
+	warnings   *[]models.CheckoutWarning // diisi saat preview; nil berarti checkout sungguhan
+}
+
+// warn mencatat err sebagai warning kalau sedang preview. false berarti
+// checkout sungguhan dan err harus dikembalikan.
+func (c checkoutPricing) warn(field string, err error) bool {
+	if c.warnings == nil {
+		return false
+	}
+	e := AsError(err)
+	msg := e.Message
+	if fields, ok := e.Details.([]FieldError); ok && len(fields) == 1 {
+		msg = fields[0].Message
+	}
+	*c.warnings = append(*c.warnings, models.CheckoutWarning{Field: field, Code: string(e.Code), Message: msg})
+	return true
+}
+
+// price menerapkan promosi otomatis, lalu diskon manual per baris, lalu diskon
+// transaksi dan promo code dari sisa setelah diskon baris, lalu service charge
+// dan pajak, terakhir pembayaran. Minimum belanja promo code dihitung setelah
+// promosi dan diskon per baris.
+func (c checkoutPricing) price(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+	if c.warnings != nil {
+		// Checkout sungguhan sudah menolak stock kurang sebelum price dipanggil
+		for _, short := range repositories.StockShortages(items, products) {
+			for i, item := range items {
+				if item.ProductID == short.ProductID {
+					*c.warnings = append(*c.warnings, models.CheckoutWarning{
+						Field:   fmt.Sprintf("items[%d].quantity", i),
+						Code:    string(CodeInsufficientStock),
+						Message: fmt.Sprintf("only %d %s in stock, %d requested", short.Available, short.ProductName, short.Requested),
+					})
+				}
+			}
+		}
+	}
+
+	trx := &models.Transaction{Details: make([]models.TransactionDetail, 0, len(items))}
+	for _, item := range items {
+		p := products[item.ProductID]
+		gross := p.Price * item.Quantity
+		trx.GrossAmount += gross
+		trx.Details = append(trx.Details, models.TransactionDetail{
+			ProductID:   item.ProductID,
+			ProductName: p.Name,
+			Quantity:    item.Quantity,
+			Subtotal:    gross,
+		})
+	}
+
+	trx.Promotions = applyPromotions(c.promotions, trx.Details, products, c.now)
+
+	net := 0
+	for i, item := range items {
+		d := &trx.Details[i]
+		lineDiscount := discountAmount(item.Discount, d.Subtotal)
+		d.Subtotal -= lineDiscount
+		d.DiscountAmount += lineDiscount
+		net += d.Subtotal
+	}
+
+	orderDiscount := discountAmount(c.discount, net)
+	if c.promo != nil {
+		if err := checkPromo(c.promo, net, c.now); err == nil {
+			orderDiscount += discountAmount(&models.Discount{Type: c.promo.Type, Value: c.promo.Value}, net-orderDiscount)
+			trx.PromoCode = c.promo.Code
+		} else if !c.warn("promo_code", err) {
+			return nil, err
+		}
+	}
+	allocateDiscount(trx.Details, orderDiscount)
+	for _, d := range trx.Details {
+		trx.DiscountAmount += d.DiscountAmount
+	}
+
+	c.tax.apply(trx, products)
+
+	var err error
+	trx.Payments, trx.PaidAmount, trx.ChangeAmount, err = SettlePayments(trx.TotalAmount, c.payments)
+	if err != nil && !c.warn("payments", err) {
+		return nil, err
+	}
+	return trx, nil
+}
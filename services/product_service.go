@@ -1,37 +1,182 @@
 package services
 
 import (
+	"errors"
+	"slices"
+	"strings"
+
 	"kasir-api-golang-v1/models"
 	"kasir-api-golang-v1/repositories"
 )
 
-type ProductService struct {
-	repo *repositories.ProductRepository
+type productService struct {
+	repo  repositories.ProductRepository
+	stock repositories.StockMovementRepository
 }
 
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(repo repositories.ProductRepository, stock repositories.StockMovementRepository) ProductService {
+	return &productService{repo: repo, stock: stock}
 }
 
-func (s *ProductService) GetAll(name string) ([]models.Product, error) {
-	return s.repo.GetAll(name)
+func validateProduct(product *models.Product) error {
+	var fields []FieldError
+	if strings.TrimSpace(product.Name) == "" {
+		fields = append(fields, FieldError{Field: "name", Message: "name is required"})
+	}
+	if product.CostPrice < 0 {
+		fields = append(fields, FieldError{Field: "cost_price", Message: "cost_price cannot be negative"})
+	}
+	if product.Stock < 0 {
+		fields = append(fields, FieldError{Field: "stock", Message: "stock cannot be negative"})
+	}
+	if product.ReorderPoint < 0 {
+		fields = append(fields, FieldError{Field: "reorder_point", Message: "reorder_point cannot be negative"})
+	}
+	if product.ReorderQuantity < 0 {
+		fields = append(fields, FieldError{Field: "reorder_quantity", Message: "reorder_quantity cannot be negative"})
+	}
+	if len(fields) > 0 {
+		return NewValidationError("invalid product", fields...)
+	}
+	return nil
 }
 
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
-	return s.repo.GetByID(id)
+// productWriteError: foreign key saat create/update berarti category_id tidak ada
+func productWriteError(err error) error {
+	if errors.Is(err, repositories.ErrForeignKey) {
+		return NewValidationError("invalid product", FieldError{Field: "category_id", Message: "category does not exist"})
+	}
+	return fromRepo(err, "product")
+}
+
+func (s *productService) GetAll(name string) ([]models.Product, error) {
+	products, err := s.repo.GetAll(name)
+	if err != nil {
+		return nil, fromRepo(err, "product")
+	}
+	return products, nil
 }
 
-func (s *ProductService) Create(product *models.Product) error {
+func (s *productService) GetByID(id int) (*models.Product, error) {
+	product, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "product")
+	}
+	return product, nil
+}
+
+func (s *productService) Create(product *models.Product, actorID int) error {
 	if product.Price < 0 {
 		product.Price = 0
 	}
-	return s.repo.Create(product)
+	if err := validateProduct(product); err != nil {
+		return err
+	}
+	if err := s.repo.Create(product, actorID); err != nil {
+		return productWriteError(err)
+	}
+	return nil
 }
 
-func (s *ProductService) Update(product *models.Product) error {
-	return s.repo.Update(product)
+func (s *productService) Update(id, actorID int, req models.ProductUpdateRequest) (*models.Product, error) {
+	// Field yang tidak dikirim memakai nilai tersimpan yang sudah valid
+	candidate := repositories.ApplyProductUpdate(models.Product{}, req)
+	if err := validateProduct(&candidate); err != nil {
+		return nil, err
+	}
+	product, err := s.repo.Update(id, req, actorID)
+	if err != nil {
+		return nil, productWriteError(err)
+	}
+	return product, nil
+}
+
+func (s *productService) Delete(id, actorID int) error {
+	if err := s.repo.Delete(id, actorID); err != nil {
+		if errors.Is(err, repositories.ErrForeignKey) {
+			return &Error{Code: CodeConflict, Message: "product already has transactions or purchase orders and cannot be deleted", Err: err}
+		}
+		return fromRepo(err, "product")
+	}
+	return nil
 }
 
-func (s *ProductService) Delete(id int) error {
-	return s.repo.Delete(id)
-}
\ No newline at end of file
+// LowStock untuk GET /api/products/low-stock
+func (s *productService) LowStock() ([]models.Product, error) {
+	products, err := s.repo.GetLowStock()
+	if err != nil {
+		return nil, fromRepo(err, "product")
+	}
+	return products, nil
+}
+
+// StockHistory untuk GET /api/products/{id}/stock-history
+func (s *productService) StockHistory(filter models.StockMovementFilter) (*models.StockMovementPage, error) {
+	fields := validateDateRange(filter.StartDate, filter.EndDate)
+	if filter.Type != "" && !slices.Contains(models.StockMovementTypes, filter.Type) {
+		fields = append(fields, FieldError{Field: "type", Message: "must be one of " + strings.Join(models.StockMovementTypes, ", ")})
+	}
+	fields = append(fields, validatePage(filter.Page, filter.Limit)...)
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid stock history filter", fields...)
+	}
+	defaultPage(&filter.Page, &filter.Limit)
+
+	product, err := s.repo.GetByID(filter.ProductID)
+	if err != nil {
+		return nil, fromRepo(err, "product")
+	}
+	movements, total, err := s.stock.GetByProduct(filter)
+	if err != nil {
+		return nil, fromRepo(err, "stock movement")
+	}
+	return &models.StockMovementPage{
+		ProductID: product.ID,
+		Stock:     product.Stock,
+		Data:      movements,
+		Page:      filter.Page,
+		Limit:     filter.Limit,
+		Total:     total,
+	}, nil
+}
+
+// CheckStock menghitung ulang stock semua produk dari ledger
+func (s *productService) CheckStock() (*models.StockCheck, error) {
+	checked, discrepancies, err := s.stock.CheckConsistency()
+	if err != nil {
+		return nil, fromRepo(err, "stock movement")
+	}
+	return &models.StockCheck{
+		ProductsChecked: checked,
+		Consistent:      len(discrepancies) == 0,
+		Discrepancies:   discrepancies,
+	}, nil
+}
+
+// maxStockNoteLength sama dengan kolom stock_movements.note
+const maxStockNoteLength = 255
+
+func (s *productService) AdjustStock(id, actorID int, req models.StockAdjustmentRequest) (*models.Product, error) {
+	req.Note = strings.TrimSpace(req.Note)
+	var fields []FieldError
+	if req.Quantity == 0 {
+		fields = append(fields, FieldError{Field: "quantity", Message: "must not be zero"})
+	}
+	if req.Note == "" {
+		fields = append(fields, FieldError{Field: "note", Message: "note is required"})
+	} else if len(req.Note) > maxStockNoteLength {
+		fields = append(fields, FieldError{Field: "note", Message: "must be at most 255 characters"})
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid stock adjustment", fields...)
+	}
+
+	product, err := s.repo.AdjustStock(id, req.Quantity, actorID, req.Note)
+	if err != nil {
+		if e := stockShortage(err); e != nil {
+			return nil, e
+		}
+		return nil, fromRepo(err, "product")
+	}
+	return product, nil
+}
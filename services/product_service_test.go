@@ -0,0 +1,162 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func newProductService() (services.ProductService, *memory.Store) {
+	store := memory.NewStore()
+	return services.NewProductService(memory.NewProductRepository(store), memory.NewStockMovementRepository(store)), store
+}
+
+func TestProductServiceCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        models.Product
+		wantPrice    int
+		wantCategory int
+	}{
+		{"keeps positive price", models.Product{Name: "Teh", Price: 5000, Stock: 10, CategoryID: 1}, 5000, 1},
+		{"clamps negative price to zero", models.Product{Name: "Kopi", Price: -100, Stock: 1}, 0, 1},
+		{"defaults empty category to 1", models.Product{Name: "Roti", Price: 8000}, 8000, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newProductService()
+			p := tt.input
+			if err := svc.Create(&p, 0); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if p.ID == 0 {
+				t.Fatal("expected ID to be assigned")
+			}
+
+			got, err := svc.GetByID(p.ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if got.Price != tt.wantPrice {
+				t.Errorf("price = %d, want %d", got.Price, tt.wantPrice)
+			}
+			if got.CategoryID != tt.wantCategory {
+				t.Errorf("category_id = %d, want %d", got.CategoryID, tt.wantCategory)
+			}
+		})
+	}
+}
+
+func TestProductServiceGetAllFilter(t *testing.T) {
+	svc, _ := newProductService()
+	for _, name := range []string{"Teh Manis", "Kopi Susu", "Teh Tarik"} {
+		p := models.Product{Name: name, Price: 1000}
+		if err := svc.Create(&p, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		filter string
+		want   int
+	}{
+		{"", 3},
+		{"teh", 2},
+		{"Kopi", 1},
+		{"jus", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			products, err := svc.GetAll(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(products) != tt.want {
+				t.Errorf("GetAll(%q) returned %d products, want %d", tt.filter, len(products), tt.want)
+			}
+			for _, p := range products {
+				if p.CategoryName != "No Category" {
+					t.Errorf("category_name = %q, want %q", p.CategoryName, "No Category")
+				}
+			}
+		})
+	}
+}
+
+func TestProductServiceUpdateDelete(t *testing.T) {
+	svc, _ := newProductService()
+	p := models.Product{Name: "Teh", Price: 5000, Stock: 5}
+	if err := svc.Create(&p, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr bool
+	}{
+		{"update existing", func() error {
+			_, err := svc.Update(p.ID, 0, models.ProductUpdateRequest{Name: "Teh Manis", Price: 6000, Stock: 5, CategoryID: 1})
+			return err
+		}, false},
+		{"update missing", func() error { _, err := svc.Update(999, 0, models.ProductUpdateRequest{Name: "X"}); return err }, true},
+		{"delete existing", func() error { return svc.Delete(p.ID, 0) }, false},
+		{"delete again", func() error { return svc.Delete(p.ID, 0) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// failingProductRepo mensimulasikan database down
+type failingProductRepo struct {
+	repositories.ProductRepository
+}
+
+func (failingProductRepo) GetByID(int) (*models.Product, error) {
+	return nil, errors.New("dial tcp: connection refused")
+}
+
+func (failingProductRepo) Delete(int, int) error {
+	return errors.New("dial tcp: connection refused")
+}
+
+func TestProductServiceErrorCodes(t *testing.T) {
+	healthy, _ := newProductService()
+	broken := services.NewProductService(failingProductRepo{}, nil)
+
+	tests := []struct {
+		name string
+		run  func() error
+		want services.ErrorCode
+	}{
+		{"missing product is not found", func() error { _, err := healthy.GetByID(42); return err }, services.CodeNotFound},
+		{"delete missing is not found", func() error { return healthy.Delete(42, 0) }, services.CodeNotFound},
+		{"update missing is not found", func() error {
+			_, err := healthy.Update(42, 0, models.ProductUpdateRequest{Name: "X", CategoryID: 1})
+			return err
+		}, services.CodeNotFound},
+		{"unknown category is validation", func() error {
+			return healthy.Create(&models.Product{Name: "X", CategoryID: 42}, 0)
+		}, services.CodeValidation},
+		{"outage on get is internal", func() error { _, err := broken.GetByID(1); return err }, services.CodeInternal},
+		{"outage on delete is internal", func() error { return broken.Delete(1, 0) }, services.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := services.AsError(tt.run()).Code; got != tt.want {
+				t.Errorf("code = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type promoCodeService struct {
+	repo repositories.PromoCodeRepository
+}
+
+func NewPromoCodeService(repo repositories.PromoCodeRepository) PromoCodeService {
+	return &promoCodeService{repo: repo}
+}
+
+// validatePromoCode menormalkan kode (trim + huruf besar) lalu memvalidasi semua field
+func validatePromoCode(promo *models.PromoCode) error {
+	promo.Code = strings.ToUpper(strings.TrimSpace(promo.Code))
+
+	var fields []FieldError
+	if promo.Code == "" {
+		fields = append(fields, FieldError{Field: "code", Message: "code is required"})
+	}
+	switch {
+	case promo.Type != models.DiscountPercent && promo.Type != models.DiscountFixed:
+		fields = append(fields, FieldError{Field: "type", Message: "must be percent or fixed"})
+	case promo.Value <= 0:
+		fields = append(fields, FieldError{Field: "value", Message: "must be greater than zero"})
+	case promo.Type == models.DiscountPercent && promo.Value > 100:
+		fields = append(fields, FieldError{Field: "value", Message: "percent must be between 1 and 100"})
+	}
+	if promo.MinSpend < 0 {
+		fields = append(fields, FieldError{Field: "min_spend", Message: "must not be negative"})
+	}
+	if promo.UsageLimit < 0 {
+		fields = append(fields, FieldError{Field: "usage_limit", Message: "must not be negative"})
+	}
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && promo.ValidUntil.Before(*promo.ValidFrom) {
+		fields = append(fields, FieldError{Field: "valid_until", Message: "must not be before valid_from"})
+	}
+	if len(fields) > 0 {
+		return NewValidationError("invalid promo code", fields...)
+	}
+	return nil
+}
+
+func (s *promoCodeService) GetAll() ([]models.PromoCode, error) {
+	promos, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fromRepo(err, "promo code")
+	}
+	return promos, nil
+}
+
+func (s *promoCodeService) GetByID(id int) (*models.PromoCode, error) {
+	promo, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "promo code")
+	}
+	return promo, nil
+}
+
+func (s *promoCodeService) Create(promo *models.PromoCode) error {
+	if err := validatePromoCode(promo); err != nil {
+		return err
+	}
+	if err := s.repo.Create(promo); err != nil {
+		return fromRepo(err, "promo code")
+	}
+	return nil
+}
+
+func (s *promoCodeService) Update(promo *models.PromoCode) error {
+	if err := validatePromoCode(promo); err != nil {
+		return err
+	}
+	if err := s.repo.Update(promo); err != nil {
+		return fromRepo(err, "promo code")
+	}
+	return nil
+}
+
+// Delete menghapus kode promo; transaksi lama tetap menyimpan kode yang dipakai
+func (s *promoCodeService) Delete(id int) error {
+	if err := s.repo.Delete(id); err != nil {
+		return fromRepo(err, "promo code")
+	}
+	return nil
+}
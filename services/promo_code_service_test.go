@@ -0,0 +1,54 @@
+package services_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func TestPromoCodeServiceValidation(t *testing.T) {
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	until := from.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name       string
+		promo      models.PromoCode
+		wantFields []string
+	}{
+		{"valid", models.PromoCode{Code: "hemat", Type: "percent", Value: 10}, nil},
+		{"missing code and type", models.PromoCode{Value: 10}, []string{"code", "type"}},
+		{"percent over 100", models.PromoCode{Code: "X", Type: "percent", Value: 101}, []string{"value"}},
+		{"zero fixed value", models.PromoCode{Code: "X", Type: "fixed"}, []string{"value"}},
+		{"negative limits", models.PromoCode{Code: "X", Type: "fixed", Value: 1, MinSpend: -1, UsageLimit: -1}, []string{"min_spend", "usage_limit"}},
+		{"window reversed", models.PromoCode{Code: "X", Type: "fixed", Value: 1, ValidFrom: &from, ValidUntil: &until}, []string{"valid_until"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := services.NewPromoCodeService(memory.NewPromoCodeRepository(memory.NewStore()))
+			err := svc.Create(&tt.promo)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tt.promo.Code != "HEMAT" {
+					t.Errorf("code = %q, want normalized HEMAT", tt.promo.Code)
+				}
+				return
+			}
+			e := services.AsError(err)
+			fields, _ := e.Details.([]services.FieldError)
+			var names []string
+			for _, f := range fields {
+				names = append(names, f.Field)
+			}
+			if e.Code != services.CodeValidation || !reflect.DeepEqual(names, tt.wantFields) {
+				t.Errorf("err = %v fields %v, want %v", err, names, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"time"
+
+	"kasir-api-golang-v1/models"
+)
+
+// inTimeWindow mengecek jam now (HH:MM) di dalam [start, end). end lebih kecil
+// dari start berarti melewati tengah malam, misalnya 22:00-02:00.
+func inTimeWindow(start, end string, now time.Time) bool {
+	cur := now.Format("15:04")
+	if start <= end {
+		return cur >= start && cur < end
+	}
+	return cur >= start || cur < end
+}
+
+// promotionActiveAt memastikan promosi aktif, dalam periode berlaku, dan untuk
+// happy hour sedang di dalam jamnya
+func promotionActiveAt(p models.Promotion, now time.Time) bool {
+	switch {
+	case !p.Active:
+		return false
+	case p.ValidFrom != nil && now.Before(*p.ValidFrom):
+		return false
+	case p.ValidUntil != nil && now.After(*p.ValidUntil):
+		return false
+	case p.Type == models.PromotionHappyHour:
+		return inTimeWindow(p.StartTime, p.EndTime, now.Local())
+	}
+	return true
+}
+
+// promotionCart adalah ringkasan cart per produk selama promosi dievaluasi
+type promotionCart struct {
+	details   []models.TransactionDetail
+	products  map[int]models.Product
+	qty       map[int]int   // qty per produk
+	remaining map[int]int   // subtotal per produk setelah promosi sebelumnya
+	lines     map[int][]int // index details per produk
+	touched   map[int]bool  // sudah kena promosi
+	exclusive map[int]bool  // sudah kena promosi yang tidak stackable
+}
+
+// blocked: produk tidak bisa kena promosi p karena aturan stacking
+func (c *promotionCart) blocked(p models.Promotion, productID int) bool {
+	return c.exclusive[productID] || (!p.Stackable && c.touched[productID])
+}
+
+// evaluate mengembalikan potongan per produk untuk satu promosi, atau nil kalau tidak berlaku
+func (c *promotionCart) evaluate(p models.Promotion) map[int]int {
+	switch p.Type {
+	case models.PromotionBuyXGetY:
+		if len(p.Items) == 0 || p.BuyQty+p.FreeQty <= 0 {
+			return nil
+		}
+		id := p.Items[0].ProductID
+		free := c.qty[id] / (p.BuyQty + p.FreeQty) * p.FreeQty
+		if free == 0 || c.blocked(p, id) {
+			return nil
+		}
+		return map[int]int{id: free * c.products[id].Price}
+
+	case models.PromotionBundle:
+		bundles, normal := -1, 0
+		weights := make([]int, len(p.Items))
+		for i, it := range p.Items {
+			if it.Quantity <= 0 || c.blocked(p, it.ProductID) {
+				return nil
+			}
+			if n := c.qty[it.ProductID] / it.Quantity; bundles < 0 || n < bundles {
+				bundles = n
+			}
+			weights[i] = it.Quantity * c.products[it.ProductID].Price
+			normal += weights[i]
+		}
+		saving := normal - p.BundlePrice
+		if bundles <= 0 || saving <= 0 {
+			return nil
+		}
+		perProduct := map[int]int{}
+		for i, share := range allocate(bundles*saving, weights) {
+			perProduct[p.Items[i].ProductID] += share
+		}
+		return perProduct
+
+	case models.PromotionHappyHour:
+		perProduct := map[int]int{}
+		targets := p.Items
+		if len(targets) == 0 {
+			for id := range c.qty {
+				targets = append(targets, models.PromotionItem{ProductID: id})
+			}
+		}
+		for _, it := range targets {
+			if c.qty[it.ProductID] == 0 || c.blocked(p, it.ProductID) {
+				continue
+			}
+			perProduct[it.ProductID] = c.remaining[it.ProductID] * p.DiscountPercent / 100
+		}
+		return perProduct
+	}
+	return nil
+}
+
+// apply membebankan potongan ke baris-baris produk dan mengembalikan total potongan
+func (c *promotionCart) apply(p models.Promotion, perProduct map[int]int) int {
+	total := 0
+	for id, amount := range perProduct {
+		if amount > c.remaining[id] {
+			amount = c.remaining[id]
+		}
+		if amount <= 0 {
+			continue
+		}
+		c.remaining[id] -= amount
+		total += amount
+
+		lines := c.lines[id]
+		weights := make([]int, len(lines))
+		for i, idx := range lines {
+			weights[i] = c.details[idx].Subtotal
+		}
+		for i, share := range allocate(amount, weights) {
+			c.details[lines[i]].Subtotal -= share
+			c.details[lines[i]].DiscountAmount += share
+		}
+
+		c.touched[id] = true
+		if !p.Stackable {
+			c.exclusive[id] = true
+		}
+	}
+	return total
+}
+
+// applyPromotions mengevaluasi promosi (sudah urut priority tertinggi dulu)
+// terhadap details yang subtotal-nya masih harga normal. Potongan langsung
+// dikurangkan dari subtotal baris; promosi yang terpakai dikembalikan untuk struk.
+func applyPromotions(promos []models.Promotion, details []models.TransactionDetail, products map[int]models.Product, now time.Time) []models.AppliedPromotion {
+	c := &promotionCart{
+		details:   details,
+		products:  products,
+		qty:       map[int]int{},
+		remaining: map[int]int{},
+		lines:     map[int][]int{},
+		touched:   map[int]bool{},
+		exclusive: map[int]bool{},
+	}
+	for i, d := range details {
+		c.qty[d.ProductID] += d.Quantity
+		c.remaining[d.ProductID] += d.Subtotal
+		c.lines[d.ProductID] = append(c.lines[d.ProductID], i)
+	}
+
+	var applied []models.AppliedPromotion
+	for _, p := range promos {
+		if !promotionActiveAt(p, now) {
+			continue
+		}
+		if amount := c.apply(p, c.evaluate(p)); amount > 0 {
+			applied = append(applied, models.AppliedPromotion{PromotionID: p.ID, Name: p.Name, Type: p.Type, DiscountAmount: amount})
+		}
+	}
+	return applied
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type promotionService struct {
+	repo repositories.PromotionRepository
+}
+
+func NewPromotionService(repo repositories.PromotionRepository) PromotionService {
+	return &promotionService{repo: repo}
+}
+
+// validatePromotion memvalidasi field sesuai type promosi. Quantity item yang
+// kosong dianggap 1.
+func validatePromotion(promo *models.Promotion) error {
+	promo.Name = strings.TrimSpace(promo.Name)
+
+	var fields []FieldError
+	if promo.Name == "" {
+		fields = append(fields, FieldError{Field: "name", Message: "name is required"})
+	}
+
+	seen := map[int]bool{}
+	for i := range promo.Items {
+		it := &promo.Items[i]
+		if it.Quantity == 0 {
+			it.Quantity = 1
+		}
+		switch {
+		case it.ProductID <= 0:
+			fields = append(fields, FieldError{Field: fmt.Sprintf("items[%d].product_id", i), Message: "must be a valid product ID"})
+		case seen[it.ProductID]:
+			fields = append(fields, FieldError{Field: fmt.Sprintf("items[%d].product_id", i), Message: "product listed more than once"})
+		}
+		if it.Quantity < 0 {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("items[%d].quantity", i), Message: "must be greater than zero"})
+		}
+		seen[it.ProductID] = true
+	}
+
+	switch promo.Type {
+	case models.PromotionBuyXGetY:
+		if len(promo.Items) != 1 {
+			fields = append(fields, FieldError{Field: "items", Message: "buy_x_get_y needs exactly one product"})
+		}
+		if promo.BuyQty <= 0 {
+			fields = append(fields, FieldError{Field: "buy_qty", Message: "must be greater than zero"})
+		}
+		if promo.FreeQty <= 0 {
+			fields = append(fields, FieldError{Field: "free_qty", Message: "must be greater than zero"})
+		}
+	case models.PromotionBundle:
+		if len(promo.Items) == 0 {
+			fields = append(fields, FieldError{Field: "items", Message: "bundle needs at least one product"})
+		}
+		if promo.BundlePrice < 0 {
+			fields = append(fields, FieldError{Field: "bundle_price", Message: "must not be negative"})
+		}
+	case models.PromotionHappyHour:
+		if promo.DiscountPercent < 1 || promo.DiscountPercent > 100 {
+			fields = append(fields, FieldError{Field: "discount_percent", Message: "must be between 1 and 100"})
+		}
+		if _, err := time.Parse("15:04", promo.StartTime); err != nil {
+			fields = append(fields, FieldError{Field: "start_time", Message: "must be HH:MM"})
+		}
+		if _, err := time.Parse("15:04", promo.EndTime); err != nil {
+			fields = append(fields, FieldError{Field: "end_time", Message: "must be HH:MM"})
+		}
+	default:
+		fields = append(fields, FieldError{Field: "type", Message: "must be buy_x_get_y, bundle or happy_hour"})
+	}
+
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && promo.ValidUntil.Before(*promo.ValidFrom) {
+		fields = append(fields, FieldError{Field: "valid_until", Message: "must not be before valid_from"})
+	}
+	if len(fields) > 0 {
+		return NewValidationError("invalid promotion", fields...)
+	}
+	return nil
+}
+
+// promotionWriteError: foreign key saat create/update berarti ada produk yang tidak ada
+func promotionWriteError(err error) error {
+	if errors.Is(err, repositories.ErrForeignKey) {
+		return NewValidationError("invalid promotion", FieldError{Field: "items", Message: "product does not exist"})
+	}
+	return fromRepo(err, "promotion")
+}
+
+func (s *promotionService) GetAll() ([]models.Promotion, error) {
+	promos, err := s.repo.GetAll(false)
+	if err != nil {
+		return nil, fromRepo(err, "promotion")
+	}
+	return promos, nil
+}
+
+func (s *promotionService) GetByID(id int) (*models.Promotion, error) {
+	promo, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "promotion")
+	}
+	return promo, nil
+}
+
+func (s *promotionService) Create(promo *models.Promotion) error {
+	if err := validatePromotion(promo); err != nil {
+		return err
+	}
+	if err := s.repo.Create(promo); err != nil {
+		return promotionWriteError(err)
+	}
+	return nil
+}
+
+func (s *promotionService) Update(promo *models.Promotion) error {
+	if err := validatePromotion(promo); err != nil {
+		return err
+	}
+	if err := s.repo.Update(promo); err != nil {
+		return promotionWriteError(err)
+	}
+	return nil
+}
+
+// Delete menghapus promosi; transaksi lama tetap menyimpan nama promosi yang terpakai
+func (s *promotionService) Delete(id int) error {
+	if err := s.repo.Delete(id); err != nil {
+		return fromRepo(err, "promotion")
+	}
+	return nil
+}
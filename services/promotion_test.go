@@ -0,0 +1,182 @@
+package services_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func TestCheckoutPromotions(t *testing.T) {
+	now := time.Now()
+	inWindow := [2]string{now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04")}
+	outWindow := [2]string{now.Add(time.Hour).Format("15:04"), now.Add(2 * time.Hour).Format("15:04")}
+
+	bogo := func(f *transactionFixture) models.Promotion {
+		return models.Promotion{Name: "Teh 2+1", Type: models.PromotionBuyXGetY, BuyQty: 2, FreeQty: 1, Priority: 10, Active: true,
+			Items: []models.PromotionItem{{ProductID: f.teh.ID}}}
+	}
+	happyHour := func(window [2]string, percent int) func(f *transactionFixture) models.Promotion {
+		return func(f *transactionFixture) models.Promotion {
+			return models.Promotion{Name: "Happy Hour", Type: models.PromotionHappyHour, DiscountPercent: percent,
+				StartTime: window[0], EndTime: window[1], Priority: 1, Stackable: true, Active: true}
+		}
+	}
+
+	// teh 5000 (stock 10), kopi 8000 (stock 2)
+	tests := []struct {
+		name           string
+		promotions     []func(f *transactionFixture) models.Promotion
+		req            func(f *transactionFixture) models.CheckoutRequest
+		wantTotal      int
+		wantPromotions []int // potongan per promosi yang terpakai
+	}{
+		{
+			name:       "buy 2 get 1",
+			promotions: []func(f *transactionFixture) models.Promotion{bogo},
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 3}}}
+			},
+			wantTotal: 10000, wantPromotions: []int{5000},
+		},
+		{
+			name:       "buy 2 get 1 not reached",
+			promotions: []func(f *transactionFixture) models.Promotion{bogo},
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}}}
+			},
+			wantTotal: 10000,
+		},
+		{
+			name: "bundle",
+			promotions: []func(f *transactionFixture) models.Promotion{func(f *transactionFixture) models.Promotion {
+				return models.Promotion{Name: "Paket", Type: models.PromotionBundle, BundlePrice: 11000, Active: true,
+					Items: []models.PromotionItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.kopi.ID, Quantity: 1}}}
+			}},
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}, {ProductID: f.kopi.ID, Quantity: 1}}}
+			},
+			wantTotal: 16000, wantPromotions: []int{2000},
+		},
+		{
+			name:       "happy hour in window",
+			promotions: []func(f *transactionFixture) models.Promotion{happyHour(inWindow, 50)},
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}}}
+			},
+			wantTotal: 5000, wantPromotions: []int{5000},
+		},
+		{
+			name:       "happy hour outside window",
+			promotions: []func(f *transactionFixture) models.Promotion{happyHour(outWindow, 50)},
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}}}
+			},
+			wantTotal: 10000,
+		},
+		{
+			name:       "non-stackable promotion blocks later ones on the same product",
+			promotions: []func(f *transactionFixture) models.Promotion{happyHour(inWindow, 10), bogo},
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 3}, {ProductID: f.kopi.ID, Quantity: 1}}}
+			},
+			wantTotal: 17200, wantPromotions: []int{5000, 800},
+		},
+		{
+			name: "stackable promotions combine",
+			promotions: []func(f *transactionFixture) models.Promotion{happyHour(inWindow, 10), func(f *transactionFixture) models.Promotion {
+				p := bogo(f)
+				p.Stackable = true
+				return p
+			}},
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 3}, {ProductID: f.kopi.ID, Quantity: 1}}}
+			},
+			wantTotal: 16200, wantPromotions: []int{5000, 1800},
+		},
+		{
+			name:       "manual line discount after promotion",
+			promotions: []func(f *transactionFixture) models.Promotion{bogo},
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{
+					{ProductID: f.teh.ID, Quantity: 3, Discount: &models.Discount{Type: "percent", Value: 10}},
+				}}
+			},
+			wantTotal: 9000, wantPromotions: []int{5000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransactionFixture(t)
+			for _, build := range tt.promotions {
+				p := build(f)
+				if err := f.rules.Create(&p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			trx, err := f.trx.Checkout(tt.req(f))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, p := range trx.Promotions {
+				got = append(got, p.DiscountAmount)
+			}
+			if trx.TotalAmount != tt.wantTotal || !reflect.DeepEqual(got, tt.wantPromotions) {
+				t.Errorf("total = %d promotions %v, want %d %v", trx.TotalAmount, got, tt.wantTotal, tt.wantPromotions)
+			}
+			if trx.GrossAmount-trx.DiscountAmount != trx.TotalAmount {
+				t.Errorf("gross %d - discount %d != total %d", trx.GrossAmount, trx.DiscountAmount, trx.TotalAmount)
+			}
+		})
+	}
+}
+
+func TestPromotionServiceValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		promo      models.Promotion
+		wantFields []string
+	}{
+		{"valid bundle", models.Promotion{Name: "Paket", Type: "bundle", BundlePrice: 1000, Items: []models.PromotionItem{{ProductID: 1}}}, nil},
+		{"missing name and type", models.Promotion{}, []string{"name", "type"}},
+		{"buy x get y needs one product", models.Promotion{Name: "X", Type: "buy_x_get_y", BuyQty: 2, FreeQty: 1}, []string{"items"}},
+		{"buy x get y quantities", models.Promotion{Name: "X", Type: "buy_x_get_y", Items: []models.PromotionItem{{ProductID: 1}}}, []string{"buy_qty", "free_qty"}},
+		{"duplicate bundle product", models.Promotion{Name: "X", Type: "bundle", Items: []models.PromotionItem{{ProductID: 1}, {ProductID: 1}}}, []string{"items[1].product_id"}},
+		{"happy hour fields", models.Promotion{Name: "X", Type: "happy_hour", DiscountPercent: 120, StartTime: "25:00", EndTime: "17:00"}, []string{"discount_percent", "start_time"}},
+		{"unknown product", models.Promotion{Name: "X", Type: "bundle", Items: []models.PromotionItem{{ProductID: 99}}}, []string{"items"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.NewStore()
+			if err := memory.NewProductRepository(store).Create(&models.Product{Name: "Teh", Price: 5000}, 0); err != nil {
+				t.Fatal(err)
+			}
+			err := services.NewPromotionService(memory.NewPromotionRepository(store)).Create(&tt.promo)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tt.promo.Items[0].Quantity != 1 {
+					t.Errorf("item quantity = %d, want default 1", tt.promo.Items[0].Quantity)
+				}
+				return
+			}
+			e := services.AsError(err)
+			fields, _ := e.Details.([]services.FieldError)
+			var names []string
+			for _, f := range fields {
+				names = append(names, f.Field)
+			}
+			if e.Code != services.CodeValidation || !reflect.DeepEqual(names, tt.wantFields) {
+				t.Errorf("err = %v fields %v, want %v", err, names, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type purchaseOrderService struct {
+	repo      repositories.PurchaseOrderRepository
+	suppliers repositories.SupplierRepository
+}
+
+func NewPurchaseOrderService(repo repositories.PurchaseOrderRepository, suppliers repositories.SupplierRepository) PurchaseOrderService {
+	return &purchaseOrderService{repo: repo, suppliers: suppliers}
+}
+
+// MaxPurchaseOrderLines adalah batas jumlah produk dalam satu PO atau satu penerimaan
+const MaxPurchaseOrderLines = 200
+
+// purchaseOrderError menerjemahkan error repository purchase order
+func purchaseOrderError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrPurchaseOrderState):
+		return &Error{Code: CodeConflict, Message: "purchase order status does not allow this action", Err: err}
+	case errors.Is(err, repositories.ErrInvalidReceipt):
+		return &Error{Code: CodeValidation, Message: err.Error(), Err: err}
+	}
+	return fromRepo(err, "purchase order")
+}
+
+// withTotals menghitung nilai pesanan dan nilai barang yang sudah diterima
+func withTotals(po *models.PurchaseOrder) *models.PurchaseOrder {
+	po.TotalCost, po.ReceivedCost = 0, 0
+	for _, it := range po.Items {
+		po.TotalCost += it.Quantity * it.UnitCost
+	}
+	for _, rc := range po.Receipts {
+		po.ReceivedCost += rc.Quantity * rc.UnitCost
+	}
+	return po
+}
+
+// validateLines memvalidasi jumlah baris PO atau penerimaan barang
+func validateLines(n int) []FieldError {
+	var fields []FieldError
+	if n == 0 {
+		fields = append(fields, FieldError{Field: "items", Message: "at least one item is required"})
+	}
+	if n > MaxPurchaseOrderLines {
+		fields = append(fields, FieldError{Field: "items", Message: fmt.Sprintf("at most %d items are allowed", MaxPurchaseOrderLines)})
+	}
+	return fields
+}
+
+func (s *purchaseOrderService) GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error) {
+	var fields []FieldError
+	if filter.Status != "" && !slices.Contains(models.PurchaseOrderStatuses, filter.Status) {
+		fields = append(fields, FieldError{Field: "status", Message: "must be one of " + strings.Join(models.PurchaseOrderStatuses, ", ")})
+	}
+	if filter.SupplierID < 0 {
+		fields = append(fields, FieldError{Field: "supplier_id", Message: "must be positive"})
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid purchase order filter", fields...)
+	}
+	orders, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, fromRepo(err, "purchase order")
+	}
+	for i := range orders {
+		withTotals(&orders[i])
+	}
+	return orders, nil
+}
+
+func (s *purchaseOrderService) GetByID(id int) (*models.PurchaseOrder, error) {
+	po, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "purchase order")
+	}
+	return withTotals(po), nil
+}
+
+func (s *purchaseOrderService) Create(actorID int, req models.CreatePurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	req.Note = strings.TrimSpace(req.Note)
+	var fields []FieldError
+	if req.SupplierID <= 0 {
+		fields = append(fields, FieldError{Field: "supplier_id", Message: "supplier_id is required"})
+	}
+	if len(req.Note) > maxStockNoteLength {
+		fields = append(fields, FieldError{Field: "note", Message: "must be at most 255 characters"})
+	}
+	fields = append(fields, validateLines(len(req.Items))...)
+	seen := map[int]bool{}
+	items := make([]models.PurchaseOrderItem, 0, len(req.Items))
+	for i, line := range req.Items {
+		prefix := fmt.Sprintf("items[%d].", i)
+		if line.ProductID <= 0 {
+			fields = append(fields, FieldError{Field: prefix + "product_id", Message: "product_id is required"})
+		} else if seen[line.ProductID] {
+			fields = append(fields, FieldError{Field: prefix + "product_id", Message: "product listed more than once"})
+		}
+		seen[line.ProductID] = true
+		if line.Quantity <= 0 {
+			fields = append(fields, FieldError{Field: prefix + "quantity", Message: "must be positive"})
+		}
+		if line.UnitCost == nil {
+			fields = append(fields, FieldError{Field: prefix + "unit_cost", Message: "unit_cost is required"})
+		} else if *line.UnitCost < 0 {
+			fields = append(fields, FieldError{Field: prefix + "unit_cost", Message: "must not be negative"})
+		} else {
+			items = append(items, models.PurchaseOrderItem{ProductID: line.ProductID, Quantity: line.Quantity, UnitCost: *line.UnitCost})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid purchase order", fields...)
+	}
+
+	// Supplier dicek dulu supaya foreign key error dari repository pasti berarti produk
+	if _, err := s.suppliers.GetByID(req.SupplierID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, NewValidationError("invalid purchase order", FieldError{Field: "supplier_id", Message: "supplier does not exist"})
+		}
+		return nil, NewInternalError(err)
+	}
+	po := &models.PurchaseOrder{SupplierID: req.SupplierID, Note: req.Note, CreatedBy: repositories.ActorRef(actorID), Items: items}
+	if err := s.repo.Create(po); err != nil {
+		if errors.Is(err, repositories.ErrForeignKey) {
+			return nil, NewValidationError("invalid purchase order", FieldError{Field: "items", Message: "product does not exist"})
+		}
+		return nil, purchaseOrderError(err)
+	}
+	return withTotals(po), nil
+}
+
+func (s *purchaseOrderService) Approve(id, actorID int) (*models.PurchaseOrder, error) {
+	if err := s.repo.Approve(id, actorID); err != nil {
+		return nil, purchaseOrderError(err)
+	}
+	return s.GetByID(id)
+}
+
+func (s *purchaseOrderService) Receive(id, actorID int, req models.ReceivePurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	fields := validateLines(len(req.Items))
+	seen := map[int]bool{}
+	for i, line := range req.Items {
+		prefix := fmt.Sprintf("items[%d].", i)
+		if line.ProductID <= 0 {
+			fields = append(fields, FieldError{Field: prefix + "product_id", Message: "product_id is required"})
+		} else if seen[line.ProductID] {
+			fields = append(fields, FieldError{Field: prefix + "product_id", Message: "product listed more than once"})
+		}
+		seen[line.ProductID] = true
+		if line.Quantity <= 0 {
+			fields = append(fields, FieldError{Field: prefix + "quantity", Message: "must be positive"})
+		}
+		if line.UnitCost != nil && *line.UnitCost < 0 {
+			fields = append(fields, FieldError{Field: prefix + "unit_cost", Message: "must not be negative"})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid receipt", fields...)
+	}
+
+	po, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "purchase order")
+	}
+	// Harga beli default sesuai PO; harga per item tidak berubah setelah PO dibuat
+	costs := make(map[int]int, len(po.Items))
+	for _, it := range po.Items {
+		costs[it.ProductID] = it.UnitCost
+	}
+	receipts := make([]models.PurchaseReceipt, 0, len(req.Items))
+	for _, line := range req.Items {
+		cost := costs[line.ProductID]
+		if line.UnitCost != nil {
+			cost = *line.UnitCost
+		}
+		receipts = append(receipts, models.PurchaseReceipt{ProductID: line.ProductID, Quantity: line.Quantity, UnitCost: cost})
+	}
+	if err := s.repo.Receive(id, receipts, actorID); err != nil {
+		return nil, purchaseOrderError(err)
+	}
+	return s.GetByID(id)
+}
+
+func (s *purchaseOrderService) Cancel(id, actorID int) (*models.PurchaseOrder, error) {
+	if err := s.repo.Cancel(id, actorID); err != nil {
+		return nil, purchaseOrderError(err)
+	}
+	return s.GetByID(id)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type roleService struct {
+	repo  repositories.RoleRepository
+	users repositories.UserRepository
+}
+
+func NewRoleService(repo repositories.RoleRepository, users repositories.UserRepository) RoleService {
+	return &roleService{repo: repo, users: users}
+}
+
+// roleByName mencari role berdasarkan nama, nil kalau tidak ada
+func roleByName(repo repositories.RoleRepository, name string) (*models.Role, error) {
+	roles, err := repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		if roles[i].Name == name {
+			return &roles[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *roleService) GetAll() ([]models.Role, error) {
+	roles, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fromRepo(err, "role")
+	}
+	return roles, nil
+}
+
+func (s *roleService) UpdatePermissions(id int, permissions []string) (*models.Role, error) {
+	role, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "role")
+	}
+
+	var fields []FieldError
+	seen := map[string]bool{}
+	unique := []string{}
+	for i, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !slices.Contains(models.AllPermissions, p) {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("permissions[%d]", i), Message: fmt.Sprintf("unknown permission %q", p)})
+			continue
+		}
+		if !seen[p] {
+			seen[p] = true
+			unique = append(unique, p)
+		}
+	}
+	if role.Name == models.RoleOwner && !seen[models.PermUserManage] {
+		fields = append(fields, FieldError{Field: "permissions", Message: "owner must keep " + models.PermUserManage})
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid permissions", fields...)
+	}
+
+	if err := s.repo.SetPermissions(id, unique); err != nil {
+		return nil, fromRepo(err, "role")
+	}
+	role, err = s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "role")
+	}
+	return role, nil
+}
+
+func (s *roleService) GetUserRoles(userID int) ([]models.Role, error) {
+	if _, err := s.users.GetByID(userID); err != nil {
+		return nil, fromRepo(err, "user")
+	}
+	roles, err := s.repo.GetUserRoles(userID)
+	if err != nil {
+		return nil, fromRepo(err, "role")
+	}
+	return roles, nil
+}
+
+func (s *roleService) SetUserRoles(userID int, names []string) ([]models.Role, error) {
+	if _, err := s.users.GetByID(userID); err != nil {
+		return nil, fromRepo(err, "user")
+	}
+
+	all, err := s.repo.GetAll()
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	var fields []FieldError
+	var ids []int
+	for i, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		idx := slices.IndexFunc(all, func(r models.Role) bool { return r.Name == name })
+		if idx < 0 {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("roles[%d]", i), Message: fmt.Sprintf("unknown role %q", name)})
+			continue
+		}
+		if !slices.Contains(ids, all[idx].ID) {
+			ids = append(ids, all[idx].ID)
+		}
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid roles", fields...)
+	}
+
+	if err := s.repo.SetUserRoles(userID, ids); err != nil {
+		return nil, fromRepo(err, "user")
+	}
+	return s.GetUserRoles(userID)
+}
+
+func (s *roleService) Authorize(userID int, permission string) error {
+	perms, err := s.repo.UserPermissions(userID)
+	if err != nil {
+		return NewInternalError(err)
+	}
+	if !slices.Contains(perms, permission) {
+		return NewForbiddenError("permission " + permission + " required")
+	}
+	return nil
+}
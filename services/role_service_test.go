@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"testing"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func TestRoleServiceAssignments(t *testing.T) {
+	store := memory.NewStore()
+	users, sessions, roleRepo := memory.NewUserRepository(store), memory.NewSessionRepository(store), memory.NewRoleRepository(store)
+	userService := services.NewUserService(users, sessions, roleRepo, testHasher)
+	roles := services.NewRoleService(roleRepo, users)
+
+	if _, err := userService.EnsureAdmin("admin", "rahasia123"); err != nil {
+		t.Fatal(err)
+	}
+	kasir := models.User{Username: "kasir", Password: "rahasia123", Active: true}
+	if err := userService.Create(&kasir); err != nil {
+		t.Fatal(err)
+	}
+
+	// User baru belum punya role, jadi belum punya permission apa pun
+	if err := roles.Authorize(kasir.ID, models.PermProductRead); services.AsError(err).Code != services.CodeForbidden {
+		t.Errorf("Authorize without role err = %v, want FORBIDDEN", err)
+	}
+	if _, err := roles.SetUserRoles(kasir.ID, []string{"Cashier", "kasir"}); services.AsError(err).Code != services.CodeValidation {
+		t.Errorf("unknown role err = %v, want VALIDATION_ERROR", err)
+	}
+	got, err := roles.SetUserRoles(kasir.ID, []string{"Cashier"})
+	if err != nil || len(got) != 1 || got[0].Name != models.RoleCashier {
+		t.Fatalf("SetUserRoles = %+v, %v", got, err)
+	}
+	if err := roles.Authorize(kasir.ID, models.PermTransactionCreate); err != nil {
+		t.Errorf("cashier checkout err = %v", err)
+	}
+	if err := roles.Authorize(kasir.ID, models.PermProductWrite); services.AsError(err).Code != services.CodeForbidden {
+		t.Errorf("cashier product:write err = %v, want FORBIDDEN", err)
+	}
+	if _, err := roles.SetUserRoles(99, []string{"cashier"}); services.AsError(err).Code != services.CodeNotFound {
+		t.Errorf("missing user err = %v, want NOT_FOUND", err)
+	}
+
+	// Owner terakhir tidak boleh dilepas, dihapus atau dinonaktifkan
+	if _, err := roles.SetUserRoles(1, []string{"manager"}); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("remove last owner err = %v, want CONFLICT", err)
+	}
+	if err := userService.Delete(1); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("delete last owner err = %v, want CONFLICT", err)
+	}
+	admin, _ := userService.GetByID(1)
+	admin.Active = false
+	if err := userService.Update(admin); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("deactivate last owner err = %v, want CONFLICT", err)
+	}
+	if _, err := roles.SetUserRoles(kasir.ID, []string{"owner"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := roles.SetUserRoles(1, []string{"manager"}); err != nil {
+		t.Errorf("remove owner when another exists err = %v", err)
+	}
+}
+
+func TestRoleServiceUpdatePermissions(t *testing.T) {
+	store := memory.NewStore()
+	roles := services.NewRoleService(memory.NewRoleRepository(store), memory.NewUserRepository(store))
+
+	tests := []struct {
+		name     string
+		id       int
+		perms    []string
+		wantCode services.ErrorCode
+	}{
+		{"unknown permission", 3, []string{"product:read", "product:delete"}, services.CodeValidation},
+		{"owner keeps user:manage", 1, []string{"product:read"}, services.CodeValidation},
+		{"missing role", 99, nil, services.CodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := roles.UpdatePermissions(tt.id, tt.perms); services.AsError(err).Code != tt.wantCode {
+				t.Errorf("err = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+
+	role, err := roles.UpdatePermissions(3, []string{"product:read", "report:read", "product:read"})
+	if err != nil || len(role.Permissions) != 2 {
+		t.Errorf("UpdatePermissions = %+v, %v, want duplicates removed", role, err)
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import "kasir-api-golang-v1/models"
+
+// ProductService adalah kontrak business logic produk yang dipakai handler.
+// actorID adalah user yang melakukan perubahan, dicatat di audit log.
+type ProductService interface {
+	GetAll(name string) ([]models.Product, error)
+	GetByID(id int) (*models.Product, error)
+	Create(product *models.Product, actorID int) error
+	// Update mengganti data produk; cost_price dan reorder yang tidak dikirim tetap dipertahankan
+	Update(id, actorID int, req models.ProductUpdateRequest) (*models.Product, error)
+	Delete(id, actorID int) error
+	StockHistory(filter models.StockMovementFilter) (*models.StockMovementPage, error)
+	CheckStock() (*models.StockCheck, error)
+	// LowStock mengembalikan produk yang sudah mencapai reorder point
+	LowStock() ([]models.Product, error)
+	// AdjustStock mengubah stock sebesar req.Quantity tanpa menyentuh data produk lain
+	AdjustStock(id, actorID int, req models.StockAdjustmentRequest) (*models.Product, error)
+}
+
+// CategoryService adalah kontrak business logic kategori (termasuk safe delete)
+type CategoryService interface {
+	GetAll() ([]models.Category, error)
+	GetByID(id int) (*models.Category, error)
+	Create(category *models.Category, actorID int) error
+	Update(category *models.Category, actorID int) error
+	Delete(id, actorID int) error
+}
+
+// TransactionService adalah kontrak checkout dan report penjualan
+type TransactionService interface {
+	// Checkout mencatat kasir dan shift open-nya kalau req.CashierID diisi
+	Checkout(req models.CheckoutRequest) (*models.Transaction, error)
+	// Preview menghitung hasil checkout tanpa menyimpan dan tanpa mengurangi stock
+	Preview(req models.CheckoutRequest) (*models.CheckoutPreview, error)
+	List(filter models.TransactionFilter) (*models.TransactionPage, error)
+	GetByID(id int) (*models.Transaction, error)
+	// Void dan Refund dicatat ke shift open milik req.CashierID (kalau diisi)
+	Void(id int, req models.RefundRequest) (*models.Refund, error)
+	Refund(id int, req models.RefundRequest) (*models.Refund, error)
+	GetTodayReport() (map[string]interface{}, error)
+	GetRangeReport(startDate, endDate string) (map[string]interface{}, error)
+	// GetProfitReport menghitung net sales, COGS dan laba kotor per groupBy (kosong = total saja)
+	GetProfitReport(startDate, endDate, groupBy string) (*models.ProfitReport, error)
+}
+
+// PromoCodeService adalah kontrak pengelolaan kode promo
+type PromoCodeService interface {
+	GetAll() ([]models.PromoCode, error)
+	GetByID(id int) (*models.PromoCode, error)
+	Create(promo *models.PromoCode) error
+	Update(promo *models.PromoCode) error
+	Delete(id int) error
+}
+
+// PromotionService adalah kontrak pengelolaan aturan promosi otomatis
+type PromotionService interface {
+	GetAll() ([]models.Promotion, error)
+	GetByID(id int) (*models.Promotion, error)
+	Create(promo *models.Promotion) error
+	Update(promo *models.Promotion) error
+	Delete(id int) error
+}
+
+// CartService adalah kontrak cart yang di-parkir. Checkout cart didelegasikan
+// ke TransactionService.Checkout supaya aturan harga dan stock tetap satu.
+type CartService interface {
+	// GetAll mengembalikan cart yang masih open
+	GetAll() ([]models.Cart, error)
+	GetByID(id int) (*models.Cart, error)
+	Create(cart *models.Cart) error
+	// Update mengganti note dan status reservasi, items tidak berubah
+	Update(cart *models.Cart) error
+	// AddItem menambah qty produk (atau baris baru kalau belum ada di cart)
+	AddItem(cartID int, item models.CartItem) (*models.Cart, error)
+	// UpdateItem mengganti qty dan diskon baris produk yang sudah ada di cart
+	UpdateItem(cartID int, item models.CartItem) (*models.Cart, error)
+	RemoveItem(cartID, productID int) (*models.Cart, error)
+	// Checkout memakai items cart; req hanya berisi diskon, promo code dan pembayaran
+	Checkout(cartID int, req models.CheckoutRequest) (*models.Transaction, error)
+	// Cancel membatalkan cart dan mengembalikan stock yang di-reserve
+	Cancel(id int) error
+	// ExpireReservations melepas reservasi yang sudah lewat TTL, dipanggil oleh sweeper
+	ExpireReservations() (int, error)
+}
+
+// IdempotencyService menjaga supaya request dengan Idempotency-Key yang sama
+// hanya diproses sekali dan retry-nya mendapat response pertama
+type IdempotencyService interface {
+	// Begin mencadangkan key untuk request dengan hash tertentu. Kalau key sudah
+	// selesai diproses untuk request yang sama, record-nya dikembalikan untuk
+	// di-replay; nil berarti request boleh diproses.
+	Begin(key, requestHash string) (*models.IdempotencyRecord, error)
+	// Complete menyimpan response request yang sudah diproses
+	Complete(key string, statusCode int, response []byte) error
+	// Abort melepas key supaya request bisa dicoba lagi (misalnya setelah error 5xx)
+	Abort(key string) error
+	// PurgeExpired menghapus key yang sudah lewat masa berlakunya, dipanggil oleh sweeper
+	PurgeExpired() (int, error)
+}
+
+// UserService adalah kontrak pengelolaan akun user
+type UserService interface {
+	GetAll() ([]models.User, error)
+	GetByID(id int) (*models.User, error)
+	// Create wajib dengan password; password di-hash sebelum disimpan
+	Create(user *models.User) error
+	// Update mengganti password hanya kalau diisi. Ganti password atau menonaktifkan
+	// user mencabut semua token login-nya.
+	Update(user *models.User) error
+	Delete(id int) error
+	// EnsureAdmin membuat user pertama dengan role owner kalau tabel users masih kosong
+	EnsureAdmin(username, password string) (bool, error)
+}
+
+// AuthService adalah kontrak login dengan token opaque yang disimpan di server
+type AuthService interface {
+	Login(req models.LoginRequest) (*models.AuthToken, error)
+	// Authenticate mengembalikan user pemilik token yang masih berlaku
+	Authenticate(token string) (*models.User, error)
+	// Refresh menerbitkan token baru dan mencabut token lama
+	Refresh(token string) (*models.AuthToken, error)
+	Logout(token string) error
+	// PurgeExpired menghapus session yang sudah kedaluwarsa, dipanggil oleh sweeper
+	PurgeExpired() (int, error)
+}
+
+// RoleService adalah kontrak role dan permission. Permission per role disimpan di
+// database sehingga bisa diubah tanpa deploy ulang.
+type RoleService interface {
+	GetAll() ([]models.Role, error)
+	// UpdatePermissions mengganti permission role, hanya permission di models.AllPermissions
+	UpdatePermissions(id int, permissions []string) (*models.Role, error)
+	GetUserRoles(userID int) ([]models.Role, error)
+	// SetUserRoles mengganti role user berdasarkan nama role; owner terakhir tidak bisa dilepas
+	SetUserRoles(userID int, roles []string) ([]models.Role, error)
+	// Authorize mengembalikan FORBIDDEN kalau role user tidak punya permission
+	Authorize(userID int, permission string) error
+}
+
+// ShiftService adalah kontrak shift kasir dan rekonsiliasi kasnya
+type ShiftService interface {
+	// Open membuka shift baru untuk user dengan modal awal di laci
+	Open(userID int, req models.OpenShiftRequest) (*models.Shift, error)
+	// Current mengembalikan report sementara shift open milik user
+	Current(userID int) (*models.ShiftReport, error)
+	// Close menutup shift dengan kas yang dihitung, hasilnya report final. Shift
+	// user lain hanya bisa ditutup dengan permission shift:manage.
+	Close(userID, shiftID int, req models.CloseShiftRequest) (*models.ShiftReport, error)
+	GetReport(id int) (*models.ShiftReport, error)
+	GetAll(filter models.ShiftFilter) ([]models.Shift, error)
+}
+
+// AuditService adalah kontrak pembacaan audit log
+type AuditService interface {
+	List(filter models.AuditFilter) (*models.AuditPage, error)
+}
+
+// StockCountService adalah kontrak business logic stock opname. actorID adalah
+// user yang menghitung / menutup sesi.
+type StockCountService interface {
+	GetAll(status string) ([]models.StockCount, error)
+	GetByID(id int) (*models.StockCount, error)
+	Start(actorID int, req models.StartStockCountRequest) (*models.StockCount, error)
+	// Submit menyimpan satu batch hitungan; bisa dipanggil dari beberapa device
+	Submit(id, actorID int, req models.SubmitStockCountRequest) (*models.StockCount, error)
+	// Finalize menerapkan semua selisih ke stock, hasilnya variance report final
+	Finalize(id, actorID int) (*models.StockCount, error)
+	Cancel(id, actorID int) (*models.StockCount, error)
+}
+
+// SupplierService adalah kontrak business logic supplier
+type SupplierService interface {
+	GetAll() ([]models.Supplier, error)
+	GetByID(id int) (*models.Supplier, error)
+	Create(supplier *models.Supplier) error
+	Update(supplier *models.Supplier) error
+	Delete(id int) error
+}
+
+// PurchaseOrderService adalah kontrak business logic purchase order. actorID
+// adalah user yang membuat, approve, menerima barang atau cancel.
+type PurchaseOrderService interface {
+	GetAll(filter models.PurchaseOrderFilter) ([]models.PurchaseOrder, error)
+	GetByID(id int) (*models.PurchaseOrder, error)
+	Create(actorID int, req models.CreatePurchaseOrderRequest) (*models.PurchaseOrder, error)
+	Approve(id, actorID int) (*models.PurchaseOrder, error)
+	// Receive menambah stock sesuai barang yang datang; boleh sebagian dan berkali-kali
+	Receive(id, actorID int, req models.ReceivePurchaseOrderRequest) (*models.PurchaseOrder, error)
+	Cancel(id, actorID int) (*models.PurchaseOrder, error)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type shiftService struct {
+	repo  repositories.ShiftRepository
+	roles RoleService
+}
+
+func NewShiftService(repo repositories.ShiftRepository, roles RoleService) ShiftService {
+	return &shiftService{repo: repo, roles: roles}
+}
+
+// maxShiftNoteLength sama dengan kolom shifts.note
+const maxShiftNoteLength = 255
+
+func validateShiftNote(note string) []FieldError {
+	if len(note) > maxShiftNoteLength {
+		return []FieldError{{Field: "note", Message: "must be at most 255 characters"}}
+	}
+	return nil
+}
+
+// shiftReport membandingkan kas yang seharusnya dengan kas yang dihitung. Untuk
+// shift open ExpectedCash dihitung dari summary saat ini.
+func shiftReport(shift *models.Shift, summary models.ShiftSummary) *models.ShiftReport {
+	report := &models.ShiftReport{
+		Shift:        *shift,
+		Summary:      summary,
+		CashSales:    summary.SalesByMethod[models.PaymentCash],
+		ExpectedCash: repositories.ExpectedCash(shift.OpeningCash, summary),
+		CountedCash:  shift.CountedCash,
+	}
+	if shift.ExpectedCash != nil {
+		report.ExpectedCash = *shift.ExpectedCash
+	}
+	if shift.CountedCash != nil {
+		diff := *shift.CountedCash - report.ExpectedCash
+		report.Difference = &diff
+	}
+	return report
+}
+
+func (s *shiftService) Open(userID int, req models.OpenShiftRequest) (*models.Shift, error) {
+	req.Note = strings.TrimSpace(req.Note)
+	fields := validateShiftNote(req.Note)
+	if req.OpeningCash < 0 {
+		fields = append(fields, FieldError{Field: "opening_cash", Message: "must not be negative"})
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid shift", fields...)
+	}
+
+	shift := &models.Shift{UserID: userID, OpeningCash: req.OpeningCash, Note: req.Note}
+	if err := s.repo.Open(shift); err != nil {
+		if errors.Is(err, repositories.ErrShiftAlreadyOpen) {
+			return nil, &Error{Code: CodeConflict, Message: "you already have an open shift", Err: err}
+		}
+		return nil, fromRepo(err, "shift")
+	}
+	return shift, nil
+}
+
+func (s *shiftService) Current(userID int) (*models.ShiftReport, error) {
+	shift, err := s.repo.GetOpenByUser(userID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, NewNotFoundError("no open shift")
+	}
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	return s.report(shift)
+}
+
+func (s *shiftService) Close(userID, shiftID int, req models.CloseShiftRequest) (*models.ShiftReport, error) {
+	req.Note = strings.TrimSpace(req.Note)
+	fields := validateShiftNote(req.Note)
+	if req.CountedCash == nil {
+		fields = append(fields, FieldError{Field: "counted_cash", Message: "counted_cash is required"})
+	} else if *req.CountedCash < 0 {
+		fields = append(fields, FieldError{Field: "counted_cash", Message: "must not be negative"})
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid shift closing", fields...)
+	}
+
+	shift, err := s.repo.GetByID(shiftID)
+	if err != nil {
+		return nil, fromRepo(err, "shift")
+	}
+	// Kas dihitung oleh kasir pemilik laci; shift:manage bisa menutup shift
+	// kasir yang pulang tanpa menutupnya
+	if shift.UserID != userID {
+		if err := s.roles.Authorize(userID, models.PermShiftManage); err != nil {
+			return nil, NewForbiddenError("you can only close your own shift")
+		}
+	}
+
+	shift, summary, err := s.repo.Close(shiftID, *req.CountedCash, req.Note)
+	if err != nil {
+		if errors.Is(err, repositories.ErrShiftClosed) {
+			return nil, &Error{Code: CodeConflict, Message: "shift already closed", Err: err}
+		}
+		return nil, fromRepo(err, "shift")
+	}
+	return shiftReport(shift, summary), nil
+}
+
+func (s *shiftService) GetReport(id int) (*models.ShiftReport, error) {
+	shift, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "shift")
+	}
+	return s.report(shift)
+}
+
+func (s *shiftService) report(shift *models.Shift) (*models.ShiftReport, error) {
+	summary, err := s.repo.Summary(shift.ID)
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	return shiftReport(shift, summary), nil
+}
+
+func (s *shiftService) GetAll(filter models.ShiftFilter) ([]models.Shift, error) {
+	if filter.Status != "" && filter.Status != models.ShiftOpen && filter.Status != models.ShiftClosed {
+		return nil, NewValidationError("invalid shift filter", FieldError{Field: "status", Message: "must be open or closed"})
+	}
+	shifts, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, fromRepo(err, "shift")
+	}
+	return shifts, nil
+}
@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"testing"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func TestShiftServiceReconciliation(t *testing.T) {
+	f := newTransactionFixture(t)
+	users := memory.NewUserRepository(f.store)
+	roles := services.NewRoleService(memory.NewRoleRepository(f.store), users)
+	shifts := services.NewShiftService(memory.NewShiftRepository(f.store), roles)
+	ani := models.User{Username: "ani", PasswordHash: "x", Active: true}
+	budi := models.User{Username: "budi", PasswordHash: "x", Active: true}
+	citra := models.User{Username: "citra", PasswordHash: "x", Active: true}
+	for _, u := range []*models.User{&ani, &budi, &citra} {
+		if err := users.Create(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id, role := range map[int]string{ani.ID: models.RoleCashier, budi.ID: models.RoleCashier, citra.ID: models.RoleManager} {
+		if _, err := roles.SetUserRoles(id, []string{role}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkout := func(cashierID int, method string, amount int) (*models.Transaction, error) {
+		return f.trx.Checkout(models.CheckoutRequest{
+			Items:     []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}},
+			Payments:  []models.CheckoutPayment{{Method: method, Amount: amount}},
+			CashierID: cashierID,
+		})
+	}
+
+	// Kasir tanpa shift open tidak bisa menerima uang
+	if _, err := checkout(ani.ID, models.PaymentCash, 10000); services.AsError(err).Code != services.CodeConflict {
+		t.Fatalf("checkout without shift err = %v, want CONFLICT", err)
+	}
+	if _, err := shifts.Open(ani.ID, models.OpenShiftRequest{OpeningCash: -1}); services.AsError(err).Code != services.CodeValidation {
+		t.Errorf("negative opening cash err = %v, want VALIDATION_ERROR", err)
+	}
+	shift, err := shifts.Open(ani.ID, models.OpenShiftRequest{OpeningCash: 50000, Note: " pagi "})
+	if err != nil || shift.Note != "pagi" {
+		t.Fatalf("Open = %+v, %v", shift, err)
+	}
+	if _, err := shifts.Open(ani.ID, models.OpenShiftRequest{}); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("second Open err = %v, want CONFLICT", err)
+	}
+
+	// Cash 20000 untuk total 10000: yang masuk laci hanya 10000 setelah kembalian
+	sale, err := checkout(ani.ID, models.PaymentCash, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sale.CashierID == nil || *sale.CashierID != ani.ID || sale.ShiftID == nil || *sale.ShiftID != shift.ID {
+		t.Errorf("transaction cashier/shift = %v/%v", sale.CashierID, sale.ShiftID)
+	}
+	if _, err := checkout(ani.ID, models.PaymentQRIS, 10000); err != nil {
+		t.Fatal(err)
+	}
+	// Transaksi tanpa kasir (misalnya dari script internal) tidak butuh shift
+	if _, err := checkout(0, models.PaymentCash, 10000); err != nil {
+		t.Fatal(err)
+	}
+	refund, err := f.trx.Refund(sale.ID, models.RefundRequest{
+		Reason: "rusak", Items: []models.RefundLine{{DetailID: 1, Quantity: 1}}, CashierID: ani.ID,
+	})
+	if err != nil || refund.ShiftID == nil || *refund.ShiftID != shift.ID {
+		t.Fatalf("Refund = %+v, %v", refund, err)
+	}
+
+	current, err := shifts.Current(ani.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 50000 modal + 10000 cash - 5000 refund
+	if current.ExpectedCash != 55000 || current.CashSales != 10000 || current.Summary.TransactionCount != 2 ||
+		current.Summary.SalesByMethod[models.PaymentQRIS] != 10000 || current.Difference != nil {
+		t.Errorf("Current = %+v", current)
+	}
+	if _, err := shifts.Current(budi.ID); services.AsError(err).Code != services.CodeNotFound {
+		t.Errorf("Current without shift err = %v, want NOT_FOUND", err)
+	}
+
+	tests := []struct {
+		name     string
+		userID   int
+		shiftID  int
+		req      models.CloseShiftRequest
+		wantCode services.ErrorCode
+	}{
+		{"counted cash required", ani.ID, shift.ID, models.CloseShiftRequest{}, services.CodeValidation},
+		{"negative counted cash", ani.ID, shift.ID, models.CloseShiftRequest{CountedCash: intPtr(-1)}, services.CodeValidation},
+		{"other cashier", budi.ID, shift.ID, models.CloseShiftRequest{CountedCash: intPtr(0)}, services.CodeForbidden},
+		{"missing shift", ani.ID, 99, models.CloseShiftRequest{CountedCash: intPtr(0)}, services.CodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := shifts.Close(tt.userID, tt.shiftID, tt.req); services.AsError(err).Code != tt.wantCode {
+				t.Errorf("err = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+
+	report, err := shifts.Close(ani.ID, shift.ID, models.CloseShiftRequest{CountedCash: intPtr(54000), Note: "kurang 1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ExpectedCash != 55000 || report.Difference == nil || *report.Difference != -1000 || report.Shift.Status != models.ShiftClosed {
+		t.Errorf("Close report = %+v", report)
+	}
+	if got, err := shifts.GetReport(shift.ID); err != nil || got.ExpectedCash != 55000 || *got.Difference != -1000 {
+		t.Errorf("GetReport = %+v, %v", got, err)
+	}
+	if _, err := shifts.Close(ani.ID, shift.ID, models.CloseShiftRequest{CountedCash: intPtr(0)}); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("second Close err = %v, want CONFLICT", err)
+	}
+	if _, err := checkout(ani.ID, models.PaymentCash, 10000); services.AsError(err).Code != services.CodeConflict {
+		t.Errorf("checkout after close err = %v, want CONFLICT", err)
+	}
+
+	// Manager menutup shift kasir yang pulang tanpa menutupnya, supaya kasir bisa buka shift lagi
+	left, err := shifts.Open(budi.ID, models.OpenShiftRequest{OpeningCash: 20000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report, err := shifts.Close(citra.ID, left.ID, models.CloseShiftRequest{CountedCash: intPtr(20000)}); err != nil || report.Shift.Status != models.ShiftClosed || report.Shift.UserID != budi.ID {
+		t.Fatalf("manager Close = %+v, %v", report, err)
+	}
+	if _, err := shifts.Open(budi.ID, models.OpenShiftRequest{}); err != nil {
+		t.Errorf("Open after manager close err = %v", err)
+	}
+
+	if _, err := shifts.GetAll(models.ShiftFilter{Status: "lost"}); services.AsError(err).Code != services.CodeValidation {
+		t.Errorf("invalid status filter err = %v, want VALIDATION_ERROR", err)
+	}
+}
+
+func intPtr(v int) *int { return &v }
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"kasir-api-golang-v1/models"
+)
+
+// DefaultStockAlertBuffer adalah kapasitas antrean alert yang belum terkirim
+const DefaultStockAlertBuffer = 100
+
+// DefaultWebhookTimeout membatasi satu kali POST ke webhook
+const DefaultWebhookTimeout = 5 * time.Second
+
+// StockNotifier mengirim alert low stock ke luar (log, webhook, file, ...).
+// Dipanggil dari goroutine StockAlerter, bukan dari request checkout.
+type StockNotifier interface {
+	Notify(alert models.LowStockAlert) error
+}
+
+// LogNotifier menulis alert ke log server
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(a models.LowStockAlert) error {
+	log.Printf("low stock: %s (product %d) stock %d, reorder point %d, reorder %d (transaction %d)",
+		a.ProductName, a.ProductID, a.Stock, a.ReorderPoint, a.ReorderQuantity, a.TransactionID)
+	return nil
+}
+
+// WebhookNotifier mengirim alert sebagai JSON lewat POST; status selain 2xx dianggap gagal
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(a models.LowStockAlert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultWebhookTimeout}
+	}
+	resp, err := client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s: unexpected status %s", n.URL, resp.Status)
+	}
+	return nil
+}
+
+// FileNotifier menambahkan alert ke file lokal, satu JSON per baris
+type FileNotifier struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (n *FileNotifier) Notify(a models.LowStockAlert) error {
+	line, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// NewStockNotifier membangun notifier dari config STOCK_ALERT_NOTIFIER dan
+// STOCK_ALERT_TARGET. "none" mengembalikan nil (alert dimatikan).
+func NewStockNotifier(kind, target string) (StockNotifier, error) {
+	switch kind {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "webhook":
+		if target == "" {
+			return nil, fmt.Errorf("webhook notifier needs a target URL")
+		}
+		return &WebhookNotifier{URL: target, Client: &http.Client{Timeout: DefaultWebhookTimeout}}, nil
+	case "file":
+		if target == "" {
+			return nil, fmt.Errorf("file notifier needs a target path")
+		}
+		return &FileNotifier{Path: target}, nil
+	case "none":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown notifier %q (use log, webhook, file or none)", kind)
+}
+
+// StockAlerter mengantrekan alert dari checkout dan mengirimnya di background,
+// jadi notifier yang lambat atau gagal tidak pernah menahan checkout.
+// StockAlerter nil berarti alert dimatikan.
+type StockAlerter struct {
+	notifier StockNotifier
+	alerts   chan models.LowStockAlert
+}
+
+func NewStockAlerter(notifier StockNotifier, buffer int) *StockAlerter {
+	if buffer <= 0 {
+		buffer = DefaultStockAlertBuffer
+	}
+	return &StockAlerter{notifier: notifier, alerts: make(chan models.LowStockAlert, buffer)}
+}
+
+// Enqueue tidak pernah blocking; kalau antrean penuh alert dibuang dan dicatat ke log
+func (a *StockAlerter) Enqueue(alerts ...models.LowStockAlert) {
+	if a == nil {
+		return
+	}
+	for _, alert := range alerts {
+		select {
+		case a.alerts <- alert:
+		default:
+			log.Printf("stock alert queue full, dropping alert for product %d", alert.ProductID)
+		}
+	}
+}
+
+// Run mengirim alert yang masuk antrean sampai ctx selesai. Dijalankan sebagai goroutine dari main.
+func (a *StockAlerter) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alert := <-a.alerts:
+			if err := a.notifier.Notify(alert); err != nil {
+				log.Printf("stock alert for product %d: %v", alert.ProductID, err)
+			}
+		}
+	}
+}
+
+// lowStockAlerts mencari produk yang stock-nya baru turun melewati reorder point
+// karena transaksi ini. products adalah snapshot sebelum stock dikurangi; produk
+// yang sudah low sebelumnya tidak di-alert lagi.
+func lowStockAlerts(items []models.CheckoutItem, products map[int]models.Product, trx *models.Transaction) []models.LowStockAlert {
+	sold := map[int]int{}
+	for _, item := range items {
+		sold[item.ProductID] += item.Quantity
+	}
+	var alerts []models.LowStockAlert
+	for id, qty := range sold {
+		p, ok := products[id]
+		if !ok || p.ReorderPoint <= 0 {
+			continue
+		}
+		if p.Stock > p.ReorderPoint && p.Stock-qty <= p.ReorderPoint {
+			alerts = append(alerts, models.LowStockAlert{
+				ProductID:       p.ID,
+				ProductName:     p.Name,
+				Stock:           p.Stock - qty,
+				ReorderPoint:    p.ReorderPoint,
+				ReorderQuantity: p.ReorderQuantity,
+				TransactionID:   trx.ID,
+				CreatedAt:       trx.CreatedAt,
+			})
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ProductID < alerts[j].ProductID })
+	return alerts
+}
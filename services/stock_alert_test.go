@@ -0,0 +1,171 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+// chanNotifier meneruskan alert ke channel supaya test bisa menunggu goroutine alerter
+type chanNotifier chan models.LowStockAlert
+
+func (c chanNotifier) Notify(a models.LowStockAlert) error {
+	c <- a
+	return nil
+}
+
+func TestCheckoutLowStockAlerts(t *testing.T) {
+	store := memory.NewStore()
+	notified := make(chanNotifier, 10)
+	alerter := services.NewStockAlerter(notified, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go alerter.Run(ctx)
+
+	products := services.NewProductService(memory.NewProductRepository(store), memory.NewStockMovementRepository(store))
+	trx := services.NewTransactionService(memory.NewTransactionRepository(store), memory.NewPromoCodeRepository(store),
+		memory.NewPromotionRepository(store), memory.NewShiftRepository(store), services.TaxConfig{}, alerter)
+	teh := models.Product{Name: "Teh", Price: 5000, Stock: 10, ReorderPoint: 5, ReorderQuantity: 24}
+	kopi := models.Product{Name: "Kopi", Price: 8000, Stock: 20}
+	for _, p := range []*models.Product{&teh, &kopi} {
+		if err := products.Create(p, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkout := func(qty int) *models.Transaction {
+		t.Helper()
+		tx, err := trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: teh.ID, Quantity: qty}, {ProductID: kopi.ID, Quantity: qty}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	next := func() models.LowStockAlert {
+		t.Helper()
+		select {
+		case a := <-notified:
+			return a
+		case <-time.After(2 * time.Second):
+			t.Fatal("no alert received")
+		}
+		return models.LowStockAlert{}
+	}
+
+	checkout(3) // 10 -> 7, masih di atas reorder point
+	crossed := checkout(2)
+	checkout(1) // sudah low, tidak di-alert lagi
+
+	// Setelah restock, turun lagi melewati reorder point memicu alert baru.
+	// Update tanpa field reorder tidak mematikan alert.
+	restock := models.ProductUpdateRequest{Name: teh.Name, Price: teh.Price, Stock: 10, CategoryID: teh.CategoryID}
+	if _, err := products.Update(teh.ID, 0, restock); err != nil {
+		t.Fatal(err)
+	}
+	again := checkout(6)
+
+	for _, want := range []struct {
+		trx   *models.Transaction
+		stock int
+	}{{crossed, 5}, {again, 4}} {
+		a := next()
+		if a.ProductID != teh.ID || a.TransactionID != want.trx.ID || a.Stock != want.stock || a.ReorderPoint != 5 || a.ReorderQuantity != 24 || a.CreatedAt.IsZero() {
+			t.Errorf("alert = %+v, want transaction %d stock %d", a, want.trx.ID, want.stock)
+		}
+	}
+	select {
+	case a := <-notified:
+		t.Errorf("unexpected alert %+v", a)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got models.LowStockAlert
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	n := &services.WebhookNotifier{URL: srv.URL, Client: srv.Client()}
+	if err := n.Notify(models.LowStockAlert{ProductID: 7, Stock: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got.ProductID != 7 || got.Stock != 2 {
+		t.Errorf("webhook payload = %+v", got)
+	}
+
+	status = http.StatusInternalServerError
+	if err := n.Notify(models.LowStockAlert{ProductID: 7}); err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.log")
+	n := &services.FileNotifier{Path: path}
+	for _, id := range []int{1, 2} {
+		if err := n.Notify(models.LowStockAlert{ProductID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	var a models.LowStockAlert
+	if err := json.Unmarshal([]byte(lines[1]), &a); err != nil || a.ProductID != 2 {
+		t.Errorf("second line = %q, %v", lines[1], err)
+	}
+}
+
+func TestNewStockNotifier(t *testing.T) {
+	tests := []struct {
+		kind, target string
+		wantNil      bool
+		wantErr      bool
+	}{
+		{"", "", false, false},
+		{"log", "", false, false},
+		{"webhook", "http://example.com/hook", false, false},
+		{"webhook", "", false, true},
+		{"file", "/tmp/alerts.log", false, false},
+		{"file", "", false, true},
+		{"none", "", true, false},
+		{"slack", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			n, err := services.NewStockNotifier(tt.kind, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (n == nil) != tt.wantNil {
+				t.Errorf("notifier = %v, wantNil %v", n, tt.wantNil)
+			}
+		})
+	}
+
+	// Alerter nil (alert dimatikan) aman dipanggil
+	var off *services.StockAlerter
+	off.Enqueue(models.LowStockAlert{ProductID: 1})
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type stockCountService struct {
+	repo repositories.StockCountRepository
+}
+
+func NewStockCountService(repo repositories.StockCountRepository) StockCountService {
+	return &stockCountService{repo: repo}
+}
+
+// MaxStockCountBatch adalah batas jumlah produk dalam satu kali submit hitungan
+const MaxStockCountBatch = 500
+
+// stockCountError menerjemahkan error repository stock opname
+func stockCountError(err error) error {
+	if e := stockShortage(err); e != nil {
+		return e
+	}
+	switch {
+	case errors.Is(err, repositories.ErrStockCountClosed):
+		return &Error{Code: CodeConflict, Message: "stock count is already finalized or cancelled", Err: err}
+	case errors.Is(err, repositories.ErrStockCountOpen):
+		return &Error{Code: CodeConflict, Message: "another stock count is still open", Err: err}
+	case errors.Is(err, repositories.ErrForeignKey):
+		return NewValidationError("invalid stock count", FieldError{Field: "items", Message: "product does not exist"})
+	}
+	return fromRepo(err, "stock count")
+}
+
+// withSummary menghitung variance report dari items
+func withSummary(count *models.StockCount) *models.StockCount {
+	sum := models.StockCountSummary{ItemsCounted: len(count.Items)}
+	for _, it := range count.Items {
+		if it.Variance == 0 {
+			continue
+		}
+		sum.ItemsWithVariance++
+		if it.Variance > 0 {
+			sum.SurplusQuantity += it.Variance
+		} else {
+			sum.ShortageQuantity -= it.Variance
+		}
+		sum.VarianceValue += it.Variance * it.Price
+	}
+	count.Summary = sum
+	return count
+}
+
+func (s *stockCountService) GetAll(status string) ([]models.StockCount, error) {
+	switch status {
+	case "", models.StockCountOpen, models.StockCountFinalized, models.StockCountCancelled:
+	default:
+		return nil, NewValidationError("invalid stock count filter", FieldError{Field: "status", Message: "must be open, finalized or cancelled"})
+	}
+	counts, err := s.repo.GetAll(status)
+	if err != nil {
+		return nil, fromRepo(err, "stock count")
+	}
+	for i := range counts {
+		withSummary(&counts[i])
+	}
+	return counts, nil
+}
+
+func (s *stockCountService) GetByID(id int) (*models.StockCount, error) {
+	count, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "stock count")
+	}
+	return withSummary(count), nil
+}
+
+func (s *stockCountService) Start(actorID int, req models.StartStockCountRequest) (*models.StockCount, error) {
+	req.Note = strings.TrimSpace(req.Note)
+	if len(req.Note) > maxStockNoteLength {
+		return nil, NewValidationError("invalid stock count", FieldError{Field: "note", Message: "must be at most 255 characters"})
+	}
+	count := &models.StockCount{Note: req.Note, CreatedBy: repositories.ActorRef(actorID)}
+	if err := s.repo.Create(count); err != nil {
+		return nil, stockCountError(err)
+	}
+	return withSummary(count), nil
+}
+
+func (s *stockCountService) Submit(id, actorID int, req models.SubmitStockCountRequest) (*models.StockCount, error) {
+	var fields []FieldError
+	if len(req.Items) == 0 {
+		fields = append(fields, FieldError{Field: "items", Message: "at least one item is required"})
+	}
+	if len(req.Items) > MaxStockCountBatch {
+		fields = append(fields, FieldError{Field: "items", Message: fmt.Sprintf("at most %d items are allowed per batch", MaxStockCountBatch)})
+	}
+	seen := map[int]bool{}
+	items := make([]models.StockCountItem, 0, len(req.Items))
+	for i, entry := range req.Items {
+		prefix := fmt.Sprintf("items[%d].", i)
+		if entry.ProductID <= 0 {
+			fields = append(fields, FieldError{Field: prefix + "product_id", Message: "product_id is required"})
+		} else if seen[entry.ProductID] {
+			fields = append(fields, FieldError{Field: prefix + "product_id", Message: "product listed more than once"})
+		}
+		seen[entry.ProductID] = true
+		if entry.CountedQuantity == nil {
+			fields = append(fields, FieldError{Field: prefix + "counted_quantity", Message: "counted_quantity is required"})
+		} else if *entry.CountedQuantity < 0 {
+			fields = append(fields, FieldError{Field: prefix + "counted_quantity", Message: "must not be negative"})
+		} else {
+			items = append(items, models.StockCountItem{ProductID: entry.ProductID, CountedQuantity: *entry.CountedQuantity})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid stock count", fields...)
+	}
+
+	if err := s.repo.SubmitItems(id, items, actorID); err != nil {
+		return nil, stockCountError(err)
+	}
+	return s.GetByID(id)
+}
+
+func (s *stockCountService) Finalize(id, actorID int) (*models.StockCount, error) {
+	count, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "stock count")
+	}
+	if count.Status == models.StockCountOpen && len(count.Items) == 0 {
+		return nil, NewConflictError("nothing has been counted yet")
+	}
+	if err := s.repo.Finalize(id, actorID); err != nil {
+		return nil, stockCountError(err)
+	}
+	return s.GetByID(id)
+}
+
+func (s *stockCountService) Cancel(id, actorID int) (*models.StockCount, error) {
+	if err := s.repo.Cancel(id, actorID); err != nil {
+		return nil, stockCountError(err)
+	}
+	return s.GetByID(id)
+}
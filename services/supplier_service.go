@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type supplierService struct {
+	repo repositories.SupplierRepository
+}
+
+func NewSupplierService(repo repositories.SupplierRepository) SupplierService {
+	return &supplierService{repo: repo}
+}
+
+// maxSupplierFieldLength sama dengan VARCHAR(255) di migration 0016
+const maxSupplierFieldLength = 255
+
+func validateSupplier(s *models.Supplier) error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Phone = strings.TrimSpace(s.Phone)
+	s.Email = strings.TrimSpace(s.Email)
+	s.Address = strings.TrimSpace(s.Address)
+
+	var fields []FieldError
+	if s.Name == "" {
+		fields = append(fields, FieldError{Field: "name", Message: "name is required"})
+	}
+	for _, f := range []struct{ field, value string }{{"name", s.Name}, {"email", s.Email}, {"address", s.Address}} {
+		if len(f.value) > maxSupplierFieldLength {
+			fields = append(fields, FieldError{Field: f.field, Message: "must be at most 255 characters"})
+		}
+	}
+	if len(s.Phone) > 50 {
+		fields = append(fields, FieldError{Field: "phone", Message: "must be at most 50 characters"})
+	}
+	if s.Email != "" && !strings.Contains(s.Email, "@") {
+		fields = append(fields, FieldError{Field: "email", Message: "must be a valid email address"})
+	}
+	if len(fields) > 0 {
+		return NewValidationError("invalid supplier", fields...)
+	}
+	return nil
+}
+
+func (s *supplierService) GetAll() ([]models.Supplier, error) {
+	suppliers, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fromRepo(err, "supplier")
+	}
+	return suppliers, nil
+}
+
+func (s *supplierService) GetByID(id int) (*models.Supplier, error) {
+	supplier, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "supplier")
+	}
+	return supplier, nil
+}
+
+func (s *supplierService) Create(supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	if err := s.repo.Create(supplier); err != nil {
+		return fromRepo(err, "supplier")
+	}
+	return nil
+}
+
+func (s *supplierService) Update(supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	if err := s.repo.Update(supplier); err != nil {
+		return fromRepo(err, "supplier")
+	}
+	return nil
+}
+
+func (s *supplierService) Delete(id int) error {
+	if err := s.repo.Delete(id); err != nil {
+		if errors.Is(err, repositories.ErrForeignKey) {
+			return &Error{Code: CodeConflict, Message: "supplier already has purchase orders and cannot be deleted", Err: err}
+		}
+		return fromRepo(err, "supplier")
+	}
+	return nil
+}
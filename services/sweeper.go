@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunSweeper memanggil sweep setiap interval sampai ctx selesai, misalnya
+// CartService.ExpireReservations atau IdempotencyService.PurgeExpired.
+// Dijalankan sebagai goroutine dari main; name hanya untuk log.
+func RunSweeper(ctx context.Context, name string, interval time.Duration, sweep func() (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := sweep()
+			if err != nil {
+				log.Printf("%s sweeper: %v", name, err)
+			} else if n > 0 {
+				log.Printf("%s sweeper: cleaned up %d", name, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"kasir-api-golang-v1/models"
+)
+
+// TaxConfig adalah aturan pajak (PPN) dan service charge yang dipakai saat checkout.
+// Zero value berarti tanpa pajak dan tanpa service charge.
+type TaxConfig struct {
+	Rate              float64 // persen, misalnya 11 untuk PPN 11%
+	Inclusive         bool    // harga produk sudah termasuk pajak
+	ServiceChargeRate float64 // persen dari subtotal setelah diskon
+	ExemptCategories  []int   // kategori yang tidak dikenakan pajak
+}
+
+// Validate dipanggil saat startup supaya konfigurasi yang salah langsung ketahuan
+func (c TaxConfig) Validate() error {
+	if c.Rate < 0 || c.Rate > 100 {
+		return fmt.Errorf("tax rate must be between 0 and 100, got %v", c.Rate)
+	}
+	if c.ServiceChargeRate < 0 || c.ServiceChargeRate > 100 {
+		return fmt.Errorf("service charge rate must be between 0 and 100, got %v", c.ServiceChargeRate)
+	}
+	return nil
+}
+
+// basisPoints mengubah persen ke basis poin (11.5% -> 1150) supaya hitungan tetap integer
+func basisPoints(rate float64) int {
+	return int(math.Round(rate * 100))
+}
+
+// roundDiv membagi bilangan non-negatif dengan pembulatan half-up
+func roundDiv(a, b int) int {
+	return (a + b/2) / b
+}
+
+// apply menghitung service charge dan pajak untuk transaksi yang details-nya
+// sudah berisi subtotal setelah diskon. Service charge dikenakan ke semua baris,
+// pajak dihitung dari subtotal + service charge baris yang kategorinya tidak
+// dikecualikan. Nilai dihitung di level transaksi lalu dibagi ke baris dengan
+// allocate, jadi jumlah per baris selalu sama dengan total transaksi.
+func (c TaxConfig) apply(trx *models.Transaction, products map[int]models.Product) {
+	exempt := map[int]bool{}
+	for _, id := range c.ExemptCategories {
+		exempt[id] = true
+	}
+
+	subtotals := make([]int, len(trx.Details))
+	subtotal := 0
+	for i, d := range trx.Details {
+		subtotals[i] = d.Subtotal
+		subtotal += d.Subtotal
+	}
+	for i, share := range allocate(roundDiv(subtotal*basisPoints(c.ServiceChargeRate), 10000), subtotals) {
+		trx.Details[i].ServiceCharge = share
+	}
+
+	bases := make([]int, len(trx.Details))
+	taxable := 0
+	for i, d := range trx.Details {
+		if !exempt[products[d.ProductID].CategoryID] {
+			bases[i] = d.Subtotal + d.ServiceCharge
+			taxable += bases[i]
+		}
+	}
+	rate := basisPoints(c.Rate)
+	tax := roundDiv(taxable*rate, 10000)
+	if c.Inclusive {
+		// Pajak diambil dari harga yang sudah termasuk pajak: base * rate / (100% + rate)
+		tax = roundDiv(taxable*rate, 10000+rate)
+	}
+	for i, share := range allocate(tax, bases) {
+		trx.Details[i].TaxAmount = share
+	}
+
+	trx.Subtotal, trx.ServiceCharge, trx.TaxAmount, trx.TotalAmount = 0, 0, 0, 0
+	trx.TaxInclusive = c.Inclusive
+	for i := range trx.Details {
+		d := &trx.Details[i]
+		d.Total = d.Subtotal + d.ServiceCharge
+		if !c.Inclusive {
+			d.Total += d.TaxAmount
+		}
+		trx.Subtotal += d.Subtotal
+		trx.ServiceCharge += d.ServiceCharge
+		trx.TaxAmount += d.TaxAmount
+		trx.TotalAmount += d.Total
+	}
+}
@@ -0,0 +1,131 @@
+package services_test
+
+import (
+	"testing"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+func TestCheckoutTax(t *testing.T) {
+	// teh 5000 (kategori default 1), kopi 8000 (kategori 2)
+	tests := []struct {
+		name          string
+		tax           services.TaxConfig
+		items         func(f *transactionFixture) []models.CheckoutItem
+		wantSubtotal  int
+		wantService   int
+		wantTax       int
+		wantTotal     int
+		wantLineTotal []int
+	}{
+		{
+			name: "no tax",
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}}
+			},
+			wantSubtotal: 10000, wantTotal: 10000, wantLineTotal: []int{10000},
+		},
+		{
+			name: "exclusive PPN 11%",
+			tax:  services.TaxConfig{Rate: 11},
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}}
+			},
+			wantSubtotal: 10000, wantTax: 1100, wantTotal: 11100, wantLineTotal: []int{11100},
+		},
+		{
+			name: "inclusive PPN 11%",
+			tax:  services.TaxConfig{Rate: 11, Inclusive: true},
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}}
+			},
+			wantSubtotal: 10000, wantTax: 991, wantTotal: 10000, wantLineTotal: []int{10000},
+		},
+		{
+			name: "service charge is taxed",
+			tax:  services.TaxConfig{Rate: 10, ServiceChargeRate: 5},
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.kopi.ID, Quantity: 1}}
+			},
+			wantSubtotal: 13000, wantService: 650, wantTax: 1365, wantTotal: 15015, wantLineTotal: []int{5775, 9240},
+		},
+		{
+			name: "exempt category",
+			tax:  services.TaxConfig{Rate: 11, ExemptCategories: []int{2}},
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.kopi.ID, Quantity: 1}}
+			},
+			wantSubtotal: 13000, wantTax: 550, wantTotal: 13550, wantLineTotal: []int{5550, 8000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransactionFixtureWithTax(t, tt.tax)
+			// kopi dipindah ke kategori 2 untuk test pengecualian pajak
+			sembako := models.Category{Name: "Sembako"}
+			if err := memory.NewCategoryRepository(f.store).Create(&sembako, 0); err != nil {
+				t.Fatal(err)
+			}
+			move := models.ProductUpdateRequest{Name: f.kopi.Name, Price: f.kopi.Price, Stock: f.kopi.Stock, CategoryID: sembako.ID}
+			if _, err := f.products.Update(f.kopi.ID, 0, move); err != nil {
+				t.Fatal(err)
+			}
+
+			trx, err := f.trx.Checkout(models.CheckoutRequest{Items: tt.items(f)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trx.Subtotal != tt.wantSubtotal || trx.ServiceCharge != tt.wantService || trx.TaxAmount != tt.wantTax || trx.TotalAmount != tt.wantTotal {
+				t.Errorf("subtotal/service/tax/total = %d/%d/%d/%d, want %d/%d/%d/%d", trx.Subtotal, trx.ServiceCharge, trx.TaxAmount, trx.TotalAmount,
+					tt.wantSubtotal, tt.wantService, tt.wantTax, tt.wantTotal)
+			}
+			if trx.TaxInclusive != tt.tax.Inclusive || trx.PaidAmount != tt.wantTotal {
+				t.Errorf("tax_inclusive/paid = %v/%d", trx.TaxInclusive, trx.PaidAmount)
+			}
+			for i, want := range tt.wantLineTotal {
+				if trx.Details[i].Total != want {
+					t.Errorf("line %d total = %d, want %d", i, trx.Details[i].Total, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRefundIncludesTax(t *testing.T) {
+	f := newTransactionFixtureWithTax(t, services.TaxConfig{Rate: 11})
+	sale, err := f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, _ := f.trx.GetByID(sale.ID)
+
+	refund, err := f.trx.Refund(sale.ID, models.RefundRequest{Reason: "rusak", Items: []models.RefundLine{{DetailID: full.Details[0].ID, Quantity: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.Amount != 5550 || refund.Items[0].TaxAmount != 550 {
+		t.Errorf("refund = %+v, want 5550 incl. 550 tax", refund)
+	}
+
+	report, err := f.trx.GetTodayReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report["total_pajak"] != 550 || report["total_revenue"] != 5550 {
+		t.Errorf("report = %v", report)
+	}
+}
+
+func TestTaxConfigValidate(t *testing.T) {
+	for _, c := range []services.TaxConfig{{Rate: -1}, {Rate: 101}, {ServiceChargeRate: -5}} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", c)
+		}
+	}
+	if err := (services.TaxConfig{Rate: 11, ServiceChargeRate: 5}).Validate(); err != nil {
+		t.Errorf("Validate(valid) = %v", err)
+	}
+}
@@ -1,37 +1,317 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
 	"kasir-api-golang-v1/models"
 	"kasir-api-golang-v1/repositories"
 )
 
-type TransactionService struct {
-	repo *repositories.TransactionRepository
+type transactionService struct {
+	repo          repositories.TransactionRepository
+	promoRepo     repositories.PromoCodeRepository
+	promotionRepo repositories.PromotionRepository
+	shiftRepo     repositories.ShiftRepository
+	tax           TaxConfig
+	alerts        *StockAlerter
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+// NewTransactionService: alerts boleh nil kalau alert low stock tidak dipakai
+func NewTransactionService(repo repositories.TransactionRepository, promoRepo repositories.PromoCodeRepository,
+	promotionRepo repositories.PromotionRepository, shiftRepo repositories.ShiftRepository, tax TaxConfig, alerts *StockAlerter) TransactionService {
+	return &transactionService{repo: repo, promoRepo: promoRepo, promotionRepo: promotionRepo, shiftRepo: shiftRepo, tax: tax, alerts: alerts}
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items)
+// openShift mengembalikan shift open milik kasir; tanpa shift kasir tidak boleh
+// menerima atau mengembalikan uang. cashierID 0 (tanpa login) tidak dicatat.
+func (s *transactionService) openShift(cashierID int) (*models.Shift, error) {
+	if cashierID == 0 {
+		return nil, nil
+	}
+	shift, err := s.shiftRepo.GetOpenByUser(cashierID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, NewConflictError("open a shift before handling transactions")
+	}
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	return shift, nil
 }
 
-// GetTodayReport untuk sales summary hari ini
-func (s *TransactionService) GetTodayReport() (map[string]interface{}, error) {
-	totalRevenue, totalTransaksi, err := s.repo.GetSalesToday()
+// prepareCheckout memvalidasi request dan menyiapkan pricing. Dipakai oleh
+// Checkout dan Preview supaya perhitungan keduanya selalu sama.
+func (s *transactionService) prepareCheckout(req models.CheckoutRequest) ([]models.CheckoutItem, checkoutPricing, error) {
+	// Validasi & gabungkan baris dengan produk yang sama
+	items, err := NormalizeCheckoutItems(req.Items)
+	if err != nil {
+		return nil, checkoutPricing{}, err
+	}
+	if fields := validateDiscount(req.Discount, "discount"); len(fields) > 0 {
+		return nil, checkoutPricing{}, NewValidationError("invalid discount", fields...)
+	}
+	if err := ValidatePayments(req.Payments); err != nil {
+		return nil, checkoutPricing{}, err
+	}
+
+	promotions, err := s.promotionRepo.GetAll(true)
+	if err != nil {
+		return nil, checkoutPricing{}, NewInternalError(err)
+	}
+
+	pricing := checkoutPricing{promotions: promotions, discount: req.Discount, payments: req.Payments, tax: s.tax, now: time.Now()}
+	if code := strings.ToUpper(strings.TrimSpace(req.PromoCode)); code != "" {
+		promo, err := s.promoRepo.GetByCode(code)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, checkoutPricing{}, NewValidationError("promo code cannot be used", FieldError{Field: "promo_code", Message: "promo code not found"})
+		}
+		if err != nil {
+			return nil, checkoutPricing{}, NewInternalError(err)
+		}
+		pricing.promo = promo
+	}
+	return items, pricing, nil
+}
+
+// checkoutError menerjemahkan error dari CreateTransaction / QuoteTransaction
+func checkoutError(err error) error {
+	var svcErr *Error
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+	if errors.Is(err, repositories.ErrPromoUnavailable) {
+		return &Error{Code: CodeConflict, Message: "promo code is no longer available", Err: err}
+	}
+	if errors.Is(err, repositories.ErrShiftClosed) {
+		return &Error{Code: CodeConflict, Message: "shift was closed, open a new shift", Err: err}
+	}
+	if errors.Is(err, repositories.ErrCartClosed) {
+		return &Error{Code: CodeConflict, Message: "cart is already checked out or cancelled", Err: err}
+	}
+	if errors.Is(err, repositories.ErrCartChanged) {
+		return &Error{Code: CodeConflict, Message: "cart changed during checkout, please try again", Err: err}
+	}
+	var stockErr *repositories.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		return NewInsufficientStockError(StockShortage{
+			ProductID:   stockErr.ProductID,
+			ProductName: stockErr.ProductName,
+			Available:   stockErr.Available,
+			Requested:   stockErr.Requested,
+		})
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
+		return &Error{Code: CodeValidation, Message: err.Error(), Err: err}
+	}
+	return NewInternalError(err)
+}
+
+func (s *transactionService) Checkout(req models.CheckoutRequest) (*models.Transaction, error) {
+	items, pricing, err := s.prepareCheckout(req)
+	if err != nil {
+		return nil, err
+	}
+	shift, err := s.openShift(req.CashierID)
 	if err != nil {
 		return nil, err
 	}
 
-	productName, qtySold, err := s.repo.GetTopProductToday()
+	// Harga dan pembayaran dihitung di dalam DB transaction, dengan harga yang sudah di-lock.
+	// Snapshot produk (stock sebelum dikurangi) disimpan untuk alert low stock.
+	var locked map[int]models.Product
+	price := func(items []models.CheckoutItem, products map[int]models.Product) (*models.Transaction, error) {
+		locked = products
+		trx, err := pricing.price(items, products)
+		if err == nil && shift != nil {
+			trx.CashierID, trx.ShiftID = &shift.UserID, &shift.ID
+		}
+		return trx, err
+	}
+	var transaction *models.Transaction
+	if req.CartID != 0 {
+		transaction, err = s.repo.CreateCartTransaction(req.CartID, items, price)
+	} else {
+		transaction, err = s.repo.CreateTransaction(items, price)
+	}
+	if err != nil {
+		return nil, checkoutError(err)
+	}
+	s.alerts.Enqueue(lowStockAlerts(items, locked, transaction)...)
+	return transaction, nil
+}
+
+// Preview menghitung transaksi persis seperti Checkout tanpa menyimpan apa pun
+// dan tanpa mengurangi stock. Stock kurang, promo code yang tidak bisa dipakai
+// dan pembayaran kurang dilaporkan sebagai warning, bukan error.
+func (s *transactionService) Preview(req models.CheckoutRequest) (*models.CheckoutPreview, error) {
+	items, pricing, err := s.prepareCheckout(req)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.CheckoutPreview{Warnings: []models.CheckoutWarning{}}
+	pricing.warnings = &preview.Warnings
+	transaction, err := s.repo.QuoteTransaction(items, pricing.price)
+	if err != nil {
+		return nil, checkoutError(err)
+	}
+	preview.Transaction = *transaction
+	return preview, nil
+}
+
+// Batas pagination untuk riwayat transaksi
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// validateDateRange memvalidasi filter tanggal opsional (YYYY-MM-DD, inklusif)
+func validateDateRange(startDate, endDate string) []FieldError {
+	var fields []FieldError
+	for _, d := range []struct{ field, value string }{{"start_date", startDate}, {"end_date", endDate}} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d.value); err != nil {
+			fields = append(fields, FieldError{Field: d.field, Message: "must be in YYYY-MM-DD format"})
+		}
+	}
+	if startDate != "" && endDate != "" && startDate > endDate {
+		fields = append(fields, FieldError{Field: "end_date", Message: "must not be before start_date"})
+	}
+	return fields
+}
+
+// validatePage memvalidasi pagination; 0 berarti default
+func validatePage(page, limit int) []FieldError {
+	var fields []FieldError
+	if page < 0 {
+		fields = append(fields, FieldError{Field: "page", Message: "must be positive"})
+	}
+	if limit < 0 || limit > MaxPageLimit {
+		fields = append(fields, FieldError{Field: "limit", Message: fmt.Sprintf("must be between 1 and %d", MaxPageLimit)})
+	}
+	return fields
+}
+
+// defaultPage mengisi page dan limit yang kosong
+func defaultPage(page, limit *int) {
+	if *page == 0 {
+		*page = 1
+	}
+	if *limit == 0 {
+		*limit = DefaultPageLimit
+	}
+}
+
+// List untuk riwayat transaksi, filter divalidasi dulu sebelum ke repository
+func (s *transactionService) List(filter models.TransactionFilter) (*models.TransactionPage, error) {
+	fields := validateDateRange(filter.StartDate, filter.EndDate)
+	if filter.MinTotal != nil && filter.MaxTotal != nil && *filter.MinTotal > *filter.MaxTotal {
+		fields = append(fields, FieldError{Field: "max_total", Message: "must not be less than min_total"})
+	}
+	fields = append(fields, validatePage(filter.Page, filter.Limit)...)
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid transaction filter", fields...)
+	}
+	defaultPage(&filter.Page, &filter.Limit)
+
+	transactions, total, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, fromRepo(err, "transaction")
+	}
+	return &models.TransactionPage{Data: transactions, Page: filter.Page, Limit: filter.Limit, Total: total}, nil
+}
+
+// GetByID untuk detail transaksi lengkap dengan item-nya
+func (s *transactionService) GetByID(id int) (*models.Transaction, error) {
+	transaction, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "transaction")
+	}
+	return transaction, nil
+}
+
+// Void membatalkan seluruh sisa transaksi dan mengembalikan semua stock. req.Items diabaikan.
+func (s *transactionService) Void(id int, req models.RefundRequest) (*models.Refund, error) {
+	return s.refund(id, models.RefundTypeVoid, req.Reason, nil, req.CashierID)
+}
+
+// Refund mengembalikan sebagian item (per TransactionDetail)
+func (s *transactionService) Refund(id int, req models.RefundRequest) (*models.Refund, error) {
+	if len(req.Items) == 0 {
+		return nil, NewValidationError("items cannot be empty", FieldError{Field: "items", Message: "at least one item is required"})
+	}
+	return s.refund(id, models.RefundTypePartial, req.Reason, req.Items, req.CashierID)
+}
+
+func (s *transactionService) refund(id int, refundType, reason string, lines []models.RefundLine, cashierID int) (*models.Refund, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, NewValidationError("reason is required", FieldError{Field: "reason", Message: "reason is required"})
+	}
+	shift, err := s.openShift(cashierID)
 	if err != nil {
 		return nil, err
 	}
 
+	refund, err := s.repo.CreateRefund(id, refundType, reason, lines, shift)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrTransactionVoided):
+			return nil, &Error{Code: CodeConflict, Message: "transaction already voided", Err: err}
+		case errors.Is(err, repositories.ErrShiftClosed):
+			return nil, &Error{Code: CodeConflict, Message: "shift was closed, open a new shift", Err: err}
+		case errors.Is(err, repositories.ErrInvalidRefund):
+			return nil, &Error{Code: CodeValidation, Message: err.Error(), Err: err}
+		}
+		return nil, fromRepo(err, "transaction")
+	}
+	return refund, nil
+}
+
+// GetTodayReport untuk sales summary hari ini
+func (s *transactionService) GetTodayReport() (map[string]interface{}, error) {
+	sales, err := s.repo.GetSalesToday()
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+
+	productName, qtySold, err := s.repo.GetTopProductToday()
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+
+	byMethod, err := s.repo.GetPaymentsToday()
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+
+	profit, err := s.repo.GetProfitToday("")
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	total := withProfit(profit[0])
+
 	report := map[string]interface{}{
-		"total_revenue":   totalRevenue,
-		"total_transaksi": totalTransaksi,
+		"total_revenue":        sales.TotalRevenue,
+		"total_transaksi":      sales.TotalTransaksi,
+		"gross_revenue":        sales.GrossRevenue,
+		"total_diskon":         sales.TotalDiscount,
+		"total_refund":         sales.TotalRefund,
+		"subtotal":             sales.Subtotal,
+		"total_service_charge": sales.TotalServiceCharge,
+		"total_pajak":          sales.TotalTax,
+		"net_sales":            total.NetSales,
+		"total_cogs":           total.COGS,
+		"unknown_cost_sales":   total.UnknownCostSales,
+		"gross_profit":         total.GrossProfit,
+		"gross_margin":         total.Margin,
+		"revenue_per_metode":   byMethod,
 	}
 
 	if productName != "" {
@@ -47,22 +327,45 @@ func (s *TransactionService) GetTodayReport() (map[string]interface{}, error) {
 }
 
 // GetRangeReport untuk sales summary dengan date range
-func (s *TransactionService) GetRangeReport(startDate, endDate string) (map[string]interface{}, error) {
-	totalRevenue, totalTransaksi, err := s.repo.GetSalesInRange(startDate, endDate)
+func (s *transactionService) GetRangeReport(startDate, endDate string) (map[string]interface{}, error) {
+	sales, err := s.repo.GetSalesInRange(startDate, endDate)
 	if err != nil {
-		return nil, err
+		return nil, NewInternalError(err)
 	}
 
 	productName, qtySold, err := s.repo.GetTopProductInRange(startDate, endDate)
 	if err != nil {
-		return nil, err
+		return nil, NewInternalError(err)
+	}
+
+	byMethod, err := s.repo.GetPaymentsInRange(startDate, endDate)
+	if err != nil {
+		return nil, NewInternalError(err)
 	}
 
+	profit, err := s.repo.GetProfitInRange(startDate, endDate, "")
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	total := withProfit(profit[0])
+
 	report := map[string]interface{}{
-		"total_revenue":   totalRevenue,
-		"total_transaksi": totalTransaksi,
-		"start_date":      startDate,
-		"end_date":        endDate,
+		"total_revenue":        sales.TotalRevenue,
+		"total_transaksi":      sales.TotalTransaksi,
+		"gross_revenue":        sales.GrossRevenue,
+		"total_diskon":         sales.TotalDiscount,
+		"total_refund":         sales.TotalRefund,
+		"subtotal":             sales.Subtotal,
+		"total_service_charge": sales.TotalServiceCharge,
+		"total_pajak":          sales.TotalTax,
+		"start_date":           startDate,
+		"end_date":             endDate,
+		"net_sales":            total.NetSales,
+		"total_cogs":           total.COGS,
+		"unknown_cost_sales":   total.UnknownCostSales,
+		"gross_profit":         total.GrossProfit,
+		"gross_margin":         total.Margin,
+		"revenue_per_metode":   byMethod,
 	}
 
 	if productName != "" {
@@ -76,3 +379,53 @@ func (s *TransactionService) GetRangeReport(startDate, endDate string) (map[stri
 
 	return report, nil
 }
+
+// withProfit mengisi laba kotor dan margin (persen, 2 desimal) dari net sales dan COGS.
+// Penjualan tanpa HPP tidak ikut dihitung supaya laba tidak terlihat lebih besar.
+func withProfit(l models.ProfitLine) models.ProfitLine {
+	costed := l.NetSales - l.UnknownCostSales
+	l.GrossProfit = costed - l.COGS
+	l.Margin = 0
+	if costed != 0 {
+		l.Margin = math.Round(float64(l.GrossProfit)*10000/float64(costed)) / 100
+	}
+	return l
+}
+
+// GetProfitReport untuk laporan laba kotor per produk, kategori atau periode
+func (s *transactionService) GetProfitReport(startDate, endDate, groupBy string) (*models.ProfitReport, error) {
+	var fields []FieldError
+	if startDate == "" {
+		fields = append(fields, FieldError{Field: "start_date", Message: "start_date is required"})
+	}
+	if endDate == "" {
+		fields = append(fields, FieldError{Field: "end_date", Message: "end_date is required"})
+	}
+	fields = append(fields, validateDateRange(startDate, endDate)...)
+	if groupBy != "" && !slices.Contains(models.ProfitGroups, groupBy) {
+		fields = append(fields, FieldError{Field: "group_by", Message: "must be one of " + strings.Join(models.ProfitGroups, ", ")})
+	}
+	if len(fields) > 0 {
+		return nil, NewValidationError("invalid profit report filter", fields...)
+	}
+
+	lines, err := s.repo.GetProfitInRange(startDate, endDate, groupBy)
+	if err != nil {
+		return nil, NewInternalError(err)
+	}
+	report := &models.ProfitReport{StartDate: startDate, EndDate: endDate, GroupBy: groupBy, Lines: []models.ProfitLine{}}
+	if groupBy == "" {
+		// Tanpa group_by repository mengembalikan satu baris total
+		report.Total = lines[0]
+	} else {
+		for _, l := range lines {
+			report.Lines = append(report.Lines, withProfit(l))
+			report.Total.Quantity += l.Quantity
+			report.Total.NetSales += l.NetSales
+			report.Total.COGS += l.COGS
+			report.Total.UnknownCostSales += l.UnknownCostSales
+		}
+	}
+	report.Total = withProfit(report.Total)
+	return report, nil
+}
@@ -0,0 +1,404 @@
+package services_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+	"kasir-api-golang-v1/repositories/memory"
+	"kasir-api-golang-v1/services"
+)
+
+type transactionFixture struct {
+	store    *memory.Store
+	products services.ProductService
+	trx      services.TransactionService
+	promos   services.PromoCodeService
+	rules    services.PromotionService
+	teh      models.Product
+	kopi     models.Product
+}
+
+func newTransactionFixture(t *testing.T) *transactionFixture {
+	t.Helper()
+	return newTransactionFixtureWithTax(t, services.TaxConfig{})
+}
+
+func newTransactionFixtureWithTax(t *testing.T, tax services.TaxConfig) *transactionFixture {
+	t.Helper()
+	store := memory.NewStore()
+	f := &transactionFixture{
+		store:    store,
+		products: services.NewProductService(memory.NewProductRepository(store), memory.NewStockMovementRepository(store)),
+		trx: services.NewTransactionService(memory.NewTransactionRepository(store), memory.NewPromoCodeRepository(store),
+			memory.NewPromotionRepository(store), memory.NewShiftRepository(store), tax, nil),
+		promos: services.NewPromoCodeService(memory.NewPromoCodeRepository(store)),
+		rules:  services.NewPromotionService(memory.NewPromotionRepository(store)),
+		teh:    models.Product{Name: "Teh", Price: 5000, Stock: 10},
+		kopi:   models.Product{Name: "Kopi", Price: 8000, Stock: 2},
+	}
+	if err := f.products.Create(&f.teh, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.products.Create(&f.kopi, 0); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *transactionFixture) stock(t *testing.T, id int) int {
+	t.Helper()
+	p, err := f.products.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.Stock
+}
+
+func TestTransactionServiceCheckout(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     func(f *transactionFixture) []models.CheckoutItem
+		wantErr   bool
+		wantTotal int
+		wantTeh   int
+		wantKopi  int
+	}{
+		{
+			name: "single item",
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 3}}
+			},
+			wantTotal: 15000, wantTeh: 7, wantKopi: 2,
+		},
+		{
+			name: "multiple items",
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.kopi.ID, Quantity: 2}}
+			},
+			wantTotal: 21000, wantTeh: 9, wantKopi: 0,
+		},
+		{
+			name: "insufficient stock rolls back everything",
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.kopi.ID, Quantity: 3}}
+			},
+			wantErr: true, wantTeh: 10, wantKopi: 2,
+		},
+		{
+			name: "unknown product",
+			items: func(f *transactionFixture) []models.CheckoutItem {
+				return []models.CheckoutItem{{ProductID: 999, Quantity: 1}}
+			},
+			wantErr: true, wantTeh: 10, wantKopi: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransactionFixture(t)
+			trx, err := f.trx.Checkout(models.CheckoutRequest{Items: tt.items(f)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Checkout err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && trx.TotalAmount != tt.wantTotal {
+				t.Errorf("total = %d, want %d", trx.TotalAmount, tt.wantTotal)
+			}
+			if got := f.stock(t, f.teh.ID); got != tt.wantTeh {
+				t.Errorf("teh stock = %d, want %d", got, tt.wantTeh)
+			}
+			if got := f.stock(t, f.kopi.ID); got != tt.wantKopi {
+				t.Errorf("kopi stock = %d, want %d", got, tt.wantKopi)
+			}
+		})
+	}
+}
+
+func TestTransactionServiceReports(t *testing.T) {
+	f := newTransactionFixture(t)
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	f.store.Now = func() time.Time { return yesterday }
+	if _, err := f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.kopi.ID, Quantity: 2}}}); err != nil {
+		t.Fatal(err)
+	}
+	f.store.Now = time.Now
+	if _, err := f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	tests := []struct {
+		name        string
+		report      func() (map[string]interface{}, error)
+		wantRevenue int
+		wantCount   int
+		wantTop     string
+	}{
+		{"today", f.trx.GetTodayReport, 5000, 1, "Teh"},
+		{"range including yesterday", func() (map[string]interface{}, error) {
+			return f.trx.GetRangeReport(yesterday.Format("2006-01-02"), today)
+		}, 21000, 2, "Kopi"},
+		{"empty range", func() (map[string]interface{}, error) {
+			return f.trx.GetRangeReport("2000-01-01", "2000-01-31")
+		}, 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := tt.report()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report["total_revenue"] != tt.wantRevenue {
+				t.Errorf("total_revenue = %v, want %d", report["total_revenue"], tt.wantRevenue)
+			}
+			if report["total_transaksi"] != tt.wantCount {
+				t.Errorf("total_transaksi = %v, want %d", report["total_transaksi"], tt.wantCount)
+			}
+
+			top, _ := report["produk_terlaris"].(map[string]interface{})
+			switch {
+			case tt.wantTop == "" && report["produk_terlaris"] != nil:
+				t.Errorf("produk_terlaris = %v, want nil", report["produk_terlaris"])
+			case tt.wantTop != "" && (top == nil || top["nama"] != tt.wantTop):
+				t.Errorf("produk_terlaris = %v, want %s", report["produk_terlaris"], tt.wantTop)
+			}
+		})
+	}
+}
+
+func TestTransactionServiceCheckoutErrorCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []models.CheckoutItem
+		wantCode services.ErrorCode
+	}{
+		{"empty items", nil, services.CodeValidation},
+		{"insufficient stock", []models.CheckoutItem{{ProductID: 2, Quantity: 5}}, services.CodeInsufficientStock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransactionFixture(t)
+			_, err := f.trx.Checkout(models.CheckoutRequest{Items: tt.items})
+			if got := services.AsError(err).Code; got != tt.wantCode {
+				t.Errorf("code = %s, want %s (err %v)", got, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestTransactionServicePreview(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          func(f *transactionFixture) models.CheckoutRequest
+		wantTotal    int
+		wantWarnings []string // field warning
+	}{
+		{
+			name: "matches checkout",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{
+					Items:    []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 3, Discount: &models.Discount{Type: "percent", Value: 10}}},
+					Discount: &models.Discount{Type: "fixed", Value: 500},
+				}
+			},
+			wantTotal: 13000,
+		},
+		{
+			name: "insufficient stock is a line warning",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}, {ProductID: f.kopi.ID, Quantity: 5}}}
+			},
+			wantTotal: 45000, wantWarnings: []string{"items[1].quantity"},
+		},
+		{
+			name: "unusable promo and underpayment are warnings",
+			req: func(f *transactionFixture) models.CheckoutRequest {
+				return models.CheckoutRequest{
+					Items:     []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}},
+					PromoCode: "BESAR",
+					Payments:  []models.CheckoutPayment{{Method: "cash", Amount: 1000}},
+				}
+			},
+			wantTotal: 5000, wantWarnings: []string{"promo_code", "payments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTransactionFixture(t)
+			if err := f.promos.Create(&models.PromoCode{Code: "BESAR", Type: "fixed", Value: 1000, MinSpend: 50000, Active: true}); err != nil {
+				t.Fatal(err)
+			}
+
+			preview, err := f.trx.Preview(tt.req(f))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields []string
+			for _, w := range preview.Warnings {
+				fields = append(fields, w.Field)
+			}
+			if preview.Transaction.TotalAmount != tt.wantTotal || !reflect.DeepEqual(fields, tt.wantWarnings) {
+				t.Errorf("preview total %d warnings %v, want %d %v", preview.Transaction.TotalAmount, fields, tt.wantTotal, tt.wantWarnings)
+			}
+			if f.stock(t, f.teh.ID) != 10 || f.stock(t, f.kopi.ID) != 2 {
+				t.Error("preview must not change stock")
+			}
+
+			// Tanpa warning, checkout dengan request yang sama harus menghasilkan angka yang sama
+			if len(tt.wantWarnings) > 0 {
+				return
+			}
+			trx, err := f.trx.Checkout(tt.req(f))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trx.TotalAmount != preview.Transaction.TotalAmount || trx.DiscountAmount != preview.Transaction.DiscountAmount {
+				t.Errorf("checkout %+v differs from preview %+v", trx, preview.Transaction)
+			}
+		})
+	}
+}
+
+func TestTransactionServicePreviewErrors(t *testing.T) {
+	f := newTransactionFixture(t)
+	tests := []struct {
+		name     string
+		req      models.CheckoutRequest
+		wantCode services.ErrorCode
+	}{
+		{"empty items", models.CheckoutRequest{}, services.CodeValidation},
+		{"unknown product", models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: 99, Quantity: 1}}}, services.CodeValidation},
+		{"unknown promo code", models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}}, PromoCode: "NOPE"}, services.CodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.trx.Preview(tt.req)
+			if got := services.AsError(err).Code; got != tt.wantCode {
+				t.Errorf("code = %s, want %s (err %v)", got, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestTransactionServiceList(t *testing.T) {
+	f := newTransactionFixture(t)
+	for i := 0; i < 3; i++ {
+		if _, err := f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 1}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	intPtr := func(v int) *int { return &v }
+	tests := []struct {
+		name      string
+		filter    models.TransactionFilter
+		wantCode  services.ErrorCode
+		wantRows  int
+		wantLimit int
+	}{
+		{"defaults", models.TransactionFilter{}, "", 3, services.DefaultPageLimit},
+		{"custom limit", models.TransactionFilter{Limit: 2}, "", 2, 2},
+		{"bad date", models.TransactionFilter{StartDate: "18-10-2026"}, services.CodeValidation, 0, 0},
+		{"reversed dates", models.TransactionFilter{StartDate: "2026-02-01", EndDate: "2026-01-01"}, services.CodeValidation, 0, 0},
+		{"min above max", models.TransactionFilter{MinTotal: intPtr(10), MaxTotal: intPtr(5)}, services.CodeValidation, 0, 0},
+		{"limit too large", models.TransactionFilter{Limit: services.MaxPageLimit + 1}, services.CodeValidation, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := f.trx.List(tt.filter)
+			if tt.wantCode != "" {
+				if got := services.AsError(err).Code; got != tt.wantCode {
+					t.Fatalf("code = %s, want %s", got, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Data) != tt.wantRows || page.Limit != tt.wantLimit || page.Page != 1 || page.Total != 3 {
+				t.Errorf("page = %+v", page)
+			}
+		})
+	}
+
+	if _, err := f.trx.GetByID(999); services.AsError(err).Code != services.CodeNotFound {
+		t.Errorf("GetByID(999) err = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestTransactionServiceRefund(t *testing.T) {
+	f := newTransactionFixture(t)
+	sale, err := f.trx.Checkout(models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: f.teh.ID, Quantity: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, _ := f.trx.GetByID(sale.ID)
+	line := receipt.Details[0].ID
+
+	tests := []struct {
+		name     string
+		run      func() error
+		wantCode services.ErrorCode
+	}{
+		{"refund requires reason", func() error {
+			_, err := f.trx.Refund(sale.ID, models.RefundRequest{Items: []models.RefundLine{{DetailID: line, Quantity: 1}}})
+			return err
+		}, services.CodeValidation},
+		{"refund requires items", func() error {
+			_, err := f.trx.Refund(sale.ID, models.RefundRequest{Reason: "rusak"})
+			return err
+		}, services.CodeValidation},
+		{"refund over quantity", func() error {
+			_, err := f.trx.Refund(sale.ID, models.RefundRequest{Reason: "rusak", Items: []models.RefundLine{{DetailID: line, Quantity: 3}}})
+			return err
+		}, services.CodeValidation},
+		{"void missing transaction", func() error { _, err := f.trx.Void(999, models.RefundRequest{Reason: "batal"}); return err }, services.CodeNotFound},
+		{"void", func() error { _, err := f.trx.Void(sale.ID, models.RefundRequest{Reason: "batal"}); return err }, ""},
+		{"void twice", func() error { _, err := f.trx.Void(sale.ID, models.RefundRequest{Reason: "batal"}); return err }, services.CodeConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if got := services.AsError(err).Code; got != tt.wantCode {
+				t.Errorf("code = %s, want %s (err %v)", got, tt.wantCode, err)
+			}
+		})
+	}
+
+	if got := f.stock(t, f.teh.ID); got != 10 {
+		t.Errorf("teh stock after void = %d, want 10", got)
+	}
+}
+
+// historicalProfitRepo mengembalikan penjualan lama yang HPP-nya tidak diketahui
+type historicalProfitRepo struct {
+	repositories.TransactionRepository
+}
+
+func (historicalProfitRepo) GetProfitInRange(startDate, endDate, groupBy string) ([]models.ProfitLine, error) {
+	return []models.ProfitLine{{ID: 1, Name: "Teh", Quantity: 3, NetSales: 15000, COGS: 3000, UnknownCostSales: 10000}}, nil
+}
+
+func TestProfitReportExcludesUnknownCost(t *testing.T) {
+	svc := services.NewTransactionService(historicalProfitRepo{}, nil, nil, nil, services.TaxConfig{}, nil)
+	report, err := svc.GetProfitReport("2026-01-01", "2026-01-31", models.ProfitGroupProduct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Laba dan margin hanya dari penjualan 5000 yang HPP-nya diketahui
+	want := models.ProfitLine{Quantity: 3, NetSales: 15000, COGS: 3000, UnknownCostSales: 10000, GrossProfit: 2000, Margin: 40}
+	if report.Total != want || report.Lines[0].GrossProfit != 2000 {
+		t.Errorf("report = %+v, want total %+v", report, want)
+	}
+}
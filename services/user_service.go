@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"kasir-api-golang-v1/models"
+	"kasir-api-golang-v1/repositories"
+)
+
+type userService struct {
+	repo     repositories.UserRepository
+	sessions repositories.SessionRepository
+	roles    repositories.RoleRepository
+	hasher   PasswordHasher
+}
+
+func NewUserService(repo repositories.UserRepository, sessions repositories.SessionRepository, roles repositories.RoleRepository, hasher PasswordHasher) UserService {
+	return &userService{repo: repo, sessions: sessions, roles: roles, hasher: hasher}
+}
+
+// validateUser menormalkan username (trim + huruf kecil) lalu memvalidasi field.
+// Password wajib saat create, saat update boleh kosong (tidak diganti).
+func validateUser(user *models.User, requirePassword bool) error {
+	user.Username = strings.ToLower(strings.TrimSpace(user.Username))
+	user.Name = strings.TrimSpace(user.Name)
+
+	var fields []FieldError
+	switch {
+	case user.Username == "":
+		fields = append(fields, FieldError{Field: "username", Message: "username is required"})
+	case len(user.Username) > 50 || strings.ContainsAny(user.Username, " \t\n"):
+		fields = append(fields, FieldError{Field: "username", Message: "must be at most 50 characters without spaces"})
+	}
+	if len(user.Name) > 100 {
+		fields = append(fields, FieldError{Field: "name", Message: "must be at most 100 characters"})
+	}
+	switch {
+	case user.Password == "" && requirePassword:
+		fields = append(fields, FieldError{Field: "password", Message: "password is required"})
+	case user.Password != "" && len(user.Password) < minPasswordLength:
+		fields = append(fields, FieldError{Field: "password", Message: "must be at least 8 characters"})
+	}
+	if len(fields) > 0 {
+		return NewValidationError("invalid user", fields...)
+	}
+	return nil
+}
+
+// setPassword meng-hash Password ke PasswordHash lalu mengosongkan Password
+// supaya tidak ikut terkirim di response
+func (s *userService) setPassword(user *models.User) error {
+	if user.Password == "" {
+		user.PasswordHash = ""
+		return nil
+	}
+	hash, err := s.hasher.Hash(user.Password)
+	if err != nil {
+		return NewInternalError(err)
+	}
+	user.PasswordHash, user.Password = hash, ""
+	return nil
+}
+
+func (s *userService) GetAll() ([]models.User, error) {
+	users, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fromRepo(err, "user")
+	}
+	return users, nil
+}
+
+func (s *userService) GetByID(id int) (*models.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, fromRepo(err, "user")
+	}
+	return user, nil
+}
+
+func (s *userService) Create(user *models.User) error {
+	if err := validateUser(user, true); err != nil {
+		return err
+	}
+	if err := s.setPassword(user); err != nil {
+		return err
+	}
+	if err := s.repo.Create(user); err != nil {
+		return fromRepo(err, "user")
+	}
+	return nil
+}
+
+func (s *userService) Update(user *models.User) error {
+	if err := validateUser(user, false); err != nil {
+		return err
+	}
+	passwordChanged := user.Password != ""
+	if err := s.setPassword(user); err != nil {
+		return err
+	}
+	if err := s.repo.Update(user); err != nil {
+		return fromRepo(err, "user")
+	}
+	if passwordChanged || !user.Active {
+		if err := s.sessions.DeleteByUser(user.ID); err != nil {
+			return NewInternalError(err)
+		}
+	}
+	return nil
+}
+
+// Delete menghapus user; token login dan role-nya ikut dihapus
+func (s *userService) Delete(id int) error {
+	if err := s.repo.Delete(id); err != nil {
+		return fromRepo(err, "user")
+	}
+	return nil
+}
+
+func (s *userService) EnsureAdmin(username, password string) (bool, error) {
+	n, err := s.repo.Count()
+	if err != nil {
+		return false, NewInternalError(err)
+	}
+	if n > 0 {
+		return false, nil
+	}
+	user := models.User{Username: username, Name: "Administrator", Password: password, Active: true}
+	if err := s.Create(&user); err != nil {
+		return false, err
+	}
+	owner, err := roleByName(s.roles, models.RoleOwner)
+	if err != nil || owner == nil {
+		return false, NewInternalError(fmt.Errorf("owner role not found: %v", err))
+	}
+	if err := s.roles.SetUserRoles(user.ID, []int{owner.ID}); err != nil {
+		return false, NewInternalError(err)
+	}
+	return true, nil
+}